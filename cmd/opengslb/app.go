@@ -13,6 +13,7 @@ import (
 	"net/netip"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,7 +26,9 @@ import (
 	"github.com/loganrossus/OpenGSLB/pkg/health"
 	"github.com/loganrossus/OpenGSLB/pkg/metrics"
 	"github.com/loganrossus/OpenGSLB/pkg/overwatch"
+	"github.com/loganrossus/OpenGSLB/pkg/querylog"
 	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/loganrossus/OpenGSLB/pkg/schedule"
 	"github.com/loganrossus/OpenGSLB/pkg/store"
 	"github.com/loganrossus/OpenGSLB/pkg/version"
 )
@@ -41,16 +44,21 @@ type Application struct {
 	dnsServer     *dns.Server
 	dnsHandler    *dns.Handler
 	dnsRegistry   *dns.Registry
+	dnsKeyring    *dns.Keyring
+	queryLog      *querylog.Logger
 	healthManager *health.Manager
 	metricsServer *metrics.Server
 	apiServer     *api.Server
 
 	// Overwatch mode components (Story 3)
 	backendRegistry     *overwatch.Registry
+	loadProvider        routing.LoadProvider // Agent-reported load from backendRegistry
 	overwatchValidator  *overwatch.Validator
 	gossipHandler       *overwatch.GossipHandler
 	gossipReceiver      *gossip.MemberlistReceiver
 	overwatchStore      store.Store
+	auditLog            *api.AuditLog
+	scheduler           *schedule.Scheduler
 	learnedLatencyTable *overwatch.LearnedLatencyTable // ADR-017: Passive latency learning
 
 	// Agent mode components (Story 2)
@@ -208,6 +216,11 @@ func (a *Application) initializeOverwatchMode() error {
 		return fmt.Errorf("failed to initialize DNS server: %w", err)
 	}
 
+	// Initialize scheduled weight profiles
+	if err := a.initializeScheduler(); err != nil {
+		return fmt.Errorf("failed to initialize scheduler: %w", err)
+	}
+
 	// Initialize metrics server
 	if err := a.initializeMetricsServer(); err != nil {
 		return fmt.Errorf("failed to initialize metrics server: %w", err)
@@ -334,7 +347,18 @@ func (a *Application) initializeGeoResolver() error {
 		"default_region", geoCfg.DefaultRegion,
 		"custom_mappings", len(geoCfg.CustomMappings),
 		"regions", len(a.config.Regions),
+		"client_coordinates", resolver.HasLocation(),
 	)
+	if !resolver.HasLocation() {
+		for _, domain := range a.config.Domains {
+			if strings.EqualFold(domain.RoutingAlgorithm, routing.AlgorithmGeoProximity) {
+				a.logger.Warn("geoproximity routing needs a City database; clients outside custom mappings get the default region",
+					"domain", domain.Name,
+					"database_path", geoCfg.DatabasePath,
+				)
+			}
+		}
+	}
 	return nil
 }
 
@@ -377,6 +401,12 @@ func (a *Application) initializeBackendRegistry() error {
 	}
 
 	a.backendRegistry = overwatch.NewRegistry(registryCfg, a.overwatchStore)
+	a.loadProvider = &backendRegistryLoadProvider{registry: a.backendRegistry}
+
+	a.auditLog = api.NewAuditLog(a.overwatchStore, a.logger)
+	if err := a.auditLog.Load(context.Background()); err != nil {
+		a.logger.Warn("failed to load audit log", "error", err)
+	}
 
 	// Set up status change callback for metrics AND DNS registration
 	// NOTE: This callback runs while holding the registry's write lock.
@@ -458,6 +488,7 @@ func (a *Application) registerStaticServers() error {
 				return fmt.Errorf("failed to register static server %s:%d: %w",
 					server.Address, server.Port, err)
 			}
+			a.backendRegistry.UpdateLabels(server.Service, server.Address, server.Port, server.Labels)
 			staticCount++
 		}
 	}
@@ -566,10 +597,11 @@ func (a *Application) initializeDNSServer() error {
 		LearnedLatencyProvider: learnedLatencyProvider, // ADR-017: Passive latency learning
 		MinLatencySamples:      1,                      // Backend registry tracks latency from external validation
 		GeoResolver:            a.geoResolver,          // Demo 4: GeoIP-based routing
+		LoadProvider:           a.loadProvider,
 		Logger:                 a.logger,
 	})
 
-	registry, err := dns.BuildRegistry(a.config, routerFactory.NewRouter)
+	registry, err := dns.BuildRegistry(a.config, routerFactory.NewRouter, routerFactory.NewPipeline)
 	if err != nil {
 		return fmt.Errorf("failed to build DNS registry: %w", err)
 	}
@@ -604,27 +636,101 @@ func (a *Application) initializeDNSServer() error {
 		logger:          a.logger,
 	}
 
+	zones, err := dns.BuildZones(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS zones: %w", err)
+	}
+	for _, zone := range zones {
+		a.logger.Info("zone configured",
+			"zone", zone.Name,
+			"serial", zone.SOA.Serial,
+			"nameservers", len(zone.Nameservers),
+		)
+	}
+
+	tsigKeys, err := dns.BuildTSIGKeys(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to build TSIG keys: %w", err)
+	}
+	a.dnsKeyring = dns.NewKeyring(tsigKeys)
+
+	transfer, err := dns.BuildTransferSettings(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to build zone transfer settings: %w", err)
+	}
+
+	rateLimiter, err := dns.BuildRateLimiter(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS rate limiter: %w", err)
+	}
+
+	views, err := dns.BuildViews(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS views: %w", err)
+	}
+
+	forwarders, err := dns.BuildForwarders(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS fallthrough upstreams: %w", err)
+	}
+
+	queryLog, err := querylog.Build(a.config.DNS.QueryLog, a.logger)
+	if err != nil {
+		return fmt.Errorf("failed to build query log: %w", err)
+	}
+	a.queryLog = queryLog
+
 	handler := dns.NewHandler(dns.HandlerConfig{
 		Registry:       registry,
 		HealthProvider: healthProvider,
 		LeaderChecker:  nil, // Standalone mode - always serve
 		DefaultTTL:     uint32(a.config.DNS.DefaultTTL),
+		Zones:          zones,
 		ECSEnabled:     a.config.Overwatch.Geolocation.ECSEnabled, // Demo 4: EDNS Client Subnet for GeoIP
 		Logger:         a.logger,
+
+		ReturnLastHealthy: a.config.DNS.ReturnLastHealthy,
+		LastHealthyTTL:    uint32(a.config.DNS.LastHealthyTTL),
+		MaxUDPSize:        uint16(a.config.DNS.MaxUDPSize),
+
+		Keyring:    a.dnsKeyring,
+		Transfer:   transfer,
+		Updater:    a.dnsUpdater(a.config),
+		UpdateKeys: a.config.DNS.Update.TSIGKeys,
+
+		RateLimiter: rateLimiter,
+		Views:       views,
+		QueryLog:    queryLog,
+		Forwarders:  forwarders,
+
+		PanicListener: a.recordPanicEvent,
 	})
 	a.dnsHandler = handler
 	a.logger.Debug("DNS handler created with registry",
 		"dns_registry_ptr", fmt.Sprintf("%p", registry),
 	)
 
-	a.dnsServer = dns.NewServer(dns.ServerConfig{
-		Address: a.config.DNS.ListenAddress,
-		Handler: handler,
-		Logger:  a.logger,
-	})
+	serverCfg := dns.ServerConfig{
+		Address:  a.config.DNS.ListenAddress,
+		Handler:  handler,
+		Logger:   a.logger,
+		DoHPath:  a.config.DNS.DoH.Path,
+		CertFile: a.config.DNS.TLS.CertFile,
+		KeyFile:  a.config.DNS.TLS.KeyFile,
+		Keyring:  a.dnsKeyring,
+	}
+	if a.config.DNS.DoT.Enabled {
+		serverCfg.DoTAddress = a.config.DNS.DoT.ListenAddress
+	}
+	if a.config.DNS.DoH.Enabled {
+		serverCfg.DoHAddress = a.config.DNS.DoH.ListenAddress
+	}
+	a.dnsServer = dns.NewServer(serverCfg)
 
 	a.logger.Info("DNS server initialized",
 		"address", a.config.DNS.ListenAddress,
+		"dot_address", serverCfg.DoTAddress,
+		"doh_address", serverCfg.DoHAddress,
 		"domains", registry.Count(),
 	)
 	return nil
@@ -651,6 +757,26 @@ func (a *Application) initializeMetricsServer() error {
 	return nil
 }
 
+// initializeScheduler creates the scheduler switching domains between
+// weight profiles, with the schedules of the configuration and the store.
+func (a *Application) initializeScheduler() error {
+	a.scheduler = schedule.New(schedule.Config{
+		Store:    a.overwatchStore,
+		Apply:    a.applyWeightProfile,
+		OnSwitch: a.recordScheduleSwitch,
+		Logger:   a.logger,
+	})
+	if err := a.scheduler.SetConfigSchedules(configSchedules(a.config)); err != nil {
+		return err
+	}
+	if err := a.scheduler.Load(context.Background()); err != nil {
+		return err
+	}
+
+	a.logger.Info("scheduler initialized", "schedules", len(a.scheduler.List()))
+	return nil
+}
+
 // initializeAPIServer creates and configures the API server.
 func (a *Application) initializeAPIServer() error {
 	if !a.config.API.Enabled {
@@ -699,6 +825,9 @@ func (a *Application) initializeAPIServer() error {
 		if a.overwatchStore != nil {
 			domainProvider.SetStore(a.overwatchStore)
 		}
+		if a.scheduler != nil {
+			domainProvider.SetScheduleProvider(a.scheduler)
+		}
 		// Wire DNS registry for dynamic domain registration
 		if a.dnsRegistry != nil {
 			domainProvider.SetDNSRegistry(a.dnsRegistry)
@@ -706,6 +835,7 @@ func (a *Application) initializeAPIServer() error {
 			latencyProvider := &backendRegistryLatencyProvider{registry: a.backendRegistry}
 			routerFactory := routing.NewFactory(routing.FactoryConfig{
 				LatencyProvider:   latencyProvider,
+				LoadProvider:      a.loadProvider,
 				MinLatencySamples: 1,
 				GeoResolver:       a.geoResolver,
 				Logger:            a.logger,
@@ -752,8 +882,7 @@ func (a *Application) initializeAPIServer() error {
 		a.logger.Debug("gossip API handlers registered")
 
 		// Audit handlers - provides audit log information
-		auditProvider := api.NewStubAuditProvider(a.logger)
-		server.SetAuditHandlers(api.NewAuditHandlers(auditProvider, a.logger))
+		server.SetAuditHandlers(api.NewAuditHandlers(a.auditLog, a.logger))
 		a.logger.Debug("audit API handlers registered")
 
 		// Metrics handlers - provides system metrics
@@ -773,10 +902,21 @@ func (a *Application) initializeAPIServer() error {
 		a.logger.Debug("config API handlers registered")
 
 		// Routing handlers - provides routing algorithm information
-		routingProvider := api.NewStubRoutingProvider(a.logger)
+		stubRoutingProvider := api.NewStubRoutingProvider(a.logger)
+		var routingProvider api.RoutingProvider = stubRoutingProvider
+		if a.dnsHandler != nil {
+			// Routing tests resolve through the DNS handler and its views
+			routingProvider = newDNSRoutingProvider(stubRoutingProvider, a.dnsHandler)
+		}
 		server.SetRoutingHandlers(api.NewRoutingHandlers(routingProvider, a.logger))
 		a.logger.Debug("routing API handlers registered")
 
+		// Schedule handlers - scheduled weight profiles and their previews
+		if a.scheduler != nil && a.dnsRegistry != nil {
+			server.SetScheduleHandlers(api.NewScheduleHandlers(newScheduleProvider(a.scheduler, a.dnsRegistry), a.logger))
+			a.logger.Debug("schedule API handlers registered")
+		}
+
 		// Override handlers - provides health override management
 		overrideManager := api.NewOverrideManager(a.overwatchStore, a.logger)
 		server.SetOverrideHandlers(api.NewOverrideHandlers(overrideManager, a.logger))
@@ -865,6 +1005,12 @@ func (a *Application) startOverwatchMode(ctx context.Context) error {
 		a.logger.Info("external validator started")
 	}
 
+	// Start switching scheduled weight profiles
+	if a.scheduler != nil {
+		a.scheduler.Start()
+		a.logger.Info("scheduler started")
+	}
+
 	// Start gossip receiver and handler
 	if a.gossipReceiver != nil {
 		if err := a.gossipReceiver.Start(ctx); err != nil {
@@ -909,6 +1055,15 @@ func (a *Application) startOverwatchMode(ctx context.Context) error {
 		}()
 	}
 
+	// Follow health changes into and out of panic mode and record them
+	// without holding up queries
+	go a.dnsHandler.WatchPanicMode(ctx, 0)
+
+	// Track zone contents so serials follow registry and health changes
+	if len(a.config.DNS.Zones) > 0 {
+		go a.dnsHandler.WatchZones(ctx, a.config.DNS.Transfer.CheckInterval)
+	}
+
 	// Start DNS server (blocks until shutdown)
 	a.logger.Info("starting DNS server", "address", a.config.DNS.ListenAddress)
 	if err := a.dnsServer.Start(ctx); err != nil {
@@ -979,6 +1134,11 @@ func (a *Application) shutdownAgentMode(ctx context.Context) error {
 func (a *Application) shutdownOverwatchMode(ctx context.Context) error {
 	var shutdownErr error
 
+	if a.scheduler != nil {
+		a.logger.Debug("stopping scheduler")
+		a.scheduler.Stop()
+	}
+
 	// Stop gossip handler first
 	if a.gossipHandler != nil {
 		a.logger.Debug("stopping gossip handler")
@@ -1043,6 +1203,7 @@ func (a *Application) shutdownOverwatchMode(ctx context.Context) error {
 	}
 
 	// DNS server shuts down when context is canceled
+	a.closeQueryLog()
 
 	return shutdownErr
 }
@@ -1063,6 +1224,9 @@ func (a *Application) Reload(newCfg *config.Config) error {
 	if oldCfg.DNS.ListenAddress != newCfg.DNS.ListenAddress {
 		a.logger.Warn("DNS listen address change requires restart")
 	}
+	if oldCfg.DNS.MaxUDPSize != newCfg.DNS.MaxUDPSize {
+		a.logger.Warn("DNS max_udp_size change requires restart")
+	}
 
 	// Mode-specific reload
 	switch a.config.GetEffectiveMode() {
@@ -1178,6 +1342,11 @@ func (a *Application) reloadAgentMode(newCfg *config.Config) error {
 
 // reloadOverwatchMode reloads overwatch-specific configuration.
 func (a *Application) reloadOverwatchMode(newCfg *config.Config) error {
+	// Region countries, coordinates and biases are read at routing time
+	if a.geoResolver != nil {
+		a.geoResolver.ReloadRegions(newCfg.Regions)
+	}
+
 	if err := a.reloadDNSRegistry(newCfg); err != nil {
 		return fmt.Errorf("failed to reload DNS registry: %w", err)
 	}
@@ -1186,6 +1355,20 @@ func (a *Application) reloadOverwatchMode(newCfg *config.Config) error {
 		return fmt.Errorf("failed to reload health manager: %w", err)
 	}
 
+	if a.scheduler != nil {
+		if err := a.scheduler.SetConfigSchedules(configSchedules(newCfg)); err != nil {
+			return fmt.Errorf("failed to reload schedules: %w", err)
+		}
+	}
+
+	// Renewed DoT/DoH certificates are picked up without restarting any
+	// listener; changing listen addresses still requires a restart.
+	if a.dnsServer != nil {
+		if err := a.dnsServer.ReloadCertificate(newCfg.DNS.TLS.CertFile, newCfg.DNS.TLS.KeyFile); err != nil {
+			return fmt.Errorf("failed to reload DNS TLS certificate: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -1195,25 +1378,170 @@ func (a *Application) reloadDNSRegistry(newCfg *config.Config) error {
 	latencyProvider := &backendRegistryLatencyProvider{registry: a.backendRegistry}
 	routerFactory := routing.NewFactory(routing.FactoryConfig{
 		LatencyProvider:   latencyProvider,
+		LoadProvider:      a.loadProvider,
 		MinLatencySamples: 1,             // Backend registry tracks latency from external validation
 		GeoResolver:       a.geoResolver, // Demo 4: GeoIP-based routing
 		Logger:            a.logger,
 	})
 
-	newRegistry, err := dns.BuildRegistry(newCfg, routerFactory.NewRouter)
+	newRegistry, err := dns.BuildRegistry(newCfg, routerFactory.NewRouter, routerFactory.NewPipeline)
 	if err != nil {
 		return fmt.Errorf("failed to build new registry: %w", err)
 	}
 
+	a.dnsRegistry.ReplaceAll(registryEntries(newRegistry))
+
+	// View registries fall back to the live registry for domains they do not override
+	viewRegistries := make(map[string]*dns.Registry)
+	for name, view := range newRegistry.Views() {
+		viewRegistries[name] = dns.NewViewRegistry(a.dnsRegistry, registryEntries(view))
+	}
+	a.dnsRegistry.SetViews(viewRegistries)
+
+	zones, err := dns.BuildZones(newCfg)
+	if err != nil {
+		return fmt.Errorf("failed to build zones: %w", err)
+	}
+	if a.dnsHandler != nil {
+		a.dnsHandler.SetZones(zones)
+	}
+
+	tsigKeys, err := dns.BuildTSIGKeys(newCfg)
+	if err != nil {
+		return fmt.Errorf("failed to build TSIG keys: %w", err)
+	}
+	if a.dnsKeyring != nil {
+		a.dnsKeyring.SetKeys(tsigKeys)
+	}
+	transfer, err := dns.BuildTransferSettings(newCfg)
+	if err != nil {
+		return fmt.Errorf("failed to build zone transfer settings: %w", err)
+	}
+	if a.dnsHandler != nil {
+		a.dnsHandler.SetTransfer(transfer)
+		a.dnsHandler.SetUpdate(a.dnsUpdater(newCfg), newCfg.DNS.Update.TSIGKeys)
+	}
+	rateLimiter, err := dns.BuildRateLimiter(newCfg)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS rate limiter: %w", err)
+	}
+	if a.dnsHandler != nil {
+		a.dnsHandler.SetRateLimiter(rateLimiter)
+	}
+	views, err := dns.BuildViews(newCfg)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS views: %w", err)
+	}
+	if a.dnsHandler != nil {
+		a.dnsHandler.SetViews(views)
+	}
+	forwarders, err := dns.BuildForwarders(newCfg)
+	if err != nil {
+		return fmt.Errorf("failed to build DNS fallthrough upstreams: %w", err)
+	}
+	if a.dnsHandler != nil {
+		a.dnsHandler.SetForwarders(forwarders)
+	}
+	if newCfg.DNS.QueryLog != a.config.DNS.QueryLog && a.dnsHandler != nil {
+		// Outputs are only reopened when their settings change
+		queryLog, err := querylog.Build(newCfg.DNS.QueryLog, a.logger)
+		if err != nil {
+			return fmt.Errorf("failed to build query log: %w", err)
+		}
+		a.dnsHandler.SetQueryLog(queryLog)
+		a.closeQueryLog()
+		a.queryLog = queryLog
+	}
+	return nil
+}
+
+// closeQueryLog writes the queued query log entries and closes its outputs.
+func (a *Application) closeQueryLog() {
+	if a.queryLog == nil {
+		return
+	}
+	if err := a.queryLog.Close(); err != nil {
+		a.logger.Warn("error closing query log", "error", err)
+	}
+	a.queryLog = nil
+}
+
+// registryEntries returns the domain entries held by a registry.
+func registryEntries(registry *dns.Registry) []*dns.DomainEntry {
 	var entries []*dns.DomainEntry
-	for _, name := range newRegistry.Domains() {
-		if entry := newRegistry.Lookup(name); entry != nil {
+	for _, name := range registry.Domains() {
+		if entry := registry.Lookup(name); entry != nil {
 			entries = append(entries, entry)
 		}
 	}
+	return entries
+}
 
-	a.dnsRegistry.ReplaceAll(entries)
-	return nil
+// dnsUpdater returns the backend updater for dynamic DNS updates, or nil
+// when no update keys are configured or there is no backend registry.
+func (a *Application) dnsUpdater(cfg *config.Config) dns.BackendUpdater {
+	if a.backendRegistry == nil || len(cfg.DNS.Update.TSIGKeys) == 0 {
+		return nil
+	}
+	return newDNSUpdateBackendProvider(a.backendRegistry, a.auditLog, cfg.DNS.Update, a.logger)
+}
+
+// recordPanicEvent records a domain entering or leaving panic mode in the
+// audit log.
+func (a *Application) recordPanicEvent(event dns.PanicEvent) {
+	if a.auditLog == nil {
+		return
+	}
+	action := "panic_mode_exited"
+	if event.Active {
+		action = "panic_mode_entered"
+	}
+	details := map[string]interface{}{
+		"family":            event.Family,
+		"healthy":           event.Healthy,
+		"total":             event.Total,
+		"threshold_percent": event.Threshold,
+	}
+	if event.View != "" {
+		details["view"] = event.View
+	}
+	if err := a.auditLog.Record(context.Background(), api.AuditEntry{
+		Action:     action,
+		Resource:   "domain",
+		ResourceID: event.Domain,
+		Details:    details,
+	}); err != nil {
+		a.logger.Warn("failed to record panic mode audit event", "domain", event.Domain, "error", err)
+	}
+}
+
+// applyWeightProfile applies the active profile of a scheduled domain to its
+// DNS answers; a nil profile restores the configured weights.
+func (a *Application) applyWeightProfile(domain string, profile *schedule.Profile) {
+	if a.dnsHandler == nil {
+		return
+	}
+	a.dnsHandler.SetWeightProfile(domain, weightProfile(profile))
+}
+
+// recordScheduleSwitch records a scheduled domain switching profile in the
+// audit log.
+func (a *Application) recordScheduleSwitch(change schedule.Switch) {
+	if a.auditLog == nil {
+		return
+	}
+	if err := a.auditLog.Record(context.Background(), api.AuditEntry{
+		Action:     "schedule_profile_switched",
+		Resource:   "domain",
+		ResourceID: change.Domain,
+		Details: map[string]interface{}{
+			"schedule": change.Schedule,
+			"from":     change.From,
+			"to":       change.To,
+		},
+	}); err != nil {
+		a.logger.Warn("failed to record schedule switch audit event", "domain", change.Domain, "error", err)
+	}
 }
 
 // reloadHealthManager updates health checks for the new server configuration.
@@ -1372,6 +1700,12 @@ func (p *combinedHealthProvider) IsHealthy(address string, port int) bool {
 	return false
 }
 
+// IsOverriddenUnhealthy returns true if the backend registry holds a manual
+// override marking the backend unhealthy. Panic mode still excludes it.
+func (p *combinedHealthProvider) IsOverriddenUnhealthy(address string, port int) bool {
+	return p.backendRegistry != nil && p.backendRegistry.IsOverriddenUnhealthy(address, port)
+}
+
 // backendRegistryLatencyProvider implements routing.LatencyProvider using the backend registry.
 // v1.1.0: Unified latency tracking for static, agent, and API-registered servers.
 type backendRegistryLatencyProvider struct {
@@ -1390,6 +1724,21 @@ func (p *backendRegistryLatencyProvider) GetLatency(address string, port int) ro
 	}
 }
 
+// backendRegistryLoadProvider implements routing.LoadProvider using the backend registry.
+type backendRegistryLoadProvider struct {
+	registry *overwatch.Registry
+}
+
+// GetLoad returns the agent-reported load of a server from the backend registry.
+func (p *backendRegistryLoadProvider) GetLoad(address string, port int) routing.LoadInfo {
+	info := p.registry.GetLoad(address, port)
+	return routing.LoadInfo{
+		ActiveConnections: info.ActiveConnections,
+		Capacity:          info.Capacity,
+		HasData:           info.HasData,
+	}
+}
+
 // learnedLatencyTableAdapter adapts LearnedLatencyTable to routing.LearnedLatencyProvider.
 // ADR-017: Enables latency routing based on passive TCP RTT learning.
 type learnedLatencyTableAdapter struct {
@@ -0,0 +1,115 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+
+	"github.com/loganrossus/OpenGSLB/pkg/api"
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/dns"
+	"github.com/loganrossus/OpenGSLB/pkg/overwatch"
+)
+
+// dnsUpdateBackendProvider adapts the backend registry to the DNS handler's
+// BackendUpdater interface. Records added by dynamic updates are registered
+// with SourceDNSUpdate; deletes only remove backends registered that way, so
+// an update cannot remove static, agent or API-registered servers, and adds
+// of such servers are refused. Changes are recorded in the audit log with the
+// TSIG key as the actor.
+type dnsUpdateBackendProvider struct {
+	registry *overwatch.Registry
+	audit    *api.AuditLog
+	port     int
+	weight   int
+	region   string
+	logger   *slog.Logger
+}
+
+// newDNSUpdateBackendProvider creates a provider registering backends with
+// the port, weight and region from dns.update. audit may be nil.
+func newDNSUpdateBackendProvider(registry *overwatch.Registry, audit *api.AuditLog, cfg config.DNSUpdateConfig, logger *slog.Logger) *dnsUpdateBackendProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &dnsUpdateBackendProvider{
+		registry: registry,
+		audit:    audit,
+		port:     cfg.Port,
+		weight:   cfg.Weight,
+		region:   cfg.Region,
+		logger:   logger,
+	}
+}
+
+// AddBackend registers ip as a backend of domain. Adding a backend already
+// registered by another source is refused.
+func (p *dnsUpdateBackendProvider) AddBackend(domain string, ip net.IP, key string) error {
+	created, err := p.registry.RegisterDNSUpdate(domain, ip.String(), p.port, p.weight, p.region)
+	if errors.Is(err, overwatch.ErrSourceConflict) {
+		p.record("backend_add_refused", "failure", domain, ip.String(), p.port, key)
+		return fmt.Errorf("%w: %v", dns.ErrUpdateRefused, err)
+	}
+	if err != nil || !created {
+		return err
+	}
+	p.record("backend_added", "success", domain, ip.String(), p.port, key)
+	p.logger.Info("backend added by dynamic update",
+		"service", domain,
+		"address", ip.String(),
+		"port", p.port,
+		"tsig_key", key,
+	)
+	return nil
+}
+
+// RemoveBackend deregisters the backends of domain at ip that were added by
+// dynamic updates, whatever their port.
+func (p *dnsUpdateBackendProvider) RemoveBackend(domain string, ip net.IP, key string) error {
+	for _, backend := range p.registry.GetBackends(domain) {
+		if backend.Source != overwatch.SourceDNSUpdate || !ip.Equal(net.ParseIP(backend.Address)) {
+			continue
+		}
+		if err := p.registry.Deregister(backend.Service, backend.Address, backend.Port); err != nil {
+			return err
+		}
+		p.record("backend_removed", "success", domain, backend.Address, backend.Port, key)
+		p.logger.Info("backend removed by dynamic update",
+			"service", domain,
+			"address", backend.Address,
+			"port", backend.Port,
+			"tsig_key", key,
+		)
+	}
+	return nil
+}
+
+// record records a backend change of a dynamic update in the audit log.
+func (p *dnsUpdateBackendProvider) record(action, status, domain, address string, port int, key string) {
+	if p.audit == nil {
+		return
+	}
+	if err := p.audit.Record(context.Background(), api.AuditEntry{
+		Action:     action,
+		Resource:   "backend",
+		ResourceID: net.JoinHostPort(address, strconv.Itoa(port)),
+		Actor:      key,
+		ActorType:  "tsig",
+		Status:     status,
+		Details: map[string]interface{}{
+			"service": domain,
+			"source":  string(overwatch.SourceDNSUpdate),
+		},
+	}); err != nil {
+		p.logger.Warn("failed to record dynamic update audit event", "service", domain, "address", address, "error", err)
+	}
+}
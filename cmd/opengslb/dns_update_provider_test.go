@@ -0,0 +1,73 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/api"
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/dns"
+	"github.com/loganrossus/OpenGSLB/pkg/overwatch"
+)
+
+func TestDNSUpdateBackendProvider(t *testing.T) {
+	registry := overwatch.NewRegistry(overwatch.RegistryConfig{
+		StaleThreshold: 30 * time.Second,
+		RemoveAfter:    5 * time.Minute,
+	}, nil)
+	audit := api.NewAuditLog(nil, nil)
+	provider := newDNSUpdateBackendProvider(registry, audit, config.DNSUpdateConfig{Port: 80, Weight: 100}, nil)
+
+	if err := registry.RegisterAPI("app.example.com", "10.0.0.2", 80, 100, "us-east"); err != nil {
+		t.Fatalf("failed to register API backend: %v", err)
+	}
+
+	ip := net.ParseIP("10.0.0.1")
+	if err := provider.AddBackend("app.example.com", ip, "update-key"); err != nil {
+		t.Fatalf("AddBackend failed: %v", err)
+	}
+	// Adding the same backend again is not audited
+	if err := provider.AddBackend("app.example.com", ip, "update-key"); err != nil {
+		t.Fatalf("duplicate AddBackend failed: %v", err)
+	}
+	if err := provider.RemoveBackend("app.example.com", ip, "update-key"); err != nil {
+		t.Fatalf("RemoveBackend failed: %v", err)
+	}
+	err := provider.AddBackend("app.example.com", net.ParseIP("10.0.0.2"), "update-key")
+	if !errors.Is(err, dns.ErrUpdateRefused) {
+		t.Fatalf("expected ErrUpdateRefused for an API backend, got %v", err)
+	}
+	if backend, _ := registry.GetBackend("app.example.com", "10.0.0.2", 80); backend.Source != overwatch.SourceAPI {
+		t.Errorf("expected the API backend to keep its source, got %s", backend.Source)
+	}
+
+	entries, _, err := audit.ListAuditLogs(api.AuditFilter{SortOrder: "asc"})
+	if err != nil {
+		t.Fatalf("ListAuditLogs failed: %v", err)
+	}
+	want := []struct{ action, resourceID, status string }{
+		{"backend_added", "10.0.0.1:80", "success"},
+		{"backend_removed", "10.0.0.1:80", "success"},
+		{"backend_add_refused", "10.0.0.2:80", "failure"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d audit entries, got %d", len(want), len(entries))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Action != w.action || e.ResourceID != w.resourceID || e.Status != w.status {
+			t.Errorf("entry %d: got %s %s %s, want %s %s %s", i, e.Action, e.ResourceID, e.Status, w.action, w.resourceID, w.status)
+		}
+		if e.Actor != "update-key" || e.ActorType != "tsig" {
+			t.Errorf("entry %d: expected actor update-key/tsig, got %s/%s", i, e.Actor, e.ActorType)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/api"
+	"github.com/loganrossus/OpenGSLB/pkg/dns"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	miekgdns "github.com/miekg/dns"
+)
+
+// dnsRoutingProvider answers routing tests with the DNS handler, so a test
+// routes exactly as a query from the client would, in the client's
+// split-horizon view or the requested one. Other routing operations are
+// served by the stub provider.
+type dnsRoutingProvider struct {
+	*api.StubRoutingProvider
+	handler *dns.Handler
+}
+
+// newDNSRoutingProvider creates a routing provider testing against handler.
+func newDNSRoutingProvider(stub *api.StubRoutingProvider, handler *dns.Handler) *dnsRoutingProvider {
+	return &dnsRoutingProvider{StubRoutingProvider: stub, handler: handler}
+}
+
+// TestRouting simulates an A or AAAA query for the request's domain.
+func (p *dnsRoutingProvider) TestRouting(request api.RoutingTestRequest) (*api.RoutingTestResult, error) {
+	clientIP := net.ParseIP(request.ClientIP)
+	if clientIP == nil {
+		return nil, fmt.Errorf("invalid client_ip %q", request.ClientIP)
+	}
+	qtype, ok := miekgdns.StringToType[strings.ToUpper(request.QueryType)]
+	if !ok {
+		return nil, fmt.Errorf("unknown query_type %q", request.QueryType)
+	}
+
+	start := time.Now()
+	route, err := p.handler.Route(miekgdns.Fqdn(request.Domain), qtype, clientIP, request.View)
+	if errors.Is(err, dns.ErrUnknownView) {
+		return nil, fmt.Errorf("%w: view %s", api.ErrNotFound, request.View)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api.RoutingTestResult{
+		Domain:       strings.TrimSuffix(route.Domain, "."),
+		ClientIP:     request.ClientIP,
+		View:         route.View,
+		Algorithm:    route.Algorithm,
+		Factors:      []api.RoutingFactor{},
+		Decision:     route.Decision,
+		DecisionTime: time.Since(start).Microseconds(),
+		TTL:          int(route.TTL),
+		Timestamp:    time.Now(),
+	}
+	for _, stage := range route.Stages {
+		result.Stages = append(result.Stages, api.RoutingStage{
+			Stage:   stage.Stage,
+			Detail:  stage.Detail,
+			Input:   backendAddresses(stage.Input),
+			Output:  backendAddresses(stage.Output),
+			Skipped: stage.Skipped,
+		})
+	}
+	for i, server := range route.Selected {
+		backend := api.SelectedBackend{
+			Address: server.Address,
+			Port:    server.Port,
+			Region:  server.Region,
+			Weight:  server.Weight,
+			Healthy: true,
+		}
+		if i == 0 {
+			result.SelectedBackend = &backend
+			continue
+		}
+		result.Alternatives = append(result.Alternatives, backend)
+	}
+	return result, nil
+}
+
+// backendAddresses returns the address:port of each server.
+func backendAddresses(servers []*routing.Server) []string {
+	addresses := make([]string, 0, len(servers))
+	for _, server := range servers {
+		addresses = append(addresses, net.JoinHostPort(server.Address, strconv.Itoa(server.Port)))
+	}
+	return addresses
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/api"
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/dns"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/loganrossus/OpenGSLB/pkg/schedule"
+)
+
+// scheduleProvider serves the schedule API from the scheduler. Previews
+// apply a profile to the domain's servers in the DNS registry.
+type scheduleProvider struct {
+	scheduler *schedule.Scheduler
+	registry  *dns.Registry
+}
+
+// newScheduleProvider creates a schedule provider.
+func newScheduleProvider(scheduler *schedule.Scheduler, registry *dns.Registry) *scheduleProvider {
+	return &scheduleProvider{scheduler: scheduler, registry: registry}
+}
+
+// ListSchedules returns every schedule.
+func (p *scheduleProvider) ListSchedules() []schedule.Schedule {
+	return p.scheduler.List()
+}
+
+// GetSchedule returns a schedule by name.
+func (p *scheduleProvider) GetSchedule(name string) (*schedule.Schedule, error) {
+	return p.scheduler.Get(name)
+}
+
+// PutSchedule creates or replaces a schedule.
+func (p *scheduleProvider) PutSchedule(ctx context.Context, sched schedule.Schedule) error {
+	return p.scheduler.Put(ctx, sched)
+}
+
+// DeleteSchedule deletes a schedule.
+func (p *scheduleProvider) DeleteSchedule(ctx context.Context, name string) error {
+	return p.scheduler.Delete(ctx, name)
+}
+
+// PreviewSchedule returns the profile of a schedule active at a time, with
+// the weights and priorities it gives the domain's servers.
+func (p *scheduleProvider) PreviewSchedule(name string, at time.Time) (*api.SchedulePreview, error) {
+	active, err := p.scheduler.Preview(name, at)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &api.SchedulePreview{
+		At:       at.UTC(),
+		Active:   active,
+		Backends: []api.SchedulePreviewBackend{},
+	}
+	entry := p.registry.Lookup(active.Domain)
+	if entry == nil {
+		return preview, nil
+	}
+	profile := weightProfile(active.Profile)
+	for _, server := range entry.Servers {
+		s := &routing.Server{
+			Address:  server.Address.String(),
+			Port:     server.Port,
+			Weight:   server.Weight,
+			Region:   server.Region,
+			Priority: server.Priority,
+		}
+		profile.Apply(s)
+		preview.Backends = append(preview.Backends, api.SchedulePreviewBackend{
+			Address:  s.Address,
+			Port:     s.Port,
+			Region:   s.Region,
+			Weight:   s.Weight,
+			Priority: s.Priority,
+		})
+	}
+	return preview, nil
+}
+
+// weightProfile converts a schedule profile to the DNS handler's form, or
+// returns nil for a nil profile.
+func weightProfile(p *schedule.Profile) *dns.WeightProfile {
+	if p == nil {
+		return nil
+	}
+	return &dns.WeightProfile{
+		Name:             p.Name,
+		RegionWeights:    p.RegionWeights,
+		ServerWeights:    p.ServerWeights,
+		RegionPreference: p.RegionPreference,
+	}
+}
+
+// configSchedules returns the schedules of the configuration file.
+func configSchedules(cfg *config.Config) []schedule.Schedule {
+	schedules := make([]schedule.Schedule, 0, len(cfg.Schedules))
+	for _, sched := range cfg.Schedules {
+		schedules = append(schedules, sched.ToSchedule())
+	}
+	return schedules
+}
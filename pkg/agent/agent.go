@@ -130,10 +130,12 @@ func NewAgent(cfg AgentConfig) (*Agent, error) {
 	// Register configured backends
 	for _, backend := range cfg.Config.Agent.Backends {
 		bcfg := BackendConfig{
-			Service: backend.Service,
-			Address: backend.Address,
-			Port:    backend.Port,
-			Weight:  backend.Weight,
+			Service:  backend.Service,
+			Address:  backend.Address,
+			Port:     backend.Port,
+			Weight:   backend.Weight,
+			Labels:   backend.Labels,
+			Capacity: backend.Capacity,
 			HealthCheck: HealthCheckConfig{
 				Type:             backend.HealthCheck.Type,
 				Path:             backend.HealthCheck.Path,
@@ -392,7 +394,7 @@ func (a *Agent) sendHealthUpdate() {
 		AgentID:    a.identity.AgentID,
 		Region:     a.identity.Region,
 		Timestamp:  time.Now(),
-		Backends:   a.backends.GetAllHealth(),
+		Backends:   a.withConnectionCounts(a.backends.GetAllHealth()),
 		Predictive: predictiveState,
 	}
 
@@ -416,6 +418,23 @@ func (a *Agent) sendDeregistration() {
 	}
 }
 
+// withConnectionCounts sets the active connections of each backend to the
+// number of established TCP connections to its port. Backends are left
+// without a count where connections cannot be counted.
+func (a *Agent) withConnectionCounts(snapshots []BackendHealthSnapshot) []BackendHealthSnapshot {
+	counts, err := latency.CountConnections(a.getBackendPorts())
+	if err != nil {
+		a.logger.Debug("active connections not reported", "error", err)
+		return snapshots
+	}
+	for i := range snapshots {
+		if count, ok := counts[uint16(snapshots[i].Port)]; ok {
+			snapshots[i].ActiveConnections = &count
+		}
+	}
+	return snapshots
+}
+
 // startLatencyCollection initializes and starts passive latency learning (ADR-017).
 func (a *Agent) startLatencyCollection(ctx context.Context) error {
 	cfg := a.config.Agent.LatencyLearning
@@ -52,6 +52,13 @@ type BackendConfig struct {
 	// Weight for routing decisions
 	Weight int
 
+	// Labels are free-form key/value pairs sent to Overwatch
+	Labels map[string]string
+
+	// Capacity is the number of connections the backend can serve (0 = unknown).
+	// Overridden by the capacity reported in HTTP health check responses.
+	Capacity int
+
 	// Health check configuration
 	HealthCheck HealthCheckConfig
 }
@@ -75,6 +82,9 @@ type BackendHealth struct {
 	address           string
 	port              int
 	weight            int
+	labels            map[string]string
+	capacity          int // Configured capacity
+	reportedCapacity  int // Capacity from the last health check response
 	healthy           bool
 	lastCheck         time.Time
 	lastHealthy       time.Time
@@ -102,17 +112,23 @@ type BackendHealthUpdate struct {
 
 // BackendHealthSnapshot is a point-in-time copy of backend health.
 type BackendHealthSnapshot struct {
-	Service           string        `json:"service"`
-	Address           string        `json:"address"`
-	Port              int           `json:"port"`
-	Weight            int           `json:"weight"`
-	Healthy           bool          `json:"healthy"`
-	LastCheck         time.Time     `json:"last_check"`
-	LastHealthy       time.Time     `json:"last_healthy"`
-	ConsecutiveFails  int           `json:"consecutive_fails"`
-	ConsecutivePasses int           `json:"consecutive_passes"`
-	LastError         string        `json:"last_error,omitempty"`
-	LastLatency       time.Duration `json:"last_latency_ns"`
+	Service           string            `json:"service"`
+	Address           string            `json:"address"`
+	Port              int               `json:"port"`
+	Weight            int               `json:"weight"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Healthy           bool              `json:"healthy"`
+	LastCheck         time.Time         `json:"last_check"`
+	LastHealthy       time.Time         `json:"last_healthy"`
+	ConsecutiveFails  int               `json:"consecutive_fails"`
+	ConsecutivePasses int               `json:"consecutive_passes"`
+	LastError         string            `json:"last_error,omitempty"`
+	LastLatency       time.Duration     `json:"last_latency_ns"`
+	// Capacity is the reported or configured capacity (0 = unknown).
+	Capacity int `json:"capacity,omitempty"`
+	// ActiveConnections is the number of established TCP connections to the
+	// backend port, set by the agent when it can count them.
+	ActiveConnections *int `json:"active_connections,omitempty"`
 }
 
 // NewBackendManager creates a new backend manager.
@@ -172,6 +188,8 @@ func (m *BackendManager) AddBackend(cfg BackendConfig) error {
 			address:       cfg.Address,
 			port:          cfg.Port,
 			weight:        cfg.Weight,
+			labels:        cfg.Labels,
+			capacity:      cfg.Capacity,
 			failThreshold: cfg.HealthCheck.FailureThreshold,
 			passThreshold: cfg.HealthCheck.SuccessThreshold,
 		},
@@ -383,6 +401,7 @@ func (h *BackendHealth) RecordResult(result health.Result) bool {
 
 	h.lastCheck = result.Timestamp
 	h.lastLatency = result.Latency
+	h.reportedCapacity = result.Capacity
 	previousHealthy := h.healthy
 
 	if result.Healthy {
@@ -423,12 +442,17 @@ func (h *BackendHealth) Snapshot() BackendHealthSnapshot {
 	if h.lastError != nil {
 		errStr = h.lastError.Error()
 	}
+	capacity := h.capacity
+	if h.reportedCapacity > 0 {
+		capacity = h.reportedCapacity
+	}
 
 	return BackendHealthSnapshot{
 		Service:           h.service,
 		Address:           h.address,
 		Port:              h.port,
 		Weight:            h.weight,
+		Labels:            h.labels,
 		Healthy:           h.healthy,
 		LastCheck:         h.lastCheck,
 		LastHealthy:       h.lastHealthy,
@@ -436,6 +460,7 @@ func (h *BackendHealth) Snapshot() BackendHealthSnapshot {
 		ConsecutivePasses: h.consecutivePasses,
 		LastError:         errStr,
 		LastLatency:       h.lastLatency,
+		Capacity:          capacity,
 	}
 }
 
@@ -442,6 +442,27 @@ func TestBackendHealth_Snapshot(t *testing.T) {
 	}
 }
 
+func TestBackendHealth_SnapshotCapacity(t *testing.T) {
+	bh := &BackendHealth{service: "webapp", address: "10.0.1.1", port: 8080, capacity: 100}
+
+	bh.RecordResult(health.Result{Healthy: true, Timestamp: time.Now()})
+	if snap := bh.Snapshot(); snap.Capacity != 100 {
+		t.Errorf("expected the configured capacity, got %d", snap.Capacity)
+	}
+
+	// A capacity reported by the backend takes precedence
+	bh.RecordResult(health.Result{Healthy: true, Timestamp: time.Now(), Capacity: 400})
+	if snap := bh.Snapshot(); snap.Capacity != 400 {
+		t.Errorf("expected the reported capacity, got %d", snap.Capacity)
+	}
+
+	// Until the backend stops reporting it
+	bh.RecordResult(health.Result{Healthy: true, Timestamp: time.Now()})
+	if snap := bh.Snapshot(); snap.Capacity != 100 {
+		t.Errorf("expected the configured capacity again, got %d", snap.Capacity)
+	}
+}
+
 func TestBackendManager_StartStop(t *testing.T) {
 	checker := newMockChecker()
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
@@ -0,0 +1,42 @@
+//go:build linux
+
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package latency
+
+import (
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// CountConnections returns the number of established TCP connections to each
+// of the given local ports, using the same netlink INET_DIAG dump as the
+// collector. Ports without connections are reported as 0.
+func CountConnections(ports []uint16) (map[uint16]int, error) {
+	counts := make(map[uint16]int, len(ports))
+	for _, p := range ports {
+		counts[p] = 0
+	}
+
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		sockets, err := netlink.SocketDiagTCP(family)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list TCP sockets: %w", err)
+		}
+		for _, sock := range sockets {
+			if sock.State != tcpEstablished {
+				continue
+			}
+			if _, ok := counts[sock.ID.SourcePort]; ok {
+				counts[sock.ID.SourcePort]++
+			}
+		}
+	}
+	return counts, nil
+}
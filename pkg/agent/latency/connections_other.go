@@ -0,0 +1,14 @@
+//go:build !linux
+
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package latency
+
+// CountConnections is only supported on Linux.
+func CountConnections(ports []uint16) (map[uint16]int, error) {
+	return nil, ErrPlatformNotSupported
+}
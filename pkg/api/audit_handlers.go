@@ -35,7 +35,7 @@ type AuditEntry struct {
 	Resource   string                 `json:"resource"`
 	ResourceID string                 `json:"resource_id,omitempty"`
 	Actor      string                 `json:"actor"`
-	ActorType  string                 `json:"actor_type"` // user, api, system
+	ActorType  string                 `json:"actor_type"` // user, api, tsig, system
 	ActorIP    string                 `json:"actor_ip,omitempty"`
 	Status     string                 `json:"status"` // success, failure
 	Details    map[string]interface{} `json:"details,omitempty"`
@@ -0,0 +1,339 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/store"
+)
+
+const (
+	// AuditKeyPrefix is the prefix for all audit entry keys in the KV store.
+	AuditKeyPrefix = "audit/"
+
+	// DefaultAuditRetention is how long audit entries are kept.
+	DefaultAuditRetention = 90 * 24 * time.Hour
+
+	// maxAuditEntries bounds the entries kept; the oldest are dropped first.
+	maxAuditEntries = 10000
+
+	// maxTopActors is the number of actors reported in audit statistics.
+	maxTopActors = 10
+)
+
+// AuditLog records audit entries of configuration and system events and
+// serves them through the audit API. It persists entries to a KV store and
+// keeps them in memory for the retention period.
+type AuditLog struct {
+	store     store.Store
+	logger    *slog.Logger
+	retention time.Duration
+
+	mu      sync.RWMutex
+	entries []AuditEntry // Oldest first
+	seq     uint64
+	now     func() time.Time
+}
+
+// NewAuditLog creates a new AuditLog.
+// If store is nil, entries will only be kept in memory.
+func NewAuditLog(kvStore store.Store, logger *slog.Logger) *AuditLog {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AuditLog{
+		store:     kvStore,
+		logger:    logger,
+		retention: DefaultAuditRetention,
+		now:       time.Now,
+	}
+}
+
+// Load reads the persisted entries from the KV store.
+func (l *AuditLog) Load(ctx context.Context) error {
+	if l.store == nil {
+		return nil
+	}
+	pairs, err := l.store.List(ctx, AuditKeyPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	entries := make([]AuditEntry, 0, len(pairs))
+	for _, pair := range pairs {
+		var entry AuditEntry
+		if err := json.Unmarshal(pair.Value, &entry); err != nil {
+			l.logger.Warn("skipping invalid audit entry", "key", pair.Key, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+
+	l.mu.Lock()
+	l.entries = entries
+	expired := l.prune()
+	l.mu.Unlock()
+
+	l.deleteEntries(ctx, expired)
+	return nil
+}
+
+// Record adds an entry to the audit log. The ID and timestamp are set when
+// empty; the actor type defaults to system and the status to success.
+func (l *AuditLog) Record(ctx context.Context, entry AuditEntry) error {
+	l.mu.Lock()
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = l.now().UTC()
+	}
+	if entry.ID == "" {
+		l.seq++
+		entry.ID = fmt.Sprintf("%020d-%d", entry.Timestamp.UnixNano(), l.seq)
+	}
+	if entry.ActorType == "" {
+		entry.ActorType = "system"
+	}
+	if entry.Actor == "" {
+		entry.Actor = "opengslb"
+	}
+	if entry.Status == "" {
+		entry.Status = "success"
+	}
+	l.entries = append(l.entries, entry)
+	expired := l.prune()
+	l.mu.Unlock()
+
+	l.logger.Info("audit event",
+		"action", entry.Action,
+		"resource", entry.Resource,
+		"resource_id", entry.ResourceID,
+		"actor", entry.Actor,
+	)
+
+	l.deleteEntries(ctx, expired)
+	if l.store == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if err := l.store.Set(ctx, AuditKeyPrefix+entry.ID, data); err != nil {
+		return fmt.Errorf("failed to persist audit entry: %w", err)
+	}
+	return nil
+}
+
+// prune drops entries past the retention period or the entry limit and
+// returns them. Caller must hold l.mu.
+func (l *AuditLog) prune() []AuditEntry {
+	cutoff := l.now().Add(-l.retention)
+	n := 0
+	for n < len(l.entries) && (l.entries[n].Timestamp.Before(cutoff) || len(l.entries)-n > maxAuditEntries) {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	expired := append([]AuditEntry(nil), l.entries[:n]...)
+	l.entries = append(l.entries[:0:0], l.entries[n:]...)
+	return expired
+}
+
+// deleteEntries removes expired entries from the KV store.
+func (l *AuditLog) deleteEntries(ctx context.Context, entries []AuditEntry) {
+	if l.store == nil {
+		return
+	}
+	for _, entry := range entries {
+		if err := l.store.Delete(ctx, AuditKeyPrefix+entry.ID); err != nil {
+			l.logger.Warn("failed to delete expired audit entry", "id", entry.ID, "error", err)
+		}
+	}
+}
+
+// ListAuditLogs returns audit log entries with pagination and filtering.
+func (l *AuditLog) ListAuditLogs(filter AuditFilter) ([]AuditEntry, int, error) {
+	matched := l.filter(filter)
+	total := len(matched)
+
+	start := min(max(filter.Offset, 0), total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+	return matched[start:end], total, nil
+}
+
+// GetAuditEntry returns a single audit entry by ID.
+func (l *AuditLog) GetAuditEntry(id string) (*AuditEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for i := range l.entries {
+		if l.entries[i].ID == id {
+			entry := l.entries[i]
+			return &entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// GetAuditStats returns audit log statistics.
+func (l *AuditLog) GetAuditStats() (*AuditStats, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	now := l.now()
+	stats := &AuditStats{
+		TotalEntries:  int64(len(l.entries)),
+		ByAction:      make(map[string]int64),
+		ByResource:    make(map[string]int64),
+		ByStatus:      make(map[string]int64),
+		ByActorType:   make(map[string]int64),
+		TopActors:     []ActorActivityCount{},
+		RetentionDays: int(l.retention / (24 * time.Hour)),
+	}
+	actors := make(map[string]int64)
+	for _, entry := range l.entries {
+		if now.Sub(entry.Timestamp) <= 24*time.Hour {
+			stats.EntriesLast24h++
+		}
+		if now.Sub(entry.Timestamp) <= 7*24*time.Hour {
+			stats.EntriesLast7d++
+		}
+		stats.ByAction[entry.Action]++
+		stats.ByResource[entry.Resource]++
+		stats.ByStatus[entry.Status]++
+		stats.ByActorType[entry.ActorType]++
+		actors[entry.Actor]++
+	}
+
+	for actor, count := range actors {
+		stats.TopActors = append(stats.TopActors, ActorActivityCount{Actor: actor, Count: count})
+	}
+	sort.Slice(stats.TopActors, func(i, j int) bool {
+		a, b := stats.TopActors[i], stats.TopActors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Actor < b.Actor
+	})
+	if len(stats.TopActors) > maxTopActors {
+		stats.TopActors = stats.TopActors[:maxTopActors]
+	}
+
+	if len(l.entries) > 0 {
+		oldest := l.entries[0].Timestamp
+		newest := l.entries[len(l.entries)-1].Timestamp
+		stats.OldestEntry = &oldest
+		stats.NewestEntry = &newest
+	}
+	return stats, nil
+}
+
+// ExportAuditLogs exports every entry matching the filter, ignoring
+// pagination, as json or csv.
+func (l *AuditLog) ExportAuditLogs(filter AuditFilter, format string) ([]byte, error) {
+	entries := l.filter(filter)
+
+	switch format {
+	case "json":
+		return json.MarshalIndent(entries, "", "  ")
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"id", "timestamp", "action", "resource", "resource_id", "actor", "actor_type", "actor_ip", "status"})
+		for _, e := range entries {
+			_ = w.Write([]string{e.ID, e.Timestamp.Format(time.RFC3339), e.Action, e.Resource, e.ResourceID,
+				e.Actor, e.ActorType, e.ActorIP, e.Status})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, fmt.Errorf("failed to write csv: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q: must be json or csv", format)
+	}
+}
+
+// filter returns the entries matching the filter, sorted as requested.
+func (l *AuditLog) filter(filter AuditFilter) []AuditEntry {
+	l.mu.RLock()
+	matched := make([]AuditEntry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		if auditEntryMatches(entry, filter) {
+			matched = append(matched, entry)
+		}
+	}
+	l.mu.RUnlock()
+
+	// Entries are kept oldest first
+	var less func(a, b AuditEntry) bool
+	switch filter.SortBy {
+	case "action":
+		less = func(a, b AuditEntry) bool { return a.Action < b.Action }
+	case "resource":
+		less = func(a, b AuditEntry) bool { return a.Resource < b.Resource }
+	case "actor":
+		less = func(a, b AuditEntry) bool { return a.Actor < b.Actor }
+	}
+	if less != nil {
+		sort.SliceStable(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+	}
+	if filter.SortOrder != "asc" {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+	return matched
+}
+
+// auditEntryMatches reports whether an entry passes every set filter field.
+func auditEntryMatches(entry AuditEntry, filter AuditFilter) bool {
+	if filter.StartTime != nil && entry.Timestamp.Before(*filter.StartTime) {
+		return false
+	}
+	if filter.EndTime != nil && entry.Timestamp.After(*filter.EndTime) {
+		return false
+	}
+	if len(filter.Actions) > 0 && !slices.Contains(filter.Actions, entry.Action) {
+		return false
+	}
+	if len(filter.Resources) > 0 && !slices.Contains(filter.Resources, entry.Resource) {
+		return false
+	}
+	if len(filter.Actors) > 0 && !slices.Contains(filter.Actors, entry.Actor) {
+		return false
+	}
+	if len(filter.ActorTypes) > 0 && !slices.Contains(filter.ActorTypes, entry.ActorType) {
+		return false
+	}
+	if filter.Status != "" && entry.Status != filter.Status {
+		return false
+	}
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		fields := strings.ToLower(strings.Join([]string{entry.Action, entry.Resource, entry.ResourceID, entry.Actor}, " "))
+		if !strings.Contains(fields, search) {
+			return false
+		}
+	}
+	return true
+}
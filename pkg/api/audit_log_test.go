@@ -0,0 +1,90 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/store"
+)
+
+// TestAuditLog_RecordAndList tests recording, filtering and persistence.
+func TestAuditLog_RecordAndList(t *testing.T) {
+	kv, err := store.NewBboltStore(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer kv.Close()
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	log := NewAuditLog(kv, nil)
+	log.now = func() time.Time { return now }
+
+	for _, action := range []string{"domain_created", "domain_updated"} {
+		if err := log.Record(ctx, AuditEntry{Action: action, Resource: "domain", ResourceID: "app.example.com"}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	entries, total, err := log.ListAuditLogs(AuditFilter{Actions: []string{"domain_created"}})
+	if err != nil {
+		t.Fatalf("ListAuditLogs failed: %v", err)
+	}
+	if total != 1 || entries[0].ActorType != "system" || entries[0].Status != "success" {
+		t.Errorf("expected one system entry, got %d: %+v", total, entries)
+	}
+
+	// Newest first by default
+	entries, _, _ = log.ListAuditLogs(AuditFilter{Limit: 1})
+	if len(entries) != 1 || entries[0].Action != "domain_updated" {
+		t.Errorf("expected the newest entry first, got %+v", entries)
+	}
+
+	// Entries survive a restart
+	reloaded := NewAuditLog(kv, nil)
+	reloaded.now = log.now
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	entry, err := reloaded.GetAuditEntry(entries[0].ID)
+	if err != nil || entry.ResourceID != "app.example.com" {
+		t.Errorf("expected the persisted entry, got %+v (%v)", entry, err)
+	}
+
+	stats, _ := reloaded.GetAuditStats()
+	if stats.TotalEntries != 2 || stats.ByAction["domain_created"] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	data, err := reloaded.ExportAuditLogs(AuditFilter{}, "csv")
+	if err != nil || strings.Count(string(data), "\n") != 3 {
+		t.Errorf("expected a header and two csv rows, got %q (%v)", data, err)
+	}
+}
+
+// TestAuditLog_Retention tests that expired entries are dropped.
+func TestAuditLog_Retention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	log := NewAuditLog(nil, nil)
+	log.now = func() time.Time { return now }
+
+	_ = log.Record(ctx, AuditEntry{Action: "old"})
+	now = now.Add(DefaultAuditRetention + time.Hour)
+	_ = log.Record(ctx, AuditEntry{Action: "new"})
+
+	entries, total, _ := log.ListAuditLogs(AuditFilter{})
+	if total != 1 || entries[0].Action != "new" {
+		t.Errorf("expected only the new entry, got %+v", entries)
+	}
+}
@@ -166,6 +166,11 @@ func (h *DiscoveryHandlers) HandleV1Root(w http.ResponseWriter, r *http.Request)
 			Description: "Routing algorithms and decisions",
 			Methods:     []string{"GET", "POST"},
 		},
+		{
+			Path:        "/api/v1/schedules",
+			Description: "Scheduled weight profiles",
+			Methods:     []string{"GET", "POST"},
+		},
 		{
 			Path:        "/api/v1/overrides",
 			Description: "Health check overrides",
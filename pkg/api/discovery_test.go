@@ -144,6 +144,7 @@ func TestDiscoveryHandlers_HandleV1Root_Response(t *testing.T) {
 		"/api/v1/config":      false,
 		"/api/v1/preferences": false,
 		"/api/v1/routing":     false,
+		"/api/v1/schedules":   false,
 		"/api/v1/overrides":   false,
 		"/api/v1/dnssec":      false,
 		"/api/v1/geo":         false,
@@ -13,6 +13,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
 )
 
 // DomainProvider defines the interface for domain management operations.
@@ -51,6 +53,46 @@ type Domain struct {
 	BackendCount    int             `json:"backend_count,omitempty"`
 	HealthyBackends int             `json:"healthy_backends,omitempty"`
 	Settings        *DomainSettings `json:"settings,omitempty"`
+	Records         []DomainRecord  `json:"records,omitempty"`
+	Failover        *DomainFailover `json:"failover,omitempty"` // Priority tier state of failover domains
+	Schedule        *DomainSchedule `json:"schedule,omitempty"` // Active profile of scheduled domains
+}
+
+// DomainSchedule is the active profile of a scheduled domain.
+type DomainSchedule struct {
+	Schedule    string     `json:"schedule"`
+	Profile     string     `json:"profile"`
+	Since       time.Time  `json:"since"`
+	NextSwitch  *time.Time `json:"next_switch,omitempty"`
+	NextProfile string     `json:"next_profile,omitempty"`
+}
+
+// DomainFailover is the priority tier state of a failover domain.
+type DomainFailover struct {
+	MinHealthy       int            `json:"min_healthy"`
+	FailbackHoldDown string         `json:"failback_hold_down,omitempty"`
+	ManualFailback   bool           `json:"manual_failback,omitempty"`
+	Tiers            []FailoverTier `json:"tiers"`
+}
+
+// FailoverTier is one priority tier of a failover domain. Healthy counts
+// are as of the last routed query.
+type FailoverTier struct {
+	Priority   int        `json:"priority"`
+	Backends   int        `json:"backends"`
+	Healthy    int        `json:"healthy"`
+	Active     bool       `json:"active"`
+	Panic      bool       `json:"panic,omitempty"`       // Health is ignored; healthy counts every backend routed to
+	FailbackAt *time.Time `json:"failback_at,omitempty"` // Pending return of traffic to the tier
+}
+
+// DomainRecord is a static record (TXT, MX, CAA, SRV or CNAME) served at or
+// below a domain name.
+type DomainRecord struct {
+	Name  string `json:"name,omitempty"` // Relative to the domain; empty or "@" for the domain itself
+	Type  string `json:"type"`
+	TTL   int    `json:"ttl,omitempty"`
+	Value string `json:"value"`
 }
 
 // DomainSettings holds advanced domain settings.
@@ -118,6 +160,7 @@ type DomainCreateRequest struct {
 	Description   string          `json:"description,omitempty"`
 	Tags          []string        `json:"tags,omitempty"`
 	Settings      *DomainSettings `json:"settings,omitempty"`
+	Records       []DomainRecord  `json:"records,omitempty"`
 }
 
 // DomainUpdateRequest is the request body for updating a domain.
@@ -129,6 +172,7 @@ type DomainUpdateRequest struct {
 	Description   *string         `json:"description,omitempty"`
 	Tags          []string        `json:"tags,omitempty"`
 	Settings      *DomainSettings `json:"settings,omitempty"`
+	Records       []DomainRecord  `json:"records,omitempty"` // Replaces all static records when present
 }
 
 // DomainHandlers provides HTTP handlers for domain API endpoints.
@@ -248,6 +292,10 @@ func (h *DomainHandlers) createDomain(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusBadRequest, "name is required")
 		return
 	}
+	if err := config.ValidateStaticRecords(req.Name, toStaticRecords(req.Records)); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid records: "+err.Error())
+		return
+	}
 
 	domain := Domain{
 		Name:          req.Name,
@@ -258,6 +306,7 @@ func (h *DomainHandlers) createDomain(w http.ResponseWriter, r *http.Request) {
 		Description:   req.Description,
 		Tags:          req.Tags,
 		Settings:      req.Settings,
+		Records:       req.Records,
 		CreatedAt:     time.Now().UTC(),
 		UpdatedAt:     time.Now().UTC(),
 	}
@@ -322,6 +371,13 @@ func (h *DomainHandlers) updateDomain(w http.ResponseWriter, r *http.Request, na
 	if req.Settings != nil {
 		existing.Settings = req.Settings
 	}
+	if req.Records != nil {
+		if err := config.ValidateStaticRecords(name, toStaticRecords(req.Records)); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid records: "+err.Error())
+			return
+		}
+		existing.Records = req.Records
+	}
 	existing.UpdatedAt = time.Now().UTC()
 
 	if err := h.provider.UpdateDomain(name, *existing); err != nil {
@@ -479,3 +535,34 @@ func (h *DomainHandlers) writeError(w http.ResponseWriter, status int, message s
 		Code:  status,
 	})
 }
+
+// toStaticRecords converts API records to their configuration form.
+func toStaticRecords(records []DomainRecord) []config.StaticRecord {
+	result := make([]config.StaticRecord, 0, len(records))
+	for _, r := range records {
+		result = append(result, config.StaticRecord{
+			Name:  r.Name,
+			Type:  r.Type,
+			TTL:   r.TTL,
+			Value: r.Value,
+		})
+	}
+	return result
+}
+
+// fromStaticRecords converts configured records to their API form.
+func fromStaticRecords(records []config.StaticRecord) []DomainRecord {
+	if len(records) == 0 {
+		return nil
+	}
+	result := make([]DomainRecord, 0, len(records))
+	for _, r := range records {
+		result = append(result, DomainRecord{
+			Name:  r.Name,
+			Type:  r.Type,
+			TTL:   r.TTL,
+			Value: r.Value,
+		})
+	}
+	return result
+}
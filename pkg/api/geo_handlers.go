@@ -67,6 +67,15 @@ type GeoTestResponse struct {
 	Comment     string `json:"comment,omitempty"`
 	Country     string `json:"country,omitempty"`
 	Continent   string `json:"continent,omitempty"`
+	// Location is the position of the IP address, from a City database
+	Location *GeoTestLocation `json:"location,omitempty"`
+}
+
+// GeoTestLocation is the position of an IP address.
+type GeoTestLocation struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	AccuracyRadiusKm int     `json:"accuracy_radius_km,omitempty"`
 }
 
 // HandleMappings routes /api/v1/geo/mappings based on HTTP method.
@@ -233,6 +242,13 @@ func (h *GeoHandlers) TestIP(w http.ResponseWriter, r *http.Request) {
 	case geo.MatchTypeGeoIP:
 		response.Country = match.Country
 		response.Continent = match.Continent
+		if match.HasLocation {
+			response.Location = &GeoTestLocation{
+				Latitude:         match.Latitude,
+				Longitude:        match.Longitude,
+				AccuracyRadiusKm: match.AccuracyRadius,
+			}
+		}
 	}
 
 	writeJSON(w, http.StatusOK, response)
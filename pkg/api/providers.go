@@ -18,6 +18,8 @@ import (
 	"github.com/loganrossus/OpenGSLB/pkg/config"
 	"github.com/loganrossus/OpenGSLB/pkg/health"
 	"github.com/loganrossus/OpenGSLB/pkg/overwatch"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/loganrossus/OpenGSLB/pkg/schedule"
 	"github.com/loganrossus/OpenGSLB/pkg/store"
 )
 
@@ -52,6 +54,19 @@ type DNSRegistryInterface interface {
 	RegisterServer(service string, address string, port int, weight int, region string) error
 	// DeregisterServer removes a server from the DNS registry.
 	DeregisterServer(service string, address string, port int) error
+	// SetStaticRecords replaces the static records (TXT, MX, CAA, SRV, CNAME) of a domain.
+	SetStaticRecords(name string, records []config.StaticRecord) error
+}
+
+// FailoverTierProvider is implemented by DNS registries that report the
+// priority tiers of failover domains.
+type FailoverTierProvider interface {
+	FailoverTiers(name string) ([]routing.FailoverTier, routing.FailoverPolicy, bool)
+}
+
+// ActiveScheduleProvider reports the active profile of scheduled domains.
+type ActiveScheduleProvider interface {
+	Active(domain string) (schedule.Active, bool)
 }
 
 // RegistryDomainProvider implements DomainProvider using the backend registry.
@@ -62,6 +77,7 @@ type RegistryDomainProvider struct {
 	logger        *slog.Logger
 	dnsRegistry   DNSRegistryInterface
 	routerFactory func(string) (interface{}, error)
+	schedules     ActiveScheduleProvider
 }
 
 // NewRegistryDomainProvider creates a new RegistryDomainProvider.
@@ -97,6 +113,12 @@ func (p *RegistryDomainProvider) SetRouterFactory(factory func(string) (interfac
 	p.routerFactory = factory
 }
 
+// SetScheduleProvider sets the provider of the active profiles of scheduled
+// domains, reported in domain status.
+func (p *RegistryDomainProvider) SetScheduleProvider(schedules ActiveScheduleProvider) {
+	p.schedules = schedules
+}
+
 // LoadStoredDomainsIntoDNS loads API-created domains and their backends from the store
 // and registers them with the DNS registry. This should be called on startup after
 // the DNS registry and router factory are set.
@@ -151,6 +173,14 @@ func (p *RegistryDomainProvider) LoadStoredDomainsIntoDNS() error {
 			)
 			continue
 		}
+		if len(domain.Records) > 0 {
+			if err := p.dnsRegistry.SetStaticRecords(domain.Name, toStaticRecords(domain.Records)); err != nil {
+				p.logger.Warn("failed to load static records for stored domain",
+					"domain", domain.Name,
+					"error", err,
+				)
+			}
+		}
 		loadedDomains++
 
 		// Load backends for this domain
@@ -236,6 +266,7 @@ func (p *RegistryDomainProvider) ListDomains() []Domain {
 				Enabled:         true,
 				BackendCount:    backendCount,
 				HealthyBackends: backendCount, // Assume healthy until checked
+				Records:         fromStaticRecords(d.Records),
 			}
 		}
 	}
@@ -296,8 +327,67 @@ func (p *RegistryDomainProvider) ListDomains() []Domain {
 	return domains
 }
 
-// GetDomain returns a domain by name.
+// GetDomain returns a domain by name, with the priority tier state of
+// failover domains and the active profile of scheduled domains.
 func (p *RegistryDomainProvider) GetDomain(name string) (*Domain, error) {
+	domain, err := p.getDomain(name)
+	if err != nil {
+		return nil, err
+	}
+	if tiers, ok := p.dnsRegistry.(FailoverTierProvider); ok {
+		domain.Failover = failoverState(tiers, name)
+	}
+	if p.schedules != nil {
+		if active, ok := p.schedules.Active(name); ok {
+			domain.Schedule = &DomainSchedule{
+				Schedule:    active.Schedule,
+				Profile:     active.Profile.Name,
+				Since:       active.Since,
+				NextProfile: active.NextName,
+			}
+			if !active.NextSwitch.IsZero() {
+				next := active.NextSwitch
+				domain.Schedule.NextSwitch = &next
+			}
+		}
+	}
+	return domain, nil
+}
+
+// failoverState returns the tier state of a failover domain, or nil.
+func failoverState(provider FailoverTierProvider, name string) *DomainFailover {
+	tiers, policy, ok := provider.FailoverTiers(name)
+	if !ok {
+		return nil
+	}
+	state := &DomainFailover{
+		MinHealthy:     policy.MinHealthy,
+		ManualFailback: policy.ManualFailback,
+		Tiers:          make([]FailoverTier, 0, len(tiers)),
+	}
+	if policy.FailbackHoldDown > 0 {
+		state.FailbackHoldDown = policy.FailbackHoldDown.String()
+	}
+	for _, tier := range tiers {
+		t := FailoverTier{
+			Priority: tier.Priority,
+			Backends: tier.Servers,
+			Healthy:  tier.Healthy,
+			Active:   tier.Active,
+			Panic:    tier.Panic,
+		}
+		if !tier.FailbackAt.IsZero() {
+			failbackAt := tier.FailbackAt
+			t.FailbackAt = &failbackAt
+		}
+		state.Tiers = append(state.Tiers, t)
+	}
+	return state
+}
+
+// getDomain returns a domain by name from the store, the configuration or
+// the backend registry.
+func (p *RegistryDomainProvider) getDomain(name string) (*Domain, error) {
 	// First check store for API-created domains
 	if p.store != nil {
 		key := store.PrefixDomains + name
@@ -367,6 +457,7 @@ func (p *RegistryDomainProvider) GetDomain(name string) (*Domain, error) {
 					Enabled:         true,
 					BackendCount:    backendCount,
 					HealthyBackends: healthyCount,
+					Records:         fromStaticRecords(d.Records),
 				}, nil
 			}
 		}
@@ -460,6 +551,7 @@ func (p *RegistryDomainProvider) CreateDomain(domain Domain) error {
 			)
 		} else {
 			p.logger.Info("domain registered with DNS server", "name", domain.Name)
+			p.setStaticRecords(domain)
 		}
 	}
 
@@ -533,6 +625,7 @@ func (p *RegistryDomainProvider) UpdateDomain(name string, domain Domain) error
 				"ttl", ttl,
 				"algorithm", algorithm,
 			)
+			p.setStaticRecords(domain)
 		}
 	}
 
@@ -584,6 +677,17 @@ func (p *RegistryDomainProvider) DeleteDomain(name string) error {
 	return nil
 }
 
+// setStaticRecords pushes a domain's static records to the DNS registry.
+// Failures are logged; the domain is persisted and reloaded on restart.
+func (p *RegistryDomainProvider) setStaticRecords(domain Domain) {
+	if err := p.dnsRegistry.SetStaticRecords(domain.Name, toStaticRecords(domain.Records)); err != nil {
+		p.logger.Warn("failed to update static records in DNS registry",
+			"domain", domain.Name,
+			"error", err,
+		)
+	}
+}
+
 // GetDomainBackends returns the backends for a domain.
 func (p *RegistryDomainProvider) GetDomainBackends(name string) ([]DomainBackend, error) {
 	result := make([]DomainBackend, 0)
@@ -597,14 +701,19 @@ func (p *RegistryDomainProvider) GetDomainBackends(name string) ([]DomainBackend
 					for _, r := range p.config.Regions {
 						if r.Name == regionName {
 							for _, s := range r.Servers {
+								priority := s.Priority
+								if priority == 0 {
+									priority = r.Priority
+								}
 								result = append(result, DomainBackend{
-									ID:      fmt.Sprintf("%s:%s:%d", name, s.Address, s.Port),
-									Address: s.Address,
-									Port:    s.Port,
-									Weight:  s.Weight,
-									Region:  regionName,
-									Healthy: true, // Assume healthy until checked
-									Enabled: true,
+									ID:       fmt.Sprintf("%s:%s:%d", name, s.Address, s.Port),
+									Address:  s.Address,
+									Port:     s.Port,
+									Weight:   s.Weight,
+									Priority: priority,
+									Region:   regionName,
+									Healthy:  true, // Assume healthy until checked
+									Enabled:  true,
 								})
 							}
 						}
@@ -2117,6 +2226,7 @@ func (p *StubRoutingProvider) TestRouting(request RoutingTestRequest) (*RoutingT
 	return &RoutingTestResult{
 		Domain:    request.Domain,
 		ClientIP:  request.ClientIP,
+		View:      request.View,
 		Algorithm: "round-robin",
 		Decision:  "no_healthy_backend",
 		Timestamp: time.Now(),
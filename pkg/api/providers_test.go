@@ -0,0 +1,130 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/dns"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/loganrossus/OpenGSLB/pkg/store"
+)
+
+// newDomainUpdateTest returns a domain provider whose store and DNS
+// registry both hold the domains of cfg.
+func newDomainUpdateTest(t *testing.T, cfg *config.Config) (*RegistryDomainProvider, *dns.Registry) {
+	t.Helper()
+	factory := routing.NewFactory(routing.FactoryConfig{})
+	registry, err := dns.BuildRegistry(cfg, factory.NewRouter, factory.NewPipeline)
+	if err != nil {
+		t.Fatalf("failed to build registry: %v", err)
+	}
+
+	kv, err := store.NewBboltStore(filepath.Join(t.TempDir(), "domains.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { kv.Close() })
+	for _, domain := range cfg.Domains {
+		data, _ := json.Marshal(Domain{Name: domain.Name, TTL: domain.TTL, RoutingPolicy: domain.RoutingAlgorithm})
+		if err := kv.Set(context.Background(), store.PrefixDomains+domain.Name, data); err != nil {
+			t.Fatalf("failed to store domain: %v", err)
+		}
+	}
+
+	provider := NewRegistryDomainProvider(nil, nil, nil)
+	provider.SetStore(kv)
+	provider.SetDNSRegistry(registry)
+	provider.SetRouterFactory(func(algorithm string) (interface{}, error) {
+		return factory.NewRouter(algorithm)
+	})
+	return provider, registry
+}
+
+func TestRegistryDomainProvider_UpdatePipelineDomain(t *testing.T) {
+	cfg := &config.Config{
+		Regions: []config.Region{{
+			Name:    "us-east-1",
+			Servers: []config.Server{{Address: "10.0.1.10", Port: 80, Weight: 100, Service: "app.example.com"}},
+		}},
+		Domains: []config.Domain{{
+			Name:             "app.example.com",
+			Regions:          []string{"us-east-1"},
+			TTL:              60,
+			RoutingAlgorithm: config.RoutingAlgorithmPipeline,
+			RoutingPipeline: []config.RoutingStage{
+				{Type: config.StageLabels, Match: map[string]string{"version": "v2"}},
+				{Type: config.StageSelect, Algorithm: "weighted"},
+			},
+		}},
+	}
+	provider, registry := newDomainUpdateTest(t, cfg)
+
+	if err := provider.UpdateDomain("app.example.com", Domain{TTL: 120, RoutingPolicy: "round-robin"}); err != nil {
+		t.Fatalf("UpdateDomain failed: %v", err)
+	}
+
+	entry := registry.Lookup("app.example.com")
+	pipeline, ok := entry.Router.(*routing.PipelineRouter)
+	if !ok {
+		t.Fatalf("expected the routing pipeline to survive the update, got %T", entry.Router)
+	}
+	if filters := pipeline.Filters(); len(filters) != 1 || filters[0].Stage() != config.StageLabels {
+		t.Errorf("expected the labels stage to survive the update, got %v", filters)
+	}
+	if got := pipeline.Selector().Algorithm(); got != routing.AlgorithmWeighted {
+		t.Errorf("expected the weighted select stage, got %s", got)
+	}
+	if entry.TTL != 120 {
+		t.Errorf("expected TTL 120, got %d", entry.TTL)
+	}
+}
+
+func TestRegistryDomainProvider_UpdateFailoverDomain(t *testing.T) {
+	cfg := &config.Config{
+		Regions: []config.Region{{
+			Name:    "us-east-1",
+			Servers: []config.Server{{Address: "10.0.1.10", Port: 80, Weight: 100, Service: "api.example.com"}},
+		}},
+		Domains: []config.Domain{{
+			Name:             "api.example.com",
+			Regions:          []string{"us-east-1"},
+			TTL:              60,
+			RoutingAlgorithm: "failover",
+			Failover:         &config.FailoverConfig{MinHealthy: 2, FailbackHoldDown: time.Minute},
+		}},
+	}
+	provider, registry := newDomainUpdateTest(t, cfg)
+	router := registry.Lookup("api.example.com").Router
+
+	// A TTL change keeps the router and its tier state
+	if err := provider.UpdateDomain("api.example.com", Domain{TTL: 120, RoutingPolicy: "failover"}); err != nil {
+		t.Fatalf("UpdateDomain failed: %v", err)
+	}
+	if entry := registry.Lookup("api.example.com"); entry.Router != router || entry.TTL != 120 {
+		t.Errorf("expected the failover router to be kept, got %T (TTL %d)", entry.Router, entry.TTL)
+	}
+
+	// A new failover router gets the configured policy
+	for _, algorithm := range []string{"round-robin", "failover"} {
+		if err := provider.UpdateDomain("api.example.com", Domain{TTL: 120, RoutingPolicy: algorithm}); err != nil {
+			t.Fatalf("UpdateDomain failed: %v", err)
+		}
+	}
+	failover, ok := registry.Lookup("api.example.com").Router.(*routing.FailoverRouter)
+	if !ok {
+		t.Fatalf("expected a failover router, got %T", registry.Lookup("api.example.com").Router)
+	}
+	if policy := failover.Policy(); policy.MinHealthy != 2 || policy.FailbackHoldDown != time.Minute {
+		t.Errorf("expected the configured failover policy, got %+v", policy)
+	}
+}
@@ -8,6 +8,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -49,6 +50,9 @@ type RoutingTestRequest struct {
 	ClientIP  string `json:"client_ip"`
 	QueryType string `json:"query_type"` // A, AAAA, CNAME, etc.
 	EDNS      *EDNS  `json:"edns,omitempty"`
+	// View is the split-horizon view to route in; empty selects the view
+	// matching ClientIP
+	View string `json:"view,omitempty"`
 }
 
 // EDNS contains EDNS client subnet information.
@@ -63,11 +67,13 @@ type RoutingTestResult struct {
 	ClientIP        string            `json:"client_ip"`
 	ClientRegion    string            `json:"client_region,omitempty"`
 	ClientCountry   string            `json:"client_country,omitempty"`
+	View            string            `json:"view,omitempty"`
 	Algorithm       string            `json:"algorithm"`
 	SelectedBackend *SelectedBackend  `json:"selected_backend,omitempty"`
 	Alternatives    []SelectedBackend `json:"alternatives,omitempty"`
 	Factors         []RoutingFactor   `json:"factors"`
-	Decision        string            `json:"decision"` // success, no_healthy_backend, domain_not_found
+	Stages          []RoutingStage    `json:"stages,omitempty"` // Routing pipeline stages, in order
+	Decision        string            `json:"decision"`         // success, no_healthy_backend, domain_not_found
 	DecisionTime    int64             `json:"decision_time_us"`
 	TTL             int               `json:"ttl"`
 	Timestamp       time.Time         `json:"timestamp"`
@@ -93,6 +99,16 @@ type RoutingFactor struct {
 	Impact string  `json:"impact"` // positive, negative, neutral
 }
 
+// RoutingStage shows how a routing pipeline stage narrowed the pool.
+// Backends are given as address:port.
+type RoutingStage struct {
+	Stage   string   `json:"stage"` // geo, health-tier, latency-threshold, labels, select
+	Detail  string   `json:"detail,omitempty"`
+	Input   []string `json:"input"`
+	Output  []string `json:"output"`
+	Skipped bool     `json:"skipped,omitempty"` // The stage would have removed every backend
+}
+
 // RoutingDecision represents a recorded routing decision.
 type RoutingDecision struct {
 	ID             string    `json:"id"`
@@ -286,8 +302,15 @@ func (h *RoutingHandlers) handleTest(w http.ResponseWriter, r *http.Request) {
 	if req.QueryType == "" {
 		req.QueryType = "A"
 	}
+	if req.View == "" {
+		req.View = r.URL.Query().Get("view")
+	}
 
 	result, err := h.provider.TestRouting(req)
+	if errors.Is(err, ErrNotFound) {
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("failed to test routing", "domain", req.Domain, "error", err)
 		h.writeError(w, http.StatusInternalServerError, "failed to test routing: "+err.Error())
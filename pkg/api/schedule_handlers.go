@@ -0,0 +1,283 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/schedule"
+)
+
+// ScheduleProvider defines the interface for schedule management operations.
+// Errors wrap schedule.ErrNotFound, schedule.ErrReadOnly and
+// schedule.ErrConflict.
+type ScheduleProvider interface {
+	// ListSchedules returns every schedule, from the configuration file and
+	// the API.
+	ListSchedules() []schedule.Schedule
+	// GetSchedule returns a schedule by name.
+	GetSchedule(name string) (*schedule.Schedule, error)
+	// PutSchedule creates or replaces a schedule and applies it.
+	PutSchedule(ctx context.Context, sched schedule.Schedule) error
+	// DeleteSchedule deletes a schedule created through the API.
+	DeleteSchedule(ctx context.Context, name string) error
+	// PreviewSchedule returns the profile of a schedule active at a time and
+	// the backend weights it results in.
+	PreviewSchedule(name string, at time.Time) (*SchedulePreview, error)
+}
+
+// ScheduleListResponse is the response for GET /api/v1/schedules.
+type ScheduleListResponse struct {
+	Schedules   []schedule.Schedule `json:"schedules"`
+	Total       int                 `json:"total"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
+
+// ScheduleResponse is the response for single schedule operations.
+type ScheduleResponse struct {
+	Schedule schedule.Schedule `json:"schedule"`
+}
+
+// SchedulePreview is the response for GET /api/v1/schedules/{name}/preview.
+type SchedulePreview struct {
+	At     time.Time       `json:"at"`
+	Active schedule.Active `json:"active"`
+	// Backends are the domain's backends with the profile's weights and
+	// priorities applied
+	Backends []SchedulePreviewBackend `json:"backends"`
+}
+
+// SchedulePreviewBackend is a backend's routing settings under a profile.
+type SchedulePreviewBackend struct {
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	Region   string `json:"region,omitempty"`
+	Weight   int    `json:"weight"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// ScheduleHandlers provides HTTP handlers for schedule API endpoints.
+type ScheduleHandlers struct {
+	provider ScheduleProvider
+	logger   *slog.Logger
+}
+
+// NewScheduleHandlers creates a new ScheduleHandlers instance.
+func NewScheduleHandlers(provider ScheduleProvider, logger *slog.Logger) *ScheduleHandlers {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ScheduleHandlers{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+// HandleSchedules routes /api/v1/schedules requests based on HTTP method and path.
+func (h *ScheduleHandlers) HandleSchedules(w http.ResponseWriter, r *http.Request) {
+	// Expected paths:
+	//   GET    /api/v1/schedules                -> list
+	//   POST   /api/v1/schedules                -> create
+	//   GET    /api/v1/schedules/{name}         -> get
+	//   PUT    /api/v1/schedules/{name}         -> create or replace
+	//   DELETE /api/v1/schedules/{name}         -> delete
+	//   GET    /api/v1/schedules/{name}/preview -> preview (?at=RFC 3339 time)
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/schedules")
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.listSchedules(w)
+		case http.MethodPost:
+			h.createSchedule(w, r)
+		default:
+			h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	if name, ok := strings.CutSuffix(path, "/preview"); ok {
+		if r.Method != http.MethodGet {
+			h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.previewSchedule(w, r, name)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getSchedule(w, path)
+	case http.MethodPut:
+		h.putSchedule(w, r, path)
+	case http.MethodDelete:
+		h.deleteSchedule(w, r, path)
+	default:
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// listSchedules handles GET /api/v1/schedules.
+func (h *ScheduleHandlers) listSchedules(w http.ResponseWriter) {
+	if h.provider == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "schedule provider not configured")
+		return
+	}
+
+	schedules := h.provider.ListSchedules()
+	h.writeJSON(w, http.StatusOK, ScheduleListResponse{
+		Schedules:   schedules,
+		Total:       len(schedules),
+		GeneratedAt: time.Now().UTC(),
+	})
+}
+
+// getSchedule handles GET /api/v1/schedules/{name}.
+func (h *ScheduleHandlers) getSchedule(w http.ResponseWriter, name string) {
+	if h.provider == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "schedule provider not configured")
+		return
+	}
+
+	sched, err := h.provider.GetSchedule(name)
+	if err != nil {
+		h.writeScheduleError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, ScheduleResponse{Schedule: *sched})
+}
+
+// createSchedule handles POST /api/v1/schedules.
+func (h *ScheduleHandlers) createSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.provider == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "schedule provider not configured")
+		return
+	}
+
+	var sched schedule.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&sched); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if _, err := h.provider.GetSchedule(sched.Name); err == nil {
+		h.writeError(w, http.StatusConflict, "schedule already exists: "+sched.Name)
+		return
+	}
+	h.saveSchedule(w, r, sched, http.StatusCreated)
+}
+
+// putSchedule handles PUT /api/v1/schedules/{name}.
+func (h *ScheduleHandlers) putSchedule(w http.ResponseWriter, r *http.Request, name string) {
+	if h.provider == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "schedule provider not configured")
+		return
+	}
+
+	var sched schedule.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&sched); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	sched.Name = name
+	h.saveSchedule(w, r, sched, http.StatusOK)
+}
+
+// saveSchedule validates and stores a schedule, responding with status.
+func (h *ScheduleHandlers) saveSchedule(w http.ResponseWriter, r *http.Request, sched schedule.Schedule, status int) {
+	if err := sched.Validate(); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid schedule: "+err.Error())
+		return
+	}
+	if err := h.provider.PutSchedule(r.Context(), sched); err != nil {
+		h.writeScheduleError(w, err)
+		return
+	}
+
+	saved, err := h.provider.GetSchedule(sched.Name)
+	if err != nil {
+		h.writeScheduleError(w, err)
+		return
+	}
+	h.logger.Info("schedule saved", "name", saved.Name, "domain", saved.Domain)
+	h.writeJSON(w, status, ScheduleResponse{Schedule: *saved})
+}
+
+// deleteSchedule handles DELETE /api/v1/schedules/{name}.
+func (h *ScheduleHandlers) deleteSchedule(w http.ResponseWriter, r *http.Request, name string) {
+	if h.provider == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "schedule provider not configured")
+		return
+	}
+
+	if err := h.provider.DeleteSchedule(r.Context(), name); err != nil {
+		h.writeScheduleError(w, err)
+		return
+	}
+	h.logger.Info("schedule deleted", "name", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// previewSchedule handles GET /api/v1/schedules/{name}/preview.
+func (h *ScheduleHandlers) previewSchedule(w http.ResponseWriter, r *http.Request, name string) {
+	if h.provider == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "schedule provider not configured")
+		return
+	}
+
+	at := time.Now().UTC()
+	if s := r.URL.Query().Get("at"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid at parameter: must be an RFC 3339 time")
+			return
+		}
+		at = t
+	}
+
+	preview, err := h.provider.PreviewSchedule(name, at)
+	if err != nil {
+		h.writeScheduleError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, preview)
+}
+
+// writeScheduleError writes the error response for a provider error.
+func (h *ScheduleHandlers) writeScheduleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, schedule.ErrNotFound):
+		h.writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, schedule.ErrReadOnly), errors.Is(err, schedule.ErrConflict):
+		h.writeError(w, http.StatusConflict, err.Error())
+	default:
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// writeJSON writes a JSON response with the given status code.
+func (h *ScheduleHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// writeError writes a JSON error response.
+func (h *ScheduleHandlers) writeError(w http.ResponseWriter, status int, message string) {
+	h.writeJSON(w, status, ErrorResponse{
+		Error: message,
+		Code:  status,
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/schedule"
+)
+
+// schedulerProvider serves the schedule API from a scheduler, previewing
+// without backends.
+type schedulerProvider struct {
+	*schedule.Scheduler
+}
+
+func (p schedulerProvider) ListSchedules() []schedule.Schedule { return p.List() }
+
+func (p schedulerProvider) GetSchedule(name string) (*schedule.Schedule, error) { return p.Get(name) }
+
+func (p schedulerProvider) PutSchedule(ctx context.Context, s schedule.Schedule) error {
+	return p.Put(ctx, s)
+}
+
+func (p schedulerProvider) DeleteSchedule(ctx context.Context, name string) error {
+	return p.Delete(ctx, name)
+}
+
+func (p schedulerProvider) PreviewSchedule(name string, at time.Time) (*SchedulePreview, error) {
+	active, err := p.Preview(name, at)
+	if err != nil {
+		return nil, err
+	}
+	return &SchedulePreview{At: at, Active: active}, nil
+}
+
+func TestScheduleHandlers(t *testing.T) {
+	scheduler := schedule.New(schedule.Config{})
+	if err := scheduler.SetConfigSchedules([]schedule.Schedule{{
+		Name:     "config",
+		Domain:   "app.example.com",
+		Profiles: []schedule.Profile{{Name: "always", Start: "0 0 * * *"}},
+	}}); err != nil {
+		t.Fatalf("SetConfigSchedules failed: %v", err)
+	}
+	h := NewScheduleHandlers(schedulerProvider{scheduler}, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		h.HandleSchedules(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	followTheSun := `{"domain": "api.example.com", "timezone": "America/New_York", "profiles": [
+		{"name": "day", "start": "0 8 * * *", "region_weights": {"us-east": 100}},
+		{"name": "night", "start": "0 20 * * *", "region_weights": {"us-east": 10}}]}`
+
+	if rr := do(http.MethodPut, "/api/v1/schedules/sun", followTheSun); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body)
+	}
+	if rr := do(http.MethodPut, "/api/v1/schedules/config", followTheSun); rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a configured schedule, got %d", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/api/v1/schedules", `{"name": "bad", "domain": "x.example.com", "profiles": [{"name": "p", "start": "never"}]}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid cron expression, got %d", rr.Code)
+	}
+
+	// 02:00 UTC is 21:00 the day before in New York
+	rr := do(http.MethodGet, "/api/v1/schedules/sun/preview?at=2025-01-15T02:00:00Z", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var preview SchedulePreview
+	if err := json.NewDecoder(rr.Body).Decode(&preview); err != nil {
+		t.Fatalf("failed to decode preview: %v", err)
+	}
+	if preview.Active.Profile == nil || preview.Active.Profile.Name != "night" {
+		t.Errorf("expected night profile, got %+v", preview.Active)
+	}
+
+	if rr := do(http.MethodGet, "/api/v1/schedules/sun/preview?at=02:00", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid time, got %d", rr.Code)
+	}
+	if rr := do(http.MethodDelete, "/api/v1/schedules/sun", ""); rr.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rr.Code)
+	}
+	if rr := do(http.MethodGet, "/api/v1/schedules/sun", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", rr.Code)
+	}
+}
@@ -62,6 +62,7 @@ type Server struct {
 	metricsHandlers      *MetricsHandlers
 	configHandlers       *ConfigHandlers
 	routingHandlers      *RoutingHandlers
+	scheduleHandlers     *ScheduleHandlers
 
 	// Discovery handlers for walkable API
 	discoveryHandlers *DiscoveryHandlers
@@ -152,6 +153,11 @@ func (s *Server) SetRoutingHandlers(h *RoutingHandlers) {
 	s.routingHandlers = h
 }
 
+// SetScheduleHandlers sets the schedule handlers.
+func (s *Server) SetScheduleHandlers(h *ScheduleHandlers) {
+	s.scheduleHandlers = h
+}
+
 // Start starts the API server.
 func (s *Server) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
@@ -271,6 +277,13 @@ func (s *Server) Start(ctx context.Context) error {
 		s.logger.Debug("routing API endpoints registered")
 	}
 
+	// Schedule endpoints
+	if s.scheduleHandlers != nil {
+		mux.HandleFunc("/api/v1/schedules", s.withACL(s.scheduleHandlers.HandleSchedules))
+		mux.HandleFunc("/api/v1/schedules/", s.withACL(s.scheduleHandlers.HandleSchedules))
+		s.logger.Debug("schedule API endpoints registered")
+	}
+
 	// Create discovery handlers for walkable API
 	s.discoveryHandlers = NewDiscoveryHandlers()
 
@@ -16,8 +16,52 @@ import (
 // Default configuration values.
 const (
 	// DNS defaults
-	DefaultListenAddress = ":53"
-	DefaultTTL           = 60
+	DefaultListenAddress  = ":53"
+	DefaultTTL            = 60
+	DefaultLastHealthyTTL = 10
+	DefaultMaxUDPSize     = 1232 // Largest UDP payload unlikely to be fragmented (DNS Flag Day 2020)
+
+	// Encrypted DNS defaults
+	DefaultDoTListenAddress = ":853"
+	DefaultDoHListenAddress = ":443"
+	DefaultDoHPath          = "/dns-query"
+
+	// Zone transfer defaults
+	DefaultTSIGAlgorithm         = "hmac-sha256"
+	DefaultTransferCheckInterval = 5 * time.Second
+
+	// Dynamic update defaults
+	DefaultUpdatePort   = 80
+	DefaultUpdateWeight = 100
+
+	// DefaultViewName is the view answering clients that match no configured view
+	DefaultViewName = "default"
+
+	// Response rate limiting defaults
+	DefaultRateLimitResponsesPerSecond = 10
+	DefaultRateLimitSlip               = 2
+	DefaultRateLimitIPv4PrefixLength   = 24
+	DefaultRateLimitIPv6PrefixLength   = 56
+	DefaultRateLimitTableSize          = 100000
+
+	// Fallthrough upstream defaults
+	DefaultFallthroughPort      = 53
+	DefaultFallthroughTimeout   = 2 * time.Second
+	DefaultFallthroughCacheSize = 10000
+
+	// DefaultDNS64Prefix is the well-known NAT64 prefix (RFC 6052)
+	DefaultDNS64Prefix = "64:ff9b::/96"
+
+	// Query log defaults
+	DefaultQueryLogBufferSize = 10000
+	DefaultQueryLogMaxSizeMB  = 100
+	DefaultQueryLogMaxBackups = 5
+
+	// SOA defaults
+	DefaultSOARefresh = 1 * time.Hour
+	DefaultSOARetry   = 10 * time.Minute
+	DefaultSOAExpire  = 7 * 24 * time.Hour
+	DefaultSOAMinimum = 60 * time.Second
 
 	// Server defaults
 	DefaultServerPort   = 80
@@ -34,6 +78,10 @@ const (
 	// Routing defaults
 	DefaultRoutingAlgorithm = "round-robin"
 
+	// RoutingAlgorithmPipeline is the routing algorithm of domains with a
+	// routing pipeline
+	RoutingAlgorithmPipeline = "pipeline"
+
 	// Logging defaults
 	DefaultLogLevel  = "info"
 	DefaultLogFormat = "json"
@@ -167,6 +215,41 @@ func applyOverwatchDefaults(cfg *Config) {
 	if cfg.DNS.DefaultTTL == 0 {
 		cfg.DNS.DefaultTTL = DefaultTTL
 	}
+	if cfg.DNS.LastHealthyTTL == 0 {
+		cfg.DNS.LastHealthyTTL = DefaultLastHealthyTTL
+	}
+	if cfg.DNS.MaxUDPSize == 0 {
+		cfg.DNS.MaxUDPSize = DefaultMaxUDPSize
+	}
+	applySOADefaults(&cfg.DNS.SOA)
+	if cfg.DNS.DoT.ListenAddress == "" {
+		cfg.DNS.DoT.ListenAddress = DefaultDoTListenAddress
+	}
+	if cfg.DNS.DoH.ListenAddress == "" {
+		cfg.DNS.DoH.ListenAddress = DefaultDoHListenAddress
+	}
+	if cfg.DNS.DoH.Path == "" {
+		cfg.DNS.DoH.Path = DefaultDoHPath
+	}
+	for i := range cfg.DNS.TSIGKeys {
+		if cfg.DNS.TSIGKeys[i].Algorithm == "" {
+			cfg.DNS.TSIGKeys[i].Algorithm = DefaultTSIGAlgorithm
+		}
+	}
+	if cfg.DNS.Transfer.CheckInterval == 0 {
+		cfg.DNS.Transfer.CheckInterval = DefaultTransferCheckInterval
+	}
+	if cfg.DNS.Update.Port == 0 {
+		cfg.DNS.Update.Port = DefaultUpdatePort
+	}
+	if cfg.DNS.Update.Weight == 0 {
+		cfg.DNS.Update.Weight = DefaultUpdateWeight
+	}
+	applyRateLimitDefaults(&cfg.DNS.RateLimit)
+	applyQueryLogDefaults(&cfg.DNS.QueryLog)
+	for i := range cfg.DNS.Fallthrough {
+		applyFallthroughDefaults(&cfg.DNS.Fallthrough[i])
+	}
 
 	// Gossip defaults - only apply bind_address default if gossip is enabled (has encryption key)
 	if cfg.Overwatch.Gossip.EncryptionKey != "" && cfg.Overwatch.Gossip.BindAddress == "" {
@@ -221,6 +304,60 @@ func applyOverwatchDefaults(cfg *Config) {
 	for i := range cfg.Domains {
 		applyDomainDefaults(&cfg.Domains[i], cfg.DNS.DefaultTTL)
 	}
+	for i := range cfg.Views {
+		applyDNS64Defaults(&cfg.Views[i].DNS64)
+	}
+}
+
+func applySOADefaults(soa *SOAConfig) {
+	if soa.Refresh == 0 {
+		soa.Refresh = DefaultSOARefresh
+	}
+	if soa.Retry == 0 {
+		soa.Retry = DefaultSOARetry
+	}
+	if soa.Expire == 0 {
+		soa.Expire = DefaultSOAExpire
+	}
+	if soa.Minimum == 0 {
+		soa.Minimum = DefaultSOAMinimum
+	}
+}
+
+func applyRateLimitDefaults(rl *RateLimitConfig) {
+	if rl.ResponsesPerSecond == 0 {
+		rl.ResponsesPerSecond = DefaultRateLimitResponsesPerSecond
+	}
+	if rl.NXDomainsPerSecond == 0 {
+		rl.NXDomainsPerSecond = rl.ResponsesPerSecond
+	}
+	if rl.ErrorsPerSecond == 0 {
+		rl.ErrorsPerSecond = rl.ResponsesPerSecond
+	}
+	if rl.Slip == 0 {
+		rl.Slip = DefaultRateLimitSlip
+	}
+	if rl.IPv4PrefixLength == 0 {
+		rl.IPv4PrefixLength = DefaultRateLimitIPv4PrefixLength
+	}
+	if rl.IPv6PrefixLength == 0 {
+		rl.IPv6PrefixLength = DefaultRateLimitIPv6PrefixLength
+	}
+	if rl.TableSize == 0 {
+		rl.TableSize = DefaultRateLimitTableSize
+	}
+}
+
+func applyQueryLogDefaults(ql *QueryLogConfig) {
+	if ql.BufferSize == 0 {
+		ql.BufferSize = DefaultQueryLogBufferSize
+	}
+	if ql.File.MaxSizeMB == 0 {
+		ql.File.MaxSizeMB = DefaultQueryLogMaxSizeMB
+	}
+	if ql.File.MaxBackups == 0 {
+		ql.File.MaxBackups = DefaultQueryLogMaxBackups
+	}
 }
 
 func applyBackendHealthCheckDefaults(hc *HealthCheck) {
@@ -316,8 +453,27 @@ func applyRegionDefaults(r *Region) {
 func applyDomainDefaults(d *Domain, defaultTTL int) {
 	if d.RoutingAlgorithm == "" {
 		d.RoutingAlgorithm = DefaultRoutingAlgorithm
+		if len(d.RoutingPipeline) > 0 {
+			d.RoutingAlgorithm = RoutingAlgorithmPipeline
+		}
 	}
 	if d.TTL == 0 {
 		d.TTL = defaultTTL
 	}
+	applyDNS64Defaults(&d.DNS64)
+}
+
+func applyFallthroughDefaults(f *FallthroughConfig) {
+	if f.Timeout == 0 {
+		f.Timeout = DefaultFallthroughTimeout
+	}
+	if f.CacheSize == 0 {
+		f.CacheSize = DefaultFallthroughCacheSize
+	}
+}
+
+func applyDNS64Defaults(d *DNS64Config) {
+	if d.Enabled && d.Prefix == "" {
+		d.Prefix = DefaultDNS64Prefix
+	}
 }
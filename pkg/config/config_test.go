@@ -1337,3 +1337,918 @@ func TestValidate_MixedIPv4IPv6(t *testing.T) {
 		t.Errorf("unexpected error for mixed IPv4/IPv6: %v", err)
 	}
 }
+
+// =============================================================================
+// Zone Authority Tests
+// =============================================================================
+
+func TestParse_SOADefaults(t *testing.T) {
+	yaml := `
+mode: overwatch
+overwatch:
+  gossip:
+    encryption_key: "` + validEncryptionKey() + `"
+dns:
+  zones: ["gslb.example.com"]
+  nameservers:
+    - name: ns1.gslb.example.com
+      address: 192.0.2.53
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	soa := cfg.DNS.SOA
+	if soa.Refresh != DefaultSOARefresh || soa.Retry != DefaultSOARetry ||
+		soa.Expire != DefaultSOAExpire || soa.Minimum != DefaultSOAMinimum {
+		t.Errorf("expected SOA defaults, got %+v", soa)
+	}
+	if cfg.DNS.LastHealthyTTL != DefaultLastHealthyTTL {
+		t.Errorf("expected last_healthy_ttl %d, got %d", DefaultLastHealthyTTL, cfg.DNS.LastHealthyTTL)
+	}
+	if len(cfg.DNS.Nameservers) != 1 || cfg.DNS.Nameservers[0].Address != "192.0.2.53" {
+		t.Errorf("unexpected nameservers: %+v", cfg.DNS.Nameservers)
+	}
+}
+
+func TestValidate_DNSNameservers(t *testing.T) {
+	tests := []struct {
+		name        string
+		nameservers []NameserverConfig
+		wantErr     string
+	}{
+		{
+			name:        "in-zone with glue",
+			nameservers: []NameserverConfig{{Name: "ns1.gslb.example.com", Address: "192.0.2.53"}},
+		},
+		{
+			name:        "out-of-zone without glue",
+			nameservers: []NameserverConfig{{Name: "ns1.example.net"}},
+		},
+		{
+			name:        "in-zone without glue",
+			nameservers: []NameserverConfig{{Name: "ns1.gslb.example.com"}},
+			wantErr:     "needs glue",
+		},
+		{
+			name:        "invalid address",
+			nameservers: []NameserverConfig{{Name: "ns1.example.net", Address: "not-an-ip"}},
+			wantErr:     "invalid IP address",
+		},
+		{
+			name: "duplicate",
+			nameservers: []NameserverConfig{
+				{Name: "ns1.example.net"},
+				{Name: "NS1.example.net."},
+			},
+			wantErr: "duplicate nameserver",
+		},
+		{
+			name:        "missing name",
+			nameservers: []NameserverConfig{{Address: "192.0.2.53"}},
+			wantErr:     "name is required",
+		},
+		{
+			name:    "zones without nameservers",
+			wantErr: "nameservers are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.Zones = []string{"gslb.example.com"}
+			cfg.DNS.Nameservers = tt.nameservers
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_EncryptedDNS(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*DNSConfig)
+		wantErr string
+	}{
+		{
+			name: "disabled without certificate",
+		},
+		{
+			name: "dot and doh with certificate",
+			mutate: func(d *DNSConfig) {
+				d.TLS = DNSTLSConfig{CertFile: "/etc/opengslb/dns.crt", KeyFile: "/etc/opengslb/dns.key"}
+				d.DoT = DoTConfig{Enabled: true, ListenAddress: ":853"}
+				d.DoH = DoHConfig{Enabled: true, ListenAddress: ":443", Path: "/dns-query"}
+			},
+		},
+		{
+			name: "missing certificate",
+			mutate: func(d *DNSConfig) {
+				d.DoT.Enabled = true
+			},
+			wantErr: "tls.cert_file and tls.key_file are required",
+		},
+		{
+			name: "invalid doh path",
+			mutate: func(d *DNSConfig) {
+				d.TLS = DNSTLSConfig{CertFile: "dns.crt", KeyFile: "dns.key"}
+				d.DoH = DoHConfig{Enabled: true, Path: "dns-query"}
+			},
+			wantErr: "doh.path",
+		},
+		{
+			name: "shared listen address",
+			mutate: func(d *DNSConfig) {
+				d.TLS = DNSTLSConfig{CertFile: "dns.crt", KeyFile: "dns.key"}
+				d.DoT = DoTConfig{Enabled: true, ListenAddress: ":8443"}
+				d.DoH = DoHConfig{Enabled: true, ListenAddress: ":8443"}
+			},
+			wantErr: "must differ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			if tt.mutate != nil {
+				tt.mutate(&cfg.DNS)
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ZoneTransfer(t *testing.T) {
+	secret := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	tests := []struct {
+		name     string
+		keys     []TSIGKeyConfig
+		transfer TransferConfig
+		wantErr  string
+	}{
+		{
+			name: "acl, key and notify",
+			keys: []TSIGKeyConfig{{Name: "transfer-key", Secret: secret}},
+			transfer: TransferConfig{
+				AllowFrom: []string{"192.0.2.0/24", "2001:db8::1"},
+				TSIGKeys:  []string{"transfer-key."},
+				Notify:    []string{"192.0.2.10", "[2001:db8::10]:5353"},
+			},
+		},
+		{
+			name:     "invalid allow_from",
+			transfer: TransferConfig{AllowFrom: []string{"not-a-network"}},
+			wantErr:  "transfer.allow_from[0]",
+		},
+		{
+			name:     "unknown key",
+			transfer: TransferConfig{TSIGKeys: []string{"missing"}},
+			wantErr:  "unknown key",
+		},
+		{
+			name:     "notify hostname",
+			transfer: TransferConfig{Notify: []string{"ns2.example.net"}},
+			wantErr:  "transfer.notify[0]",
+		},
+		{
+			name:    "invalid algorithm",
+			keys:    []TSIGKeyConfig{{Name: "k", Algorithm: "hmac-md5", Secret: secret}},
+			wantErr: "algorithm",
+		},
+		{
+			name:    "invalid secret",
+			keys:    []TSIGKeyConfig{{Name: "k", Secret: "!!"}},
+			wantErr: "base64",
+		},
+		{
+			name:    "duplicate key",
+			keys:    []TSIGKeyConfig{{Name: "k", Secret: secret}, {Name: "K.", Secret: secret}},
+			wantErr: "duplicate key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.TSIGKeys = tt.keys
+			cfg.DNS.Transfer = tt.transfer
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_DynamicUpdate(t *testing.T) {
+	secret := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	tests := []struct {
+		name    string
+		update  DNSUpdateConfig
+		wantErr string
+	}{
+		{name: "valid", update: DNSUpdateConfig{TSIGKeys: []string{"update-key"}, Port: 443, Weight: 50}},
+		{name: "unknown key", update: DNSUpdateConfig{TSIGKeys: []string{"missing"}}, wantErr: "update.tsig_keys[0]"},
+		{name: "invalid port", update: DNSUpdateConfig{Port: 70000}, wantErr: "update.port"},
+		{name: "negative weight", update: DNSUpdateConfig{Weight: -1}, wantErr: "update.weight"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.TSIGKeys = []TSIGKeyConfig{{Name: "update-key", Secret: secret}}
+			cfg.DNS.Update = tt.update
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_RateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		rl      RateLimitConfig
+		wantErr string
+	}{
+		{name: "valid", rl: RateLimitConfig{Enabled: true, ResponsesPerSecond: 5, Slip: -1, AllowFrom: []string{"10.0.0.0/8"}}},
+		{name: "negative rate", rl: RateLimitConfig{ErrorsPerSecond: -1}, wantErr: "rates must be non-negative"},
+		{name: "invalid slip", rl: RateLimitConfig{Slip: -2}, wantErr: "rate_limit.slip"},
+		{name: "invalid ipv4 prefix", rl: RateLimitConfig{IPv4PrefixLength: 33}, wantErr: "ipv4_prefix_length"},
+		{name: "invalid ipv6 prefix", rl: RateLimitConfig{IPv6PrefixLength: 129}, wantErr: "ipv6_prefix_length"},
+		{name: "invalid allow_from", rl: RateLimitConfig{AllowFrom: []string{"nope"}}, wantErr: "rate_limit.allow_from[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.RateLimit = tt.rl
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Views(t *testing.T) {
+	secret := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	internal := func(domains ...ViewDomain) View {
+		return View{Name: "internal", MatchClients: []string{"10.0.0.0/8"}, Domains: domains}
+	}
+	tests := []struct {
+		name    string
+		views   []View
+		wantErr string
+	}{
+		{name: "valid", views: []View{
+			internal(ViewDomain{Name: "app.example.com", Regions: []string{"us-east-1"}, RoutingAlgorithm: "weighted"}),
+			{Name: "partners", TSIGKeys: []string{"partner-key"}},
+		}},
+		{name: "missing name", views: []View{{MatchClients: []string{"10.0.0.1"}}}, wantErr: "views[0].name is required"},
+		{name: "reserved name", views: []View{{Name: DefaultViewName, MatchClients: []string{"10.0.0.1"}}}, wantErr: "duplicate view name"},
+		{name: "duplicate name", views: []View{internal(), internal()}, wantErr: "views[1]: duplicate view name"},
+		{name: "no selector", views: []View{{Name: "internal"}}, wantErr: "match_clients or tsig_keys is required"},
+		{name: "invalid network", views: []View{{Name: "internal", MatchClients: []string{"nope"}}}, wantErr: "views[0].match_clients[0]"},
+		{name: "unknown key", views: []View{{Name: "internal", TSIGKeys: []string{"missing"}}}, wantErr: "views[0].tsig_keys[0]"},
+		{name: "unknown domain", views: []View{internal(ViewDomain{Name: "other.example.com"})}, wantErr: "views[0].domains[0]"},
+		{name: "unknown region", views: []View{internal(ViewDomain{Name: "app.example.com", Regions: []string{"eu-west-1"}})}, wantErr: "region \"eu-west-1\" not found"},
+		{name: "invalid algorithm", views: []View{internal(ViewDomain{Name: "app.example.com", RoutingAlgorithm: "random"})}, wantErr: "views[0].domains[0].routing_algorithm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.TSIGKeys = []TSIGKeyConfig{{Name: "partner-key", Secret: secret}}
+			cfg.Views = tt.views
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ViewDomains(t *testing.T) {
+	cfg := validOverwatchConfig()
+	domains := cfg.ViewDomains(View{Domains: []ViewDomain{{Name: "app.example.com", Regions: []string{"private"}}}})
+	if len(domains) != 1 || domains[0].Regions[0] != "private" || domains[0].RoutingAlgorithm != "round-robin" {
+		t.Errorf("unexpected view domains: %+v", domains)
+	}
+	if cfg.Domains[0].Regions[0] != "us-east-1" {
+		t.Error("expected configured domain to be unchanged")
+	}
+
+	// A view with DNS64 answers every domain with it
+	dns64 := DNS64Config{Enabled: true, Prefix: DefaultDNS64Prefix}
+	domains = cfg.ViewDomains(View{DNS64: dns64})
+	if len(domains) != len(cfg.Domains) || domains[0].DNS64 != dns64 {
+		t.Errorf("expected all domains with DNS64 in the view, got %+v", domains)
+	}
+	if cfg.Domains[0].DNS64.Enabled {
+		t.Error("expected configured domain to be unchanged")
+	}
+}
+
+func TestApplyDefaults_RateLimit(t *testing.T) {
+	rl := RateLimitConfig{ResponsesPerSecond: 20}
+	applyRateLimitDefaults(&rl)
+
+	if rl.NXDomainsPerSecond != 20 || rl.ErrorsPerSecond != 20 {
+		t.Errorf("expected nxdomain and error rates to follow responses_per_second, got %d and %d",
+			rl.NXDomainsPerSecond, rl.ErrorsPerSecond)
+	}
+	if rl.Slip != DefaultRateLimitSlip || rl.IPv4PrefixLength != 24 || rl.IPv6PrefixLength != 56 {
+		t.Errorf("unexpected defaults: %+v", rl)
+	}
+}
+
+func TestValidate_QueryLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		ql      QueryLogConfig
+		wantErr string
+	}{
+		{name: "valid", ql: QueryLogConfig{Enabled: true, Dnstap: DnstapConfig{Socket: "/run/dnstap.sock"}, File: QueryLogFileConfig{Path: "/var/log/queries.log"}}},
+		{name: "disabled without output", ql: QueryLogConfig{}},
+		{name: "negative buffer", ql: QueryLogConfig{BufferSize: -1}, wantErr: "query_log.buffer_size"},
+		{name: "socket and file", ql: QueryLogConfig{Dnstap: DnstapConfig{Socket: "/run/dnstap.sock", File: "/tmp/q.dnstap"}}, wantErr: "mutually exclusive"},
+		{name: "negative rotation", ql: QueryLogConfig{File: QueryLogFileConfig{MaxBackups: -1}}, wantErr: "query_log.file"},
+		{name: "enabled without output", ql: QueryLogConfig{Enabled: true}, wantErr: "required when enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.QueryLog = tt.ql
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_Fallthrough(t *testing.T) {
+	f := FallthroughConfig{Zone: "example.com", Upstreams: []string{"192.0.2.53"}}
+	applyFallthroughDefaults(&f)
+
+	if f.Timeout != DefaultFallthroughTimeout || f.CacheSize != DefaultFallthroughCacheSize {
+		t.Errorf("unexpected defaults: %+v", f)
+	}
+}
+
+func TestApplyDefaults_QueryLog(t *testing.T) {
+	ql := QueryLogConfig{File: QueryLogFileConfig{MaxBackups: 2}}
+	applyQueryLogDefaults(&ql)
+
+	if ql.BufferSize != DefaultQueryLogBufferSize || ql.File.MaxSizeMB != DefaultQueryLogMaxSizeMB {
+		t.Errorf("unexpected defaults: %+v", ql)
+	}
+	if ql.File.MaxBackups != 2 {
+		t.Errorf("expected configured max_backups to be kept, got %d", ql.File.MaxBackups)
+	}
+}
+
+func TestValidate_SOAExpireShorterThanRefresh(t *testing.T) {
+	cfg := validOverwatchConfig()
+	cfg.DNS.SOA = SOAConfig{Refresh: time.Hour, Expire: time.Minute}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "soa.expire") {
+		t.Errorf("expected soa.expire error, got %v", err)
+	}
+}
+
+func TestValidate_MaxUDPSize(t *testing.T) {
+	for _, size := range []int{-1, 511, 65536} {
+		cfg := validOverwatchConfig()
+		cfg.DNS.MaxUDPSize = size
+
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "max_udp_size") {
+			t.Errorf("max_udp_size %d: expected error, got %v", size, err)
+		}
+	}
+}
+
+func TestValidate_HTTPSRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		https   *HTTPSRecordConfig
+		wantErr string
+	}{
+		{name: "valid", https: &HTTPSRecordConfig{ALPN: []string{"h3", "h2"}, Port: 8443, ECH: "AAE="}},
+		{name: "empty", https: &HTTPSRecordConfig{}},
+		{name: "invalid alpn", https: &HTTPSRecordConfig{ALPN: []string{"h3,h2"}}, wantErr: "https: alpn[0]"},
+		{name: "invalid port", https: &HTTPSRecordConfig{Port: 70000}, wantErr: "https: port"},
+		{name: "invalid ech", https: &HTTPSRecordConfig{ECH: "not base64!"}, wantErr: "https: ech"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Domains[0].HTTPS = tt.https
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Fallthrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		ft      []FallthroughConfig
+		wantErr string
+	}{
+		{name: "valid", ft: []FallthroughConfig{{Zone: "Example.com.", Upstreams: []string{"192.0.2.53", "ns1.legacy.example:5353"}}}},
+		{name: "zone not served", ft: []FallthroughConfig{{Zone: "example.org", Upstreams: []string{"192.0.2.53"}}}, wantErr: "must be one of dns.zones"},
+		{name: "duplicate zone", ft: []FallthroughConfig{
+			{Zone: "example.com", Upstreams: []string{"192.0.2.53"}},
+			{Zone: "example.com.", Upstreams: []string{"192.0.2.54"}},
+		}, wantErr: "duplicate zone"},
+		{name: "no upstreams", ft: []FallthroughConfig{{Zone: "example.com"}}, wantErr: "at least one upstream"},
+		{name: "invalid port", ft: []FallthroughConfig{{Zone: "example.com", Upstreams: []string{"192.0.2.53:0"}}}, wantErr: "invalid port"},
+		{name: "negative timeout", ft: []FallthroughConfig{{Zone: "example.com", Upstreams: []string{"192.0.2.53"}, Timeout: -time.Second}}, wantErr: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.DNS.Zones = []string{"example.com"}
+			cfg.DNS.Nameservers = []NameserverConfig{{Name: "ns1.example.net"}}
+			cfg.DNS.Fallthrough = tt.ft
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUpstreamAddress(t *testing.T) {
+	tests := []struct {
+		upstream string
+		want     string
+	}{
+		{upstream: "192.0.2.53", want: "192.0.2.53:53"},
+		{upstream: "192.0.2.53:5353", want: "192.0.2.53:5353"},
+		{upstream: "2001:db8::53", want: "[2001:db8::53]:53"},
+		{upstream: "[2001:db8::53]:5353", want: "[2001:db8::53]:5353"},
+		{upstream: "ns1.legacy.example", want: "ns1.legacy.example:53"},
+		{upstream: "[2001:db8::53]"},
+		{upstream: ":53"},
+	}
+	for _, tt := range tests {
+		got, err := UpstreamAddress(tt.upstream)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("UpstreamAddress(%q): expected error, got %s", tt.upstream, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("UpstreamAddress(%q) = %s, %v; want %s", tt.upstream, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidate_DNS64(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		wantErr string
+	}{
+		{name: "well-known prefix", prefix: "64:ff9b::/96"},
+		{name: "network-specific prefix", prefix: "2001:db8:64::/96"},
+		{name: "invalid prefix", prefix: "64:ff9b::", wantErr: "dns64: invalid prefix"},
+		{name: "wrong length", prefix: "2001:db8::/64", wantErr: "must be an IPv6 /96"},
+		{name: "ipv4 prefix", prefix: "10.0.0.0/8", wantErr: "must be an IPv6 /96"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Domains[0].DNS64 = DNS64Config{Enabled: true, Prefix: tt.prefix}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_RoutingPipeline(t *testing.T) {
+	tierAndSelect := []RoutingStage{
+		{Type: StageHealthTier, Regions: []string{"us-east-1"}, MinHealthy: 2},
+		{Type: StageLatencyThreshold, Source: "learned", ToleranceMs: 20},
+		{Type: StageSelect, Algorithm: "weighted"},
+	}
+	tests := []struct {
+		name      string
+		algorithm string
+		stages    []RoutingStage
+		wantErr   string
+	}{
+		{name: "valid", algorithm: RoutingAlgorithmPipeline, stages: tierAndSelect},
+		{name: "algorithm set", algorithm: "weighted", stages: tierAndSelect, wantErr: "must be unset when routing_pipeline is set"},
+		{name: "no stages", algorithm: RoutingAlgorithmPipeline, wantErr: "routing_pipeline is required"},
+		{name: "unknown stage", algorithm: RoutingAlgorithmPipeline,
+			stages: []RoutingStage{{Type: "random"}}, wantErr: "routing_pipeline[0].type"},
+		{name: "select not last", algorithm: RoutingAlgorithmPipeline,
+			stages:  []RoutingStage{{Type: StageSelect}, {Type: StageLabels, Match: map[string]string{"version": "v2"}}},
+			wantErr: "select must be the last stage"},
+		{name: "unknown select algorithm", algorithm: RoutingAlgorithmPipeline,
+			stages: []RoutingStage{{Type: StageSelect, Algorithm: "fastest"}}, wantErr: "routing_pipeline[0].algorithm"},
+		{name: "unknown tier region", algorithm: RoutingAlgorithmPipeline,
+			stages: []RoutingStage{{Type: StageHealthTier, Regions: []string{"eu-west-1"}}}, wantErr: "region \"eu-west-1\" not found"},
+		{name: "latency without threshold", algorithm: RoutingAlgorithmPipeline,
+			stages: []RoutingStage{{Type: StageLatencyThreshold}}, wantErr: "max_latency_ms or tolerance_ms is required"},
+		{name: "empty label selector", algorithm: RoutingAlgorithmPipeline,
+			stages: []RoutingStage{{Type: StageLabels}}, wantErr: "routing_pipeline[0].match is required"},
+		{name: "geo without database", algorithm: RoutingAlgorithmPipeline,
+			stages: []RoutingStage{{Type: StageGeo}}, wantErr: "database_path is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Domains[0].RoutingAlgorithm = tt.algorithm
+			cfg.Domains[0].RoutingPipeline = tt.stages
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_RoutingPipeline(t *testing.T) {
+	d := Domain{RoutingPipeline: []RoutingStage{{Type: StageGeo}}}
+	applyDomainDefaults(&d, 60)
+	if d.RoutingAlgorithm != RoutingAlgorithmPipeline {
+		t.Errorf("expected routing_algorithm %q, got %q", RoutingAlgorithmPipeline, d.RoutingAlgorithm)
+	}
+}
+
+func TestValidate_NegativeMaxAnswers(t *testing.T) {
+	cfg := validOverwatchConfig()
+	cfg.Domains[0].MaxAnswers = -1
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "max_answers") {
+		t.Errorf("expected max_answers error, got %v", err)
+	}
+}
+
+func TestValidate_SorryServers(t *testing.T) {
+	cfg := validOverwatchConfig()
+	cfg.Domains[0].SorryServers = []string{"192.0.2.200", "2001:db8::200"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Domains[0].SorryServers = []string{"sorry.example.com"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "sorry_servers[0]") {
+		t.Errorf("expected sorry_servers error, got %v", err)
+	}
+}
+
+func TestValidate_StaticRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []StaticRecord
+		wantErr string
+	}{
+		{"valid", []StaticRecord{
+			{Type: "TXT", Value: "verify"},
+			{Type: "MX", Value: "10 mail.example.com."},
+			{Name: "www", Type: "CNAME", Value: "app.example.com."},
+		}, ""},
+		{"CNAME at domain", []StaticRecord{{Type: "CNAME", Value: "other.example.com."}}, "CNAME not allowed"},
+		{"CNAME with other data", []StaticRecord{
+			{Name: "www", Type: "CNAME", Value: "app.example.com."},
+			{Name: "www", Type: "TXT", Value: "x"},
+		}, "cannot coexist"},
+		{"duplicate CNAME", []StaticRecord{
+			{Name: "www", Type: "CNAME", Value: "a.example.com."},
+			{Name: "www", Type: "CNAME", Value: "b.example.com."},
+		}, "multiple CNAME"},
+		{"bad value", []StaticRecord{{Type: "SRV", Value: "nope"}}, "records[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Domains[0].Records = tt.records
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_WildcardDomainName(t *testing.T) {
+	cfg := validOverwatchConfig()
+	cfg.Domains[0].Name = "*.apps.example.com"
+	cfg.Regions[0].Servers[0].Service = "*.apps.example.com"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Domains[0].Name = "apps.*.example.com"
+	cfg.Regions[0].Servers[0].Service = "apps.*.example.com"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "wildcard") {
+		t.Errorf("expected wildcard error, got %v", err)
+	}
+}
+
+func TestValidate_FailoverTiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "valid", modify: func(c *Config) {
+			c.Regions[0].Priority = 1
+			c.Regions[0].Servers[0].Priority = 2
+			c.Domains[0].Failover = &FailoverConfig{MinHealthy: 2, FailbackHoldDown: 5 * time.Minute}
+		}},
+		{name: "negative region priority", modify: func(c *Config) { c.Regions[0].Priority = -1 },
+			wantErr: "regions[0].priority must be non-negative"},
+		{name: "negative server priority", modify: func(c *Config) { c.Regions[0].Servers[0].Priority = -1 },
+			wantErr: "servers[0].priority must be non-negative"},
+		{name: "negative min healthy", modify: func(c *Config) { c.Domains[0].Failover = &FailoverConfig{MinHealthy: -1} },
+			wantErr: "failover.min_healthy must be non-negative"},
+		{name: "negative hold-down", modify: func(c *Config) { c.Domains[0].Failover = &FailoverConfig{FailbackHoldDown: -time.Second} },
+			wantErr: "failover.failback_hold_down must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_PanicThreshold(t *testing.T) {
+	for _, tt := range []struct {
+		threshold int
+		wantErr   bool
+	}{
+		{threshold: 0}, {threshold: 30}, {threshold: 100},
+		{threshold: -1, wantErr: true}, {threshold: 101, wantErr: true},
+	} {
+		cfg := validOverwatchConfig()
+		cfg.Domains[0].PanicThreshold = tt.threshold
+		err := cfg.Validate()
+		if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "panic_threshold")) {
+			t.Errorf("threshold %d: expected a panic_threshold error, got %v", tt.threshold, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("threshold %d: unexpected error: %v", tt.threshold, err)
+		}
+	}
+}
+
+func TestValidate_TrafficSplit(t *testing.T) {
+	v2 := map[string]string{"version": "v2"}
+	tests := []struct {
+		name    string
+		splits  []TrafficSplit
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"canary", []TrafficSplit{{Match: v2, Percent: 5}}, ""},
+		{"fractional", []TrafficSplit{{Match: v2, Percent: 0.5}}, ""},
+		{"all", []TrafficSplit{{Match: v2, Percent: 60}, {Match: map[string]string{"version": "v1"}, Percent: 40}}, ""},
+		{"missing match", []TrafficSplit{{Percent: 5}}, "match is required"},
+		{"zero percent", []TrafficSplit{{Match: v2}}, "percent"},
+		{"over 100", []TrafficSplit{{Match: v2, Percent: 60}, {Match: map[string]string{"version": "v3"}, Percent: 50}}, "more than 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Domains[0].TrafficSplit = tt.splits
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_GeoProximity(t *testing.T) {
+	lat, lon, outOfRange := 38.9, -77.4, 95.0
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"select stage", func(c *Config) {
+			c.Domains[0].RoutingAlgorithm = RoutingAlgorithmPipeline
+			c.Domains[0].RoutingPipeline = []RoutingStage{{Type: StageSelect, Algorithm: "geoproximity"}}
+		}, ""},
+		{"without database", func(c *Config) { c.Overwatch.Geolocation.DatabasePath = "" }, "database_path is required"},
+		{"region without coordinates", func(c *Config) {
+			c.Regions[0].Latitude, c.Regions[0].Longitude = nil, nil
+		}, "region \"us-east-1\" has no latitude and longitude"},
+		{"latitude only", func(c *Config) { c.Regions[0].Longitude = nil }, "must be set together"},
+		{"latitude out of range", func(c *Config) { c.Regions[0].Latitude = &outOfRange }, "latitude must be between"},
+		{"longitude out of range", func(c *Config) {
+			far := 200.0
+			c.Regions[0].Longitude = &far
+		}, "longitude must be between"},
+		{"bias out of range", func(c *Config) { c.Regions[0].Bias = 100 }, "bias must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Regions[0].Latitude, cfg.Regions[0].Longitude = &lat, &lon
+			cfg.Regions[0].Bias = 25
+			cfg.Domains[0].RoutingAlgorithm = "geoproximity"
+			cfg.Overwatch.Geolocation.DatabasePath = "/var/lib/opengslb/GeoLite2-City.mmdb"
+			cfg.Overwatch.Geolocation.DefaultRegion = "us-east-1"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Schedules(t *testing.T) {
+	valid := func() Schedule {
+		return Schedule{
+			Name:     "follow-the-sun",
+			Domain:   "app.example.com",
+			Timezone: "Europe/London",
+			Profiles: []ScheduleProfile{
+				{Name: "day", Start: "0 8 * * mon-fri", RegionWeights: map[string]int{"us-east-1": 100}},
+				{Name: "night", Start: "0 20 * * *", RegionPreference: []string{"us-east-1"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"invalid cron", func(c *Config) { c.Schedules[0].Profiles[0].Start = "0 8 * *" }, "expected 5 fields"},
+		{"invalid timezone", func(c *Config) { c.Schedules[0].Timezone = "Nowhere/City" }, "invalid timezone"},
+		{"unknown domain", func(c *Config) { c.Schedules[0].Domain = "missing.example.com" }, "not found"},
+		{"unknown region weight", func(c *Config) {
+			c.Schedules[0].Profiles[0].RegionWeights = map[string]int{"eu-west-1": 10}
+		}, "region_weights"},
+		{"unknown preferred region", func(c *Config) {
+			c.Schedules[0].Profiles[1].RegionPreference = []string{"eu-west-1"}
+		}, "region_preference"},
+		{"second schedule for domain", func(c *Config) {
+			s := valid()
+			s.Name = "other"
+			c.Schedules = append(c.Schedules, s)
+		}, "already has schedule"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validOverwatchConfig()
+			cfg.Schedules = []Schedule{valid()}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// StaticRecordTypes are the record types accepted in domain records.
+var StaticRecordTypes = map[string]uint16{
+	"TXT":   dns.TypeTXT,
+	"MX":    dns.TypeMX,
+	"CAA":   dns.TypeCAA,
+	"SRV":   dns.TypeSRV,
+	"CNAME": dns.TypeCNAME,
+}
+
+// maxTXTStringLen is the longest character-string a TXT record can hold.
+const maxTXTStringLen = 255
+
+// Owner returns the fully qualified, lower-case owner name of the record
+// under domain.
+func (r StaticRecord) Owner(domain string) string {
+	domain = dns.Fqdn(strings.ToLower(domain))
+	name := strings.ToLower(r.Name)
+	switch {
+	case name == "" || name == "@":
+		return domain
+	case dns.IsFqdn(name):
+		return name
+	default:
+		return name + "." + domain
+	}
+}
+
+// RR parses the record into a resource record owned by a name under domain.
+// defaultTTL is used when the record has no TTL of its own.
+func (r StaticRecord) RR(domain string, defaultTTL int) (dns.RR, error) {
+	rrtype, ok := StaticRecordTypes[strings.ToUpper(r.Type)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %q: must be TXT, MX, CAA, SRV or CNAME", r.Type)
+	}
+	if strings.TrimSpace(r.Value) == "" {
+		return nil, fmt.Errorf("value is required")
+	}
+	if r.TTL < 0 {
+		return nil, fmt.Errorf("ttl must be non-negative")
+	}
+
+	owner := r.Owner(domain)
+	if _, ok := dns.IsDomainName(owner); !ok {
+		return nil, fmt.Errorf("invalid name %q", r.Name)
+	}
+	if strings.Contains(strings.TrimPrefix(owner, "*."), "*") {
+		return nil, fmt.Errorf("name %q: a wildcard is only allowed as the first label", r.Name)
+	}
+	if !dns.IsSubDomain(dns.Fqdn(strings.ToLower(domain)), owner) {
+		return nil, fmt.Errorf("name %q is not at or below %s", r.Name, domain)
+	}
+
+	ttl := r.TTL
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	hdr := dns.RR_Header{Name: owner, Rrtype: rrtype, Class: dns.ClassINET, Ttl: uint32(ttl)}
+
+	// Unquoted TXT values are taken literally and split into 255-byte strings
+	if rrtype == dns.TypeTXT && !strings.HasPrefix(r.Value, `"`) {
+		return &dns.TXT{Hdr: hdr, Txt: splitTXT(r.Value)}, nil
+	}
+
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", owner, ttl, dns.TypeToString[rrtype], r.Value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q: %w", dns.TypeToString[rrtype], r.Value, err)
+	}
+	if rr == nil {
+		return nil, fmt.Errorf("invalid %s value %q", dns.TypeToString[rrtype], r.Value)
+	}
+	return rr, nil
+}
+
+// ValidateStaticRecords checks that records parse and that CNAMEs follow
+// RFC 1034: no CNAME at the domain name itself (it carries the GSLB answers)
+// and no other data alongside a CNAME.
+func ValidateStaticRecords(domain string, records []StaticRecord) error {
+	cnames := make(map[string]int)
+	others := make(map[string]bool)
+	for i, record := range records {
+		if _, err := record.RR(domain, DefaultTTL); err != nil {
+			return fmt.Errorf("records[%d]: %w", i, err)
+		}
+
+		owner := record.Owner(domain)
+		if strings.ToUpper(record.Type) != "CNAME" {
+			others[owner] = true
+			continue
+		}
+		if owner == dns.Fqdn(strings.ToLower(domain)) {
+			return fmt.Errorf("records[%d]: CNAME not allowed at the domain name itself", i)
+		}
+		cnames[owner]++
+	}
+
+	for owner, count := range cnames {
+		if count > 1 {
+			return fmt.Errorf("multiple CNAME records at %s", owner)
+		}
+		if others[owner] {
+			return fmt.Errorf("CNAME at %s cannot coexist with other records", owner)
+		}
+	}
+	return nil
+}
+
+// splitTXT splits a TXT value into character-strings of at most 255 bytes.
+func splitTXT(value string) []string {
+	var parts []string
+	for len(value) > maxTXTStringLen {
+		parts = append(parts, value[:maxTXTStringLen])
+		value = value[maxTXTStringLen:]
+	}
+	return append(parts, value)
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestStaticRecord_RR(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  StaticRecord
+		owner   string
+		ttl     uint32
+		wantErr bool
+	}{
+		{"TXT at domain", StaticRecord{Type: "TXT", Value: "v=spf1 -all"}, "app.example.com.", 60, false},
+		{"relative name", StaticRecord{Name: "_acme-challenge", Type: "txt", Value: `"token"`, TTL: 30}, "_acme-challenge.app.example.com.", 30, false},
+		{"absolute name", StaticRecord{Name: "www.app.example.com.", Type: "CNAME", Value: "app.example.com."}, "www.app.example.com.", 60, false},
+		{"MX", StaticRecord{Name: "@", Type: "MX", Value: "10 mail.example.com."}, "app.example.com.", 60, false},
+		{"CAA", StaticRecord{Type: "CAA", Value: `0 issue "letsencrypt.org"`}, "app.example.com.", 60, false},
+		{"SRV", StaticRecord{Name: "_sip._tcp", Type: "SRV", Value: "10 5 5060 sip.example.com."}, "_sip._tcp.app.example.com.", 60, false},
+		{"unsupported type", StaticRecord{Type: "A", Value: "192.0.2.1"}, "", 0, true},
+		{"missing value", StaticRecord{Type: "TXT"}, "", 0, true},
+		{"invalid MX", StaticRecord{Type: "MX", Value: "mail.example.com."}, "", 0, true},
+		{"outside domain", StaticRecord{Name: "other.example.com.", Type: "TXT", Value: "x"}, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr, err := tt.record.RR("app.example.com", 60)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", rr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rr.Header().Name != tt.owner {
+				t.Errorf("expected owner %s, got %s", tt.owner, rr.Header().Name)
+			}
+			if rr.Header().Ttl != tt.ttl {
+				t.Errorf("expected TTL %d, got %d", tt.ttl, rr.Header().Ttl)
+			}
+		})
+	}
+}
+
+func TestStaticRecord_LongTXTIsSplit(t *testing.T) {
+	record := StaticRecord{Type: "TXT", Value: strings.Repeat("a", 300)}
+	rr, err := record.RR("app.example.com", 60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	txt := rr.(*dns.TXT)
+	if len(txt.Txt) != 2 || len(txt.Txt[0]) != 255 || len(txt.Txt[1]) != 45 {
+		t.Errorf("expected 255+45 byte strings, got %d strings", len(txt.Txt))
+	}
+}
@@ -9,6 +9,8 @@ package config
 
 import (
 	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/schedule"
 )
 
 // RuntimeMode defines the operational mode of OpenGSLB (ADR-015).
@@ -46,6 +48,12 @@ type Config struct {
 	// Domains define GSLB-managed zones (overwatch mode only)
 	Domains []Domain `yaml:"domains"`
 
+	// Views define split-horizon answers for groups of clients (overwatch mode only)
+	Views []View `yaml:"views,omitempty"`
+
+	// Schedules switch domains between weight profiles by time of day (overwatch mode only)
+	Schedules []Schedule `yaml:"schedules,omitempty"`
+
 	// Logging settings (both modes)
 	Logging LoggingConfig `yaml:"logging"`
 
@@ -112,6 +120,17 @@ type AgentBackend struct {
 	// Weight is the routing weight (higher = more traffic)
 	Weight int `yaml:"weight"`
 
+	// Labels are free-form key/value pairs sent to Overwatch and matched by
+	// routing pipeline label stages and traffic splits, e.g. version: v2
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Capacity is the number of concurrent connections the backend can serve,
+	// used by least-load routing. An HTTP(S) health check response can
+	// report it instead in the X-OpenGSLB-Capacity header, which takes
+	// precedence.
+	// Default: 0 (the weight is used as a relative capacity)
+	Capacity int `yaml:"capacity"`
+
 	// HealthCheck defines how to check this backend's health
 	HealthCheck HealthCheck `yaml:"health_check"`
 }
@@ -305,6 +324,294 @@ type DNSConfig struct {
 	DefaultTTL        int      `yaml:"default_ttl"`
 	ReturnLastHealthy bool     `yaml:"return_last_healthy"`
 	Zones             []string `yaml:"zones"`
+
+	// LastHealthyTTL is the TTL in seconds of fallback answers (last healthy
+	// records or sorry servers) served while every backend of a domain is down.
+	// Kept short so clients return to real backends soon after they recover.
+	// Default: 10
+	LastHealthyTTL int `yaml:"last_healthy_ttl"`
+
+	// MaxUDPSize is the EDNS buffer size advertised to clients and the
+	// largest UDP response sent; larger responses are truncated (TC=1) so the
+	// client retries over TCP. The client's own buffer size applies if smaller.
+	// Default: 1232
+	MaxUDPSize int `yaml:"max_udp_size"`
+
+	// SOA contains the start-of-authority settings applied to every zone
+	SOA SOAConfig `yaml:"soa"`
+
+	// Nameservers are the Overwatch nodes published as the NS set of every zone.
+	// Addresses are served as glue when the name falls inside the zone.
+	Nameservers []NameserverConfig `yaml:"nameservers"`
+
+	// TLS contains the certificate served by the DoT and DoH listeners
+	TLS DNSTLSConfig `yaml:"tls"`
+
+	// DoT contains the DNS-over-TLS listener settings (RFC 7858)
+	DoT DoTConfig `yaml:"dot"`
+
+	// DoH contains the DNS-over-HTTPS listener settings (RFC 8484)
+	DoH DoHConfig `yaml:"doh"`
+
+	// TSIGKeys are the shared secrets accepted on TSIG-signed requests (RFC 8945)
+	TSIGKeys []TSIGKeyConfig `yaml:"tsig_keys"`
+
+	// Transfer contains the zone transfer (AXFR/IXFR) and NOTIFY settings
+	Transfer TransferConfig `yaml:"transfer"`
+
+	// Update contains the dynamic update (RFC 2136) settings
+	Update DNSUpdateConfig `yaml:"update"`
+
+	// RateLimit contains the response rate limiting (RRL) settings
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// QueryLog contains the query log settings
+	QueryLog QueryLogConfig `yaml:"query_log"`
+
+	// Fallthrough proxies queries for names in a zone that OpenGSLB does not
+	// manage to the zone's existing authoritative servers
+	Fallthrough []FallthroughConfig `yaml:"fallthrough"`
+}
+
+// FallthroughConfig defines the upstream answering the names of a zone that
+// are not in the registry, so an existing zone can be moved to OpenGSLB one
+// name at a time. Upstream responses are cached for their TTL.
+type FallthroughConfig struct {
+	// Zone is one of dns.zones
+	Zone string `yaml:"zone"`
+
+	// Upstreams are the authoritative servers queried in order, as host or
+	// host:port
+	// Default port: 53
+	Upstreams []string `yaml:"upstreams"`
+
+	// Timeout bounds the time spent querying the upstreams for one query
+	// Default: 2s
+	Timeout time.Duration `yaml:"timeout"`
+
+	// CacheSize is the maximum number of cached responses
+	// Default: 10000
+	CacheSize int `yaml:"cache_size"`
+}
+
+// QueryLogConfig defines the query log, recording each query with its client,
+// answer, chosen backend and latency. Entries are queued and written in the
+// background; when the queue is full they are dropped rather than delaying
+// responses.
+type QueryLogConfig struct {
+	// Enabled turns on query logging
+	Enabled bool `yaml:"enabled"`
+
+	// BufferSize is the number of entries queued for writing
+	// Default: 10000
+	BufferSize int `yaml:"buffer_size"`
+
+	// Dnstap writes dnstap frames to a unix socket or file
+	Dnstap DnstapConfig `yaml:"dnstap"`
+
+	// File writes JSON lines to a rotated file
+	File QueryLogFileConfig `yaml:"file"`
+}
+
+// DnstapConfig defines the dnstap output of the query log. Set one of Socket
+// or File.
+type DnstapConfig struct {
+	// Socket is the path of a dnstap collector's unix socket
+	Socket string `yaml:"socket,omitempty"`
+
+	// File is the path of a file receiving a dnstap frame stream
+	File string `yaml:"file,omitempty"`
+
+	// Identity identifies this server in dnstap messages
+	// Default: the hostname
+	Identity string `yaml:"identity,omitempty"`
+}
+
+// QueryLogFileConfig defines the JSON lines output of the query log.
+type QueryLogFileConfig struct {
+	// Path of the log file; rotated files get a .1, .2, ... suffix
+	Path string `yaml:"path,omitempty"`
+
+	// MaxSizeMB is the size at which the file is rotated
+	// Default: 100
+	MaxSizeMB int `yaml:"max_size_mb"`
+
+	// MaxBackups is the number of rotated files kept
+	// Default: 5
+	MaxBackups int `yaml:"max_backups"`
+}
+
+// RateLimitConfig defines response rate limiting for UDP queries. Responses
+// are counted per client prefix and response type; over the limit they are
+// dropped, or every slip-th one is sent truncated (TC=1) so legitimate
+// clients can retry over TCP. TCP, DoT and DoH are never limited.
+type RateLimitConfig struct {
+	// Enabled turns on response rate limiting
+	Enabled bool `yaml:"enabled"`
+
+	// ResponsesPerSecond limits answers and NODATA responses per client
+	// prefix, name and type
+	// Default: 10
+	ResponsesPerSecond int `yaml:"responses_per_second"`
+
+	// NXDomainsPerSecond limits NXDOMAIN responses per client prefix and zone
+	// Default: responses_per_second
+	NXDomainsPerSecond int `yaml:"nxdomains_per_second"`
+
+	// ErrorsPerSecond limits error responses (SERVFAIL, REFUSED, FORMERR...)
+	// per client prefix
+	// Default: responses_per_second
+	ErrorsPerSecond int `yaml:"errors_per_second"`
+
+	// Slip sends every slip-th limited response truncated instead of
+	// dropping it; 1 truncates every one and -1 drops every one.
+	// Default: 2
+	Slip int `yaml:"slip"`
+
+	// IPv4PrefixLength is the prefix length grouping IPv4 clients
+	// Default: 24
+	IPv4PrefixLength int `yaml:"ipv4_prefix_length"`
+
+	// IPv6PrefixLength is the prefix length grouping IPv6 clients
+	// Default: 56
+	IPv6PrefixLength int `yaml:"ipv6_prefix_length"`
+
+	// AllowFrom lists IP addresses or CIDRs that are never rate limited
+	AllowFrom []string `yaml:"allow_from"`
+
+	// TableSize is the maximum number of tracked client buckets
+	// Default: 100000
+	TableSize int `yaml:"table_size"`
+}
+
+// TSIGKeyConfig defines a TSIG key shared with secondaries or update clients.
+type TSIGKeyConfig struct {
+	// Name is the key name (e.g., "transfer-key")
+	Name string `yaml:"name"`
+
+	// Algorithm is the HMAC algorithm: hmac-sha1, hmac-sha224, hmac-sha256,
+	// hmac-sha384 or hmac-sha512
+	// Default: "hmac-sha256"
+	Algorithm string `yaml:"algorithm"`
+
+	// Secret is the base64-encoded shared secret
+	Secret string `yaml:"secret"`
+}
+
+// TransferConfig defines who may transfer the authoritative zones and which
+// secondaries are notified of changes. Transfers are refused unless allowed
+// by source address or TSIG key.
+type TransferConfig struct {
+	// AllowFrom lists IP addresses or CIDRs allowed to transfer without TSIG
+	AllowFrom []string `yaml:"allow_from"`
+
+	// TSIGKeys lists the dns.tsig_keys names allowed to transfer from any address
+	TSIGKeys []string `yaml:"tsig_keys"`
+
+	// Notify lists secondaries ("ip" or "ip:port") sent DNS NOTIFY when a zone changes
+	Notify []string `yaml:"notify"`
+
+	// CheckInterval is how often zone contents are checked for changes.
+	// The zone serial is bumped when records or backend health change.
+	// Default: 5s
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// DNSUpdateConfig defines who may register backends with dynamic DNS
+// updates (RFC 2136). Adding an A or AAAA record to a GSLB domain registers
+// the address as a backend server; deleting it deregisters the backend.
+// Updates are refused unless signed with one of the listed TSIG keys.
+type DNSUpdateConfig struct {
+	// TSIGKeys lists the dns.tsig_keys names allowed to send updates
+	TSIGKeys []string `yaml:"tsig_keys"`
+
+	// Port is the service port of backends registered by updates
+	// Default: 80
+	Port int `yaml:"port"`
+
+	// Weight is the routing weight of backends registered by updates
+	// Default: 100
+	Weight int `yaml:"weight"`
+
+	// Region is the region of backends registered by updates (optional)
+	Region string `yaml:"region"`
+}
+
+// DNSTLSConfig defines the certificate used by the encrypted DNS listeners.
+// The files are re-read on SIGHUP, so renewed certificates are picked up
+// without restarting the listeners.
+type DNSTLSConfig struct {
+	// CertFile is the path to the PEM-encoded certificate chain
+	CertFile string `yaml:"cert_file"`
+
+	// KeyFile is the path to the PEM-encoded private key
+	KeyFile string `yaml:"key_file"`
+}
+
+// DoTConfig defines the DNS-over-TLS listener.
+type DoTConfig struct {
+	// Enabled turns on the DoT listener
+	// Default: false
+	Enabled bool `yaml:"enabled"`
+
+	// ListenAddress is the address for the DoT listener
+	// Default: ":853"
+	ListenAddress string `yaml:"listen_address"`
+}
+
+// DoHConfig defines the DNS-over-HTTPS listener.
+type DoHConfig struct {
+	// Enabled turns on the DoH listener
+	// Default: false
+	Enabled bool `yaml:"enabled"`
+
+	// ListenAddress is the address for the DoH listener
+	// Default: ":443"
+	ListenAddress string `yaml:"listen_address"`
+
+	// Path is the URL path queries are served on
+	// Default: "/dns-query"
+	Path string `yaml:"path"`
+}
+
+// SOAConfig defines the SOA record served at the apex of each zone.
+type SOAConfig struct {
+	// PrimaryNS is the MNAME field of the SOA record
+	// Default: first entry in dns.nameservers
+	PrimaryNS string `yaml:"primary_ns"`
+
+	// Mailbox is the RNAME field of the SOA record (e.g., "hostmaster.example.com")
+	// Default: "hostmaster.<zone>"
+	Mailbox string `yaml:"mailbox"`
+
+	// Serial is the zone serial number
+	// Default: 0 (derived from the Unix time at startup)
+	Serial uint32 `yaml:"serial"`
+
+	// Refresh is how often secondaries should check the serial
+	// Default: 1h
+	Refresh time.Duration `yaml:"refresh"`
+
+	// Retry is how long secondaries wait after a failed refresh
+	// Default: 10m
+	Retry time.Duration `yaml:"retry"`
+
+	// Expire is how long secondaries keep serving without a successful refresh
+	// Default: 168h
+	Expire time.Duration `yaml:"expire"`
+
+	// Minimum is the negative caching TTL for NXDOMAIN/NODATA responses
+	// Default: 60s
+	Minimum time.Duration `yaml:"minimum"`
+}
+
+// NameserverConfig defines an Overwatch node advertised as an authoritative nameserver.
+type NameserverConfig struct {
+	// Name is the nameserver hostname (e.g., "ns1.gslb.example.com")
+	Name string `yaml:"name"`
+
+	// Address is the IPv4 or IPv6 address used for glue records
+	Address string `yaml:"address,omitempty"`
 }
 
 // Region defines a geographic region with its servers and health check configuration.
@@ -321,6 +628,21 @@ type Region struct {
 	// Valid codes: AF (Africa), AN (Antarctica), AS (Asia), EU (Europe),
 	// NA (North America), OC (Oceania), SA (South America)
 	Continents []string `yaml:"continents,omitempty"`
+	// Priority is the failover tier of the region's servers, including
+	// agent-registered ones; lower values are preferred
+	// Default: 0
+	Priority int `yaml:"priority,omitempty"`
+
+	// Latitude and Longitude locate the region's data center for
+	// geoproximity routing, in decimal degrees
+	Latitude  *float64 `yaml:"latitude,omitempty"`
+	Longitude *float64 `yaml:"longitude,omitempty"`
+
+	// Bias expands (positive) or shrinks (negative) the area geoproximity
+	// routing sends to the region, from -99 to 99. Distances to the region
+	// are scaled by 1 - bias/100.
+	// Default: 0
+	Bias int `yaml:"bias,omitempty"`
 }
 
 // Server defines a backend server within a region.
@@ -330,6 +652,14 @@ type Server struct {
 	Weight  int    `yaml:"weight"`
 	Service string `yaml:"service"` // Required in v1.1.0: Domain/service this server belongs to
 	Host    string `yaml:"host"`
+
+	// Priority is the failover tier of the server; lower values are preferred
+	// Default: the region's priority
+	Priority int `yaml:"priority,omitempty"`
+
+	// Labels are free-form key/value pairs matched by routing pipeline
+	// label stages and traffic splits, e.g. version: v2
+	Labels map[string]string `yaml:"labels,omitempty"`
 }
 
 // HealthCheck defines health check configuration.
@@ -350,6 +680,299 @@ type Domain struct {
 	Regions          []string       `yaml:"regions"`
 	TTL              int            `yaml:"ttl"`
 	LatencyConfig    *LatencyConfig `yaml:"latency_config,omitempty"`
+
+	// MaxAnswers is the number of healthy records returned per A/AAAA query,
+	// ordered best first by the routing algorithm
+	// Default: 1
+	MaxAnswers int `yaml:"max_answers,omitempty"`
+
+	// SorryServers are fallback IPv4/IPv6 addresses served when every backend
+	// of the domain is unhealthy. They take precedence over dns.return_last_healthy.
+	SorryServers []string `yaml:"sorry_servers,omitempty"`
+
+	// Records are static records (TXT, MX, CAA, SRV, CNAME) served at or below
+	// the domain name alongside its GSLB answers
+	Records []StaticRecord `yaml:"records,omitempty"`
+
+	// HTTPS publishes HTTPS and SVCB records (RFC 9460) at the domain name,
+	// hinting the healthy backends the routing algorithm selects
+	HTTPS *HTTPSRecordConfig `yaml:"https,omitempty"`
+
+	// DNS64 synthesizes AAAA answers from the routed IPv4 backends when the
+	// domain has no healthy IPv6 backend
+	DNS64 DNS64Config `yaml:"dns64,omitempty"`
+
+	// Failover controls how failover routing moves between priority tiers
+	Failover *FailoverConfig `yaml:"failover,omitempty"`
+
+	// PanicThreshold is the percentage of healthy backends below which the
+	// domain enters panic mode: health is ignored and every backend not
+	// overridden down receives traffic, so the few healthy backends are not
+	// overwhelmed
+	// Default: 0 (disabled)
+	PanicThreshold int `yaml:"panic_threshold,omitempty"`
+
+	// RoutingPipeline routes the domain through a chain of filter stages
+	// narrowing the pool, ending in an optional select stage picking among
+	// the remaining servers. Replaces routing_algorithm.
+	RoutingPipeline []RoutingStage `yaml:"routing_pipeline,omitempty"`
+
+	// TrafficSplit sends a percentage of client subnets to the servers
+	// matching each label selector, e.g. 5% to version: v2. The remaining
+	// subnets go to the servers matching no selector. A subnet keeps its
+	// share as long as the split is unchanged.
+	TrafficSplit []TrafficSplit `yaml:"traffic_split,omitempty"`
+}
+
+// TrafficSplit is a share of a domain's clients routed to the servers
+// matching a label selector.
+type TrafficSplit struct {
+	// Match is the label selector of the servers receiving the share
+	Match map[string]string `yaml:"match"`
+
+	// Percent is the share of client subnets, greater than 0 and up to 100;
+	// fractions such as 0.5 are allowed
+	Percent float64 `yaml:"percent"`
+}
+
+// FailoverConfig defines the priority tier policy of a failover domain.
+type FailoverConfig struct {
+	// MinHealthy is the number of healthy servers a tier needs to receive
+	// traffic; below it, traffic spills to the next tier
+	// Default: 1
+	MinHealthy int `yaml:"min_healthy,omitempty"`
+
+	// FailbackHoldDown is how long a recovered higher tier must stay at
+	// min_healthy before traffic returns to it
+	// Default: 0 (immediate)
+	FailbackHoldDown time.Duration `yaml:"failback_hold_down,omitempty"`
+
+	// ManualFailback keeps traffic on a lower tier until it fails or the
+	// configuration is reloaded, instead of returning automatically
+	ManualFailback bool `yaml:"manual_failback,omitempty"`
+}
+
+// Routing pipeline stage types.
+const (
+	StageGeo              = "geo"
+	StageHealthTier       = "health-tier"
+	StageLatencyThreshold = "latency-threshold"
+	StageLabels           = "labels"
+	StageSelect           = "select"
+)
+
+// RoutingStage is one stage of a domain's routing pipeline. A stage that
+// would remove every server is skipped.
+type RoutingStage struct {
+	// Type is geo, health-tier, latency-threshold, labels, or select.
+	// geo keeps the servers in the client's region (or the default region);
+	// health-tier keeps the most preferred regions holding min_healthy
+	// healthy servers; latency-threshold keeps the servers under
+	// max_latency_ms and within tolerance_ms of the fastest; labels keeps the
+	// servers matching every label; select orders the rest and must be last.
+	Type string `yaml:"type"`
+
+	// Regions orders the tiers of a health-tier stage, most preferred first
+	// Default: the domain's regions
+	Regions []string `yaml:"regions,omitempty"`
+
+	// MinHealthy is the number of healthy servers a health-tier stage keeps
+	// adding tiers for
+	// Default: 1
+	MinHealthy int `yaml:"min_healthy,omitempty"`
+
+	// Source of a latency-threshold stage: validation (health check
+	// latency) or learned (agent-measured client latency)
+	// Default: validation
+	Source string `yaml:"source,omitempty"`
+
+	// MaxLatencyMs is the latency limit of a latency-threshold stage
+	MaxLatencyMs int `yaml:"max_latency_ms,omitempty"`
+
+	// ToleranceMs keeps servers at most this much slower than the fastest
+	ToleranceMs int `yaml:"tolerance_ms,omitempty"`
+
+	// Match is the label selector of a labels stage
+	Match map[string]string `yaml:"match,omitempty"`
+
+	// Algorithm of a select stage
+	// Default: weighted
+	Algorithm string `yaml:"algorithm,omitempty"`
+}
+
+// DNS64Config defines DNS64 (RFC 6147) synthesis for IPv6-only clients.
+type DNS64Config struct {
+	// Enabled turns on AAAA synthesis
+	Enabled bool `yaml:"enabled"`
+
+	// Prefix is the /96 NAT64 prefix the IPv4 address is embedded in
+	// Default: 64:ff9b::/96
+	Prefix string `yaml:"prefix,omitempty"`
+}
+
+// HTTPSRecordConfig defines the service parameters of a domain's HTTPS and
+// SVCB records. The ipv4hint and ipv6hint parameters are filled in from the
+// domain's backends.
+type HTTPSRecordConfig struct {
+	// ALPN lists the application protocols offered, e.g. ["h3", "h2"]
+	ALPN []string `yaml:"alpn,omitempty"`
+
+	// Port clients connect to
+	// Default: the default port of the scheme (443 for HTTPS), omitted from the record
+	Port int `yaml:"port,omitempty"`
+
+	// ECH is the base64-encoded ECHConfigList published as the ech parameter
+	ECH string `yaml:"ech,omitempty"`
+}
+
+// View is a split-horizon view: clients matched by source network or TSIG
+// key get answers from the view's copy of the domains, in which a domain can
+// draw servers from other regions or use another routing algorithm. Other
+// clients get the default answers. Views are evaluated in order.
+type View struct {
+	// Name identifies the view (e.g., "internal")
+	Name string `yaml:"name"`
+
+	// MatchClients lists the IP addresses or CIDRs of the view's clients
+	MatchClients []string `yaml:"match_clients,omitempty"`
+
+	// TSIGKeys lists dns.tsig_keys names selecting the view for signed queries
+	TSIGKeys []string `yaml:"tsig_keys,omitempty"`
+
+	// Domains override domains for the view. Domains not listed are answered
+	// as in the default view.
+	Domains []ViewDomain `yaml:"domains,omitempty"`
+
+	// DNS64 synthesizes AAAA answers for every domain answered in the view,
+	// e.g. for IPv6-only clusters. A domain's own dns64 settings take precedence.
+	DNS64 DNS64Config `yaml:"dns64,omitempty"`
+}
+
+// ViewDomain overrides a domain within a view.
+type ViewDomain struct {
+	// Name of the domain, as in the domains section
+	Name string `yaml:"name"`
+
+	// Regions replace the domain's regions in this view
+	// Default: the domain's regions
+	Regions []string `yaml:"regions,omitempty"`
+
+	// RoutingAlgorithm replaces the domain's algorithm or routing pipeline
+	// in this view
+	// Default: the domain's algorithm
+	RoutingAlgorithm string `yaml:"routing_algorithm,omitempty"`
+}
+
+// ViewDomains returns the domains overridden by view, with the view's
+// regions and routing algorithm applied. A view with DNS64 overrides every
+// domain, so that all of them are answered with DNS64.
+func (c *Config) ViewDomains(view View) []Domain {
+	var domains []Domain
+	for _, domain := range c.Domains {
+		overridden := false
+		for _, override := range view.Domains {
+			if domain.Name != override.Name {
+				continue
+			}
+			if len(override.Regions) > 0 {
+				domain.Regions = override.Regions
+			}
+			if override.RoutingAlgorithm != "" {
+				domain.RoutingAlgorithm = override.RoutingAlgorithm
+				domain.RoutingPipeline = nil
+			}
+			overridden = true
+		}
+		if view.DNS64.Enabled {
+			if !domain.DNS64.Enabled {
+				domain.DNS64 = view.DNS64
+			}
+			overridden = true
+		}
+		if overridden {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// Schedule switches a domain between weight profiles on cron schedules, for
+// example to follow the sun across regions. Each profile stays active from
+// its start until another profile of the schedule starts.
+type Schedule struct {
+	// Name identifies the schedule (e.g., "follow-the-sun")
+	Name string `yaml:"name"`
+
+	// Domain is the name of the scheduled domain, as in the domains section
+	Domain string `yaml:"domain"`
+
+	// Timezone is the IANA timezone of the profiles' start times
+	// Default: UTC
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Profiles are the weight profiles the domain switches between
+	Profiles []ScheduleProfile `yaml:"profiles"`
+}
+
+// ScheduleProfile defines the weights and region preference of a domain
+// while the profile is active.
+type ScheduleProfile struct {
+	// Name identifies the profile within the schedule (e.g., "emea-business-hours")
+	Name string `yaml:"name"`
+
+	// Start is a five-field cron expression of when the profile becomes
+	// active (e.g., "0 8 * * mon-fri")
+	Start string `yaml:"start"`
+
+	// RegionWeights set the weight of every server of the domain in a region
+	RegionWeights map[string]int `yaml:"region_weights,omitempty"`
+
+	// ServerWeights set the weight of servers by address, overriding RegionWeights
+	ServerWeights map[string]int `yaml:"server_weights,omitempty"`
+
+	// RegionPreference orders regions most preferred first, setting the
+	// failover priority of their servers. Unlisted regions come last.
+	RegionPreference []string `yaml:"region_preference,omitempty"`
+}
+
+// ToSchedule converts the schedule to the form run by the scheduler.
+func (s Schedule) ToSchedule() schedule.Schedule {
+	result := schedule.Schedule{
+		Name:     s.Name,
+		Domain:   s.Domain,
+		Timezone: s.Timezone,
+		Source:   schedule.SourceConfig,
+	}
+	for _, p := range s.Profiles {
+		result.Profiles = append(result.Profiles, schedule.Profile{
+			Name:             p.Name,
+			Start:            p.Start,
+			RegionWeights:    p.RegionWeights,
+			ServerWeights:    p.ServerWeights,
+			RegionPreference: p.RegionPreference,
+		})
+	}
+	return result
+}
+
+// StaticRecord defines a single static resource record. Records with the same
+// name and type form one RRset.
+type StaticRecord struct {
+	// Name is the owner name relative to the domain, e.g. "_acme-challenge".
+	// Empty or "@" means the domain name itself. Absolute names (ending in ".")
+	// must be at or below the domain.
+	Name string `yaml:"name,omitempty"`
+
+	// Type is one of TXT, MX, CAA, SRV or CNAME
+	Type string `yaml:"type"`
+
+	// TTL in seconds
+	// Default: domain TTL
+	TTL int `yaml:"ttl,omitempty"`
+
+	// Value is the record data in zone file format, e.g. "10 mail.example.com."
+	// for MX. TXT values may be given unquoted.
+	Value string `yaml:"value"`
 }
 
 // LatencyConfig defines configuration for latency-based routing.
@@ -10,8 +10,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/miekg/dns"
 )
 
 // Validate checks the configuration for errors.
@@ -148,6 +152,9 @@ func validateAgentBackend(b AgentBackend, index int) error {
 	if b.Weight < 0 {
 		return fmt.Errorf("%s.weight must be non-negative", prefix)
 	}
+	if b.Capacity < 0 {
+		return fmt.Errorf("%s.capacity must be non-negative", prefix)
+	}
 
 	// Health check validation
 	hc := b.HealthCheck
@@ -195,6 +202,16 @@ func (c *Config) validateOverwatchMode() error {
 		return err
 	}
 
+	// Views validation
+	if err := c.validateViews(); err != nil {
+		return err
+	}
+
+	// Schedules validation
+	if err := c.validateSchedules(); err != nil {
+		return err
+	}
+
 	// Geolocation validation (only if any domain uses geolocation)
 	if err := c.validateGeolocation(); err != nil {
 		return fmt.Errorf("geolocation: %w", err)
@@ -240,6 +257,361 @@ func (c *Config) validateDNS() error {
 		return fmt.Errorf("default_ttl must be non-negative")
 	}
 
+	if c.DNS.LastHealthyTTL < 0 {
+		return fmt.Errorf("last_healthy_ttl must be non-negative")
+	}
+
+	if c.DNS.MaxUDPSize != 0 && (c.DNS.MaxUDPSize < dns.MinMsgSize || c.DNS.MaxUDPSize > dns.MaxMsgSize) {
+		return fmt.Errorf("max_udp_size must be between %d and %d", dns.MinMsgSize, dns.MaxMsgSize)
+	}
+
+	for i, zone := range c.DNS.Zones {
+		if _, ok := dns.IsDomainName(zone); !ok || zone == "" {
+			return fmt.Errorf("zones[%d] %q: invalid zone name", i, zone)
+		}
+	}
+
+	soa := c.DNS.SOA
+	if soa.Refresh < 0 || soa.Retry < 0 || soa.Expire < 0 || soa.Minimum < 0 {
+		return fmt.Errorf("soa timers must be non-negative")
+	}
+	if soa.Expire > 0 && soa.Expire < soa.Refresh {
+		return fmt.Errorf("soa.expire must not be shorter than soa.refresh")
+	}
+	if soa.PrimaryNS != "" {
+		if _, ok := dns.IsDomainName(soa.PrimaryNS); !ok {
+			return fmt.Errorf("soa.primary_ns %q: invalid hostname", soa.PrimaryNS)
+		}
+	}
+
+	nsNames := make(map[string]bool)
+	for i, ns := range c.DNS.Nameservers {
+		prefix := fmt.Sprintf("nameservers[%d]", i)
+		if ns.Name == "" {
+			return fmt.Errorf("%s.name is required", prefix)
+		}
+		if _, ok := dns.IsDomainName(ns.Name); !ok {
+			return fmt.Errorf("%s.name %q: invalid hostname", prefix, ns.Name)
+		}
+		if nsNames[strings.ToLower(dns.Fqdn(ns.Name))] {
+			return fmt.Errorf("%s: duplicate nameserver %q", prefix, ns.Name)
+		}
+		nsNames[strings.ToLower(dns.Fqdn(ns.Name))] = true
+		if ns.Address != "" && net.ParseIP(ns.Address) == nil {
+			return fmt.Errorf("%s.address %q: invalid IP address", prefix, ns.Address)
+		}
+		// Glue is mandatory for in-bailiwick nameservers or the delegation cannot resolve
+		if ns.Address == "" {
+			for _, zone := range c.DNS.Zones {
+				if dns.IsSubDomain(dns.Fqdn(zone), dns.Fqdn(ns.Name)) {
+					return fmt.Errorf("%s.address is required: %q is inside zone %q and needs glue", prefix, ns.Name, zone)
+				}
+			}
+		}
+	}
+	// A zone without an NS set cannot be delegated
+	if len(c.DNS.Zones) > 0 && len(c.DNS.Nameservers) == 0 {
+		return fmt.Errorf("nameservers are required when zones are configured")
+	}
+
+	if err := c.validateTSIGKeys(); err != nil {
+		return err
+	}
+	if err := c.validateTransfer(); err != nil {
+		return err
+	}
+	if err := c.validateUpdate(); err != nil {
+		return err
+	}
+	if err := c.validateRateLimit(); err != nil {
+		return err
+	}
+	if err := c.validateQueryLog(); err != nil {
+		return err
+	}
+	if err := c.validateFallthrough(); err != nil {
+		return err
+	}
+
+	return c.validateEncryptedDNS()
+}
+
+// TSIGAlgorithms are the accepted TSIG key algorithms.
+var TSIGAlgorithms = map[string]bool{
+	"hmac-sha1":   true,
+	"hmac-sha224": true,
+	"hmac-sha256": true,
+	"hmac-sha384": true,
+	"hmac-sha512": true,
+}
+
+// validateTSIGKeys validates the TSIG key definitions.
+func (c *Config) validateTSIGKeys() error {
+	names := make(map[string]bool)
+	for i, key := range c.DNS.TSIGKeys {
+		prefix := fmt.Sprintf("tsig_keys[%d]", i)
+		if key.Name == "" {
+			return fmt.Errorf("%s.name is required", prefix)
+		}
+		if _, ok := dns.IsDomainName(key.Name); !ok {
+			return fmt.Errorf("%s.name %q: invalid key name", prefix, key.Name)
+		}
+		name := strings.ToLower(dns.Fqdn(key.Name))
+		if names[name] {
+			return fmt.Errorf("%s: duplicate key %q", prefix, key.Name)
+		}
+		names[name] = true
+
+		if key.Algorithm != "" && !TSIGAlgorithms[strings.ToLower(strings.TrimSuffix(key.Algorithm, "."))] {
+			return fmt.Errorf("%s.algorithm %q: must be hmac-sha1, hmac-sha224, hmac-sha256, hmac-sha384 or hmac-sha512", prefix, key.Algorithm)
+		}
+		if key.Secret == "" {
+			return fmt.Errorf("%s.secret is required", prefix)
+		}
+		if _, err := base64.StdEncoding.DecodeString(key.Secret); err != nil {
+			return fmt.Errorf("%s.secret must be valid base64: %w", prefix, err)
+		}
+	}
+	return nil
+}
+
+// validateTransfer validates the zone transfer and NOTIFY settings.
+func (c *Config) validateTransfer() error {
+	t := c.DNS.Transfer
+	for i, entry := range t.AllowFrom {
+		if _, err := ParseNetwork(entry); err != nil {
+			return fmt.Errorf("transfer.allow_from[%d]: %w", i, err)
+		}
+	}
+
+	for i, name := range t.TSIGKeys {
+		if !c.hasTSIGKey(name) {
+			return fmt.Errorf("transfer.tsig_keys[%d]: unknown key %q", i, name)
+		}
+	}
+
+	for i, addr := range t.Notify {
+		host := addr
+		if h, _, err := net.SplitHostPort(addr); err == nil {
+			host = h
+		}
+		if net.ParseIP(host) == nil {
+			return fmt.Errorf("transfer.notify[%d] %q: must be an IP address or ip:port", i, addr)
+		}
+	}
+
+	if t.CheckInterval < 0 {
+		return fmt.Errorf("transfer.check_interval must be non-negative")
+	}
+	return nil
+}
+
+// validateUpdate validates the dynamic update settings.
+func (c *Config) validateUpdate() error {
+	u := c.DNS.Update
+	for i, name := range u.TSIGKeys {
+		if !c.hasTSIGKey(name) {
+			return fmt.Errorf("update.tsig_keys[%d]: unknown key %q", i, name)
+		}
+	}
+	if u.Port < 0 || u.Port > 65535 {
+		return fmt.Errorf("update.port must be between 1 and 65535")
+	}
+	if u.Weight < 0 {
+		return fmt.Errorf("update.weight must be non-negative")
+	}
+	return nil
+}
+
+// validateRateLimit validates the response rate limiting settings.
+func (c *Config) validateRateLimit() error {
+	rl := c.DNS.RateLimit
+	if rl.ResponsesPerSecond < 0 || rl.NXDomainsPerSecond < 0 || rl.ErrorsPerSecond < 0 {
+		return fmt.Errorf("rate_limit rates must be non-negative")
+	}
+	if rl.Slip < -1 {
+		return fmt.Errorf("rate_limit.slip must be -1 (never) or greater")
+	}
+	if rl.IPv4PrefixLength < 0 || rl.IPv4PrefixLength > 32 {
+		return fmt.Errorf("rate_limit.ipv4_prefix_length must be between 1 and 32")
+	}
+	if rl.IPv6PrefixLength < 0 || rl.IPv6PrefixLength > 128 {
+		return fmt.Errorf("rate_limit.ipv6_prefix_length must be between 1 and 128")
+	}
+	if rl.TableSize < 0 {
+		return fmt.Errorf("rate_limit.table_size must be non-negative")
+	}
+	for i, entry := range rl.AllowFrom {
+		if _, err := ParseNetwork(entry); err != nil {
+			return fmt.Errorf("rate_limit.allow_from[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateQueryLog validates the query log settings.
+func (c *Config) validateQueryLog() error {
+	ql := c.DNS.QueryLog
+	if ql.BufferSize < 0 {
+		return fmt.Errorf("query_log.buffer_size must be non-negative")
+	}
+	if ql.Dnstap.Socket != "" && ql.Dnstap.File != "" {
+		return fmt.Errorf("query_log.dnstap: socket and file are mutually exclusive")
+	}
+	if ql.File.MaxSizeMB < 0 || ql.File.MaxBackups < 0 {
+		return fmt.Errorf("query_log.file: max_size_mb and max_backups must be non-negative")
+	}
+	if ql.Enabled && ql.Dnstap.Socket == "" && ql.Dnstap.File == "" && ql.File.Path == "" {
+		return fmt.Errorf("query_log: dnstap.socket, dnstap.file or file.path is required when enabled")
+	}
+	return nil
+}
+
+// validateFallthrough validates the fallthrough upstreams. Each must serve
+// one of dns.zones, at most once.
+func (c *Config) validateFallthrough() error {
+	seen := make(map[string]bool)
+	for i, f := range c.DNS.Fallthrough {
+		prefix := fmt.Sprintf("fallthrough[%d]", i)
+		zone := strings.ToLower(dns.Fqdn(f.Zone))
+		if !slices.ContainsFunc(c.DNS.Zones, func(z string) bool { return strings.EqualFold(dns.Fqdn(z), zone) }) {
+			return fmt.Errorf("%s.zone %q must be one of dns.zones", prefix, f.Zone)
+		}
+		if seen[zone] {
+			return fmt.Errorf("%s: duplicate zone %q", prefix, f.Zone)
+		}
+		seen[zone] = true
+
+		if len(f.Upstreams) == 0 {
+			return fmt.Errorf("%s.upstreams: at least one upstream is required", prefix)
+		}
+		for j, upstream := range f.Upstreams {
+			if _, err := UpstreamAddress(upstream); err != nil {
+				return fmt.Errorf("%s.upstreams[%d]: %w", prefix, j, err)
+			}
+		}
+		if f.Timeout < 0 {
+			return fmt.Errorf("%s.timeout must be non-negative", prefix)
+		}
+		if f.CacheSize < 0 {
+			return fmt.Errorf("%s.cache_size must be non-negative", prefix)
+		}
+	}
+	return nil
+}
+
+// validateHTTPSRecord validates the service parameters of a domain's HTTPS
+// records. nil means the domain has none.
+func validateHTTPSRecord(https *HTTPSRecordConfig) error {
+	if https == nil {
+		return nil
+	}
+	for i, alpn := range https.ALPN {
+		if alpn == "" || len(alpn) > 255 || strings.ContainsAny(alpn, ", ") {
+			return fmt.Errorf("alpn[%d]: invalid protocol identifier %q", i, alpn)
+		}
+	}
+	if https.Port < 0 || https.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535")
+	}
+	if https.ECH != "" {
+		if _, err := base64.StdEncoding.DecodeString(https.ECH); err != nil {
+			return fmt.Errorf("ech: invalid base64: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateDNS64 validates a DNS64 prefix, which must be an IPv6 /96.
+func validateDNS64(d DNS64Config) error {
+	if d.Prefix == "" {
+		return nil
+	}
+	ip, network, err := net.ParseCIDR(d.Prefix)
+	if err != nil {
+		return fmt.Errorf("invalid prefix %q: %w", d.Prefix, err)
+	}
+	if ones, bits := network.Mask.Size(); ip.To4() != nil || bits != 128 || ones != 96 {
+		return fmt.Errorf("prefix %q must be an IPv6 /96", d.Prefix)
+	}
+	return nil
+}
+
+// hasTSIGKey reports whether a key named name is defined in dns.tsig_keys.
+func (c *Config) hasTSIGKey(name string) bool {
+	for _, key := range c.DNS.TSIGKeys {
+		if strings.EqualFold(dns.Fqdn(key.Name), dns.Fqdn(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseNetwork parses an IP address or CIDR. A bare address is treated as
+// a single-host network.
+func ParseNetwork(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", s, err)
+		}
+		return network, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// UpstreamAddress returns the host:port of an upstream given as host or
+// host:port, adding the default port 53.
+func UpstreamAddress(upstream string) (string, error) {
+	host, port, err := net.SplitHostPort(upstream)
+	if err != nil {
+		// No port; bracketed IPv6 addresses need one
+		host, port = upstream, strconv.Itoa(DefaultFallthroughPort)
+	}
+	if host == "" || strings.ContainsAny(host, "[]") {
+		return "", fmt.Errorf("invalid upstream %q", upstream)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid port in upstream %q", upstream)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// validateEncryptedDNS validates the DNS-over-TLS and DNS-over-HTTPS listeners.
+func (c *Config) validateEncryptedDNS() error {
+	if !c.DNS.DoT.Enabled && !c.DNS.DoH.Enabled {
+		return nil
+	}
+
+	if c.DNS.TLS.CertFile == "" || c.DNS.TLS.KeyFile == "" {
+		return fmt.Errorf("tls.cert_file and tls.key_file are required when dot or doh is enabled")
+	}
+	if c.DNS.DoT.Enabled && c.DNS.DoT.ListenAddress != "" {
+		if _, _, err := net.SplitHostPort(c.DNS.DoT.ListenAddress); err != nil {
+			return fmt.Errorf("invalid dot.listen_address %q: %w", c.DNS.DoT.ListenAddress, err)
+		}
+	}
+	if c.DNS.DoH.Enabled {
+		if c.DNS.DoH.ListenAddress != "" {
+			if _, _, err := net.SplitHostPort(c.DNS.DoH.ListenAddress); err != nil {
+				return fmt.Errorf("invalid doh.listen_address %q: %w", c.DNS.DoH.ListenAddress, err)
+			}
+		}
+		if c.DNS.DoH.Path != "" && !strings.HasPrefix(c.DNS.DoH.Path, "/") {
+			return fmt.Errorf("doh.path %q must start with /", c.DNS.DoH.Path)
+		}
+	}
+	if c.DNS.DoT.Enabled && c.DNS.DoH.Enabled && c.DNS.DoT.ListenAddress != "" &&
+		c.DNS.DoT.ListenAddress == c.DNS.DoH.ListenAddress {
+		return fmt.Errorf("dot.listen_address and doh.listen_address must differ")
+	}
 	return nil
 }
 
@@ -261,6 +633,21 @@ func (c *Config) validateRegions() error {
 		if len(region.Servers) == 0 {
 			return fmt.Errorf("%s: at least one server required", prefix)
 		}
+		if region.Priority < 0 {
+			return fmt.Errorf("%s.priority must be non-negative", prefix)
+		}
+		if (region.Latitude == nil) != (region.Longitude == nil) {
+			return fmt.Errorf("%s: latitude and longitude must be set together", prefix)
+		}
+		if region.Latitude != nil && (*region.Latitude < -90 || *region.Latitude > 90) {
+			return fmt.Errorf("%s.latitude must be between -90 and 90", prefix)
+		}
+		if region.Longitude != nil && (*region.Longitude < -180 || *region.Longitude > 180) {
+			return fmt.Errorf("%s.longitude must be between -180 and 180", prefix)
+		}
+		if region.Bias < -99 || region.Bias > 99 {
+			return fmt.Errorf("%s.bias must be between -99 and 99", prefix)
+		}
 
 		for j, server := range region.Servers {
 			serverPrefix := fmt.Sprintf("%s.servers[%d]", prefix, j)
@@ -270,6 +657,9 @@ func (c *Config) validateRegions() error {
 			if server.Port <= 0 || server.Port > 65535 {
 				return fmt.Errorf("%s.port must be between 1 and 65535", serverPrefix)
 			}
+			if server.Priority < 0 {
+				return fmt.Errorf("%s.priority must be non-negative", serverPrefix)
+			}
 			// v1.1.0: Service field is required (breaking change)
 			if server.Service == "" {
 				return fmt.Errorf("%s.service is required (v1.1.0). Each server must specify which domain/service it belongs to.\n       Example: service: webapp.example.com", serverPrefix)
@@ -287,6 +677,13 @@ func (c *Config) validateRegions() error {
 	return nil
 }
 
+// validRoutingAlgorithms are the accepted domain routing algorithms.
+var validRoutingAlgorithms = map[string]bool{
+	"round-robin": true, "weighted": true, "failover": true,
+	"geolocation": true, "geoproximity": true, "latency": true, "consistent-hash": true,
+	"least-load": true, "": true,
+}
+
 // validateDomains validates domain configurations.
 func (c *Config) validateDomains() error {
 	// Build region name set for validation
@@ -304,21 +701,63 @@ func (c *Config) validateDomains() error {
 		if domain.Name == "" {
 			return fmt.Errorf("%s.name is required", prefix)
 		}
+		if strings.Contains(strings.TrimPrefix(domain.Name, "*."), "*") {
+			return fmt.Errorf("%s.name %q: a wildcard is only allowed as the first label", prefix, domain.Name)
+		}
 		if domainNames[domain.Name] {
 			return fmt.Errorf("%s: duplicate domain name %q", prefix, domain.Name)
 		}
 		domainNames[domain.Name] = true
 
 		// Validate routing algorithm
-		validAlgorithms := map[string]bool{
-			"round-robin": true, "weighted": true, "failover": true,
-			"geolocation": true, "latency": true, "": true,
-		}
-		if !validAlgorithms[strings.ToLower(domain.RoutingAlgorithm)] {
-			return fmt.Errorf("%s.routing_algorithm %q: must be round-robin, weighted, failover, geolocation, or latency",
+		if len(domain.RoutingPipeline) > 0 || strings.EqualFold(domain.RoutingAlgorithm, RoutingAlgorithmPipeline) {
+			if err := validateRoutingPipeline(domain, regionNames); err != nil {
+				return fmt.Errorf("%s: %w", prefix, err)
+			}
+		} else if !validRoutingAlgorithms[strings.ToLower(domain.RoutingAlgorithm)] {
+			return fmt.Errorf("%s.routing_algorithm %q: must be round-robin, weighted, failover, geolocation, geoproximity, latency, consistent-hash, or least-load",
 				prefix, domain.RoutingAlgorithm)
 		}
 
+		if domain.MaxAnswers < 0 {
+			return fmt.Errorf("%s.max_answers must be non-negative", prefix)
+		}
+
+		if f := domain.Failover; f != nil {
+			if f.MinHealthy < 0 {
+				return fmt.Errorf("%s.failover.min_healthy must be non-negative", prefix)
+			}
+			if f.FailbackHoldDown < 0 {
+				return fmt.Errorf("%s.failover.failback_hold_down must be non-negative", prefix)
+			}
+		}
+
+		if domain.PanicThreshold < 0 || domain.PanicThreshold > 100 {
+			return fmt.Errorf("%s.panic_threshold must be between 0 and 100", prefix)
+		}
+
+		if err := validateTrafficSplit(domain.TrafficSplit); err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+
+		for j, addr := range domain.SorryServers {
+			if net.ParseIP(addr) == nil {
+				return fmt.Errorf("%s.sorry_servers[%d]: invalid IP address %q", prefix, j, addr)
+			}
+		}
+
+		if err := ValidateStaticRecords(domain.Name, domain.Records); err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+
+		if err := validateHTTPSRecord(domain.HTTPS); err != nil {
+			return fmt.Errorf("%s.https: %w", prefix, err)
+		}
+
+		if err := validateDNS64(domain.DNS64); err != nil {
+			return fmt.Errorf("%s.dns64: %w", prefix, err)
+		}
+
 		// Validate regions exist
 		if len(domain.Regions) == 0 {
 			return fmt.Errorf("%s: at least one region required", prefix)
@@ -330,6 +769,21 @@ func (c *Config) validateDomains() error {
 		}
 	}
 
+	// A static CNAME cannot share its name with a GSLB domain
+	for i, domain := range c.Domains {
+		for j, record := range domain.Records {
+			if strings.ToUpper(record.Type) != "CNAME" {
+				continue
+			}
+			owner := strings.TrimSuffix(record.Owner(domain.Name), ".")
+			for name := range domainNames {
+				if strings.EqualFold(strings.TrimSuffix(name, "."), owner) {
+					return fmt.Errorf("domains[%d].records[%d]: CNAME at %s conflicts with domain %q", i, j, owner, name)
+				}
+			}
+		}
+	}
+
 	// v1.1.0: Validate that server.service fields reference defined domains
 	if err := c.validateServerServiceReferences(domainNames); err != nil {
 		return err
@@ -338,6 +792,212 @@ func (c *Config) validateDomains() error {
 	return nil
 }
 
+// validateRoutingPipeline validates the routing pipeline of a domain.
+func validateRoutingPipeline(domain Domain, regionNames map[string]bool) error {
+	if len(domain.RoutingPipeline) == 0 {
+		return fmt.Errorf("routing_pipeline is required for routing_algorithm %q", domain.RoutingAlgorithm)
+	}
+	if !strings.EqualFold(domain.RoutingAlgorithm, RoutingAlgorithmPipeline) {
+		return fmt.Errorf("routing_algorithm %q: must be unset when routing_pipeline is set", domain.RoutingAlgorithm)
+	}
+
+	for i, stage := range domain.RoutingPipeline {
+		prefix := fmt.Sprintf("routing_pipeline[%d]", i)
+		switch strings.ToLower(stage.Type) {
+		case StageGeo:
+		case StageHealthTier:
+			if stage.MinHealthy < 0 {
+				return fmt.Errorf("%s.min_healthy must be non-negative", prefix)
+			}
+			for _, regionName := range stage.Regions {
+				if !regionNames[regionName] {
+					return fmt.Errorf("%s: region %q not found", prefix, regionName)
+				}
+			}
+		case StageLatencyThreshold:
+			switch strings.ToLower(stage.Source) {
+			case "", "validation", "learned":
+			default:
+				return fmt.Errorf("%s.source %q: must be validation or learned", prefix, stage.Source)
+			}
+			if stage.MaxLatencyMs < 0 || stage.ToleranceMs < 0 {
+				return fmt.Errorf("%s: max_latency_ms and tolerance_ms must be non-negative", prefix)
+			}
+			if stage.MaxLatencyMs == 0 && stage.ToleranceMs == 0 {
+				return fmt.Errorf("%s: max_latency_ms or tolerance_ms is required", prefix)
+			}
+		case StageLabels:
+			if len(stage.Match) == 0 {
+				return fmt.Errorf("%s.match is required", prefix)
+			}
+		case StageSelect:
+			if i != len(domain.RoutingPipeline)-1 {
+				return fmt.Errorf("%s: select must be the last stage", prefix)
+			}
+			if !validRoutingAlgorithms[strings.ToLower(stage.Algorithm)] {
+				return fmt.Errorf("%s.algorithm %q: must be round-robin, weighted, failover, geolocation, geoproximity, latency, consistent-hash, or least-load",
+					prefix, stage.Algorithm)
+			}
+		default:
+			return fmt.Errorf("%s.type %q: must be geo, health-tier, latency-threshold, labels, or select", prefix, stage.Type)
+		}
+	}
+	return nil
+}
+
+// validateTrafficSplit validates the traffic split of a domain.
+func validateTrafficSplit(splits []TrafficSplit) error {
+	total := 0.0
+	for i, split := range splits {
+		prefix := fmt.Sprintf("traffic_split[%d]", i)
+		if len(split.Match) == 0 {
+			return fmt.Errorf("%s.match is required", prefix)
+		}
+		if split.Percent <= 0 || split.Percent > 100 {
+			return fmt.Errorf("%s.percent must be greater than 0 and at most 100", prefix)
+		}
+		total += split.Percent
+	}
+	if total > 100 {
+		return fmt.Errorf("traffic_split percentages add up to %g, more than 100", total)
+	}
+	return nil
+}
+
+// hasStage reports whether the domain's routing pipeline has a stage of
+// the given type.
+func (d Domain) hasStage(stageType string) bool {
+	for _, stage := range d.RoutingPipeline {
+		if strings.EqualFold(stage.Type, stageType) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesAlgorithm reports whether the domain is routed by the algorithm,
+// directly or in the select stage of its routing pipeline.
+func (d Domain) usesAlgorithm(algorithm string) bool {
+	if strings.EqualFold(d.RoutingAlgorithm, algorithm) {
+		return true
+	}
+	for _, stage := range d.RoutingPipeline {
+		if strings.EqualFold(stage.Type, StageSelect) && strings.EqualFold(stage.Algorithm, algorithm) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateViews validates the split-horizon views.
+func (c *Config) validateViews() error {
+	regionNames := make(map[string]bool)
+	for _, region := range c.Regions {
+		regionNames[region.Name] = true
+	}
+	domainNames := make(map[string]bool)
+	for _, domain := range c.Domains {
+		domainNames[domain.Name] = true
+	}
+
+	viewNames := make(map[string]bool)
+	for i, view := range c.Views {
+		prefix := fmt.Sprintf("views[%d]", i)
+		if view.Name == "" {
+			return fmt.Errorf("%s.name is required", prefix)
+		}
+		if view.Name == DefaultViewName || viewNames[view.Name] {
+			return fmt.Errorf("%s: duplicate view name %q", prefix, view.Name)
+		}
+		viewNames[view.Name] = true
+
+		if len(view.MatchClients) == 0 && len(view.TSIGKeys) == 0 {
+			return fmt.Errorf("%s: match_clients or tsig_keys is required", prefix)
+		}
+		for j, entry := range view.MatchClients {
+			if _, err := ParseNetwork(entry); err != nil {
+				return fmt.Errorf("%s.match_clients[%d]: %w", prefix, j, err)
+			}
+		}
+		for j, name := range view.TSIGKeys {
+			if !c.hasTSIGKey(name) {
+				return fmt.Errorf("%s.tsig_keys[%d]: unknown key %q", prefix, j, name)
+			}
+		}
+
+		if err := validateDNS64(view.DNS64); err != nil {
+			return fmt.Errorf("%s.dns64: %w", prefix, err)
+		}
+
+		for j, domain := range view.Domains {
+			domainPrefix := fmt.Sprintf("%s.domains[%d]", prefix, j)
+			if !domainNames[domain.Name] {
+				return fmt.Errorf("%s: domain %q not found", domainPrefix, domain.Name)
+			}
+			if !validRoutingAlgorithms[strings.ToLower(domain.RoutingAlgorithm)] {
+				return fmt.Errorf("%s.routing_algorithm %q: must be round-robin, weighted, failover, geolocation, geoproximity, latency, consistent-hash, or least-load",
+					domainPrefix, domain.RoutingAlgorithm)
+			}
+			for _, regionName := range domain.Regions {
+				if !regionNames[regionName] {
+					return fmt.Errorf("%s: region %q not found", domainPrefix, regionName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateSchedules validates the schedules: their cron expressions and
+// timezones, and that each scheduled domain and region exists and that a
+// domain has at most one schedule.
+func (c *Config) validateSchedules() error {
+	regionNames := make(map[string]bool)
+	for _, region := range c.Regions {
+		regionNames[region.Name] = true
+	}
+	domainNames := make(map[string]bool)
+	for _, domain := range c.Domains {
+		domainNames[domain.Name] = true
+	}
+
+	scheduleNames := make(map[string]bool)
+	scheduled := make(map[string]string)
+	for i, sched := range c.Schedules {
+		prefix := fmt.Sprintf("schedules[%d]", i)
+		s := sched.ToSchedule()
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+		if scheduleNames[sched.Name] {
+			return fmt.Errorf("%s: duplicate schedule name %q", prefix, sched.Name)
+		}
+		scheduleNames[sched.Name] = true
+
+		if !domainNames[sched.Domain] {
+			return fmt.Errorf("%s: domain %q not found", prefix, sched.Domain)
+		}
+		if other, ok := scheduled[sched.Domain]; ok {
+			return fmt.Errorf("%s: domain %q already has schedule %q", prefix, sched.Domain, other)
+		}
+		scheduled[sched.Domain] = sched.Name
+
+		for j, p := range sched.Profiles {
+			for regionName := range p.RegionWeights {
+				if !regionNames[regionName] {
+					return fmt.Errorf("%s.profiles[%d].region_weights: region %q not found", prefix, j, regionName)
+				}
+			}
+			for _, regionName := range p.RegionPreference {
+				if !regionNames[regionName] {
+					return fmt.Errorf("%s.profiles[%d].region_preference: region %q not found", prefix, j, regionName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // validateServerServiceReferences ensures all server.service fields reference defined domains.
 func (c *Config) validateServerServiceReferences(domainNames map[string]bool) error {
 	for i, region := range c.Regions {
@@ -378,11 +1038,19 @@ func (c *Config) validateGeolocation() error {
 	// Check if any domain uses geolocation routing
 	usesGeo := false
 	for _, domain := range c.Domains {
-		if strings.ToLower(domain.RoutingAlgorithm) == "geolocation" {
+		if strings.ToLower(domain.RoutingAlgorithm) == "geolocation" || domain.hasStage(StageGeo) ||
+			domain.usesAlgorithm("geoproximity") {
 			usesGeo = true
 			break
 		}
 	}
+	for _, view := range c.Views {
+		for _, domain := range view.Domains {
+			if algorithm := strings.ToLower(domain.RoutingAlgorithm); algorithm == "geolocation" || algorithm == "geoproximity" {
+				usesGeo = true
+			}
+		}
+	}
 
 	if !usesGeo {
 		return nil
@@ -439,6 +1107,25 @@ func (c *Config) validateGeolocation() error {
 		}
 	}
 
+	// Geoproximity routing measures distances to the regions' coordinates
+	domains := append([]Domain(nil), c.Domains...)
+	for _, view := range c.Views {
+		domains = append(domains, c.ViewDomains(view)...)
+	}
+	for _, domain := range domains {
+		if !domain.usesAlgorithm("geoproximity") {
+			continue
+		}
+		for _, regionName := range domain.Regions {
+			for _, region := range c.Regions {
+				if region.Name == regionName && region.Latitude == nil {
+					return fmt.Errorf("domain %q uses geoproximity routing: region %q has no latitude and longitude",
+						domain.Name, region.Name)
+				}
+			}
+		}
+	}
+
 	// Validate regions have countries/continents defined (for domains using geolocation)
 	for _, domain := range c.Domains {
 		if strings.ToLower(domain.RoutingAlgorithm) != "geolocation" {
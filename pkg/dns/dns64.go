@@ -0,0 +1,40 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// addDNS64Records adds AAAA records synthesized from the selected IPv4
+// servers in the entry's DNS64 prefix (RFC 6147).
+func (h *Handler) addDNS64Records(m *dns.Msg, q dns.Question, entry *DomainEntry, selected []*routing.Server) {
+	for _, server := range selected {
+		ip := synthesizeDNS64(entry.DNS64, net.ParseIP(server.Address))
+		if ip == nil {
+			h.logger.Error("invalid IPv4 address for DNS64", "address", server.Address)
+			continue
+		}
+		m.Answer = append(m.Answer, addressRecord(q.Name, ip, h.ttlFor(entry)))
+	}
+}
+
+// synthesizeDNS64 embeds an IPv4 address in the last 32 bits of a /96
+// prefix (RFC 6052 section 2.2). Returns nil if ip is not IPv4.
+func synthesizeDNS64(prefix, ip net.IP) net.IP {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return nil
+	}
+	synthesized := make(net.IP, net.IPv6len)
+	copy(synthesized, prefix.To16()[:12])
+	copy(synthesized[12:], ip4)
+	return synthesized
+}
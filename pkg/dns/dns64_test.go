@@ -0,0 +1,144 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// poolRecordingRouter routes to the last server of the pool and records the
+// pool and client address it was given.
+type poolRecordingRouter struct {
+	mockRouter
+	pool     []*routing.Server
+	clientIP net.IP
+}
+
+func (r *poolRecordingRouter) Route(ctx context.Context, pool routing.ServerPool) (*routing.Server, error) {
+	r.pool = pool.Servers()
+	r.clientIP = routing.GetClientIP(ctx)
+	return r.pool[len(r.pool)-1], nil
+}
+
+func TestSynthesizeDNS64(t *testing.T) {
+	tests := []struct {
+		prefix string
+		ip     string
+		want   string
+	}{
+		{prefix: "64:ff9b::", ip: "192.0.2.33", want: "64:ff9b::c000:221"},
+		{prefix: "2001:db8:64::", ip: "10.0.0.1", want: "2001:db8:64::a00:1"},
+		{prefix: "64:ff9b::", ip: "2001:db8::1", want: "<nil>"},
+	}
+	for _, tt := range tests {
+		got := synthesizeDNS64(net.ParseIP(tt.prefix), net.ParseIP(tt.ip))
+		if got.String() != tt.want {
+			t.Errorf("synthesizeDNS64(%s, %s) = %s, want %s", tt.prefix, tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_DNS64(t *testing.T) {
+	v4 := []ServerInfo{
+		{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+	}
+	router := &poolRecordingRouter{}
+	health := newMockHealthProvider()
+	health.SetHealthy("2001:db8::2", false)
+
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{Name: "v4only.example.com", TTL: 30, Router: router, Servers: v4, DNS64: net.ParseIP("64:ff9b::")})
+	registry.Register(&DomainEntry{Name: "dual.example.com", Router: &mockRouter{}, DNS64: net.ParseIP("64:ff9b::"),
+		Servers: append([]ServerInfo{{Address: net.ParseIP("2001:db8::1"), Port: 80, Weight: 100}}, v4...)})
+	registry.Register(&DomainEntry{Name: "v6down.example.com", Router: &mockRouter{}, DNS64: net.ParseIP("2001:db8:64::"),
+		Servers: append([]ServerInfo{{Address: net.ParseIP("2001:db8::2"), Port: 80, Weight: 100}}, v4...)})
+	registry.Register(&DomainEntry{Name: "plain.example.com", Router: &mockRouter{}, Servers: v4})
+	h := NewHandler(HandlerConfig{Registry: registry, HealthProvider: health, DefaultTTL: 60})
+
+	tests := []struct {
+		name  string
+		qname string
+		want  string
+	}{
+		{name: "routed IPv4 backend synthesized", qname: "v4only.example.com.", want: "64:ff9b::a00:2"},
+		{name: "healthy IPv6 backend preferred", qname: "dual.example.com.", want: "2001:db8::1"},
+		{name: "unhealthy IPv6 backends synthesized", qname: "v6down.example.com.", want: "2001:db8:64::a00:1"},
+		{name: "disabled", qname: "plain.example.com."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, h, tt.qname, dns.TypeAAAA)
+			if resp.Rcode != dns.RcodeSuccess {
+				t.Fatalf("expected NOERROR, got %s", dns.RcodeToString[resp.Rcode])
+			}
+			if tt.want == "" {
+				if len(resp.Answer) != 0 {
+					t.Errorf("expected NODATA, got %v", resp.Answer)
+				}
+				return
+			}
+			if len(resp.Answer) != 1 {
+				t.Fatalf("expected 1 answer, got %d", len(resp.Answer))
+			}
+			aaaa, ok := resp.Answer[0].(*dns.AAAA)
+			if !ok || aaaa.AAAA.String() != tt.want {
+				t.Errorf("expected AAAA %s, got %v", tt.want, resp.Answer[0])
+			}
+		})
+	}
+
+	// Routing happens on the IPv4 pool for the querying client
+	if len(router.pool) != 2 || router.pool[0].Address != "10.0.0.1" {
+		t.Errorf("expected the router to get the IPv4 pool, got %v", router.pool)
+	}
+	if !router.clientIP.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("expected the client address in the routing context, got %v", router.clientIP)
+	}
+
+	// A records are unaffected
+	resp := query(t, h, "v4only.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 || resp.Answer[0].(*dns.A).A.String() != "10.0.0.2" {
+		t.Errorf("expected the IPv4 backend for A queries, got %v", resp.Answer)
+	}
+}
+
+func TestBuildRegistry_ViewDNS64(t *testing.T) {
+	cfg := newViewTestConfig()
+	cfg.Domains[1].DNS64 = config.DNS64Config{Enabled: true, Prefix: "2001:db8:64::/96"}
+	cfg.Views[0].DNS64 = config.DNS64Config{Enabled: true, Prefix: "64:ff9b::/96"}
+	registry, err := BuildRegistry(cfg, mockRouterFactory, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+
+	if entry := registry.Lookup("app.example.com"); entry.DNS64 != nil {
+		t.Errorf("expected no DNS64 outside the view, got %s", entry.DNS64)
+	}
+	internal := registry.View("internal")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "app.example.com", want: "64:ff9b::"},
+		// Domains not overridden by the view get its DNS64 prefix too, unless
+		// they have their own
+		{name: "www.example.com", want: "2001:db8:64::"},
+	}
+	for _, tt := range tests {
+		entry := internal.Lookup(tt.name)
+		if entry == nil || entry.DNS64.String() != tt.want {
+			t.Errorf("%s: expected DNS64 prefix %s in the view, got %+v", tt.name, tt.want, entry)
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"net"
 	"testing"
 
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/dnssec"
 	"github.com/loganrossus/OpenGSLB/pkg/routing"
 	"github.com/miekg/dns"
 )
@@ -37,6 +39,17 @@ func (m *mockRouter) Route(ctx context.Context, pool routing.ServerPool) (*routi
 	return nil, routing.ErrNoHealthyServers
 }
 
+func (m *mockRouter) Rank(ctx context.Context, pool routing.ServerPool) ([]*routing.Server, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, routing.ErrNoHealthyServers
+	}
+	return servers, nil
+}
+
 func (m *mockRouter) Algorithm() string {
 	if m.algorithm != "" {
 		return m.algorithm
@@ -67,6 +80,42 @@ func (m *mockHealthProvider) SetHealthy(address string, healthy bool) {
 	m.healthy[address] = healthy
 }
 
+// testResponseWriter implements dns.ResponseWriter and captures the reply.
+type testResponseWriter struct {
+	remote net.Addr
+	msg    *dns.Msg
+}
+
+func newTestResponseWriter() *testResponseWriter {
+	return &testResponseWriter{
+		remote: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 53000},
+	}
+}
+
+func (w *testResponseWriter) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 53}
+}
+func (w *testResponseWriter) RemoteAddr() net.Addr        { return w.remote }
+func (w *testResponseWriter) WriteMsg(m *dns.Msg) error   { w.msg = m; return nil }
+func (w *testResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *testResponseWriter) Close() error                { return nil }
+func (w *testResponseWriter) TsigStatus() error           { return nil }
+func (w *testResponseWriter) TsigTimersOnly(bool)         {}
+func (w *testResponseWriter) Hijack()                     {}
+
+// query sends a single question through the handler and returns the reply.
+func query(t *testing.T, h *Handler, name string, qtype uint16) *dns.Msg {
+	t.Helper()
+	req := new(dns.Msg)
+	req.SetQuestion(name, qtype)
+	w := newTestResponseWriter()
+	h.ServeDNS(w, req)
+	if w.msg == nil {
+		t.Fatalf("no response written for %s %s", name, dns.TypeToString[qtype])
+	}
+	return w.msg
+}
+
 func TestRegistry_Basic(t *testing.T) {
 	registry := NewRegistry()
 
@@ -368,3 +417,461 @@ func TestRegistry_SameInstanceForHandlerAndProvider(t *testing.T) {
 		t.Error("both should return the same entry")
 	}
 }
+
+// TestHandler_NoDataSemantics verifies NODATA vs NXDOMAIN vs SERVFAIL selection.
+func TestHandler_NoDataSemantics(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "v4only.example.com",
+		TTL:    30,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		},
+	})
+	registry.Register(&DomainEntry{
+		Name:   "dual.example.com",
+		TTL:    30,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("2001:db8::2"), Port: 80, Weight: 100},
+		},
+	})
+	registry.Register(&DomainEntry{
+		Name:   "down.example.com",
+		TTL:    30,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.3"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("2001:db8::3"), Port: 80, Weight: 100},
+		},
+	})
+
+	health := newMockHealthProvider()
+	health.SetHealthy("2001:db8::2", false)
+	health.SetHealthy("10.0.0.3", false)
+	health.SetHealthy("2001:db8::3", false)
+
+	handler := NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: health,
+		DefaultTTL:     60,
+	})
+
+	tests := []struct {
+		name    string
+		qname   string
+		qtype   uint16
+		rcode   int
+		answers int
+	}{
+		{"A for IPv4-only domain", "v4only.example.com.", dns.TypeA, dns.RcodeSuccess, 1},
+		{"AAAA for IPv4-only domain is NODATA", "v4only.example.com.", dns.TypeAAAA, dns.RcodeSuccess, 0},
+		{"AAAA with IPv6 down but IPv4 healthy is NODATA", "dual.example.com.", dns.TypeAAAA, dns.RcodeSuccess, 0},
+		{"A with every backend down is SERVFAIL", "down.example.com.", dns.TypeA, dns.RcodeServerFailure, 0},
+		{"AAAA with every backend down is SERVFAIL", "down.example.com.", dns.TypeAAAA, dns.RcodeServerFailure, 0},
+		{"unknown type for existing name is NODATA", "v4only.example.com.", dns.TypeTXT, dns.RcodeSuccess, 0},
+		{"unknown type for missing name is NXDOMAIN", "missing.example.com.", dns.TypeMX, dns.RcodeNameError, 0},
+		{"A for missing name is NXDOMAIN", "missing.example.com.", dns.TypeA, dns.RcodeNameError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, handler, tt.qname, tt.qtype)
+			if resp.Rcode != tt.rcode {
+				t.Errorf("expected %s, got %s", dns.RcodeToString[tt.rcode], dns.RcodeToString[resp.Rcode])
+			}
+			if len(resp.Answer) != tt.answers {
+				t.Errorf("expected %d answers, got %d", tt.answers, len(resp.Answer))
+			}
+		})
+	}
+}
+
+// mockNoDataSigner records the types passed for NODATA proofs.
+type mockNoDataSigner struct {
+	noDataTypes []uint16
+	noDataCalls int
+}
+
+func (m *mockNoDataSigner) SignResponse(msg interface{}) (interface{}, error) {
+	return msg, nil
+}
+
+func (m *mockNoDataSigner) SignNoDataResponse(msg interface{}, existingTypes []uint16) (interface{}, error) {
+	m.noDataCalls++
+	m.noDataTypes = existingTypes
+	return msg, nil
+}
+
+func TestHandler_NoDataUsesNSEC3Signer(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "v4only.example.com",
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		},
+	})
+
+	signer := &mockNoDataSigner{}
+	handler := NewHandler(HandlerConfig{
+		Registry:      registry,
+		DNSSECSigner:  signer,
+		DNSSECEnabled: true,
+		DefaultTTL:    60,
+	})
+
+	query(t, handler, "v4only.example.com.", dns.TypeA)
+	if signer.noDataCalls != 0 {
+		t.Fatal("positive answers must not use the NODATA signer")
+	}
+
+	query(t, handler, "v4only.example.com.", dns.TypeAAAA)
+	if signer.noDataCalls != 1 {
+		t.Fatalf("expected NODATA signer to be used once, got %d", signer.noDataCalls)
+	}
+	hasA := false
+	for _, typ := range signer.noDataTypes {
+		if typ == dns.TypeAAAA {
+			t.Error("existing types must not include AAAA")
+		}
+		if typ == dns.TypeA {
+			hasA = true
+		}
+	}
+	if !hasA {
+		t.Errorf("expected existing types to include A, got %v", signer.noDataTypes)
+	}
+}
+
+func TestHandler_MaxAnswers(t *testing.T) {
+	registry := NewRegistry()
+	servers := []ServerInfo{
+		{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+		{Address: net.ParseIP("10.0.0.3"), Port: 80, Weight: 100},
+	}
+	registry.Register(&DomainEntry{Name: "single.example.com", Router: &mockRouter{}, Servers: servers})
+	registry.Register(&DomainEntry{Name: "multi.example.com", Router: &mockRouter{}, Servers: servers, MaxAnswers: 2})
+	registry.Register(&DomainEntry{Name: "all.example.com", Router: &mockRouter{}, Servers: servers, MaxAnswers: 10})
+
+	health := newMockHealthProvider()
+	health.SetHealthy("10.0.0.1", false)
+
+	handler := NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: health,
+		DefaultTTL:     60,
+	})
+
+	tests := []struct {
+		qname string
+		want  []string
+	}{
+		{"single.example.com.", []string{"10.0.0.2"}},
+		{"multi.example.com.", []string{"10.0.0.2", "10.0.0.3"}},
+		{"all.example.com.", []string{"10.0.0.2", "10.0.0.3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.qname, func(t *testing.T) {
+			resp := query(t, handler, tt.qname, dns.TypeA)
+			if len(resp.Answer) != len(tt.want) {
+				t.Fatalf("expected %d answers, got %d", len(tt.want), len(resp.Answer))
+			}
+			for i, rr := range resp.Answer {
+				a := rr.(*dns.A)
+				if a.A.String() != tt.want[i] {
+					t.Errorf("answer %d: expected %s, got %s", i, tt.want[i], a.A)
+				}
+			}
+		})
+	}
+}
+
+func newStaticRecordHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    30,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		},
+	})
+	err := registry.SetStaticRecords("app.example.com", []config.StaticRecord{
+		{Type: "TXT", Value: "google-site-verification=abc"},
+		{Type: "MX", Value: "10 mail.example.com.", TTL: 3600},
+		{Type: "CAA", Value: `0 issue "letsencrypt.org"`},
+		{Name: "_sip._tcp", Type: "SRV", Value: "10 5 5060 sip.example.com."},
+		{Name: "www", Type: "CNAME", Value: "app.example.com."},
+	})
+	if err != nil {
+		t.Fatalf("failed to set static records: %v", err)
+	}
+
+	return NewHandler(HandlerConfig{
+		Registry:   registry,
+		DefaultTTL: 60,
+	})
+}
+
+func TestHandler_StaticRecords(t *testing.T) {
+	handler := newStaticRecordHandler(t)
+
+	tests := []struct {
+		name    string
+		qname   string
+		qtype   uint16
+		rcode   int
+		answers []uint16
+	}{
+		{"TXT at domain", "app.example.com.", dns.TypeTXT, dns.RcodeSuccess, []uint16{dns.TypeTXT}},
+		{"MX at domain", "app.example.com.", dns.TypeMX, dns.RcodeSuccess, []uint16{dns.TypeMX}},
+		{"CAA at domain", "app.example.com.", dns.TypeCAA, dns.RcodeSuccess, []uint16{dns.TypeCAA}},
+		{"A still routed", "app.example.com.", dns.TypeA, dns.RcodeSuccess, []uint16{dns.TypeA}},
+		{"SRV below domain", "_sip._tcp.app.example.com.", dns.TypeSRV, dns.RcodeSuccess, []uint16{dns.TypeSRV}},
+		{"missing type at static name is NODATA", "_sip._tcp.app.example.com.", dns.TypeTXT, dns.RcodeSuccess, nil},
+		{"empty non-terminal is NODATA", "_tcp.app.example.com.", dns.TypeTXT, dns.RcodeSuccess, nil},
+		{"CNAME answers A", "www.app.example.com.", dns.TypeA, dns.RcodeSuccess, []uint16{dns.TypeCNAME}},
+		{"CNAME answers TXT", "www.app.example.com.", dns.TypeTXT, dns.RcodeSuccess, []uint16{dns.TypeCNAME}},
+		{"CNAME query", "www.app.example.com.", dns.TypeCNAME, dns.RcodeSuccess, []uint16{dns.TypeCNAME}},
+		{"unknown name is NXDOMAIN", "mail.app.example.com.", dns.TypeTXT, dns.RcodeNameError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, handler, tt.qname, tt.qtype)
+			if resp.Rcode != tt.rcode {
+				t.Fatalf("expected %s, got %s", dns.RcodeToString[tt.rcode], dns.RcodeToString[resp.Rcode])
+			}
+			if len(resp.Answer) != len(tt.answers) {
+				t.Fatalf("expected %d answers, got %d", len(tt.answers), len(resp.Answer))
+			}
+			for i, rr := range resp.Answer {
+				if rr.Header().Rrtype != tt.answers[i] {
+					t.Errorf("answer %d: expected %s, got %s", i,
+						dns.TypeToString[tt.answers[i]], dns.TypeToString[rr.Header().Rrtype])
+				}
+				if rr.Header().Name != tt.qname {
+					t.Errorf("answer %d: expected owner %s, got %s", i, tt.qname, rr.Header().Name)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_StaticRecordTTL(t *testing.T) {
+	handler := newStaticRecordHandler(t)
+
+	if resp := query(t, handler, "app.example.com.", dns.TypeMX); resp.Answer[0].Header().Ttl != 3600 {
+		t.Errorf("expected record TTL 3600, got %d", resp.Answer[0].Header().Ttl)
+	}
+	if resp := query(t, handler, "app.example.com.", dns.TypeTXT); resp.Answer[0].Header().Ttl != 30 {
+		t.Errorf("expected domain TTL 30, got %d", resp.Answer[0].Header().Ttl)
+	}
+}
+
+func TestHandler_StaticRecordsSigned(t *testing.T) {
+	handler := newStaticRecordHandler(t)
+
+	km := dnssec.NewKeyManager("test-node")
+	if _, err := km.GenerateKey("example.com.", dnssec.AlgorithmECDSAP256SHA256); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	handler.SetDNSSECSigner(dnssec.NewSigner(dnssec.SignerConfig{KeyManager: km}), true)
+
+	resp := query(t, handler, "app.example.com.", dns.TypeTXT)
+	signed := false
+	for _, rr := range resp.Answer {
+		if sig, ok := rr.(*dns.RRSIG); ok && sig.TypeCovered == dns.TypeTXT {
+			signed = true
+		}
+	}
+	if !signed {
+		t.Errorf("expected RRSIG covering TXT, got %v", resp.Answer)
+	}
+}
+
+func TestRegistry_SetStaticRecords(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{Name: "app.example.com", TTL: 30, Router: &mockRouter{}})
+
+	if err := registry.SetStaticRecords("missing.example.com", nil); err == nil {
+		t.Error("expected error for unknown domain")
+	}
+	if err := registry.SetStaticRecords("app.example.com", []config.StaticRecord{{Type: "CNAME", Value: "x.example.com."}}); err == nil {
+		t.Error("expected error for CNAME at domain name")
+	}
+
+	if err := registry.SetStaticRecords("app.example.com", []config.StaticRecord{{Name: "_dmarc", Type: "TXT", Value: "v=DMARC1"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registry.StaticRecords("_dmarc.APP.example.com.")) != 1 {
+		t.Error("expected case-insensitive static record lookup")
+	}
+
+	// Replacing clears the previous set
+	if err := registry.SetStaticRecords("app.example.com", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if registry.HasStaticName("_dmarc.app.example.com") {
+		t.Error("expected static records to be cleared")
+	}
+
+	// Removing the domain drops its records from the index
+	_ = registry.SetStaticRecords("app.example.com", []config.StaticRecord{{Type: "TXT", Value: "x"}})
+	registry.Remove("app.example.com")
+	if len(registry.StaticRecords("app.example.com")) != 0 {
+		t.Error("expected records of removed domain to be dropped")
+	}
+}
+
+func TestRegistry_WildcardLookup(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{Name: "*.apps.example.com", Router: &mockRouter{}})
+	registry.Register(&DomainEntry{Name: "*.eu.apps.example.com", Router: &mockRouter{}})
+	registry.Register(&DomainEntry{Name: "admin.apps.example.com", Router: &mockRouter{}})
+
+	tests := []struct {
+		name      string
+		wantEntry string
+		wantLabel string
+	}{
+		{"acme.apps.example.com.", "*.apps.example.com.", "acme"},
+		{"ACME.apps.example.com", "*.apps.example.com.", "acme"},
+		{"a.b.apps.example.com.", "*.apps.example.com.", "a.b"},
+		{"acme.eu.apps.example.com.", "*.eu.apps.example.com.", "acme"},
+		{"eu.apps.example.com.", "*.apps.example.com.", "eu"},
+		{"admin.apps.example.com.", "admin.apps.example.com.", ""},
+		{"apps.example.com.", "", ""},
+		{"acme.other.example.com.", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, label := registry.LookupMatch(tt.name)
+			if tt.wantEntry == "" {
+				if entry != nil {
+					t.Errorf("expected no match, got %s", entry.Name)
+				}
+				return
+			}
+			if entry == nil || entry.Name != tt.wantEntry {
+				t.Fatalf("expected %s, got %v", tt.wantEntry, entry)
+			}
+			if label != tt.wantLabel {
+				t.Errorf("expected label %q, got %q", tt.wantLabel, label)
+			}
+		})
+	}
+}
+
+// labelRouter records the wildcard label passed in the routing context.
+type labelRouter struct {
+	mockRouter
+	label string
+}
+
+func (r *labelRouter) Route(ctx context.Context, pool routing.ServerPool) (*routing.Server, error) {
+	r.label = routing.GetWildcardLabel(ctx)
+	return r.mockRouter.Route(ctx, pool)
+}
+
+func TestHandler_WildcardDomain(t *testing.T) {
+	router := &labelRouter{}
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "*.apps.example.com",
+		TTL:    30,
+		Router: router,
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		},
+	})
+	handler := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60})
+
+	resp := query(t, handler, "customer42.apps.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected 1 answer, got %d", len(resp.Answer))
+	}
+	if resp.Answer[0].Header().Name != "customer42.apps.example.com." {
+		t.Errorf("expected answer owned by the query name, got %s", resp.Answer[0].Header().Name)
+	}
+	if router.label != "customer42" {
+		t.Errorf("expected wildcard label customer42 in routing context, got %q", router.label)
+	}
+
+	resp = query(t, handler, "apps.example.com.", dns.TypeA)
+	if resp.Rcode != dns.RcodeNameError {
+		t.Errorf("expected NXDOMAIN for the wildcard parent, got %s", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestWeightProfile_Apply(t *testing.T) {
+	profile := &WeightProfile{
+		RegionWeights:    map[string]int{"eu-west": 10},
+		ServerWeights:    map[string]int{"10.0.0.2": 50},
+		RegionPreference: []string{"us-east", "eu-west"},
+	}
+
+	tests := []struct {
+		server       routing.Server
+		wantWeight   int
+		wantPriority int
+	}{
+		{routing.Server{Address: "10.0.0.1", Region: "eu-west", Weight: 100}, 10, 2},
+		{routing.Server{Address: "10.0.0.2", Region: "eu-west", Weight: 100}, 50, 2},
+		{routing.Server{Address: "10.0.0.3", Region: "us-east", Weight: 100}, 100, 1},
+		{routing.Server{Address: "10.0.0.4", Region: "ap-south", Weight: 100}, 100, 3},
+	}
+	for _, tt := range tests {
+		s := tt.server
+		profile.Apply(&s)
+		if s.Weight != tt.wantWeight || s.Priority != tt.wantPriority {
+			t.Errorf("%s: got weight %d priority %d, want %d and %d",
+				s.Address, s.Weight, s.Priority, tt.wantWeight, tt.wantPriority)
+		}
+	}
+}
+
+func TestHandler_WeightProfile(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    30,
+		Router: routing.NewFailoverRouter(),
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100, Region: "eu-west"},
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100, Region: "us-east"},
+		},
+	})
+	handler := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60})
+
+	answer := func() string {
+		t.Helper()
+		resp := query(t, handler, "app.example.com.", dns.TypeA)
+		if len(resp.Answer) != 1 {
+			t.Fatalf("expected 1 answer, got %d", len(resp.Answer))
+		}
+		return resp.Answer[0].(*dns.A).A.String()
+	}
+
+	if got := answer(); got != "10.0.0.1" {
+		t.Errorf("expected the first server without a profile, got %s", got)
+	}
+
+	handler.SetWeightProfile("app.example.com", &WeightProfile{Name: "americas", RegionPreference: []string{"us-east"}})
+	if got := answer(); got != "10.0.0.2" {
+		t.Errorf("expected the preferred region's server, got %s", got)
+	}
+
+	handler.SetWeightProfile("app.example.com", nil)
+	if got := answer(); got != "10.0.0.1" {
+		t.Errorf("expected the first server after clearing the profile, got %s", got)
+	}
+}
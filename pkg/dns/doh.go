@@ -0,0 +1,159 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// dohMediaType is the RFC 8484 media type of DNS messages over HTTPS.
+const dohMediaType = "application/dns-message"
+
+// dohHandler serves RFC 8484 DNS-over-HTTPS queries, sent either as the
+// base64url "dns" parameter of a GET request or as the body of a POST.
+type dohHandler struct {
+	handler dns.Handler
+	logger  *slog.Logger
+}
+
+func (d *dohHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		packed []byte
+		err    error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		param := r.URL.Query().Get("dns")
+		if param == "" {
+			http.Error(w, "missing dns parameter", http.StatusBadRequest)
+			return
+		}
+		packed, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(param, "="))
+	case http.MethodPost:
+		if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) != dohMediaType {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		packed, err = io.ReadAll(io.LimitReader(r.Body, dns.MaxMsgSize+1))
+		if err == nil && len(packed) > dns.MaxMsgSize {
+			http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "invalid dns message", http.StatusBadRequest)
+		return
+	}
+
+	req := new(dns.Msg)
+	if err := req.Unpack(packed); err != nil {
+		http.Error(w, "invalid dns message", http.StatusBadRequest)
+		return
+	}
+
+	rw := &dohResponseWriter{remote: remoteAddr(r), local: localAddr(r)}
+	if len(req.Question) > 0 && (req.Question[0].Qtype == dns.TypeAXFR || req.Question[0].Qtype == dns.TypeIXFR) {
+		// Zone transfers span several messages and are only served over TCP
+		rw.msg = new(dns.Msg)
+		rw.msg.SetRcode(req, dns.RcodeRefused)
+	} else {
+		d.handler.ServeDNS(rw, req)
+	}
+	if rw.msg == nil {
+		http.Error(w, "no response", http.StatusInternalServerError)
+		return
+	}
+
+	out, err := rw.msg.Pack()
+	if err != nil {
+		d.logger.Error("failed to pack DoH response", "error", err)
+		http.Error(w, "failed to pack response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", dohMediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", minTTL(rw.msg)))
+	_, _ = w.Write(out)
+}
+
+// minTTL returns the smallest TTL in a response, which bounds how long HTTP
+// caches may keep it (RFC 8484 section 5.1).
+func minTTL(m *dns.Msg) uint32 {
+	var ttl uint32
+	first := true
+	for _, section := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			if first || rr.Header().Ttl < ttl {
+				ttl = rr.Header().Ttl
+				first = false
+			}
+		}
+	}
+	return ttl
+}
+
+// remoteAddr returns the client address of an HTTP request.
+func remoteAddr(r *http.Request) net.Addr {
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		return addr
+	}
+	return &net.TCPAddr{}
+}
+
+// localAddr returns the local address an HTTP request was received on.
+func localAddr(r *http.Request) net.Addr {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		return addr
+	}
+	return &net.TCPAddr{}
+}
+
+// errTSIGUnsupported is the TSIG status of DoH requests, which are never
+// verified, so that signed requests are not mistaken for authenticated ones.
+var errTSIGUnsupported = errors.New("TSIG is not supported over DNS-over-HTTPS")
+
+// dohResponseWriter captures the reply to a DoH query so it can be written
+// as the HTTP response body.
+type dohResponseWriter struct {
+	remote net.Addr
+	local  net.Addr
+	msg    *dns.Msg
+}
+
+func (w *dohResponseWriter) LocalAddr() net.Addr       { return w.local }
+func (w *dohResponseWriter) RemoteAddr() net.Addr      { return w.remote }
+func (w *dohResponseWriter) WriteMsg(m *dns.Msg) error { w.msg = m; return nil }
+func (w *dohResponseWriter) Close() error              { return nil }
+func (w *dohResponseWriter) TsigStatus() error         { return errTSIGUnsupported }
+func (w *dohResponseWriter) TsigTimersOnly(bool)       {}
+func (w *dohResponseWriter) Hijack()                   {}
+
+func (w *dohResponseWriter) Write(b []byte) (int, error) {
+	m := new(dns.Msg)
+	if err := m.Unpack(b); err != nil {
+		return 0, err
+	}
+	w.msg = m
+	return len(b), nil
+}
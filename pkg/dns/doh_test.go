@@ -0,0 +1,152 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func newDoHTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    30,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		},
+	})
+	handler := NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: newMockHealthProvider(),
+		DefaultTTL:     60,
+	})
+
+	srv := httptest.NewServer(&dohHandler{
+		handler: transportHandler{transport: transportDoH, handler: handler},
+		logger:  slog.Default(),
+	})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func packQuery(t *testing.T, name string, qtype uint16) []byte {
+	t.Helper()
+	req := new(dns.Msg)
+	req.SetQuestion(name, qtype)
+	req.Id = 0
+	packed, err := req.Pack()
+	if err != nil {
+		t.Fatalf("failed to pack query: %v", err)
+	}
+	return packed
+}
+
+func readDoHResponse(t *testing.T, resp *http.Response) *dns.Msg {
+	t.Helper()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != dohMediaType {
+		t.Errorf("expected content type %s, got %s", dohMediaType, ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	msg := new(dns.Msg)
+	if err := msg.Unpack(body); err != nil {
+		t.Fatalf("failed to unpack response: %v", err)
+	}
+	return msg
+}
+
+func TestDoH_Get(t *testing.T) {
+	srv := newDoHTestServer(t)
+
+	param := base64.RawURLEncoding.EncodeToString(packQuery(t, "app.example.com.", dns.TypeA))
+	resp, err := http.Get(srv.URL + "/dns-query?dns=" + param)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "max-age=30" {
+		t.Errorf("expected max-age from answer TTL, got %q", cc)
+	}
+
+	msg := readDoHResponse(t, resp)
+	if len(msg.Answer) != 1 {
+		t.Fatalf("expected 1 answer, got %d", len(msg.Answer))
+	}
+	if a := msg.Answer[0].(*dns.A); !a.A.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("expected 10.0.0.1, got %s", a.A)
+	}
+}
+
+func TestDoH_Post(t *testing.T) {
+	srv := newDoHTestServer(t)
+
+	body := bytes.NewReader(packQuery(t, "missing.example.com.", dns.TypeA))
+	resp, err := http.Post(srv.URL+"/dns-query", dohMediaType, body)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+
+	msg := readDoHResponse(t, resp)
+	if msg.Rcode != dns.RcodeNameError {
+		t.Errorf("expected NXDOMAIN, got %s", dns.RcodeToString[msg.Rcode])
+	}
+}
+
+func TestDoH_BadRequests(t *testing.T) {
+	srv := newDoHTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		ctype  string
+		body   []byte
+		status int
+	}{
+		{"missing dns parameter", http.MethodGet, "/dns-query", "", nil, http.StatusBadRequest},
+		{"invalid base64", http.MethodGet, "/dns-query?dns=!!!", "", nil, http.StatusBadRequest},
+		{"invalid message", http.MethodPost, "/dns-query", dohMediaType, []byte{0x01}, http.StatusBadRequest},
+		{"wrong content type", http.MethodPost, "/dns-query", "text/plain", []byte("x"), http.StatusUnsupportedMediaType},
+		{"wrong method", http.MethodPut, "/dns-query", dohMediaType, nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.url, bytes.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("failed to build request: %v", err)
+			}
+			if tt.ctype != "" {
+				req.Header.Set("Content-Type", tt.ctype)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/geo"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// badVersion answers a query with an EDNS version other than 0, the only one
+// supported, with BADVERS (RFC 6891 section 6.1.3).
+func (h *Handler) badVersion(w dns.ResponseWriter, m *dns.Msg, start time.Time) {
+	m.Rcode = dns.RcodeBadVers
+	m.SetEdns0(h.maxUDPSize, false)
+	h.writeResponse(w, m, start, "BADVERS", m.Question[0].Name)
+}
+
+// setEDNS adds an OPT record to the response of a query that has one (RFC
+// 6891): the buffer size this server accepts and the DO bit of the query.
+func (h *Handler) setEDNS(r, m *dns.Msg) {
+	opt := r.IsEdns0()
+	if opt == nil || m.IsEdns0() != nil {
+		return
+	}
+	m.SetEdns0(h.maxUDPSize, opt.Do())
+}
+
+// addECS returns the client subnet of the query with the scope the answer
+// applies to (RFC 7871): the prefix length the routing decision depended on,
+// or 0 when every client gets the same answer, so resolvers cache routed
+// answers per subnet only when they need to. Must follow setEDNS.
+func (h *Handler) addECS(reg *Registry, r, m *dns.Msg) {
+	if !h.ecsEnabled {
+		return
+	}
+	ecs := geo.ParseECS(r)
+	if !ecs.Found || ecs.IP == nil {
+		return
+	}
+
+	// A source prefix of 0 asks for an answer valid for every client
+	scope := 0
+	if ecs.SourceNetmask > 0 {
+		if entry, _ := answeredDomain(reg, m, m.Question[0]); entry != nil {
+			if scoper, ok := entry.Router.(routing.ECSScoper); ok {
+				scope = scoper.ECSScope(ecs.IP, int(ecs.SourceNetmask))
+			}
+			if entry.Split != nil {
+				scope = max(scope, entry.Split.ECSScope(ecs.IP, int(ecs.SourceNetmask)))
+			}
+		}
+	}
+	geo.AddECSResponse(m, ecs.IP, ecs.SourceNetmask, uint8(scope))
+}
+
+// truncate fits a UDP response into the client's buffer: 512 bytes without
+// EDNS, otherwise the smaller of the client's and this server's buffer size.
+// Records that do not fit are dropped and TC is set so the client retries
+// over TCP. Must precede TSIG signing, which truncation would invalidate.
+func (h *Handler) truncate(w dns.ResponseWriter, r, m *dns.Msg) {
+	if _, ok := w.RemoteAddr().(*net.UDPAddr); !ok {
+		return
+	}
+	size := uint16(dns.MinMsgSize)
+	if opt := r.IsEdns0(); opt != nil {
+		size = min(opt.UDPSize(), h.maxUDPSize)
+	}
+
+	m.Truncate(int(size))
+	if m.Truncated {
+		h.logger.Debug("UDP response truncated",
+			"name", m.Question[0].Name,
+			"size", size,
+		)
+	}
+}
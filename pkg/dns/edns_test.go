@@ -0,0 +1,201 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// scopedRouter is a mockRouter whose decision depends on the client /24.
+type scopedRouter struct {
+	mockRouter
+}
+
+func (r *scopedRouter) ECSScope(clientIP net.IP, sourcePrefix int) int { return 24 }
+
+// ednsQuery sends a query with an OPT record through the handler, over TCP
+// when tcp is set, and returns the reply.
+func ednsQuery(t *testing.T, h *Handler, name string, opt *dns.OPT, tcp bool) *dns.Msg {
+	t.Helper()
+	req := new(dns.Msg)
+	req.SetQuestion(name, dns.TypeA)
+	if opt != nil {
+		req.Extra = append(req.Extra, opt)
+	}
+	w := newTestResponseWriter()
+	if tcp {
+		w.remote = &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 53000}
+	}
+	h.ServeDNS(w, req)
+	if w.msg == nil {
+		t.Fatalf("no response written for %s", name)
+	}
+	return w.msg
+}
+
+// newOPT returns an OPT record with the given buffer size and options.
+func newOPT(size uint16, do bool, options ...dns.EDNS0) *dns.OPT {
+	opt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	opt.SetUDPSize(size)
+	if do {
+		opt.SetDo()
+	}
+	opt.Option = options
+	return opt
+}
+
+func TestHandler_EDNSEcho(t *testing.T) {
+	h := newZoneTestHandler(t)
+
+	resp := ednsQuery(t, h, "app.gslb.example.com.", newOPT(4096, true), false)
+	opt := resp.IsEdns0()
+	if opt == nil {
+		t.Fatal("expected OPT record in response")
+	}
+	if opt.UDPSize() != config.DefaultMaxUDPSize || !opt.Do() || opt.Version() != 0 {
+		t.Errorf("unexpected OPT: size %d, DO %v, version %d", opt.UDPSize(), opt.Do(), opt.Version())
+	}
+
+	// NXDOMAIN responses carry the OPT record too
+	resp = ednsQuery(t, h, "missing.gslb.example.com.", newOPT(4096, false), false)
+	if resp.Rcode != dns.RcodeNameError || resp.IsEdns0() == nil || resp.IsEdns0().Do() {
+		t.Errorf("expected NXDOMAIN with OPT and DO clear, got %v", resp)
+	}
+
+	resp = query(t, h, "app.gslb.example.com.", dns.TypeA)
+	if resp.IsEdns0() != nil {
+		t.Error("expected no OPT record for a query without EDNS")
+	}
+}
+
+func TestHandler_EDNSBadVersion(t *testing.T) {
+	h := newZoneTestHandler(t)
+	opt := newOPT(4096, false)
+	opt.SetVersion(1)
+
+	resp := ednsQuery(t, h, "app.gslb.example.com.", opt, false)
+	if resp.Rcode != dns.RcodeBadVers || len(resp.Answer) != 0 {
+		t.Fatalf("expected BADVERS without answers, got rcode %d", resp.Rcode)
+	}
+
+	// The extended rcode is carried by the OPT record on the wire
+	wire, err := resp.Pack()
+	if err != nil {
+		t.Fatalf("failed to pack BADVERS response: %v", err)
+	}
+	unpacked := new(dns.Msg)
+	if err := unpacked.Unpack(wire); err != nil {
+		t.Fatalf("failed to unpack BADVERS response: %v", err)
+	}
+	if unpacked.Rcode != dns.RcodeBadVers || unpacked.IsEdns0().Version() != 0 {
+		t.Errorf("expected BADVERS with EDNS version 0, got rcode %d", unpacked.Rcode)
+	}
+}
+
+func TestHandler_UDPTruncation(t *testing.T) {
+	var servers []ServerInfo
+	for i := 1; i <= 60; i++ {
+		servers = append(servers, ServerInfo{Address: net.ParseIP(fmt.Sprintf("10.0.0.%d", i)), Port: 80, Weight: 100})
+	}
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{Name: "big.example.com", Router: &mockRouter{}, Servers: servers, MaxAnswers: 60})
+	h := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60, MaxUDPSize: 1232})
+
+	tests := []struct {
+		name          string
+		opt           *dns.OPT
+		tcp           bool
+		wantTruncated bool
+		maxSize       int
+	}{
+		{name: "udp without edns", wantTruncated: true, maxSize: dns.MinMsgSize},
+		{name: "udp small buffer", opt: newOPT(600, false), wantTruncated: true, maxSize: 600},
+		{name: "udp large buffer", opt: newOPT(4096, false), maxSize: 1232},
+		{name: "tcp", tcp: true, maxSize: dns.MaxMsgSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ednsQuery(t, h, "big.example.com.", tt.opt, tt.tcp)
+			if resp.Truncated != tt.wantTruncated {
+				t.Errorf("expected TC=%v, got %v", tt.wantTruncated, resp.Truncated)
+			}
+			if !tt.wantTruncated && len(resp.Answer) != 60 {
+				t.Errorf("expected 60 answers, got %d", len(resp.Answer))
+			}
+			if resp.Len() > tt.maxSize {
+				t.Errorf("response of %d bytes exceeds %d", resp.Len(), tt.maxSize)
+			}
+			if tt.opt != nil && resp.IsEdns0() == nil {
+				t.Error("expected OPT record to survive truncation")
+			}
+		})
+	}
+}
+
+func TestHandler_ECSScope(t *testing.T) {
+	registry := NewRegistry()
+	servers := []ServerInfo{{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100}}
+	registry.Register(&DomainEntry{Name: "geo.example.com", Router: &scopedRouter{}, Servers: servers})
+	registry.Register(&DomainEntry{Name: "rr.example.com", Router: &mockRouter{}, Servers: servers})
+	registry.Register(&DomainEntry{Name: "split.example.com", Router: &mockRouter{}, Servers: servers,
+		Split: routing.NewTrafficSplit([]routing.SplitRule{{Match: map[string]string{"version": "v2"}, Percent: 5}})})
+	h := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60, ECSEnabled: true})
+
+	subnet := func(source uint8) *dns.EDNS0_SUBNET {
+		return &dns.EDNS0_SUBNET{
+			Code:          dns.EDNS0SUBNET,
+			Family:        1,
+			SourceNetmask: source,
+			Address:       net.ParseIP("198.51.100.0").To4(),
+		}
+	}
+	tests := []struct {
+		name      string
+		qname     string
+		source    uint8
+		wantScope uint8
+	}{
+		{name: "client dependent routing", qname: "geo.example.com.", source: 24, wantScope: 24},
+		{name: "client independent routing", qname: "rr.example.com.", source: 24, wantScope: 0},
+		{name: "traffic split", qname: "split.example.com.", source: 24, wantScope: 24},
+		{name: "negative answer", qname: "missing.example.com.", source: 24, wantScope: 0},
+		{name: "source prefix zero", qname: "geo.example.com.", source: 0, wantScope: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ednsQuery(t, h, tt.qname, newOPT(1232, false, subnet(tt.source)), false)
+			opt := resp.IsEdns0()
+			if opt == nil || len(opt.Option) != 1 {
+				t.Fatalf("expected one ECS option in response, got %v", opt)
+			}
+			ecs, ok := opt.Option[0].(*dns.EDNS0_SUBNET)
+			if !ok {
+				t.Fatalf("expected ECS option, got %T", opt.Option[0])
+			}
+			if ecs.SourceNetmask != tt.source || ecs.SourceScope != tt.wantScope {
+				t.Errorf("expected source %d scope %d, got source %d scope %d",
+					tt.source, tt.wantScope, ecs.SourceNetmask, ecs.SourceScope)
+			}
+			if !ecs.Address.Equal(net.ParseIP("198.51.100.0")) {
+				t.Errorf("expected client subnet address echoed, got %s", ecs.Address)
+			}
+		})
+	}
+
+	// Without ECS support the option is not returned
+	h = NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60})
+	resp := ednsQuery(t, h, "geo.example.com.", newOPT(1232, false, subnet(24)), false)
+	if opt := resp.IsEdns0(); opt == nil || len(opt.Option) != 0 {
+		t.Errorf("expected OPT without options when ECS is disabled, got %v", opt)
+	}
+}
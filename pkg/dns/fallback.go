@@ -0,0 +1,147 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"sync"
+
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// Fallback answer sources, used as the "source" metric label.
+const (
+	fallbackSourceSorryServer = "sorry_server"
+	fallbackSourceLastHealthy = "last_healthy"
+)
+
+// fallbackKey identifies a domain and address family (dns.TypeA or dns.TypeAAAA)
+// within a split-horizon view.
+type fallbackKey struct {
+	view   string
+	domain string
+	qtype  uint16
+}
+
+// fallbackCache remembers the last healthy answer per domain and address
+// family, and which of them are currently being answered from a fallback.
+type fallbackCache struct {
+	mu      sync.Mutex
+	answers map[fallbackKey][]*routing.Server
+	serving map[fallbackKey]bool
+}
+
+func newFallbackCache() *fallbackCache {
+	return &fallbackCache{
+		answers: make(map[fallbackKey][]*routing.Server),
+		serving: make(map[fallbackKey]bool),
+	}
+}
+
+// store records a healthy answer. The servers are only remembered when keep
+// is true. Reports whether the key was being served from a fallback, i.e.
+// the domain just recovered.
+func (c *fallbackCache) store(key fallbackKey, servers []*routing.Server, keep bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if keep {
+		c.answers[key] = append([]*routing.Server(nil), servers...)
+	}
+	recovered := c.serving[key]
+	delete(c.serving, key)
+	return recovered
+}
+
+// lookup returns the last healthy answer for key, or nil.
+func (c *fallbackCache) lookup(key fallbackKey) []*routing.Server {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answers[key]
+}
+
+// markServing flags key as answered from a fallback.
+// Reports whether this is the first fallback answer since it was last healthy.
+func (c *fallbackCache) markServing(key fallbackKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.serving[key] {
+		return false
+	}
+	c.serving[key] = true
+	return true
+}
+
+// rememberAnswer records a healthy answer for use by serveFallback and logs
+// when a domain that was being answered from a fallback has recovered.
+func (h *Handler) rememberAnswer(entry *DomainEntry, qtype uint16, selected []*routing.Server) {
+	key := fallbackKey{view: entry.View, domain: entry.Name, qtype: qtype}
+	if h.fallback.store(key, selected, h.returnLastHealthy) {
+		h.logger.Info("healthy backends available again, fallback answers stopped",
+			"domain", entry.Name,
+			"type", dns.TypeToString[qtype],
+		)
+	}
+}
+
+// serveFallback answers a query for a domain whose backends are all
+// unhealthy. The domain's sorry servers are used when configured for the
+// address family; otherwise, with dns.return_last_healthy enabled, the last
+// healthy answer is replayed. Fallback answers use the short last-healthy TTL.
+// Reports whether any records were added.
+// Caller must hold h.mu.
+func (h *Handler) serveFallback(m *dns.Msg, entry *DomainEntry, q dns.Question) bool {
+	key := fallbackKey{view: entry.View, domain: entry.Name, qtype: q.Qtype}
+
+	source := fallbackSourceSorryServer
+	servers := sorryServers(entry, q.Qtype)
+	if len(servers) == 0 && h.returnLastHealthy {
+		source = fallbackSourceLastHealthy
+		servers = h.fallback.lookup(key)
+	}
+	if len(servers) == 0 {
+		return false
+	}
+
+	for _, server := range servers {
+		if q.Qtype == dns.TypeA {
+			h.addARecord(m, q, server, h.lastHealthyTTL)
+		} else {
+			h.addAAAARecord(m, q, server, h.lastHealthyTTL)
+		}
+	}
+	metrics.RecordDNSFallback(entry.Name, dns.TypeToString[q.Qtype], source)
+
+	if h.fallback.markServing(key) {
+		h.logger.Warn("no healthy backends, serving fallback answer",
+			"domain", entry.Name,
+			"type", dns.TypeToString[q.Qtype],
+			"source", source,
+			"answers", len(servers),
+		)
+	} else {
+		h.logger.Debug("served fallback answer",
+			"domain", entry.Name,
+			"type", dns.TypeToString[q.Qtype],
+			"source", source,
+		)
+	}
+	return true
+}
+
+// sorryServers returns the entry's sorry servers of the queried address family.
+func sorryServers(entry *DomainEntry, qtype uint16) []*routing.Server {
+	var servers []*routing.Server
+	for _, ip := range entry.SorryServers {
+		if (ip.To4() != nil) != (qtype == dns.TypeA) {
+			continue
+		}
+		servers = append(servers, &routing.Server{Address: ip.String()})
+	}
+	return servers
+}
@@ -0,0 +1,367 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+func newFallbackTestHandler(returnLastHealthy bool, sorry ...string) (*Handler, *mockHealthProvider) {
+	var sorryServers []net.IP
+	for _, addr := range sorry {
+		sorryServers = append(sorryServers, net.ParseIP(addr))
+	}
+
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    300,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("2001:db8::1"), Port: 80, Weight: 100},
+		},
+		SorryServers: sorryServers,
+	})
+
+	health := newMockHealthProvider()
+	handler := NewHandler(HandlerConfig{
+		Registry:          registry,
+		HealthProvider:    health,
+		DefaultTTL:        60,
+		ReturnLastHealthy: returnLastHealthy,
+		LastHealthyTTL:    5,
+	})
+	return handler, health
+}
+
+func setAllHealthy(health *mockHealthProvider, healthy bool) {
+	health.SetHealthy("10.0.0.1", healthy)
+	health.SetHealthy("2001:db8::1", healthy)
+}
+
+func TestHandler_ReturnLastHealthy(t *testing.T) {
+	handler, health := newFallbackTestHandler(true)
+
+	// Prime the cache for both address families
+	query(t, handler, "app.example.com.", dns.TypeA)
+	query(t, handler, "app.example.com.", dns.TypeAAAA)
+
+	setAllHealthy(health, false)
+
+	resp := query(t, handler, "app.example.com.", dns.TypeA)
+	if resp.Rcode != dns.RcodeSuccess || len(resp.Answer) != 1 {
+		t.Fatalf("expected last healthy A answer, got %s with %d answers",
+			dns.RcodeToString[resp.Rcode], len(resp.Answer))
+	}
+	a := resp.Answer[0].(*dns.A)
+	if !a.A.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("expected 10.0.0.1, got %s", a.A)
+	}
+	if a.Hdr.Ttl != 5 {
+		t.Errorf("expected fallback TTL 5, got %d", a.Hdr.Ttl)
+	}
+
+	resp = query(t, handler, "app.example.com.", dns.TypeAAAA)
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected last healthy AAAA answer, got %d answers", len(resp.Answer))
+	}
+	if aaaa := resp.Answer[0].(*dns.AAAA); !aaaa.AAAA.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("expected 2001:db8::1, got %s", aaaa.AAAA)
+	}
+
+	// Recovery returns to normal answers with the domain TTL
+	setAllHealthy(health, true)
+	resp = query(t, handler, "app.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 || resp.Answer[0].Header().Ttl != 300 {
+		t.Errorf("expected normal answer after recovery, got %v", resp.Answer)
+	}
+	if handler.fallback.serving[fallbackKey{domain: "app.example.com.", qtype: dns.TypeA}] {
+		t.Error("expected fallback state to be cleared after recovery")
+	}
+}
+
+func TestHandler_ReturnLastHealthyWithoutHistory(t *testing.T) {
+	handler, health := newFallbackTestHandler(true)
+	setAllHealthy(health, false)
+
+	resp := query(t, handler, "app.example.com.", dns.TypeA)
+	if resp.Rcode != dns.RcodeServerFailure {
+		t.Errorf("expected SERVFAIL without a remembered answer, got %s", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestHandler_ReturnLastHealthyDisabled(t *testing.T) {
+	handler, health := newFallbackTestHandler(false)
+
+	query(t, handler, "app.example.com.", dns.TypeA)
+	setAllHealthy(health, false)
+
+	resp := query(t, handler, "app.example.com.", dns.TypeA)
+	if resp.Rcode != dns.RcodeServerFailure {
+		t.Errorf("expected SERVFAIL, got %s", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestHandler_SorryServer(t *testing.T) {
+	handler, health := newFallbackTestHandler(true, "192.0.2.200")
+
+	query(t, handler, "app.example.com.", dns.TypeA)
+	setAllHealthy(health, false)
+
+	// The sorry server takes precedence over the last healthy answer
+	resp := query(t, handler, "app.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected sorry server answer, got %d answers", len(resp.Answer))
+	}
+	a := resp.Answer[0].(*dns.A)
+	if !a.A.Equal(net.ParseIP("192.0.2.200")) {
+		t.Errorf("expected 192.0.2.200, got %s", a.A)
+	}
+	if a.Hdr.Ttl != 5 {
+		t.Errorf("expected fallback TTL 5, got %d", a.Hdr.Ttl)
+	}
+
+	// No IPv6 sorry server and no remembered AAAA answer
+	resp = query(t, handler, "app.example.com.", dns.TypeAAAA)
+	if resp.Rcode != dns.RcodeServerFailure {
+		t.Errorf("expected SERVFAIL for AAAA, got %s", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestHandler_SorryServerWithoutLastHealthy(t *testing.T) {
+	handler, health := newFallbackTestHandler(false, "2001:db8::200")
+	setAllHealthy(health, false)
+
+	resp := query(t, handler, "app.example.com.", dns.TypeAAAA)
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected sorry server answer, got %d answers", len(resp.Answer))
+	}
+	if aaaa := resp.Answer[0].(*dns.AAAA); !aaaa.AAAA.Equal(net.ParseIP("2001:db8::200")) {
+		t.Errorf("expected 2001:db8::200, got %s", aaaa.AAAA)
+	}
+}
+
+// mockOverrideHealthProvider adds manual overrides to mockHealthProvider.
+type mockOverrideHealthProvider struct {
+	*mockHealthProvider
+	overridden map[string]bool
+}
+
+func (m *mockOverrideHealthProvider) IsOverriddenUnhealthy(address string, port int) bool {
+	return m.overridden[address]
+}
+
+func TestHandler_PanicThreshold(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    300,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.0.3"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.0.4"), Port: 80, Weight: 100},
+		},
+		MaxAnswers:     10,
+		PanicThreshold: 50,
+	})
+
+	health := &mockOverrideHealthProvider{
+		mockHealthProvider: newMockHealthProvider(),
+		overridden:         map[string]bool{"10.0.0.4": true},
+	}
+	health.SetHealthy("10.0.0.4", false)
+
+	var events []PanicEvent
+	handler := NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: health,
+		DefaultTTL:     60,
+		PanicListener:  func(PanicEvent) {},
+	})
+
+	answers := func() int {
+		t.Helper()
+		resp := query(t, handler, "app.example.com.", dns.TypeA)
+		for _, rr := range resp.Answer {
+			if rr.(*dns.A).A.Equal(net.ParseIP("10.0.0.4")) {
+				t.Errorf("overridden server answered: %s", rr)
+			}
+		}
+		return len(resp.Answer)
+	}
+
+	// The overridden server does not count towards the pool
+	if n := answers(); n != 3 || len(queuedPanicEvents(handler)) != 0 {
+		t.Fatalf("expected 3 healthy answers without panic, got %d answers and %+v", n, events)
+	}
+
+	// 1 of 3 healthy is below 50%: every non-overridden server answers
+	health.SetHealthy("10.0.0.2", false)
+	health.SetHealthy("10.0.0.3", false)
+	if n := answers(); n != 3 {
+		t.Errorf("expected 3 answers in panic mode, got %d", n)
+	}
+	answers()
+	events = queuedPanicEvents(handler)
+	if len(events) != 1 || !events[0].Active || events[0].Healthy != 1 || events[0].Total != 3 || events[0].Family != "ipv4" {
+		t.Fatalf("expected one panic event, got %+v", events)
+	}
+
+	// 2 of 3 healthy leaves panic mode
+	health.SetHealthy("10.0.0.2", true)
+	if n := answers(); n != 2 {
+		t.Errorf("expected 2 healthy answers, got %d", n)
+	}
+	events = append(events, queuedPanicEvents(handler)...)
+	if len(events) != 2 || events[1].Active {
+		t.Errorf("expected panic mode to end, got %+v", events)
+	}
+}
+
+func TestHandler_PanicModeChanges(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    300,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+		},
+		PanicThreshold: 60,
+	})
+	health := newMockHealthProvider()
+	health.SetHealthy("10.0.0.2", false)
+
+	handler := NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: health,
+		DefaultTTL:     60,
+		PanicListener:  func(PanicEvent) {},
+	})
+
+	// Zone contents, type probes and the routing test API see the panic
+	// pool without entering panic mode
+	entry := registry.Lookup("app.example.com")
+	if servers := handler.getHealthyIPv4Servers(entry); len(servers) != 2 {
+		t.Errorf("expected the panic pool of 2 servers, got %d", len(servers))
+	}
+	handler.existingTypes(registry, "app.example.com.")
+	if _, err := handler.Route("app.example.com", dns.TypeA, net.ParseIP("192.0.2.1"), ""); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if events := queuedPanicEvents(handler); len(events) != 0 {
+		t.Fatalf("expected no panic events outside queries, got %+v", events)
+	}
+
+	query(t, handler, "app.example.com.", dns.TypeA)
+	if events := queuedPanicEvents(handler); len(events) != 1 || !events[0].Active {
+		t.Errorf("expected a query to enter panic mode, got %+v", events)
+	}
+
+	// Recovery without queries leaves panic mode on the next check
+	health.SetHealthy("10.0.0.2", true)
+	handler.UpdatePanicMode()
+	if events := queuedPanicEvents(handler); len(events) != 1 || events[0].Active {
+		t.Errorf("expected the check to leave panic mode, got %+v", events)
+	}
+}
+
+// queuedPanicEvents returns the panic mode changes queued for the listener.
+func queuedPanicEvents(h *Handler) []PanicEvent {
+	var events []PanicEvent
+	for {
+		select {
+		case event := <-h.panicEvents:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestHandler_PanicListenerOutsideQueries(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    300,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+		},
+		PanicThreshold: 60,
+	})
+	health := newMockHealthProvider()
+	health.SetHealthy("10.0.0.2", false)
+
+	release := make(chan struct{})
+	delivered := make(chan PanicEvent, 1)
+	handler := NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: health,
+		DefaultTTL:     60,
+		PanicListener: func(e PanicEvent) {
+			<-release
+			delivered <- e
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.WatchPanicMode(ctx, time.Hour)
+
+	// The query entering panic mode is answered while the listener blocks
+	query(t, handler, "app.example.com.", dns.TypeA)
+	close(release)
+	select {
+	case event := <-delivered:
+		if !event.Active || event.Domain != "app.example.com." {
+			t.Errorf("unexpected panic event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic event was not delivered")
+	}
+}
+
+func TestHandler_PanicFailoverTiers(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    300,
+		Router: routing.NewFailoverRouter(),
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100, Priority: 1},
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100, Priority: 2},
+		},
+		PanicThreshold: 60,
+	})
+	health := newMockHealthProvider()
+	handler := NewHandler(HandlerConfig{Registry: registry, HealthProvider: health, DefaultTTL: 60})
+
+	query(t, handler, "app.example.com.", dns.TypeA)
+	tiers, _, _ := registry.FailoverTiers("app.example.com")
+	if len(tiers) != 2 || tiers[0].Panic || tiers[1].Panic {
+		t.Fatalf("expected tiers outside panic mode, got %+v", tiers)
+	}
+
+	// With 1 of 2 healthy the pool is in panic mode, and the tiers say so
+	health.SetHealthy("10.0.0.2", false)
+	query(t, handler, "app.example.com.", dns.TypeA)
+	tiers, _, _ = registry.FailoverTiers("app.example.com")
+	if len(tiers) != 2 || !tiers[0].Panic || !tiers[1].Panic {
+		t.Errorf("expected tiers reported in panic mode, got %+v", tiers)
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/miekg/dns"
+)
+
+// Fallthrough results, used as the "result" metric label.
+const (
+	fallthroughCached    = "cached"
+	fallthroughForwarded = "forwarded"
+	fallthroughError     = "error"
+)
+
+// FallthroughSettings controls the upstream answering a zone's unmanaged names.
+type FallthroughSettings struct {
+	Zone      string        // Fully qualified zone name
+	Upstreams []string      // host:port, queried in order
+	Timeout   time.Duration // Bound on the time spent on one query
+	CacheSize int           // Maximum number of cached responses; <= 0 is unbounded
+}
+
+// BuildForwarders creates the fallthrough forwarders from dns.fallthrough.
+func BuildForwarders(cfg *config.Config) ([]*Forwarder, error) {
+	forwarders := make([]*Forwarder, 0, len(cfg.DNS.Fallthrough))
+	for _, f := range cfg.DNS.Fallthrough {
+		settings := FallthroughSettings{
+			Zone:      normalizeDomain(strings.ToLower(f.Zone)),
+			Timeout:   f.Timeout,
+			CacheSize: f.CacheSize,
+		}
+		for _, upstream := range f.Upstreams {
+			addr, err := config.UpstreamAddress(upstream)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fallthrough upstream for zone %s: %w", f.Zone, err)
+			}
+			settings.Upstreams = append(settings.Upstreams, addr)
+		}
+		forwarders = append(forwarders, NewForwarder(settings))
+	}
+	return forwarders, nil
+}
+
+// Forwarder answers queries for the names of a zone that OpenGSLB does not
+// manage by proxying them to the zone's existing authoritative servers, so
+// a zone can be moved over one name at a time. Responses are cached for
+// their TTL.
+type Forwarder struct {
+	settings FallthroughSettings
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[forwardKey]*forwardEntry
+}
+
+// forwardKey identifies a cached upstream response.
+type forwardKey struct {
+	name   string
+	qtype  uint16
+	qclass uint16
+}
+
+// forwardEntry is a cached upstream response.
+type forwardEntry struct {
+	msg     *dns.Msg
+	stored  time.Time
+	expires time.Time
+}
+
+// NewForwarder creates a forwarder.
+func NewForwarder(settings FallthroughSettings) *Forwarder {
+	if settings.Timeout <= 0 {
+		settings.Timeout = config.DefaultFallthroughTimeout
+	}
+	return &Forwarder{
+		settings: settings,
+		now:      time.Now,
+		cache:    make(map[forwardKey]*forwardEntry),
+	}
+}
+
+// Zone returns the zone the forwarder answers for.
+func (f *Forwarder) Zone() string {
+	return f.settings.Zone
+}
+
+// Forward returns the upstream response to q, from the cache when possible,
+// and whether it was cached. Upstreams are tried in order until one answers
+// NOERROR or NXDOMAIN, within the forwarder's timeout. DNSSEC records and
+// the OPT record are removed from the response.
+func (f *Forwarder) Forward(ctx context.Context, q dns.Question) (*dns.Msg, bool, error) {
+	key := forwardKey{name: strings.ToLower(q.Name), qtype: q.Qtype, qclass: q.Qclass}
+	if resp := f.cached(key); resp != nil {
+		return resp, true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.settings.Timeout)
+	defer cancel()
+
+	req := new(dns.Msg)
+	req.SetQuestion(q.Name, q.Qtype)
+	req.Question[0].Qclass = q.Qclass
+	req.RecursionDesired = false
+	req.SetEdns0(config.DefaultMaxUDPSize, false)
+
+	err := fmt.Errorf("no upstreams configured")
+	for _, upstream := range f.settings.Upstreams {
+		var resp *dns.Msg
+		resp, err = exchangeUpstream(ctx, req, upstream)
+		if err != nil {
+			err = fmt.Errorf("upstream %s: %w", upstream, err)
+			continue
+		}
+		if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+			err = fmt.Errorf("upstream %s answered %s", upstream, dns.RcodeToString[resp.Rcode])
+			continue
+		}
+		stripUpstreamRecords(resp)
+		f.store(key, resp)
+		return resp.Copy(), false, nil
+	}
+	return nil, false, err
+}
+
+// exchangeUpstream sends req to upstream over UDP, retrying over TCP when
+// the response is truncated.
+func exchangeUpstream(ctx context.Context, req *dns.Msg, upstream string) (*dns.Msg, error) {
+	client := &dns.Client{Net: "udp"}
+	resp, _, err := client.ExchangeContext(ctx, req, upstream)
+	if err == nil && resp.Truncated {
+		client.Net = "tcp"
+		resp, _, err = client.ExchangeContext(ctx, req, upstream)
+	}
+	return resp, err
+}
+
+// stripUpstreamRecords removes the upstream's DNSSEC records, which only its
+// own keys can validate, and its OPT record, replaced by this server's.
+func stripUpstreamRecords(m *dns.Msg) {
+	keep := func(records []dns.RR) []dns.RR {
+		kept := records[:0]
+		for _, rr := range records {
+			switch rr.Header().Rrtype {
+			case dns.TypeRRSIG, dns.TypeNSEC, dns.TypeNSEC3, dns.TypeOPT:
+				continue
+			}
+			kept = append(kept, rr)
+		}
+		return kept
+	}
+	m.Answer = keep(m.Answer)
+	m.Ns = keep(m.Ns)
+	m.Extra = keep(m.Extra)
+}
+
+// cached returns a copy of the cached response for key with its TTLs aged,
+// or nil.
+func (f *Forwarder) cached(key forwardKey) *dns.Msg {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, ok := f.cache[key]
+	if !ok {
+		return nil
+	}
+	now := f.now()
+	if !now.Before(entry.expires) {
+		delete(f.cache, key)
+		return nil
+	}
+
+	resp := entry.msg.Copy()
+	age := uint32(now.Sub(entry.stored) / time.Second)
+	for _, section := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+		for _, rr := range section {
+			rr.Header().Ttl -= min(age, rr.Header().Ttl)
+		}
+	}
+	return resp
+}
+
+// store caches resp for key if it is cacheable and there is room.
+func (f *Forwarder) store(key forwardKey, resp *dns.Msg) {
+	ttl := cacheTTL(resp)
+	if ttl <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if _, ok := f.cache[key]; !ok && !f.makeRoom(now) {
+		return
+	}
+	f.cache[key] = &forwardEntry{msg: resp.Copy(), stored: now, expires: now.Add(ttl)}
+}
+
+// makeRoom ensures there is room for a new cache entry, evicting expired
+// ones. Reports whether an entry can be added.
+// Caller must hold f.mu.
+func (f *Forwarder) makeRoom(now time.Time) bool {
+	if f.settings.CacheSize <= 0 || len(f.cache) < f.settings.CacheSize {
+		return true
+	}
+	for key, entry := range f.cache {
+		if !now.Before(entry.expires) {
+			delete(f.cache, key)
+		}
+	}
+	return len(f.cache) < f.settings.CacheSize
+}
+
+// cacheTTL returns how long a response may be cached: the smallest TTL of
+// its records, or for a negative response the negative caching TTL of its
+// SOA (RFC 2308). Negative responses without an SOA are not cached.
+func cacheTTL(m *dns.Msg) time.Duration {
+	if m.Rcode == dns.RcodeNameError || len(m.Answer) == 0 {
+		for _, rr := range m.Ns {
+			if soa, ok := rr.(*dns.SOA); ok {
+				return time.Duration(min(soa.Hdr.Ttl, soa.Minttl)) * time.Second
+			}
+		}
+		return 0
+	}
+
+	ttl := m.Answer[0].Header().Ttl
+	for _, section := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		for _, rr := range section {
+			ttl = min(ttl, rr.Header().Ttl)
+		}
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// SetForwarders replaces the fallthrough forwarders. nil disables fallthrough.
+func (h *Handler) SetForwarders(forwarders []*Forwarder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.forwarders = forwarders
+}
+
+// fallthroughResponse returns the reply to r from the zone's fallthrough
+// upstream when m is a negative response for a name OpenGSLB does not
+// manage, or m otherwise. Upstream failures are answered with SERVFAIL.
+func (h *Handler) fallthroughResponse(reg *Registry, r, m *dns.Msg) *dns.Msg {
+	if m.Rcode != dns.RcodeNameError && !isNoData(m) {
+		return m
+	}
+	q := r.Question[0]
+
+	h.mu.RLock()
+	fwd := h.forwarderFor(q.Name)
+	managed := fwd != nil && h.managesName(reg, q, findZone(h.zones, q.Name))
+	h.mu.RUnlock()
+	if fwd == nil || managed {
+		return m
+	}
+
+	resp, cached, err := fwd.Forward(context.Background(), q)
+	if err != nil {
+		metrics.RecordDNSFallthrough(fwd.Zone(), fallthroughError)
+		h.logger.Warn("fallthrough upstream failed",
+			"name", q.Name,
+			"type", dns.TypeToString[q.Qtype],
+			"zone", fwd.Zone(),
+			"error", err,
+		)
+		reply := new(dns.Msg)
+		reply.SetRcode(r, dns.RcodeServerFailure)
+		return reply
+	}
+	result := fallthroughForwarded
+	if cached {
+		result = fallthroughCached
+	}
+	metrics.RecordDNSFallthrough(fwd.Zone(), result)
+
+	reply := new(dns.Msg)
+	reply.SetReply(r)
+	reply.Authoritative = resp.Authoritative
+	reply.Rcode = resp.Rcode
+	reply.Answer, reply.Ns, reply.Extra = resp.Answer, resp.Ns, resp.Extra
+
+	h.logger.Debug("answered from fallthrough upstream",
+		"name", q.Name,
+		"type", dns.TypeToString[q.Qtype],
+		"zone", fwd.Zone(),
+		"rcode", dns.RcodeToString[resp.Rcode],
+		"cached", cached,
+	)
+	return reply
+}
+
+// forwarderFor returns the forwarder of the most specific zone containing
+// qname, or nil.
+// Caller must hold h.mu.
+func (h *Handler) forwarderFor(qname string) *Forwarder {
+	name := strings.ToLower(normalizeDomain(qname))
+	var best *Forwarder
+	for _, fwd := range h.forwarders {
+		if dns.IsSubDomain(fwd.Zone(), name) && (best == nil || len(fwd.Zone()) > len(best.Zone())) {
+			best = fwd
+		}
+	}
+	return best
+}
+
+// managesName reports whether OpenGSLB answers q itself: the name is a
+// domain (including wildcard matches), has static records or SRV records,
+// is a nameserver of the zone, or q asks for the zone's own apex records.
+// Caller must hold h.mu.
+func (h *Handler) managesName(reg *Registry, q dns.Question, zone *Zone) bool {
+	if zone != nil {
+		if zone.IsNameserver(q.Name) {
+			return true
+		}
+		if zone.IsApex(q.Name) && (q.Qtype == dns.TypeSOA || q.Qtype == dns.TypeNS || q.Qtype == dns.TypeDNSKEY) {
+			return true
+		}
+	}
+	if reg == nil {
+		return false
+	}
+	if entry, _ := reg.match(q.Name); entry != nil || reg.HasStaticName(q.Name) {
+		return true
+	}
+	if entry, _, _ := h.srvDomain(reg, q.Name); entry != nil {
+		return true
+	}
+	entry, _ := h.srvTarget(reg, q.Name)
+	return entry != nil
+}
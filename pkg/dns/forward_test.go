@@ -0,0 +1,205 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// legacyZone answers as the existing authoritative server of
+// gslb.example.com: legacy.gslb.example.com and an MX record at the apex exist,
+// everything else is NXDOMAIN.
+func legacyZone(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	q := r.Question[0]
+	switch {
+	case q.Name == "legacy.gslb.example.com." && q.Qtype == dns.TypeA:
+		rr, _ := dns.NewRR("legacy.gslb.example.com. 300 IN A 198.51.100.7")
+		sig, _ := dns.NewRR("legacy.gslb.example.com. 300 IN RRSIG A 13 4 300 20300101000000 20250101000000 1 gslb.example.com. AAAA")
+		m.Answer = []dns.RR{rr, sig}
+	case q.Name == "gslb.example.com." && q.Qtype == dns.TypeMX:
+		rr, _ := dns.NewRR("gslb.example.com. 3600 IN MX 10 mail.gslb.example.com.")
+		m.Answer = []dns.RR{rr}
+	default:
+		soa, _ := dns.NewRR("gslb.example.com. 600 IN SOA ns.legacy.example. hostmaster.legacy.example. 7 3600 600 604800 120")
+		m.Rcode = dns.RcodeNameError
+		m.Ns = []dns.RR{soa}
+	}
+	_ = w.WriteMsg(m)
+}
+
+// startUpstream serves handler over UDP on a local port and returns its
+// address and the number of queries it received.
+func startUpstream(t *testing.T, handler dns.HandlerFunc) (string, *atomic.Int32) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	queries := new(atomic.Int32)
+	server := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			queries.Add(1)
+			handler(w, r)
+		}),
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return pc.LocalAddr().String(), queries
+}
+
+func TestHandler_Fallthrough(t *testing.T) {
+	upstream, queries := startUpstream(t, legacyZone)
+	h := newZoneTestHandler(t)
+	h.SetForwarders([]*Forwarder{NewForwarder(FallthroughSettings{
+		Zone:      "gslb.example.com.",
+		Upstreams: []string{upstream},
+		Timeout:   time.Second,
+	})})
+
+	tests := []struct {
+		name      string
+		qname     string
+		qtype     uint16
+		wantRcode int
+		wantRR    string
+		forwarded bool
+	}{
+		{name: "unmanaged name", qname: "legacy.gslb.example.com.", qtype: dns.TypeA,
+			wantRR: "198.51.100.7", forwarded: true},
+		{name: "unmanaged apex type", qname: "gslb.example.com.", qtype: dns.TypeMX,
+			wantRR: "mail.gslb.example.com.", forwarded: true},
+		{name: "unknown name", qname: "missing.gslb.example.com.", qtype: dns.TypeA,
+			wantRcode: dns.RcodeNameError, forwarded: true},
+		{name: "managed domain", qname: "app.gslb.example.com.", qtype: dns.TypeA, wantRR: "10.0.0.1"},
+		{name: "managed domain without the type", qname: "app.gslb.example.com.", qtype: dns.TypeMX},
+		{name: "zone apex", qname: "gslb.example.com.", qtype: dns.TypeSOA, wantRR: "2025010101"},
+		{name: "zone nameserver", qname: "ns1.gslb.example.com.", qtype: dns.TypeAAAA},
+		{name: "outside the zone", qname: "other.example.org.", qtype: dns.TypeA, wantRcode: dns.RcodeNameError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := queries.Load()
+			resp := query(t, h, tt.qname, tt.qtype)
+			if resp.Rcode != tt.wantRcode {
+				t.Fatalf("expected rcode %s, got %s", dns.RcodeToString[tt.wantRcode], dns.RcodeToString[resp.Rcode])
+			}
+			if got := queries.Load() - before; (got > 0) != tt.forwarded {
+				t.Errorf("expected forwarded=%v, upstream got %d queries", tt.forwarded, got)
+			}
+			if tt.wantRR == "" {
+				if len(resp.Answer) != 0 {
+					t.Errorf("expected no answer, got %v", resp.Answer)
+				}
+				return
+			}
+			if len(resp.Answer) != 1 {
+				t.Fatalf("expected one answer, got %v", resp.Answer)
+			}
+			if rr := resp.Answer[0].String(); !strings.Contains(rr, tt.wantRR) {
+				t.Errorf("expected answer with %s, got %s", tt.wantRR, rr)
+			}
+		})
+	}
+
+	// Negative answers keep the upstream's SOA
+	resp := query(t, h, "missing.gslb.example.com.", dns.TypeA)
+	if len(resp.Ns) != 1 || resp.Ns[0].(*dns.SOA).Serial != 7 {
+		t.Errorf("expected the upstream SOA, got %v", resp.Ns)
+	}
+}
+
+func TestForwarder_Cache(t *testing.T) {
+	upstream, queries := startUpstream(t, legacyZone)
+	fwd := NewForwarder(FallthroughSettings{Zone: "gslb.example.com.", Upstreams: []string{upstream}, Timeout: time.Second})
+	now := time.Now()
+	fwd.now = func() time.Time { return now }
+
+	q := dns.Question{Name: "legacy.gslb.example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}
+	resp, cached, err := fwd.Forward(context.Background(), q)
+	if err != nil || cached {
+		t.Fatalf("expected a forwarded response, got cached=%v err=%v", cached, err)
+	}
+	// The upstream's signature cannot be validated with this server's keys
+	if len(resp.Answer) != 1 || resp.Answer[0].Header().Rrtype != dns.TypeA {
+		t.Errorf("expected the RRSIG to be removed, got %v", resp.Answer)
+	}
+
+	now = now.Add(100 * time.Second)
+	q.Name = "Legacy.GSLB.example.com."
+	resp, cached, err = fwd.Forward(context.Background(), q)
+	if err != nil || !cached {
+		t.Fatalf("expected a cached response, got cached=%v err=%v", cached, err)
+	}
+	if ttl := resp.Answer[0].Header().Ttl; ttl != 200 {
+		t.Errorf("expected TTL aged to 200, got %d", ttl)
+	}
+
+	// NXDOMAIN is cached for the SOA minimum
+	q = dns.Question{Name: "missing.gslb.example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}
+	if _, _, err := fwd.Forward(context.Background(), q); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	now = now.Add(119 * time.Second)
+	if _, cached, _ := fwd.Forward(context.Background(), q); !cached {
+		t.Error("expected NXDOMAIN to be cached")
+	}
+
+	// Expired responses are fetched again
+	now = now.Add(200 * time.Second)
+	q.Name = "legacy.gslb.example.com."
+	if _, cached, _ := fwd.Forward(context.Background(), q); cached {
+		t.Error("expected the expired response to be refetched")
+	}
+	if got := queries.Load(); got != 3 {
+		t.Errorf("expected 3 upstream queries, got %d", got)
+	}
+}
+
+func TestForwarder_UpstreamFailure(t *testing.T) {
+	refusing, _ := startUpstream(t, func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeRefused)
+		_ = w.WriteMsg(m)
+	})
+	silent, _ := startUpstream(t, func(w dns.ResponseWriter, r *dns.Msg) {})
+	legacy, _ := startUpstream(t, legacyZone)
+
+	q := dns.Question{Name: "legacy.gslb.example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}
+
+	// The next upstream is tried
+	fwd := NewForwarder(FallthroughSettings{Zone: "gslb.example.com.", Upstreams: []string{refusing, legacy}, Timeout: time.Second})
+	if resp, _, err := fwd.Forward(context.Background(), q); err != nil || len(resp.Answer) != 1 {
+		t.Errorf("expected an answer from the second upstream, got %v, %v", resp, err)
+	}
+
+	// Upstreams that do not answer in time fail the query with SERVFAIL
+	h := newZoneTestHandler(t)
+	h.SetForwarders([]*Forwarder{NewForwarder(FallthroughSettings{
+		Zone:      "gslb.example.com.",
+		Upstreams: []string{silent},
+		Timeout:   100 * time.Millisecond,
+	})})
+	start := time.Now()
+	resp := query(t, h, "legacy.gslb.example.com.", dns.TypeA)
+	if resp.Rcode != dns.RcodeServerFailure {
+		t.Errorf("expected SERVFAIL, got %s", dns.RcodeToString[resp.Rcode])
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the timeout to bound the query, took %s", elapsed)
+	}
+}
@@ -11,15 +11,28 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/loganrossus/OpenGSLB/pkg/config"
 	"github.com/loganrossus/OpenGSLB/pkg/geo"
 	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/loganrossus/OpenGSLB/pkg/querylog"
 	"github.com/loganrossus/OpenGSLB/pkg/routing"
 	"github.com/miekg/dns"
 )
 
+const (
+	// panicEventBuffer is the number of panic mode changes queued for the
+	// panic listener; changes are dropped while the queue is full.
+	panicEventBuffer = 64
+
+	// defaultPanicCheckInterval is used by WatchPanicMode when no interval
+	// is set.
+	defaultPanicCheckInterval = 5 * time.Second
+)
+
 // Handler processes DNS queries.
 type Handler struct {
 	mu            sync.RWMutex
@@ -29,7 +42,45 @@ type Handler struct {
 	dnssecEnabled bool
 	ecsEnabled    bool
 	defaultTTL    uint32
+	maxUDPSize    uint16
+	zones         []*Zone
 	logger        *slog.Logger
+
+	// Fallback answers for domains with no healthy backends
+	returnLastHealthy bool
+	lastHealthyTTL    uint32
+	fallback          *fallbackCache
+
+	// Zone transfers and change tracking
+	keyring    *Keyring
+	transfer   *TransferSettings
+	zoneStates map[string]zoneState
+
+	// Dynamic updates registering backends
+	updater    BackendUpdater
+	updateKeys []string
+
+	// Response rate limiting for UDP; nil disables limiting
+	rateLimiter *RateLimiter
+
+	// Split-horizon view selection, evaluated in order
+	views []ViewSettings
+
+	// Query log; nil disables query logging
+	queryLog *querylog.Logger
+
+	// Upstreams answering the unmanaged names of their zones
+	forwarders []*Forwarder
+
+	// Pools in panic mode, by view, domain and address family
+	panicMu       sync.Mutex
+	panicking     map[string]bool
+	panicListener func(PanicEvent)
+	panicEvents   chan PanicEvent // Queued for panicListener by WatchPanicMode
+
+	// Weight profiles of scheduled domains, by domain name
+	profileMu sync.RWMutex
+	profiles  map[string]*WeightProfile
 }
 
 // NewHandler creates a new DNS handler.
@@ -40,15 +91,47 @@ func NewHandler(cfg HandlerConfig) *Handler {
 		logger = slog.Default()
 	}
 
-	return &Handler{
+	lastHealthyTTL := cfg.LastHealthyTTL
+	if lastHealthyTTL == 0 {
+		lastHealthyTTL = config.DefaultLastHealthyTTL
+	}
+	maxUDPSize := cfg.MaxUDPSize
+	if maxUDPSize == 0 {
+		maxUDPSize = config.DefaultMaxUDPSize
+	}
+
+	h := &Handler{
 		registry:      cfg.Registry,
 		health:        cfg.HealthProvider,
 		dnssecSigner:  cfg.DNSSECSigner,
 		dnssecEnabled: cfg.DNSSECEnabled,
 		ecsEnabled:    cfg.ECSEnabled,
 		defaultTTL:    cfg.DefaultTTL,
+		maxUDPSize:    maxUDPSize,
+		zones:         cfg.Zones,
 		logger:        logger,
+
+		returnLastHealthy: cfg.ReturnLastHealthy,
+		lastHealthyTTL:    lastHealthyTTL,
+		fallback:          newFallbackCache(),
+
+		keyring:    cfg.Keyring,
+		transfer:   cfg.Transfer,
+		zoneStates: make(map[string]zoneState),
+
+		rateLimiter: cfg.RateLimiter,
+		views:       cfg.Views,
+		queryLog:    cfg.QueryLog,
+		forwarders:  cfg.Forwarders,
+
+		panicking:     make(map[string]bool),
+		panicListener: cfg.PanicListener,
+		panicEvents:   make(chan PanicEvent, panicEventBuffer),
+
+		profiles: make(map[string]*WeightProfile),
 	}
+	h.SetUpdate(cfg.Updater, cfg.UpdateKeys)
+	return h
 }
 
 // ServeDNS implements the dns.Handler interface.
@@ -64,6 +147,18 @@ func (h *Handler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		h.logger.Warn("DNS query with no questions")
 		m.SetRcode(m, dns.RcodeFormatError)
 		h.writeResponse(w, m, start, "FORMERR", "")
+		h.logQuery(w, r, m, start, "", nil)
+		return
+	}
+
+	if opt := r.IsEdns0(); opt != nil && opt.Version() != 0 {
+		h.badVersion(w, m, start)
+		h.logQuery(w, r, m, start, "", nil)
+		return
+	}
+
+	if r.Opcode == dns.OpcodeUpdate {
+		h.logQuery(w, r, h.serveUpdate(w, r, start), start, "", nil)
 		return
 	}
 
@@ -74,188 +169,601 @@ func (h *Handler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	// Get client IP for geolocation routing (ECS or source address)
 	clientIP := geo.GetClientIP(r, w.RemoteAddr(), h.ecsEnabled)
 
+	// Split-horizon view answering the client
+	view, reg := h.selectView(w, r)
+
 	h.logger.Debug("DNS query received",
 		"name", qname,
 		"type", qtype,
 		"source", w.RemoteAddr().String(),
 		"clientIP", clientIP,
+		"view", view,
 	)
 
 	switch q.Qtype {
 	case dns.TypeA:
-		h.handleAQuery(m, qname, q, clientIP)
+		h.handleAQuery(reg, m, qname, q, clientIP)
 	case dns.TypeAAAA:
-		h.handleAAAAQuery(m, qname, q, clientIP)
+		h.handleAAAAQuery(reg, m, qname, q, clientIP)
 	case dns.TypeDNSKEY:
-		h.handleDNSKEYQuery(m, qname, q)
+		h.handleDNSKEYQuery(reg, m, qname, q)
+	case dns.TypeSOA:
+		h.handleSOAQuery(reg, m, qname)
+	case dns.TypeNS:
+		h.handleNSQuery(reg, m, qname)
+	case dns.TypeSRV:
+		h.handleSRVQuery(reg, m, q, clientIP)
+	case dns.TypeHTTPS, dns.TypeSVCB:
+		h.handleHTTPSQuery(reg, m, q, clientIP)
+	case dns.TypeAXFR, dns.TypeIXFR:
+		// Transfers stream their own responses
+		h.logQuery(w, r, h.serveTransfer(w, r, start), start, view, nil)
+		return
 	default:
-		h.logger.Debug("unsupported query type", "name", qname, "type", qtype)
-		m.SetRcode(m, dns.RcodeNotImplemented)
+		h.handleOtherQuery(reg, m, q)
 	}
 
+	// Names a zone's fallthrough upstream answers instead of OpenGSLB
+	m = h.fallthroughResponse(reg, r, m)
+
+	// Negative answers carry the zone SOA so resolvers can cache them (RFC 2308)
+	h.addNegativeSOA(m, qname)
+
+	if slipped, limited := h.rateLimit(w, m, qname); limited {
+		if slipped == nil {
+			// Dropped responses are never written but still counted and logged
+			metrics.RecordDNSQuery(qname, qtype, statusDropped)
+			metrics.RecordDNSQueryDuration(qname, statusDropped, time.Since(start).Seconds())
+			h.logQuery(w, r, nil, start, view, reg)
+			return
+		}
+		h.setEDNS(r, slipped)
+		if _, ok := verifiedKey(w, r); ok {
+			signReply(slipped, r)
+		}
+		h.writeResponse(w, slipped, start, dns.RcodeToString[slipped.Rcode], qname)
+		h.logQuery(w, r, slipped, start, view, reg)
+		return
+	}
+
+	h.setEDNS(r, m)
+	h.addECS(reg, r, m)
+
 	// Sign the response if DNSSEC is enabled
-	signed := h.signResponse(m)
+	signed := h.signResponse(reg, m)
+	h.truncate(w, r, signed)
+	if _, ok := verifiedKey(w, r); ok {
+		// Queries signed to select a view expect signed answers
+		signReply(signed, r)
+	}
 
 	status := dns.RcodeToString[signed.Rcode]
 	h.writeResponse(w, signed, start, status, qname)
+	h.logQuery(w, r, signed, start, view, reg)
 }
 
 // handleAQuery processes A record queries (IPv4).
-func (h *Handler) handleAQuery(m *dns.Msg, qname string, q dns.Question, clientIP net.IP) {
+func (h *Handler) handleAQuery(reg *Registry, m *dns.Msg, qname string, q dns.Question, clientIP net.IP) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	// Debug: log handler's registry pointer
 	h.logger.Debug("handler lookup using registry",
 		"query_name", qname,
-		"registry_ptr", fmt.Sprintf("%p", h.registry),
+		"registry_ptr", fmt.Sprintf("%p", reg),
 	)
 
-	entry := h.registry.Lookup(qname)
+	// SRV target names are checked first so they are not shadowed by a wildcard domain
+	if h.answerSRVTarget(reg, m, q) {
+		return
+	}
+
+	entry, label := reg.LookupMatch(qname)
 	if entry == nil {
+		if h.answerStaticRecords(reg, m, q) || h.answerFromZone(m, q) {
+			return
+		}
 		h.logger.Debug("domain not found", "name", qname)
 		m.SetRcode(m, dns.RcodeNameError) // NXDOMAIN
 		return
 	}
 
-	servers := h.getHealthyIPv4Servers(entry)
+	servers := h.routingServers(entry, "ipv4")
 	if len(servers) == 0 {
-		h.logger.Debug("no healthy IPv4 servers", "domain", qname)
-		m.SetRcode(m, dns.RcodeServerFailure)
+		h.handleNoHealthyServers(m, entry, q)
 		return
 	}
 
-	// Create context with client IP for geolocation routing
-	// and domain name for learned latency routing (ADR-017)
-	ctx := context.Background()
-	if clientIP != nil {
-		ctx = routing.WithClientIP(ctx, clientIP)
-	}
-	// Use entry.Name which is normalized, strip trailing dot for service name format
-	domainName := entry.Name
-	if len(domainName) > 0 && domainName[len(domainName)-1] == '.' {
-		domainName = domainName[:len(domainName)-1]
-	}
-	ctx = routing.WithDomain(ctx, domainName)
-
 	pool := routing.NewSimpleServerPool(servers)
-	selected, err := entry.Router.Route(ctx, pool)
+	selected, err := h.selectServers(h.routingContext(entry, clientIP, label), entry, pool)
 	if err != nil {
 		h.logger.Error("routing failed", "domain", qname, "error", err)
 		m.SetRcode(m, dns.RcodeServerFailure)
 		return
 	}
 
-	h.addARecord(m, q, selected, entry.TTL)
-	metrics.RecordRoutingDecision(qname, entry.Router.Algorithm(), selected.Address)
+	for _, server := range selected {
+		h.addARecord(m, q, server, entry.TTL)
+	}
+	h.rememberAnswer(entry, q.Qtype, selected)
+	metrics.RecordRoutingDecision(entry.Name, entry.Router.Algorithm(), selected[0].Address)
 
 	h.logger.Debug("resolved A query",
 		"domain", qname,
-		"selected", selected.Address,
+		"selected", selected[0].Address,
+		"answers", len(selected),
 		"algorithm", entry.Router.Algorithm(),
 	)
 }
 
 // handleAAAAQuery processes AAAA record queries (IPv6).
-func (h *Handler) handleAAAAQuery(m *dns.Msg, qname string, q dns.Question, clientIP net.IP) {
+func (h *Handler) handleAAAAQuery(reg *Registry, m *dns.Msg, qname string, q dns.Question, clientIP net.IP) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	entry := h.registry.Lookup(qname)
+	// SRV target names are checked first so they are not shadowed by a wildcard domain
+	if h.answerSRVTarget(reg, m, q) {
+		return
+	}
+
+	entry, label := reg.LookupMatch(qname)
 	if entry == nil {
+		if h.answerStaticRecords(reg, m, q) || h.answerFromZone(m, q) {
+			return
+		}
 		h.logger.Debug("domain not found", "name", qname)
 		m.SetRcode(m, dns.RcodeNameError) // NXDOMAIN
 		return
 	}
 
-	servers := h.getHealthyIPv6Servers(entry)
+	servers := h.routingServers(entry, "ipv6")
+	synthesized := false
+	if len(servers) == 0 && entry.DNS64 != nil {
+		// DNS64: route on the IPv4 pool and embed the chosen address
+		servers = h.routingServers(entry, "ipv4")
+		synthesized = len(servers) > 0
+	}
 	if len(servers) == 0 {
-		h.logger.Debug("no healthy IPv6 servers", "domain", qname)
-		m.SetRcode(m, dns.RcodeServerFailure)
+		h.handleNoHealthyServers(m, entry, q)
 		return
 	}
 
-	// Create context with client IP for geolocation routing
-	// and domain name for learned latency routing (ADR-017)
-	ctx := context.Background()
-	if clientIP != nil {
-		ctx = routing.WithClientIP(ctx, clientIP)
-	}
-	// Use entry.Name which is normalized, strip trailing dot for service name format
-	domainName := entry.Name
-	if len(domainName) > 0 && domainName[len(domainName)-1] == '.' {
-		domainName = domainName[:len(domainName)-1]
-	}
-	ctx = routing.WithDomain(ctx, domainName)
-
 	pool := routing.NewSimpleServerPool(servers)
-	selected, err := entry.Router.Route(ctx, pool)
+	selected, err := h.selectServers(h.routingContext(entry, clientIP, label), entry, pool)
 	if err != nil {
 		h.logger.Error("routing failed", "domain", qname, "error", err)
 		m.SetRcode(m, dns.RcodeServerFailure)
 		return
 	}
 
-	h.addAAAARecord(m, q, selected, entry.TTL)
-	metrics.RecordRoutingDecision(qname, entry.Router.Algorithm(), selected.Address)
+	if synthesized {
+		h.addDNS64Records(m, q, entry, selected)
+	} else {
+		for _, server := range selected {
+			h.addAAAARecord(m, q, server, entry.TTL)
+		}
+		h.rememberAnswer(entry, q.Qtype, selected)
+	}
+	metrics.RecordRoutingDecision(entry.Name, entry.Router.Algorithm(), selected[0].Address)
 
 	h.logger.Debug("resolved AAAA query",
 		"domain", qname,
-		"selected", selected.Address,
+		"selected", selected[0].Address,
+		"answers", len(selected),
 		"algorithm", entry.Router.Algorithm(),
+		"dns64", synthesized,
 	)
 }
 
-// getHealthyIPv4Servers returns healthy IPv4 servers from the entry.
-func (h *Handler) getHealthyIPv4Servers(entry *DomainEntry) []*routing.Server {
-	var servers []*routing.Server
+// selectServers returns the servers to answer with, best first.
+// With max_answers unset or 1 the router picks a single server; otherwise
+// the top max_answers servers of the router's ranking are returned. A
+// traffic split first narrows the pool to the client's group.
+func (h *Handler) selectServers(ctx context.Context, entry *DomainEntry, pool routing.ServerPool) ([]*routing.Server, error) {
+	pool = h.splitPool(ctx, entry, pool)
 
+	if entry.MaxAnswers <= 1 {
+		selected, err := entry.Router.Route(ctx, pool)
+		if err != nil {
+			return nil, err
+		}
+		return []*routing.Server{selected}, nil
+	}
+
+	ranked, err := entry.Router.Rank(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranked) == 0 {
+		return nil, routing.ErrNoHealthyServers
+	}
+	if len(ranked) > entry.MaxAnswers {
+		ranked = ranked[:entry.MaxAnswers]
+	}
+	return ranked, nil
+}
+
+// splitPool narrows pool to the client's group of the domain's traffic
+// split, if it has one.
+func (h *Handler) splitPool(ctx context.Context, entry *DomainEntry, pool routing.ServerPool) routing.ServerPool {
+	if entry.Split == nil {
+		return pool
+	}
+	servers, group := entry.Split.Filter(ctx, pool.Servers())
+	h.logger.Debug("traffic split", "domain", entry.Name, "group", group, "servers", len(servers))
+	return routing.NewSimpleServerPool(servers)
+}
+
+// handleNoHealthyServers answers when a domain has no healthy servers of the
+// queried address family:
+//   - servers of the other family are still healthy: NODATA, so dual-stack
+//     clients fall back to the working family instead of failing the name
+//   - a sorry server or last healthy answer is available: the fallback answer
+//   - no servers of that family are configured: NODATA (the type does not exist)
+//   - every backend is down: SERVFAIL
+//
+// Caller must hold h.mu.
+func (h *Handler) handleNoHealthyServers(m *dns.Msg, entry *DomainEntry, q dns.Question) {
+	qname := q.Name
+	wantIPv4 := q.Qtype == dns.TypeA
+
+	var otherHealthy []*routing.Server
+	if wantIPv4 {
+		otherHealthy = h.getHealthyIPv6Servers(entry)
+	} else {
+		otherHealthy = h.getHealthyIPv4Servers(entry)
+	}
+	if len(otherHealthy) > 0 {
+		h.logger.Debug("no healthy servers for address family, other family available",
+			"domain", qname,
+			"type", dns.TypeToString[q.Qtype],
+		)
+		return
+	}
+
+	if h.serveFallback(m, entry, q) {
+		return
+	}
+
+	hasFamily := false
 	for _, server := range entry.Servers {
-		// Check if IPv4
-		if server.Address.To4() == nil {
-			continue
+		if (server.Address.To4() != nil) == wantIPv4 {
+			hasFamily = true
+			break
 		}
+	}
+	if !hasFamily {
+		h.logger.Debug("no servers for address family, returning NODATA",
+			"domain", qname,
+			"type", dns.TypeToString[q.Qtype],
+		)
+		return
+	}
 
-		// Check health
-		if h.health != nil && !h.health.IsHealthy(server.Address.String(), server.Port) {
-			continue
+	h.logger.Debug("no healthy servers", "domain", qname, "type", dns.TypeToString[q.Qtype])
+	m.SetRcode(m, dns.RcodeServerFailure)
+}
+
+// handleOtherQuery answers query types served from static records. Without a
+// matching record the name either exists without the type (NODATA) or does
+// not exist (NXDOMAIN).
+func (h *Handler) handleOtherQuery(reg *Registry, m *dns.Msg, q dns.Question) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	qname, qtype := q.Name, q.Qtype
+	if h.answerStaticRecords(reg, m, q) {
+		h.logger.Debug("resolved static records",
+			"name", qname,
+			"type", dns.TypeToString[qtype],
+			"answers", len(m.Answer),
+		)
+		return
+	}
+
+	h.answerMissingType(reg, m, q)
+}
+
+// answerMissingType answers a query with no records of its type: NODATA if
+// the name exists, NXDOMAIN otherwise.
+// Caller must hold h.mu.
+func (h *Handler) answerMissingType(reg *Registry, m *dns.Msg, q dns.Question) {
+	if h.nameExists(reg, q.Name, findZone(h.zones, q.Name)) {
+		h.logger.Debug("no records of queried type, returning NODATA",
+			"name", q.Name,
+			"type", dns.TypeToString[q.Qtype],
+		)
+		return
+	}
+
+	h.logger.Debug("domain not found", "name", q.Name, "type", dns.TypeToString[q.Qtype])
+	m.SetRcode(m, dns.RcodeNameError) // NXDOMAIN
+}
+
+// existingTypes returns the record types that exist at qname.
+// Used as the type bitmap of NSEC3 NODATA proofs.
+// Caller must hold h.mu.
+func (h *Handler) existingTypes(reg *Registry, qname string) []uint16 {
+	types := []uint16{dns.TypeRRSIG}
+
+	if entry := reg.Lookup(qname); entry != nil {
+		if len(h.getHealthyIPv4Servers(entry)) > 0 {
+			types = append(types, dns.TypeA)
+		}
+		if len(h.getHealthyIPv6Servers(entry)) > 0 || (entry.DNS64 != nil && len(h.getHealthyIPv4Servers(entry)) > 0) {
+			types = append(types, dns.TypeAAAA)
+		}
+		if entry.HTTPS != nil {
+			types = append(types, dns.TypeSVCB, dns.TypeHTTPS)
+		}
+	}
+	if entry, _, _ := h.srvDomain(reg, qname); entry != nil {
+		types = append(types, dns.TypeSRV)
+	}
+	if _, ip := h.srvTarget(reg, qname); ip != nil {
+		if ip.To4() != nil {
+			types = append(types, dns.TypeA)
+		} else {
+			types = append(types, dns.TypeAAAA)
 		}
+	}
+	for _, rr := range reg.StaticRecords(qname) {
+		if !slices.Contains(types, rr.Header().Rrtype) {
+			types = append(types, rr.Header().Rrtype)
+		}
+	}
 
-		servers = append(servers, &routing.Server{
-			Address: server.Address.String(),
-			Port:    server.Port,
-			Weight:  server.Weight,
-			Region:  server.Region,
-		})
+	zone := findZone(h.zones, qname)
+	if zone == nil {
+		return types
+	}
+	if zone.IsApex(qname) {
+		types = append(types, dns.TypeSOA, dns.TypeNS, dns.TypeDNSKEY)
+	}
+	if rr := zone.NameserverAddress(qname, dns.TypeA); rr != nil {
+		types = append(types, dns.TypeA)
+	}
+	if rr := zone.NameserverAddress(qname, dns.TypeAAAA); rr != nil {
+		types = append(types, dns.TypeAAAA)
 	}
 
-	return servers
+	return types
 }
 
-// getHealthyIPv6Servers returns healthy IPv6 servers from the entry.
+// getHealthyIPv4Servers returns healthy IPv4 servers from the entry, or
+// every IPv4 server not overridden to unhealthy in panic mode.
+func (h *Handler) getHealthyIPv4Servers(entry *DomainEntry) []*routing.Server {
+	return h.getHealthyServers(entry, "ipv4")
+}
+
+// getHealthyIPv6Servers returns healthy IPv6 servers from the entry, or
+// every IPv6 server not overridden to unhealthy in panic mode.
 func (h *Handler) getHealthyIPv6Servers(entry *DomainEntry) []*routing.Server {
-	var servers []*routing.Server
+	return h.getHealthyServers(entry, "ipv6")
+}
+
+// SetWeightProfile sets the weight profile applied to a domain's servers in
+// every view. A nil profile restores the configured weights.
+func (h *Handler) SetWeightProfile(domain string, profile *WeightProfile) {
+	h.profileMu.Lock()
+	defer h.profileMu.Unlock()
+	if profile == nil {
+		delete(h.profiles, normalizeDomain(domain))
+		return
+	}
+	h.profiles[normalizeDomain(domain)] = profile
+}
+
+// WeightProfile returns the weight profile applied to a domain, or nil.
+func (h *Handler) WeightProfile(domain string) *WeightProfile {
+	h.profileMu.RLock()
+	defer h.profileMu.RUnlock()
+	return h.profiles[normalizeDomain(domain)]
+}
+
+// getHealthyServers returns the healthy servers of an address family from
+// the entry, with the domain's weight profile applied. When the healthy share
+// of the servers not overridden to unhealthy falls below the domain's panic
+// threshold, health is ignored and those servers are returned instead,
+// spreading traffic over the whole pool rather than overwhelming the few
+// healthy servers. The domain's panic state is left unchanged; the query
+// path updates it through routingServers.
+func (h *Handler) getHealthyServers(entry *DomainEntry, family string) []*routing.Server {
+	servers, _ := h.healthyPool(entry, family)
+	return servers
+}
+
+// routingServers returns the servers of an address family to route a query
+// among, as getHealthyServers, and records whether the domain is in panic
+// mode. Only queries and WatchPanicMode update the panic state, so zone
+// transfers, type probes and the routing test API do not report panic mode
+// changes.
+func (h *Handler) routingServers(entry *DomainEntry, family string) []*routing.Server {
+	servers, status := h.healthyPool(entry, family)
+	if entry.PanicThreshold > 0 {
+		h.setPanic(entry, family, status.active, status.healthy, status.total)
+	}
+	return servers
+}
+
+// panicStatus is the outcome of a domain's panic threshold check.
+type panicStatus struct {
+	active  bool
+	healthy int // Healthy servers
+	total   int // Servers not overridden to unhealthy
+}
+
+// healthyPool returns the servers getHealthyServers routes among and the
+// domain's panic status.
+func (h *Handler) healthyPool(entry *DomainEntry, family string) ([]*routing.Server, panicStatus) {
+	var healthy, eligible []*routing.Server
+	overrides, _ := h.health.(OverrideProvider)
+	profile := h.WeightProfile(entry.Name)
 
 	for _, server := range entry.Servers {
-		// Check if IPv6 (not IPv4)
-		if server.Address.To4() != nil {
+		if (server.Address.To4() == nil) != (family == "ipv6") {
 			continue
 		}
 
-		// Check health
-		if h.health != nil && !h.health.IsHealthy(server.Address.String(), server.Port) {
-			continue
+		s := &routing.Server{
+			Address:  server.Address.String(),
+			Port:     server.Port,
+			Weight:   server.Weight,
+			Region:   server.Region,
+			Priority: server.Priority,
+			Labels:   server.Labels,
+		}
+		if profile != nil {
+			profile.Apply(s)
+		}
+		if h.health == nil || h.health.IsHealthy(s.Address, s.Port) {
+			healthy = append(healthy, s)
+		}
+		if entry.PanicThreshold > 0 && (overrides == nil || !overrides.IsOverriddenUnhealthy(s.Address, s.Port)) {
+			eligible = append(eligible, s)
 		}
+	}
 
-		servers = append(servers, &routing.Server{
-			Address: server.Address.String(),
-			Port:    server.Port,
-			Weight:  server.Weight,
-			Region:  server.Region,
-		})
+	if entry.PanicThreshold == 0 {
+		return healthy, panicStatus{}
+	}
+	status := panicStatus{
+		active:  len(eligible) > 0 && len(healthy)*100 < entry.PanicThreshold*len(eligible),
+		healthy: len(healthy),
+		total:   len(eligible),
 	}
+	if status.active {
+		return eligible, status
+	}
+	return healthy, status
+}
 
-	return servers
+// setPanic records whether a domain's pool is in panic mode, reporting
+// changes to the log and metrics and queueing them for the panic listener,
+// so queries never wait on it.
+func (h *Handler) setPanic(entry *DomainEntry, family string, active bool, healthy, total int) {
+	key := panicKey(entry, family)
+	h.panicMu.Lock()
+	if h.panicking[key] == active {
+		h.panicMu.Unlock()
+		return
+	}
+	if active {
+		h.panicking[key] = true
+	} else {
+		delete(h.panicking, key)
+	}
+	h.panicMu.Unlock()
+
+	view := entry.View
+	if view == "" {
+		view = config.DefaultViewName
+	}
+	metrics.SetRoutingPanicMode(entry.Name, view, family, active)
+	if active {
+		h.logger.Warn("domain entered panic mode, ignoring health",
+			"domain", entry.Name,
+			"view", entry.View,
+			"family", family,
+			"healthy", healthy,
+			"total", total,
+			"threshold_percent", entry.PanicThreshold,
+		)
+	} else {
+		h.logger.Info("domain left panic mode",
+			"domain", entry.Name,
+			"view", entry.View,
+			"family", family,
+			"healthy", healthy,
+			"total", total,
+		)
+	}
+
+	if h.panicListener == nil {
+		return
+	}
+	select {
+	case h.panicEvents <- PanicEvent{
+		Domain:    entry.Name,
+		View:      entry.View,
+		Family:    family,
+		Active:    active,
+		Healthy:   healthy,
+		Total:     total,
+		Threshold: entry.PanicThreshold,
+	}:
+	default:
+		h.logger.Warn("panic mode event dropped, listener queue full", "domain", entry.Name, "family", family)
+	}
+}
+
+// panicKey returns the key of a domain's pool of an address family in
+// h.panicking.
+func panicKey(entry *DomainEntry, family string) string {
+	return entry.View + "/" + entry.Name + "/" + family
+}
+
+// panicFamilies returns the address families of a domain in panic mode.
+func (h *Handler) panicFamilies(entry *DomainEntry) []string {
+	if entry.PanicThreshold == 0 {
+		return nil
+	}
+	h.panicMu.Lock()
+	defer h.panicMu.Unlock()
+	var families []string
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if h.panicking[panicKey(entry, family)] {
+			families = append(families, family)
+		}
+	}
+	return families
+}
+
+// WatchPanicMode delivers panic mode changes to the panic listener, in
+// order, and re-evaluates the panic state of every domain with a panic
+// threshold each interval, so pools enter and leave panic mode as health
+// changes even when they get no queries. Runs until ctx is done.
+func (h *Handler) WatchPanicMode(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPanicCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-h.panicEvents:
+			h.panicListener(event)
+		case <-ticker.C:
+			h.UpdatePanicMode()
+		}
+	}
+}
+
+// UpdatePanicMode re-evaluates the panic state of every domain with a panic
+// threshold, in the default registry and every view.
+func (h *Handler) UpdatePanicMode() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.registry == nil {
+		return
+	}
+
+	registries := []*Registry{h.registry}
+	for _, view := range h.registry.Views() {
+		registries = append(registries, view)
+	}
+	for _, reg := range registries {
+		for _, name := range reg.Domains() {
+			entry := reg.Lookup(name)
+			if entry == nil || entry.PanicThreshold == 0 {
+				continue
+			}
+			h.routingServers(entry, "ipv4")
+			h.routingServers(entry, "ipv6")
+		}
+	}
 }
 
 // addARecord adds an A record to the response.
@@ -343,13 +851,13 @@ func (h *Handler) UpdateRegistry(registry *Registry) {
 }
 
 // handleDNSKEYQuery processes DNSKEY record queries.
-func (h *Handler) handleDNSKEYQuery(m *dns.Msg, qname string, q dns.Question) {
+func (h *Handler) handleDNSKEYQuery(reg *Registry, m *dns.Msg, qname string, q dns.Question) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	// Check if the domain exists in our registry
-	entry := h.registry.Lookup(qname)
-	if entry == nil {
+	entry := reg.Lookup(qname)
+	if entry == nil && !h.isZoneApex(qname) {
 		h.logger.Debug("domain not found for DNSKEY query", "name", qname)
 		m.SetRcode(m, dns.RcodeNameError) // NXDOMAIN
 		return
@@ -360,15 +868,177 @@ func (h *Handler) handleDNSKEYQuery(m *dns.Msg, qname string, q dns.Question) {
 	h.logger.Debug("resolved DNSKEY query", "domain", qname)
 }
 
+// handleSOAQuery processes SOA record queries.
+// The SOA exists only at a zone apex; other names get NODATA or NXDOMAIN.
+func (h *Handler) handleSOAQuery(reg *Registry, m *dns.Msg, qname string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	zone := findZone(h.zones, qname)
+	if zone != nil && zone.IsApex(qname) {
+		m.Answer = append(m.Answer, zone.SOARecord())
+		m.Ns = append(m.Ns, zone.NSRecords()...)
+		m.Extra = append(m.Extra, zone.GlueRecords()...)
+		h.logger.Debug("resolved SOA query", "zone", zone.Name)
+		return
+	}
+
+	if !h.nameExists(reg, qname, zone) {
+		m.SetRcode(m, dns.RcodeNameError) // NXDOMAIN
+	}
+}
+
+// handleNSQuery processes NS record queries.
+// The NS set exists only at a zone apex; other names get NODATA or NXDOMAIN.
+func (h *Handler) handleNSQuery(reg *Registry, m *dns.Msg, qname string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	zone := findZone(h.zones, qname)
+	if zone != nil && zone.IsApex(qname) {
+		m.Answer = append(m.Answer, zone.NSRecords()...)
+		m.Extra = append(m.Extra, zone.GlueRecords()...)
+		h.logger.Debug("resolved NS query", "zone", zone.Name, "nameservers", len(zone.Nameservers))
+		return
+	}
+
+	if !h.nameExists(reg, qname, zone) {
+		m.SetRcode(m, dns.RcodeNameError) // NXDOMAIN
+	}
+}
+
+// answerFromZone answers A/AAAA queries for names owned by the zone itself
+// rather than the registry: in-zone nameserver addresses and the apex.
+// Returns true if the name exists in the zone (the answer may be empty, i.e. NODATA).
+// Caller must hold h.mu.
+func (h *Handler) answerFromZone(m *dns.Msg, q dns.Question) bool {
+	zone := findZone(h.zones, q.Name)
+	if zone == nil {
+		return false
+	}
+
+	if rr := zone.NameserverAddress(q.Name, q.Qtype); rr != nil {
+		rr.Header().Name = q.Name
+		m.Answer = append(m.Answer, rr)
+		return true
+	}
+
+	return zone.IsApex(q.Name) || zone.IsNameserver(q.Name)
+}
+
+// answerStaticRecords answers from the static records at q.Name. A CNAME
+// answers every query type. Reports whether the name has static records,
+// in which case an empty answer is NODATA.
+// Caller must hold h.mu.
+func (h *Handler) answerStaticRecords(reg *Registry, m *dns.Msg, q dns.Question) bool {
+	if reg == nil {
+		return false
+	}
+	records := reg.StaticRecords(q.Name)
+	if len(records) == 0 {
+		return false
+	}
+
+	for _, rr := range records {
+		rrtype := rr.Header().Rrtype
+		if rrtype != q.Qtype && rrtype != dns.TypeCNAME {
+			continue
+		}
+		answer := dns.Copy(rr)
+		answer.Header().Name = q.Name
+		m.Answer = append(m.Answer, answer)
+	}
+	return true
+}
+
+// nameExists reports whether a name exists as a domain, static record owner,
+// SRV owner or target, apex or nameserver.
+// Caller must hold h.mu.
+func (h *Handler) nameExists(reg *Registry, qname string, zone *Zone) bool {
+	if reg != nil && (reg.Lookup(qname) != nil || reg.HasStaticName(qname)) {
+		return true
+	}
+	if entry, _, _ := h.srvDomain(reg, qname); entry != nil {
+		return true
+	}
+	if entry, _ := h.srvTarget(reg, qname); entry != nil {
+		return true
+	}
+	return zone != nil && (zone.IsApex(qname) || zone.IsNameserver(qname))
+}
+
+// isZoneApex reports whether qname is the apex of a configured zone.
+// Caller must hold h.mu.
+func (h *Handler) isZoneApex(qname string) bool {
+	zone := findZone(h.zones, qname)
+	return zone != nil && zone.IsApex(qname)
+}
+
+// addNegativeSOA adds the zone SOA to the authority section of NXDOMAIN and
+// NODATA responses so resolvers can cache the negative answer (RFC 2308).
+func (h *Handler) addNegativeSOA(m *dns.Msg, qname string) {
+	if m.Rcode != dns.RcodeNameError && (m.Rcode != dns.RcodeSuccess || len(m.Answer) > 0) {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	// DNSKEY answers are filled in by the signer, so the response is not NODATA yet
+	if len(m.Question) > 0 && m.Question[0].Qtype == dns.TypeDNSKEY && h.dnssecEnabled && h.dnssecSigner != nil {
+		return
+	}
+
+	zone := findZone(h.zones, qname)
+	if zone == nil {
+		return
+	}
+
+	for _, rr := range m.Ns {
+		if rr.Header().Rrtype == dns.TypeSOA {
+			return
+		}
+	}
+	m.Ns = append(m.Ns, zone.NegativeSOARecord())
+}
+
+// SetZones replaces the authoritative zones served by the handler.
+// A zone keeps its current serial if that is newer than the new one, so
+// serials bumped at runtime never move backwards on reload.
+func (h *Handler) SetZones(zones []*Zone) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, zone := range zones {
+		for _, old := range h.zones {
+			if old.Name == zone.Name && int32(old.SOA.Serial-zone.SOA.Serial) > 0 {
+				zone.SOA.Serial = old.SOA.Serial
+			}
+		}
+	}
+	h.zones = zones
+	h.logger.Info("DNS handler zones updated", "zones", len(zones))
+}
+
 // signResponse signs the DNS response if DNSSEC is enabled.
 // Returns the original message if DNSSEC is disabled or signing fails.
-func (h *Handler) signResponse(m *dns.Msg) *dns.Msg {
+func (h *Handler) signResponse(reg *Registry, m *dns.Msg) *dns.Msg {
 	if !h.dnssecEnabled || h.dnssecSigner == nil {
 		return m
 	}
 
-	// Call the signer - it handles all DNSSEC signing
-	signed, err := h.dnssecSigner.SignResponse(m)
+	// NODATA responses need an NSEC3 proof listing the types that do exist
+	var signed interface{}
+	var err error
+	noDataSigner, ok := h.dnssecSigner.(DNSSECNoDataSigner)
+	if ok && isNoData(m) {
+		h.mu.RLock()
+		types := h.existingTypes(reg, m.Question[0].Name)
+		h.mu.RUnlock()
+		signed, err = noDataSigner.SignNoDataResponse(m, types)
+	} else {
+		// Call the signer - it handles all DNSSEC signing
+		signed, err = h.dnssecSigner.SignResponse(m)
+	}
 	if err != nil {
 		h.logger.Warn("failed to sign DNS response",
 			"error", err,
@@ -384,6 +1054,15 @@ func (h *Handler) signResponse(m *dns.Msg) *dns.Msg {
 	return m
 }
 
+// isNoData reports whether m is a NODATA response: NOERROR with an empty
+// answer section. DNSKEY answers are added by the signer, so they never count.
+func isNoData(m *dns.Msg) bool {
+	if m.Rcode != dns.RcodeSuccess || len(m.Answer) > 0 || len(m.Question) == 0 {
+		return false
+	}
+	return m.Question[0].Qtype != dns.TypeDNSKEY
+}
+
 // SetDNSSECSigner sets the DNSSEC signer for signing responses.
 func (h *Handler) SetDNSSECSigner(signer DNSSECSigner, enabled bool) {
 	h.mu.Lock()
@@ -0,0 +1,144 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// handleHTTPSQuery answers HTTPS and SVCB queries (RFC 9460) for domains
+// publishing HTTPS records. The record is in service mode at the domain
+// itself, hinting the healthy backends the routing algorithm selects for the
+// client, so clients get routed addresses and protocol upgrades in one round
+// trip. Static records take precedence.
+func (h *Handler) handleHTTPSQuery(reg *Registry, m *dns.Msg, q dns.Question, clientIP net.IP) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.answerStaticRecords(reg, m, q) {
+		return
+	}
+
+	var entry *DomainEntry
+	var label string
+	if reg != nil {
+		entry, label = reg.LookupMatch(q.Name)
+	}
+	if entry == nil || entry.HTTPS == nil {
+		h.answerMissingType(reg, m, q)
+		return
+	}
+
+	// Hints are optional: without healthy backends the record is served
+	// without them and clients resolve the name as usual
+	pools := [][]*routing.Server{h.routingServers(entry, "ipv4"), h.routingServers(entry, "ipv6")}
+	ctx := h.routingContext(entry, clientIP, label)
+	var hints []*routing.Server
+	for _, servers := range pools {
+		if len(servers) == 0 {
+			continue
+		}
+		selected, err := h.selectServers(ctx, entry, routing.NewSimpleServerPool(servers))
+		if err != nil {
+			h.logger.Error("routing failed", "domain", q.Name, "error", err)
+			m.SetRcode(m, dns.RcodeServerFailure)
+			return
+		}
+		hints = append(hints, selected...)
+	}
+
+	m.Answer = append(m.Answer, httpsRecord(q.Name, q.Qtype, h.ttlFor(entry), entry.HTTPS, hints))
+	if len(hints) > 0 {
+		metrics.RecordRoutingDecision(entry.Name, entry.Router.Algorithm(), hints[0].Address)
+	}
+
+	h.logger.Debug("resolved HTTPS query",
+		"name", q.Name,
+		"type", dns.TypeToString[q.Qtype],
+		"hints", len(hints),
+		"algorithm", entry.Router.Algorithm(),
+	)
+}
+
+// httpsRecord returns a service mode HTTPS or SVCB record (rrtype) for name
+// with the domain's parameters and the servers' addresses as hints.
+// Parameters are in ascending key order, as RFC 9460 requires.
+func httpsRecord(name string, rrtype uint16, ttl uint32, params *HTTPSParams, servers []*routing.Server) dns.RR {
+	svcb := dns.SVCB{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: rrtype,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Priority: 1,
+		Target:   ".",
+	}
+
+	// Backends listening on several ports share one hint
+	var ipv4, ipv6 []net.IP
+	seen := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		ip := net.ParseIP(server.Address)
+		if ip == nil || seen[ip.String()] {
+			continue
+		}
+		seen[ip.String()] = true
+		if ip4 := ip.To4(); ip4 != nil {
+			ipv4 = append(ipv4, ip4)
+		} else {
+			ipv6 = append(ipv6, ip)
+		}
+	}
+
+	if len(params.ALPN) > 0 {
+		svcb.Value = append(svcb.Value, &dns.SVCBAlpn{Alpn: params.ALPN})
+	}
+	if params.Port != 0 {
+		svcb.Value = append(svcb.Value, &dns.SVCBPort{Port: params.Port})
+	}
+	if len(ipv4) > 0 {
+		svcb.Value = append(svcb.Value, &dns.SVCBIPv4Hint{Hint: ipv4})
+	}
+	if len(params.ECH) > 0 {
+		svcb.Value = append(svcb.Value, &dns.SVCBECHConfig{ECH: params.ECH})
+	}
+	if len(ipv6) > 0 {
+		svcb.Value = append(svcb.Value, &dns.SVCBIPv6Hint{Hint: ipv6})
+	}
+
+	if rrtype == dns.TypeHTTPS {
+		return &dns.HTTPS{SVCB: svcb}
+	}
+	return &svcb
+}
+
+// hintOf returns the first address hint of an HTTPS or SVCB record.
+func hintOf(rr dns.RR) net.IP {
+	var svcb *dns.SVCB
+	switch v := rr.(type) {
+	case *dns.HTTPS:
+		svcb = &v.SVCB
+	case *dns.SVCB:
+		svcb = v
+	default:
+		return nil
+	}
+	for _, kv := range svcb.Value {
+		switch hint := kv.(type) {
+		case *dns.SVCBIPv4Hint:
+			return hint.Hint[0]
+		case *dns.SVCBIPv6Hint:
+			return hint.Hint[0]
+		}
+	}
+	return nil
+}
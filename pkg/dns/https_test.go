@@ -0,0 +1,152 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/loganrossus/OpenGSLB/pkg/dnssec"
+	"github.com/miekg/dns"
+)
+
+// newHTTPSTestHandler returns a handler for app.example.com publishing
+// HTTPS records, with two ports on one IPv4 backend and an IPv6 backend,
+// and plain.example.com without them.
+func newHTTPSTestHandler(health HealthProvider) *Handler {
+	registry := NewRegistry()
+	servers := []ServerInfo{
+		{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		{Address: net.ParseIP("10.0.0.1"), Port: 81, Weight: 100},
+		{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+		{Address: net.ParseIP("2001:db8::1"), Port: 80, Weight: 100},
+	}
+	registry.Register(&DomainEntry{
+		Name:       "app.example.com",
+		TTL:        30,
+		Router:     &mockRouter{},
+		Servers:    servers,
+		MaxAnswers: 3,
+		HTTPS:      &HTTPSParams{ALPN: []string{"h3", "h2"}, Port: 8443, ECH: []byte{0x00, 0x01}},
+	})
+	registry.Register(&DomainEntry{Name: "plain.example.com", Router: &mockRouter{}, Servers: servers})
+	return NewHandler(HandlerConfig{Registry: registry, HealthProvider: health, DefaultTTL: 60})
+}
+
+// svcbOf returns the SVCB data of an HTTPS or SVCB record.
+func svcbOf(t *testing.T, rr dns.RR) *dns.SVCB {
+	t.Helper()
+	switch v := rr.(type) {
+	case *dns.HTTPS:
+		return &v.SVCB
+	case *dns.SVCB:
+		return v
+	}
+	t.Fatalf("expected HTTPS or SVCB record, got %T", rr)
+	return nil
+}
+
+func TestHandler_HTTPSRecord(t *testing.T) {
+	h := newHTTPSTestHandler(nil)
+
+	for _, qtype := range []uint16{dns.TypeHTTPS, dns.TypeSVCB} {
+		resp := query(t, h, "app.example.com.", qtype)
+		if resp.Rcode != dns.RcodeSuccess || len(resp.Answer) != 1 {
+			t.Fatalf("%s: expected one answer, got rcode %d with %d answers",
+				dns.TypeToString[qtype], resp.Rcode, len(resp.Answer))
+		}
+		rr := resp.Answer[0]
+		if rr.Header().Rrtype != qtype || rr.Header().Ttl != 30 {
+			t.Errorf("unexpected record header: %s", rr.Header())
+		}
+		svcb := svcbOf(t, rr)
+		if svcb.Priority != 1 || svcb.Target != "." {
+			t.Errorf("expected service mode record for the owner, got %s", rr)
+		}
+
+		// Parameters in key order; the duplicate backend address is hinted once
+		want := "alpn=\"h3,h2\" port=\"8443\" ipv4hint=\"10.0.0.1,10.0.0.2\" ech=\"AAE=\" ipv6hint=\"2001:db8::1\""
+		var got string
+		for i, kv := range svcb.Value {
+			if i > 0 {
+				got += " "
+			}
+			got += kv.Key().String() + "=\"" + kv.String() + "\""
+		}
+		if got != want {
+			t.Errorf("unexpected parameters:\ngot  %s\nwant %s", got, want)
+		}
+
+		// The record survives a round trip through the wire format
+		wire, err := resp.Pack()
+		if err != nil {
+			t.Fatalf("failed to pack response: %v", err)
+		}
+		if err := new(dns.Msg).Unpack(wire); err != nil {
+			t.Fatalf("failed to unpack response: %v", err)
+		}
+	}
+}
+
+func TestHandler_HTTPSRecordWithoutHealthyBackends(t *testing.T) {
+	health := newMockHealthProvider()
+	for _, addr := range []string{"10.0.0.1", "10.0.0.2", "2001:db8::1"} {
+		health.SetHealthy(addr, false)
+	}
+	h := newHTTPSTestHandler(health)
+
+	resp := query(t, h, "app.example.com.", dns.TypeHTTPS)
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected one answer, got %d", len(resp.Answer))
+	}
+	for _, kv := range svcbOf(t, resp.Answer[0]).Value {
+		if kv.Key() == dns.SVCB_IPV4HINT || kv.Key() == dns.SVCB_IPV6HINT {
+			t.Errorf("expected no hints without healthy backends, got %s", kv.Key())
+		}
+	}
+}
+
+func TestHandler_HTTPSRecordNotPublished(t *testing.T) {
+	h := newHTTPSTestHandler(nil)
+
+	resp := query(t, h, "plain.example.com.", dns.TypeHTTPS)
+	if resp.Rcode != dns.RcodeSuccess || len(resp.Answer) != 0 {
+		t.Errorf("expected NODATA, got rcode %d with %d answers", resp.Rcode, len(resp.Answer))
+	}
+	resp = query(t, h, "missing.example.com.", dns.TypeSVCB)
+	if resp.Rcode != dns.RcodeNameError {
+		t.Errorf("expected NXDOMAIN, got rcode %d", resp.Rcode)
+	}
+}
+
+func TestHandler_HTTPSRecordSigned(t *testing.T) {
+	km := dnssec.NewKeyManager("test-node")
+	key, err := km.GenerateKey("example.com.", dnssec.AlgorithmECDSAP256SHA256)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	h := newHTTPSTestHandler(nil)
+	h.SetDNSSECSigner(dnssec.NewSigner(dnssec.SignerConfig{KeyManager: km}), true)
+
+	resp := query(t, h, "app.example.com.", dns.TypeHTTPS)
+	var rrset []dns.RR
+	var sig *dns.RRSIG
+	for _, rr := range resp.Answer {
+		switch v := rr.(type) {
+		case *dns.HTTPS:
+			rrset = append(rrset, v)
+		case *dns.RRSIG:
+			sig = v
+		}
+	}
+	if len(rrset) != 1 || sig == nil || sig.TypeCovered != dns.TypeHTTPS {
+		t.Fatalf("expected a signed HTTPS record, got %v", resp.Answer)
+	}
+	if err := sig.Verify(key.DNSKEY(), rrset); err != nil {
+		t.Errorf("RRSIG does not verify: %v", err)
+	}
+}
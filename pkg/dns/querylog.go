@@ -0,0 +1,101 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/querylog"
+	"github.com/miekg/dns"
+)
+
+// SetQueryLog replaces the query log. nil disables query logging.
+func (h *Handler) SetQueryLog(ql *querylog.Logger) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queryLog = ql
+}
+
+// logQuery queues a query log entry for r answered with resp in view. The
+// backend is looked up in reg; requests not answered from a registry, such
+// as malformed queries, updates and zone transfers, pass nil and are logged
+// without one. A nil resp logs a response dropped by the rate limiter. The
+// entry is written in the background; nothing is done without a query log.
+func (h *Handler) logQuery(w dns.ResponseWriter, r, resp *dns.Msg, start time.Time, view string, reg *Registry) {
+	h.mu.RLock()
+	ql := h.queryLog
+	h.mu.RUnlock()
+	if ql == nil {
+		return
+	}
+
+	e := &querylog.Entry{
+		Time:     start,
+		Protocol: "tcp",
+		Rcode:    statusDropped,
+		View:     view,
+		Latency:  time.Since(start),
+		Query:    r,
+		Response: resp,
+	}
+	if resp != nil {
+		e.Rcode = rcodeString(resp.Rcode)
+	}
+	if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+		e.Protocol = "udp"
+	}
+	e.ClientIP, e.ClientPort = addrIPPort(w.RemoteAddr())
+	e.ServerIP, e.ServerPort = addrIPPort(w.LocalAddr())
+	if opt := r.IsEdns0(); opt != nil {
+		for _, option := range opt.Option {
+			if subnet, ok := option.(*dns.EDNS0_SUBNET); ok {
+				e.ECS = fmt.Sprintf("%s/%d", subnet.Address, subnet.SourceNetmask)
+			}
+		}
+	}
+	if len(r.Question) > 0 {
+		q := r.Question[0]
+		e.QName, e.QType = q.Name, dns.TypeToString[q.Qtype]
+		if reg != nil && resp != nil {
+			e.Backend, e.Algorithm = routedBackend(reg, resp, q)
+		}
+	}
+
+	ql.Log(e)
+}
+
+// routedBackend returns the backend address and routing algorithm of a
+// response answered from a GSLB domain.
+func routedBackend(reg *Registry, resp *dns.Msg, q dns.Question) (string, string) {
+	entry, backend := answeredDomain(reg, resp, q)
+	if entry == nil {
+		return "", ""
+	}
+	return backend.String(), entry.Router.Algorithm()
+}
+
+// rcodeString returns the name of a response code. 16 is BADVERS in a
+// message header; BADSIG, which shares the code, only appears in TSIG records.
+func rcodeString(rcode int) string {
+	if rcode == dns.RcodeBadVers {
+		return "BADVERS"
+	}
+	return dns.RcodeToString[rcode]
+}
+
+// addrIPPort returns the IP address and port of a network address.
+func addrIPPort(addr net.Addr) (net.IP, int) {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP, a.Port
+	case *net.TCPAddr:
+		return a.IP, a.Port
+	}
+	return addrIP(addr), 0
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/loganrossus/OpenGSLB/pkg/querylog"
+	"github.com/miekg/dns"
+)
+
+// recordingSink is a querylog.Sink keeping the entries written.
+type recordingSink struct {
+	entries []*querylog.Entry
+}
+
+func (s *recordingSink) Write(e *querylog.Entry) error { s.entries = append(s.entries, e); return nil }
+func (s *recordingSink) Flush() error                  { return nil }
+func (s *recordingSink) Close() error                  { return nil }
+
+func TestHandler_QueryLog(t *testing.T) {
+	h := newZoneTestHandler(t)
+	sink := &recordingSink{}
+	ql := querylog.New([]querylog.Sink{sink}, 16, nil)
+	h.SetQueryLog(ql)
+
+	req := new(dns.Msg)
+	req.SetQuestion("app.gslb.example.com.", dns.TypeA)
+	opt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	opt.SetUDPSize(dns.DefaultMsgSize)
+	opt.Option = append(opt.Option, &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        1,
+		SourceNetmask: 24,
+		Address:       net.ParseIP("198.51.100.0").To4(),
+	})
+	req.Extra = append(req.Extra, opt)
+	h.ServeDNS(newTestResponseWriter(), req)
+
+	query(t, h, "missing.gslb.example.com.", dns.TypeA)
+
+	if err := ql.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(sink.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(sink.entries))
+	}
+
+	e := sink.entries[0]
+	if e.QName != "app.gslb.example.com." || e.QType != "A" || e.Rcode != "NOERROR" {
+		t.Errorf("unexpected query fields: %s %s %s", e.QName, e.QType, e.Rcode)
+	}
+	if e.Backend != "10.0.0.1" || e.Algorithm != "mock" || e.View != "default" {
+		t.Errorf("unexpected routing fields: backend %q algorithm %q view %q", e.Backend, e.Algorithm, e.View)
+	}
+	if !e.ClientIP.Equal(net.ParseIP("192.0.2.1")) || e.ClientPort != 53000 || e.Protocol != "udp" {
+		t.Errorf("unexpected client: %s:%d/%s", e.ClientIP, e.ClientPort, e.Protocol)
+	}
+	if e.ECS != "198.51.100.0/24" {
+		t.Errorf("expected ECS 198.51.100.0/24, got %q", e.ECS)
+	}
+	if e.Response == nil || len(e.Response.Answer) != 1 {
+		t.Errorf("expected the response to be logged")
+	}
+
+	e = sink.entries[1]
+	if e.Rcode != "NXDOMAIN" || e.Backend != "" || e.Algorithm != "" {
+		t.Errorf("unexpected NXDOMAIN entry: rcode %s backend %q algorithm %q", e.Rcode, e.Backend, e.Algorithm)
+	}
+}
+
+func TestHandler_QueryLogEarlyReplies(t *testing.T) {
+	h, _ := newTransferTestHandler(t)
+	sink := &recordingSink{}
+	ql := querylog.New([]querylog.Sink{sink}, 16, nil)
+	h.SetQueryLog(ql)
+
+	// No question
+	h.ServeDNS(newTestResponseWriter(), new(dns.Msg))
+
+	// Unsupported EDNS version
+	req := new(dns.Msg)
+	req.SetQuestion("app.gslb.example.com.", dns.TypeA)
+	opt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	opt.SetVersion(1)
+	req.Extra = append(req.Extra, opt)
+	h.ServeDNS(newTestResponseWriter(), req)
+
+	// Unsigned dynamic update
+	update := new(dns.Msg)
+	update.SetUpdate("gslb.example.com.")
+	h.ServeDNS(newTestResponseWriter(), update)
+
+	// Zone transfer
+	axfr := new(dns.Msg)
+	axfr.SetAxfr("gslb.example.com.")
+	h.ServeDNS(newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000}), axfr)
+
+	if err := ql.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	want := []struct{ qname, qtype, rcode string }{
+		{"", "", "FORMERR"},
+		{"app.gslb.example.com.", "A", "BADVERS"},
+		{"gslb.example.com.", "SOA", "REFUSED"},
+		{"gslb.example.com.", "AXFR", "NOERROR"},
+	}
+	if len(sink.entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(sink.entries))
+	}
+	for i, w := range want {
+		e := sink.entries[i]
+		if e.QName != w.qname || e.QType != w.qtype || e.Rcode != w.rcode {
+			t.Errorf("entry %d: got %q %q %s, want %q %q %s", i, e.QName, e.QType, e.Rcode, w.qname, w.qtype, w.rcode)
+		}
+		if e.Backend != "" || e.Algorithm != "" {
+			t.Errorf("entry %d: expected no backend, got %q %q", i, e.Backend, e.Algorithm)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/miekg/dns"
+)
+
+// Response categories counted separately by the rate limiter.
+const (
+	rrlAnswer   = "answer"
+	rrlNXDomain = "nxdomain"
+	rrlError    = "error"
+)
+
+// statusDropped is the query status of responses dropped by the rate
+// limiter, used in metrics and the query log.
+const statusDropped = "DROPPED"
+
+// rrlAction is the rate limiter's decision for one response.
+type rrlAction int
+
+const (
+	rrlAllow rrlAction = iota
+	rrlDrop
+	rrlSlip
+)
+
+// RateLimitSettings controls response rate limiting (RRL).
+type RateLimitSettings struct {
+	ResponsesPerSecond int
+	NXDomainsPerSecond int
+	ErrorsPerSecond    int
+	Slip               int // Every slip-th limited response is truncated; <= 0 drops all
+	IPv4PrefixLength   int
+	IPv6PrefixLength   int
+	AllowFrom          []*net.IPNet // Clients that are never limited
+	TableSize          int          // Maximum number of tracked buckets
+}
+
+// BuildRateLimiter creates the response rate limiter from dns.rate_limit.
+// Returns nil when rate limiting is disabled.
+func BuildRateLimiter(cfg *config.Config) (*RateLimiter, error) {
+	rl := cfg.DNS.RateLimit
+	if !rl.Enabled {
+		return nil, nil
+	}
+	settings := RateLimitSettings{
+		ResponsesPerSecond: rl.ResponsesPerSecond,
+		NXDomainsPerSecond: rl.NXDomainsPerSecond,
+		ErrorsPerSecond:    rl.ErrorsPerSecond,
+		Slip:               rl.Slip,
+		IPv4PrefixLength:   rl.IPv4PrefixLength,
+		IPv6PrefixLength:   rl.IPv6PrefixLength,
+		TableSize:          rl.TableSize,
+	}
+	for _, entry := range rl.AllowFrom {
+		network, err := config.ParseNetwork(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate_limit allow_from entry: %w", err)
+		}
+		settings.AllowFrom = append(settings.AllowFrom, network)
+	}
+	return NewRateLimiter(settings), nil
+}
+
+// RateLimiter limits UDP responses per client prefix and response type, as
+// BIND's response rate limiting does. Each bucket allows a category's rate
+// of responses per second; over the limit, responses are dropped except for
+// every slip-th one, which is sent truncated so that a real client behind a
+// spoofed prefix can still get an answer over TCP.
+type RateLimiter struct {
+	settings RateLimitSettings
+	now      func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*rrlBucket
+}
+
+// rrlBucket is a token bucket for one client prefix and response key.
+type rrlBucket struct {
+	tokens  float64
+	last    time.Time
+	limited int // Responses limited since the bucket was last allowed
+}
+
+// NewRateLimiter creates a rate limiter.
+func NewRateLimiter(settings RateLimitSettings) *RateLimiter {
+	return &RateLimiter{
+		settings: settings,
+		now:      time.Now,
+		buckets:  make(map[string]*rrlBucket),
+	}
+}
+
+// check decides whether a response of category for key may be sent to ip.
+func (rl *RateLimiter) check(ip net.IP, category, key string) rrlAction {
+	if ip == nil || rl.allowed(ip) {
+		return rrlAllow
+	}
+	rate := float64(rl.rate(category))
+	if rate <= 0 {
+		return rrlAllow
+	}
+	bucketKey := rl.prefix(ip) + "|" + category + "|" + key
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	b, ok := rl.buckets[bucketKey]
+	if !ok {
+		if !rl.makeRoom(now) {
+			// Table full of active clients: fail open rather than drop
+			return rrlAllow
+		}
+		b = &rrlBucket{tokens: rate, last: now}
+		rl.buckets[bucketKey] = b
+	}
+
+	b.tokens = min(rate, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.limited = 0
+		return rrlAllow
+	}
+
+	b.limited++
+	if rl.settings.Slip > 0 && b.limited%rl.settings.Slip == 0 {
+		return rrlSlip
+	}
+	return rrlDrop
+}
+
+// makeRoom ensures there is room for a new bucket, evicting buckets that
+// have refilled. Reports whether a bucket can be added.
+// Caller must hold rl.mu.
+func (rl *RateLimiter) makeRoom(now time.Time) bool {
+	if rl.settings.TableSize <= 0 || len(rl.buckets) < rl.settings.TableSize {
+		return true
+	}
+	// A bucket idle for a second is full again and equivalent to a new one
+	for key, b := range rl.buckets {
+		if now.Sub(b.last) >= time.Second {
+			delete(rl.buckets, key)
+		}
+	}
+	return len(rl.buckets) < rl.settings.TableSize
+}
+
+func (rl *RateLimiter) allowed(ip net.IP) bool {
+	for _, network := range rl.settings.AllowFrom {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rl *RateLimiter) rate(category string) int {
+	switch category {
+	case rrlNXDomain:
+		return rl.settings.NXDomainsPerSecond
+	case rrlError:
+		return rl.settings.ErrorsPerSecond
+	default:
+		return rl.settings.ResponsesPerSecond
+	}
+}
+
+// prefix returns the client prefix ip is counted in.
+func (rl *RateLimiter) prefix(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(rl.settings.IPv4PrefixLength, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(rl.settings.IPv6PrefixLength, 128)).String()
+}
+
+// SetRateLimiter replaces the response rate limiter. nil disables limiting.
+func (h *Handler) SetRateLimiter(rl *RateLimiter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rateLimiter = rl
+}
+
+// rateLimit applies response rate limiting to a response about to be sent
+// over UDP. When the response is limited, it returns the truncated reply to
+// send instead, or nil to drop the response. Limits are checked before DNSSEC
+// signing so that a flood of queries does not cost signatures.
+func (h *Handler) rateLimit(w dns.ResponseWriter, m *dns.Msg, qname string) (*dns.Msg, bool) {
+	h.mu.RLock()
+	rl := h.rateLimiter
+	h.mu.RUnlock()
+	if rl == nil {
+		return nil, false
+	}
+	addr, ok := w.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		// Only UDP source addresses can be spoofed for reflection
+		return nil, false
+	}
+
+	category, key := h.responseKey(m, qname)
+	switch rl.check(addr.IP, category, key) {
+	case rrlDrop:
+		metrics.RecordDNSRateLimited(category, "dropped")
+		h.logger.Debug("response dropped by rate limit",
+			"name", qname,
+			"category", category,
+			"client", addr.String(),
+		)
+		return nil, true
+	case rrlSlip:
+		metrics.RecordDNSRateLimited(category, "slipped")
+		slipped := new(dns.Msg)
+		slipped.SetReply(m)
+		slipped.Rcode = m.Rcode
+		slipped.Authoritative = m.Authoritative
+		slipped.Truncated = true
+		return slipped, true
+	}
+	return nil, false
+}
+
+// responseKey returns the rate limiting category of a response and the name
+// its bucket is keyed on: the query name and type for answers, the zone for
+// NXDOMAIN (so random subdomains share a bucket) and nothing for errors.
+func (h *Handler) responseKey(m *dns.Msg, qname string) (string, string) {
+	name := strings.ToLower(qname)
+	switch m.Rcode {
+	case dns.RcodeSuccess:
+		qtype := ""
+		if len(m.Question) > 0 {
+			qtype = dns.TypeToString[m.Question[0].Qtype]
+		}
+		return rrlAnswer, name + "/" + qtype
+	case dns.RcodeNameError:
+		h.mu.RLock()
+		zone := findZone(h.zones, name)
+		h.mu.RUnlock()
+		if zone != nil {
+			return rrlNXDomain, zone.Name
+		}
+		return rrlNXDomain, ""
+	default:
+		return rrlError, ""
+	}
+}
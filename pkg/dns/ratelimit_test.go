@@ -0,0 +1,177 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/querylog"
+	"github.com/miekg/dns"
+)
+
+func newTestRateLimiter(settings RateLimitSettings) (*RateLimiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := NewRateLimiter(settings)
+	rl.now = func() time.Time { return now }
+	return rl, &now
+}
+
+func TestRateLimiter_Check(t *testing.T) {
+	rl, now := newTestRateLimiter(RateLimitSettings{
+		ResponsesPerSecond: 2,
+		NXDomainsPerSecond: 1,
+		Slip:               2,
+		IPv4PrefixLength:   24,
+		IPv6PrefixLength:   56,
+	})
+	client := net.ParseIP("198.51.100.7")
+
+	want := []rrlAction{rrlAllow, rrlAllow, rrlDrop, rrlSlip, rrlDrop, rrlSlip}
+	for i, expected := range want {
+		if got := rl.check(client, rrlAnswer, "app.example.com./A"); got != expected {
+			t.Errorf("response %d: expected action %d, got %d", i, expected, got)
+		}
+	}
+
+	// Clients in the same /24 share the bucket; other keys and prefixes do not
+	if got := rl.check(net.ParseIP("198.51.100.200"), rrlAnswer, "app.example.com./A"); got == rrlAllow {
+		t.Error("expected neighbour in the same prefix to be limited")
+	}
+	if got := rl.check(client, rrlAnswer, "app.example.com./AAAA"); got != rrlAllow {
+		t.Error("expected a different query type to have its own bucket")
+	}
+	if got := rl.check(net.ParseIP("203.0.113.7"), rrlAnswer, "app.example.com./A"); got != rrlAllow {
+		t.Error("expected another prefix to have its own bucket")
+	}
+
+	// Categories have their own rate
+	if got := rl.check(client, rrlNXDomain, "example.com."); got != rrlAllow {
+		t.Error("expected first NXDOMAIN to be allowed")
+	}
+	if got := rl.check(client, rrlNXDomain, "example.com."); got == rrlAllow {
+		t.Error("expected second NXDOMAIN in the same second to be limited")
+	}
+
+	// Tokens refill over time
+	*now = now.Add(time.Second)
+	if got := rl.check(client, rrlAnswer, "app.example.com./A"); got != rrlAllow {
+		t.Error("expected bucket to refill after a second")
+	}
+}
+
+func TestRateLimiter_AllowFromAndSlip(t *testing.T) {
+	_, allowed, _ := net.ParseCIDR("10.0.0.0/8")
+	rl, _ := newTestRateLimiter(RateLimitSettings{
+		ResponsesPerSecond: 1,
+		Slip:               -1,
+		IPv4PrefixLength:   24,
+		IPv6PrefixLength:   56,
+		AllowFrom:          []*net.IPNet{allowed},
+	})
+
+	for i := 0; i < 5; i++ {
+		if got := rl.check(net.ParseIP("10.1.2.3"), rrlAnswer, "a"); got != rrlAllow {
+			t.Fatalf("expected allowlisted client to never be limited, got %d", got)
+		}
+	}
+
+	rl.check(net.ParseIP("2001:db8:0:1::1"), rrlAnswer, "a")
+	for i := 0; i < 4; i++ {
+		// Same /56, and slip -1 never truncates
+		if got := rl.check(net.ParseIP("2001:db8:0:2::1"), rrlAnswer, "a"); got != rrlDrop {
+			t.Errorf("expected drop, got %d", got)
+		}
+	}
+}
+
+func TestRateLimiter_TableSize(t *testing.T) {
+	rl, now := newTestRateLimiter(RateLimitSettings{
+		ResponsesPerSecond: 1,
+		Slip:               2,
+		IPv4PrefixLength:   32,
+		TableSize:          2,
+	})
+
+	rl.check(net.ParseIP("192.0.2.1"), rrlAnswer, "a")
+	rl.check(net.ParseIP("192.0.2.2"), rrlAnswer, "a")
+	// A full table of active buckets fails open
+	rl.check(net.ParseIP("192.0.2.3"), rrlAnswer, "a")
+	if got := rl.check(net.ParseIP("192.0.2.3"), rrlAnswer, "a"); got != rrlAllow {
+		t.Errorf("expected untracked client to be allowed, got %d", got)
+	}
+
+	// Idle buckets are evicted to make room
+	*now = now.Add(2 * time.Second)
+	rl.check(net.ParseIP("192.0.2.3"), rrlAnswer, "a")
+	if got := rl.check(net.ParseIP("192.0.2.3"), rrlAnswer, "a"); got == rrlAllow {
+		t.Error("expected client to be tracked after idle buckets were evicted")
+	}
+	if len(rl.buckets) != 1 {
+		t.Errorf("expected idle buckets to be evicted, got %d buckets", len(rl.buckets))
+	}
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	handler := newZoneTestHandler(t)
+	handler.SetRateLimiter(NewRateLimiter(RateLimitSettings{
+		ResponsesPerSecond: 1,
+		Slip:               1,
+		IPv4PrefixLength:   24,
+		IPv6PrefixLength:   56,
+	}))
+
+	if resp := query(t, handler, "app.gslb.example.com.", dns.TypeA); resp.Truncated || len(resp.Answer) == 0 {
+		t.Fatalf("expected first response to be answered, got %v", resp)
+	}
+	resp := query(t, handler, "app.gslb.example.com.", dns.TypeA)
+	if !resp.Truncated || len(resp.Answer) != 0 {
+		t.Errorf("expected a truncated empty response over the limit, got %v", resp)
+	}
+
+	// TCP clients are never limited
+	req := new(dns.Msg)
+	req.SetQuestion("app.gslb.example.com.", dns.TypeA)
+	w := newTestResponseWriter()
+	w.remote = &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 53000}
+	handler.ServeDNS(w, req)
+	if w.msg == nil || w.msg.Truncated || len(w.msg.Answer) == 0 {
+		t.Errorf("expected TCP response to be answered, got %v", w.msg)
+	}
+
+	// Slipped replies to signed queries are signed
+	signedReq := new(dns.Msg)
+	signedReq.SetQuestion("app.gslb.example.com.", dns.TypeA)
+	signedReq.SetTsig("transfer-key.", dns.HmacSHA256, 300, time.Now().Unix())
+	w = newTestResponseWriter()
+	handler.ServeDNS(w, signedReq)
+	if w.msg == nil || !w.msg.Truncated || w.msg.IsTsig() == nil {
+		t.Errorf("expected a signed truncated response, got %v", w.msg)
+	}
+
+	// Dropped responses are not written, but logged
+	sink := &recordingSink{}
+	ql := querylog.New([]querylog.Sink{sink}, 16, nil)
+	handler.SetQueryLog(ql)
+	handler.SetRateLimiter(NewRateLimiter(RateLimitSettings{ResponsesPerSecond: 1, Slip: -1, IPv4PrefixLength: 24}))
+	query(t, handler, "app.gslb.example.com.", dns.TypeA)
+	w = newTestResponseWriter()
+	handler.ServeDNS(w, req)
+	if w.msg != nil {
+		t.Errorf("expected response to be dropped, got %v", w.msg)
+	}
+	if err := ql.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(sink.entries) != 2 {
+		t.Fatalf("expected 2 query log entries, got %d", len(sink.entries))
+	}
+	if e := sink.entries[1]; e.Rcode != statusDropped || e.Response != nil || e.QName != "app.gslb.example.com." {
+		t.Errorf("unexpected dropped entry: %s %s response %v", e.QName, e.Rcode, e.Response)
+	}
+}
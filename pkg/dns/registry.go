@@ -7,36 +7,79 @@
 package dns
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"net"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/loganrossus/OpenGSLB/pkg/config"
 	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
 )
 
 // RouterFactory is a function type that creates routers by algorithm name.
 type RouterFactory func(algorithm string) (routing.Router, error)
 
+// PipelineFactory is a function type that creates routing pipelines from
+// their stages.
+type PipelineFactory func(stages []routing.PipelineStage) (routing.Router, error)
+
 // Registry provides thread-safe lookup of domain configurations.
 type Registry struct {
 	mu      sync.RWMutex
 	domains map[string]*DomainEntry
+	records map[string][]dns.RR  // Static records indexed by lower-case owner name
+	views   map[string]*Registry // Split-horizon views, by view name
+	parent  *Registry            // For a view, the default registry answering domains it does not override
 }
 
 // NewRegistry creates a new empty registry.
 func NewRegistry() *Registry {
 	r := &Registry{
 		domains: make(map[string]*DomainEntry),
+		records: make(map[string][]dns.RR),
 	}
 	slog.Debug("DNS registry created", "registry_ptr", fmt.Sprintf("%p", r))
 	return r
 }
 
-// BuildRegistry creates a registry from configuration.
-func BuildRegistry(cfg *config.Config, routerFactory RouterFactory) (*Registry, error) {
+// BuildRegistry creates a registry from configuration, with a view registry
+// per configured split-horizon view. pipelineFactory creates the routers of
+// domains with a routing pipeline and may be nil when there are none.
+func BuildRegistry(cfg *config.Config, routerFactory RouterFactory, pipelineFactory PipelineFactory) (*Registry, error) {
+	entries, err := buildDomainEntries(cfg, cfg.Domains, routerFactory, pipelineFactory)
+	if err != nil {
+		return nil, err
+	}
 	registry := NewRegistry()
+	for _, entry := range entries {
+		registry.Register(entry)
+	}
+
+	views := make(map[string]*Registry, len(cfg.Views))
+	for _, view := range cfg.Views {
+		entries, err := buildDomainEntries(cfg, cfg.ViewDomains(view), routerFactory, pipelineFactory)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %w", view.Name, err)
+		}
+		for _, entry := range entries {
+			entry.View = view.Name
+		}
+		views[view.Name] = NewViewRegistry(registry, entries)
+	}
+	registry.SetViews(views)
+
+	return registry, nil
+}
+
+// buildDomainEntries creates the domain entries for domains, drawing their
+// servers from the configured regions.
+func buildDomainEntries(cfg *config.Config, domains []config.Domain, routerFactory RouterFactory, pipelineFactory PipelineFactory) ([]*DomainEntry, error) {
+	var entries []*DomainEntry
 
 	// v1.1.0: Build a map of (region, service) -> servers for filtered lookup
 	// This allows servers in the same region to be filtered by service
@@ -45,8 +88,10 @@ func BuildRegistry(cfg *config.Config, routerFactory RouterFactory) (*Registry,
 		service string
 	}
 	regionServiceServers := make(map[regionServiceKey][]ServerInfo)
+	regionPriority := make(map[string]int)
 
 	for _, region := range cfg.Regions {
+		regionPriority[region.Name] = region.Priority
 		for _, server := range region.Servers {
 			ip := net.ParseIP(server.Address)
 			if ip == nil {
@@ -58,25 +103,42 @@ func BuildRegistry(cfg *config.Config, routerFactory RouterFactory) (*Registry,
 				service: server.Service, // v1.1.0: Service is required
 			}
 
+			priority := server.Priority
+			if priority == 0 {
+				priority = region.Priority
+			}
 			regionServiceServers[key] = append(regionServiceServers[key], ServerInfo{
-				Address: ip,
-				Port:    server.Port,
-				Weight:  server.Weight,
-				Region:  region.Name,
+				Address:  ip,
+				Port:     server.Port,
+				Weight:   server.Weight,
+				Region:   region.Name,
+				Priority: priority,
+				Labels:   server.Labels,
 			})
 		}
 	}
 
 	// Build domain entries
-	for _, domain := range cfg.Domains {
-		router, err := routerFactory(domain.RoutingAlgorithm)
+	for _, domain := range domains {
+		router, err := newDomainRouter(domain, routerFactory, pipelineFactory)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create router for domain %s: %w", domain.Name, err)
 		}
+		var failoverPolicy *routing.FailoverPolicy
+		if domain.Failover != nil {
+			failoverPolicy = &routing.FailoverPolicy{
+				MinHealthy:       domain.Failover.MinHealthy,
+				FailbackHoldDown: domain.Failover.FailbackHoldDown,
+				ManualFailback:   domain.Failover.ManualFailback,
+			}
+		}
+		setFailoverPolicy(router, failoverPolicy)
 
 		// v1.1.0: Collect servers that match this domain's service name from specified regions
 		var servers []ServerInfo
+		priorities := make(map[string]int, len(domain.Regions))
 		for _, regionName := range domain.Regions {
+			priorities[regionName] = regionPriority[regionName]
 			key := regionServiceKey{
 				region:  regionName,
 				service: domain.Name, // Match servers where service == domain name
@@ -91,18 +153,108 @@ func BuildRegistry(cfg *config.Config, routerFactory RouterFactory) (*Registry,
 			ttl = uint32(cfg.DNS.DefaultTTL)
 		}
 
+		var sorryServers []net.IP
+		for _, addr := range domain.SorryServers {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid sorry server address for domain %s: %s", domain.Name, addr)
+			}
+			sorryServers = append(sorryServers, ip)
+		}
+
+		records, err := parseStaticRecords(domain.Name, domain.Records, ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		https, err := parseHTTPSParams(domain.Name, domain.HTTPS)
+		if err != nil {
+			return nil, err
+		}
+
+		var dns64 net.IP
+		if domain.DNS64.Enabled {
+			prefix := domain.DNS64.Prefix
+			if prefix == "" {
+				prefix = config.DefaultDNS64Prefix
+			}
+			if dns64, _, err = net.ParseCIDR(prefix); err != nil {
+				return nil, fmt.Errorf("invalid dns64 prefix for domain %s: %w", domain.Name, err)
+			}
+		}
+
 		entry := &DomainEntry{
 			Name:             domain.Name,
 			TTL:              ttl,
 			RoutingAlgorithm: domain.RoutingAlgorithm,
 			Router:           router,
 			Servers:          servers,
+			MaxAnswers:       domain.MaxAnswers,
+			SorryServers:     sorryServers,
+			Records:          records,
+			Regions:          domain.Regions,
+			RegionPriority:   priorities,
+			HTTPS:            https,
+			DNS64:            dns64,
+			PanicThreshold:   domain.PanicThreshold,
+			Split:            newTrafficSplit(domain.TrafficSplit),
+			FailoverPolicy:   failoverPolicy,
 		}
+		entries = append(entries, entry)
+	}
 
-		registry.Register(entry)
+	return entries, nil
+}
+
+// setFailoverPolicy applies a configured failover policy to a failover
+// router. Other routers and a nil policy are left unchanged.
+func setFailoverPolicy(router routing.Router, policy *routing.FailoverPolicy) {
+	if failover, ok := router.(*routing.FailoverRouter); ok && policy != nil {
+		failover.SetPolicy(*policy)
 	}
+}
 
-	return registry, nil
+// newTrafficSplit returns the traffic split of a domain, or nil when it
+// has none.
+func newTrafficSplit(splits []config.TrafficSplit) *routing.TrafficSplit {
+	if len(splits) == 0 {
+		return nil
+	}
+	rules := make([]routing.SplitRule, len(splits))
+	for i, split := range splits {
+		rules[i] = routing.SplitRule{Match: split.Match, Percent: split.Percent}
+	}
+	return routing.NewTrafficSplit(rules)
+}
+
+// newDomainRouter creates the router of a domain: its routing pipeline when
+// it has one, or the router of its routing algorithm.
+func newDomainRouter(domain config.Domain, routerFactory RouterFactory, pipelineFactory PipelineFactory) (routing.Router, error) {
+	if len(domain.RoutingPipeline) == 0 {
+		return routerFactory(domain.RoutingAlgorithm)
+	}
+	if pipelineFactory == nil {
+		return nil, fmt.Errorf("routing pipelines are not supported")
+	}
+
+	stages := make([]routing.PipelineStage, 0, len(domain.RoutingPipeline))
+	for _, stage := range domain.RoutingPipeline {
+		regions := stage.Regions
+		if len(regions) == 0 && strings.EqualFold(stage.Type, config.StageHealthTier) {
+			regions = domain.Regions
+		}
+		stages = append(stages, routing.PipelineStage{
+			Type:         stage.Type,
+			Regions:      regions,
+			MinHealthy:   stage.MinHealthy,
+			Source:       stage.Source,
+			MaxLatencyMs: stage.MaxLatencyMs,
+			ToleranceMs:  stage.ToleranceMs,
+			Match:        stage.Match,
+			Algorithm:    stage.Algorithm,
+		})
+	}
+	return pipelineFactory(stages)
 }
 
 // Register adds or updates a domain entry in the registry.
@@ -112,6 +264,7 @@ func (r *Registry) Register(entry *DomainEntry) {
 	name := normalizeDomain(entry.Name)
 	entry.Name = name
 	r.domains[name] = entry
+	r.records = indexRecords(r.domains)
 	// Log registry state after registration (INFO level for runtime visibility)
 	slog.Info("domain registered in DNS registry",
 		"name", name,
@@ -123,10 +276,22 @@ func (r *Registry) Register(entry *DomainEntry) {
 // Lookup retrieves a domain entry by name.
 // Returns nil if the domain is not registered.
 func (r *Registry) Lookup(name string) *DomainEntry {
+	entry, _ := r.LookupMatch(name)
+	return entry
+}
+
+// LookupMatch retrieves the domain entry for name. Exact entries win; otherwise
+// the most specific wildcard entry (*.apps.example.com) covering name is used,
+// and the labels it matched are returned ("acme" for acme.apps.example.com).
+// Returns nil if no entry matches.
+func (r *Registry) LookupMatch(name string) (*DomainEntry, string) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	normalizedName := normalizeDomain(name)
-	entry := r.domains[normalizedName]
+	entry, label := r.matchLocked(normalizedName)
+	if entry == nil && r.parent != nil {
+		return r.parent.LookupMatch(name)
+	}
 	// Log lookup details at appropriate level
 	if entry == nil {
 		// Log at INFO level when not found - helps diagnose issues
@@ -140,10 +305,80 @@ func (r *Registry) Lookup(name string) *DomainEntry {
 		slog.Debug("DNS registry lookup - found",
 			"query_name", name,
 			"normalized_name", normalizedName,
+			"wildcard_label", label,
 			"registry_ptr", fmt.Sprintf("%p", r),
 		)
 	}
-	return entry
+	return entry, label
+}
+
+// match is LookupMatch without logging.
+func (r *Registry) match(name string) (*DomainEntry, string) {
+	r.mu.RLock()
+	entry, label := r.matchLocked(normalizeDomain(name))
+	r.mu.RUnlock()
+	if entry == nil && r.parent != nil {
+		return r.parent.match(name)
+	}
+	return entry, label
+}
+
+// matchLocked returns the exact or wildcard entry for a normalized name.
+// Caller must hold r.mu.
+func (r *Registry) matchLocked(name string) (*DomainEntry, string) {
+	if entry := r.domains[name]; entry != nil {
+		return entry, ""
+	}
+	return r.lookupWildcard(name)
+}
+
+// lookupWildcard walks up from name to the root and returns the first
+// wildcard entry found, i.e. the one with the longest matching suffix.
+// Caller must hold r.mu.
+func (r *Registry) lookupWildcard(name string) (*DomainEntry, string) {
+	name = strings.ToLower(name)
+	labels := dns.SplitDomainName(name)
+	for i := 1; i < len(labels); i++ {
+		wildcard := "*." + strings.Join(labels[i:], ".") + "."
+		if entry, ok := r.domains[wildcard]; ok {
+			return entry, strings.Join(labels[:i], ".")
+		}
+	}
+	return nil, ""
+}
+
+// NewViewRegistry creates the registry of a split-horizon view holding the
+// domains the view overrides. Other names are looked up in parent.
+func NewViewRegistry(parent *Registry, entries []*DomainEntry) *Registry {
+	r := NewRegistry()
+	r.parent = parent
+	r.ReplaceAll(entries)
+	return r
+}
+
+// SetViews replaces the split-horizon views.
+func (r *Registry) SetViews(views map[string]*Registry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.views = views
+}
+
+// Views returns the split-horizon view registries by view name.
+func (r *Registry) Views() map[string]*Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	views := make(map[string]*Registry, len(r.views))
+	for name, view := range r.views {
+		views[name] = view
+	}
+	return views
+}
+
+// View returns the registry of a view, or nil if there is no such view.
+func (r *Registry) View(name string) *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.views[name]
 }
 
 // Remove deletes a domain from the registry.
@@ -151,6 +386,7 @@ func (r *Registry) Remove(name string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.domains, normalizeDomain(name))
+	r.records = indexRecords(r.domains)
 }
 
 // Domains returns a list of all registered domain names.
@@ -176,6 +412,7 @@ func (r *Registry) Clear() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.domains = make(map[string]*DomainEntry)
+	r.records = make(map[string][]dns.RR)
 }
 
 // ReplaceAll atomically replaces all domain entries in the registry.
@@ -187,8 +424,10 @@ func (r *Registry) ReplaceAll(entries []*DomainEntry) {
 		entry.Name = name
 		newDomains[name] = entry
 	}
+	newRecords := indexRecords(newDomains)
 	r.mu.Lock()
 	r.domains = newDomains
+	r.records = newRecords
 	r.mu.Unlock()
 }
 
@@ -198,6 +437,13 @@ func (r *Registry) ReplaceAll(entries []*DomainEntry) {
 // - A server is added via API
 // v1.1.0: Enables dynamic server registration for unified architecture
 func (r *Registry) RegisterServer(service string, address string, port int, weight int, region string) error {
+	// Views answer with servers of the regions they map the domain to
+	for _, view := range r.Views() {
+		if view.acceptsServer(service, region) {
+			_ = view.RegisterServer(service, address, port, weight, region)
+		}
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -215,10 +461,11 @@ func (r *Registry) RegisterServer(service string, address string, port int, weig
 
 	// Create server info
 	serverInfo := ServerInfo{
-		Address: ip,
-		Port:    port,
-		Weight:  weight,
-		Region:  region,
+		Address:  ip,
+		Port:     port,
+		Weight:   weight,
+		Region:   region,
+		Priority: entry.RegionPriority[region],
 	}
 
 	// Check if server already exists, update if so
@@ -227,6 +474,9 @@ func (r *Registry) RegisterServer(service string, address string, port int, weig
 	for i, existingServer := range entry.Servers {
 		existingKey := fmt.Sprintf("%s:%d", existingServer.Address.String(), existingServer.Port)
 		if existingKey == serverKey {
+			// Keep the priority and labels of configured servers
+			serverInfo.Priority = existingServer.Priority
+			serverInfo.Labels = existingServer.Labels
 			entry.Servers[i] = serverInfo
 			found = true
 			break
@@ -241,12 +491,58 @@ func (r *Registry) RegisterServer(service string, address string, port int, weig
 	return nil
 }
 
+// FailoverTiers returns the priority tiers of a failover domain: the
+// servers of each tier with the router's tier state, in priority order,
+// and the tier policy. Reports false for domains not routed by failover.
+func (r *Registry) FailoverTiers(name string) ([]routing.FailoverTier, routing.FailoverPolicy, bool) {
+	r.mu.RLock()
+	entry, exists := r.domains[normalizeDomain(name)]
+	var router *routing.FailoverRouter
+	servers := make(map[int]int)
+	if exists {
+		router, _ = entry.Router.(*routing.FailoverRouter)
+		for _, server := range entry.Servers {
+			servers[server.Priority]++
+		}
+	}
+	r.mu.RUnlock()
+	if router == nil {
+		return nil, routing.FailoverPolicy{}, false
+	}
+
+	tiers := router.Tiers()
+	for i := range tiers {
+		tiers[i].Servers = servers[tiers[i].Priority]
+		delete(servers, tiers[i].Priority)
+	}
+	// Tiers without healthy servers at the last routing decision
+	for priority, count := range servers {
+		tiers = append(tiers, routing.FailoverTier{Priority: priority, Servers: count})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Priority < tiers[j].Priority })
+	return tiers, router.Policy(), true
+}
+
+// acceptsServer reports whether the registry holds the domain named service
+// and draws its servers from region.
+func (r *Registry) acceptsServer(service, region string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.domains[normalizeDomain(service)]
+	return ok && (len(entry.Regions) == 0 || slices.Contains(entry.Regions, region))
+}
+
 // DeregisterServer removes a server from the DNS registry.
 // This is called when:
 // - An agent goes stale/deregisters
 // - A server is removed via API
 // v1.1.0: Enables dynamic server removal for unified architecture
 func (r *Registry) DeregisterServer(service string, address string, port int) error {
+	for _, view := range r.Views() {
+		// The server is not in views that do not use its region
+		_ = view.DeregisterServer(service, address, port)
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -274,6 +570,10 @@ func (r *Registry) DeregisterServer(service string, address string, port int) er
 // UpdateServerWeight updates the weight of an existing server.
 // v1.1.0: Enables dynamic weight adjustment
 func (r *Registry) UpdateServerWeight(service string, address string, port int, weight int) error {
+	for _, view := range r.Views() {
+		_ = view.UpdateServerWeight(service, address, port, weight)
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -296,6 +596,34 @@ func (r *Registry) UpdateServerWeight(service string, address string, port int,
 	return fmt.Errorf("server %s:%d not found in domain %q", address, port, service)
 }
 
+// SetServerLabels replaces the labels of an existing server, such as those
+// reported by the agent monitoring it.
+func (r *Registry) SetServerLabels(service string, address string, port int, labels map[string]string) error {
+	for _, view := range r.Views() {
+		_ = view.SetServerLabels(service, address, port, labels)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	domainName := normalizeDomain(service)
+	entry, exists := r.domains[domainName]
+	if !exists {
+		return fmt.Errorf("domain %q not configured", service)
+	}
+
+	serverKey := fmt.Sprintf("%s:%d", address, port)
+	for i, server := range entry.Servers {
+		existingKey := fmt.Sprintf("%s:%d", server.Address.String(), server.Port)
+		if existingKey == serverKey {
+			entry.Servers[i].Labels = labels
+			return nil
+		}
+	}
+
+	return fmt.Errorf("server %s:%d not found in domain %q", address, port, service)
+}
+
 // RegisterDomain creates and registers a new domain entry with the given parameters.
 // This is used for dynamic domain creation via API.
 // The domain is created with an empty server list; servers are added via RegisterServer.
@@ -337,7 +665,10 @@ func (r *Registry) RegisterDomainDynamic(name string, ttl uint32, algorithm stri
 }
 
 // UpdateDomainSettings updates a domain's TTL and routing algorithm while preserving servers.
-// This is used by the API layer when a domain is updated.
+// This is used by the API layer when a domain is updated. The router, with
+// its state such as failover tiers, is kept while the algorithm is unchanged,
+// and a domain with a routing pipeline keeps its pipeline, which is defined
+// by configuration.
 func (r *Registry) UpdateDomainSettings(name string, ttl uint32, algorithm string, routerFactory func(string) (interface{}, error)) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -348,19 +679,39 @@ func (r *Registry) UpdateDomainSettings(name string, ttl uint32, algorithm strin
 		return fmt.Errorf("domain %q not found in DNS registry", name)
 	}
 
-	// Create new router if algorithm changed
-	result, err := routerFactory(algorithm)
-	if err != nil {
-		return fmt.Errorf("failed to create router: %w", err)
+	if _, ok := existing.Router.(*routing.PipelineRouter); ok {
+		existing.TTL = ttl
+		if !strings.EqualFold(algorithm, existing.RoutingAlgorithm) {
+			slog.Warn("domain has a routing pipeline, keeping it instead of the requested algorithm",
+				"name", domainName,
+				"algorithm", algorithm,
+			)
+		}
+		slog.Info("domain settings updated in DNS registry",
+			"name", domainName,
+			"ttl", ttl,
+			"algorithm", existing.RoutingAlgorithm,
+		)
+		return nil
 	}
-	router, ok := result.(routing.Router)
-	if !ok {
-		return fmt.Errorf("router factory returned non-Router type: %T", result)
+
+	// Create new router if algorithm changed
+	if !strings.EqualFold(algorithm, existing.RoutingAlgorithm) || existing.Router == nil {
+		result, err := routerFactory(algorithm)
+		if err != nil {
+			return fmt.Errorf("failed to create router: %w", err)
+		}
+		router, ok := result.(routing.Router)
+		if !ok {
+			return fmt.Errorf("router factory returned non-Router type: %T", result)
+		}
+		setFailoverPolicy(router, existing.FailoverPolicy)
+		existing.Router = router
+		existing.RoutingAlgorithm = algorithm
 	}
 
 	// Update settings while preserving servers
 	existing.TTL = ttl
-	existing.Router = router
 
 	slog.Info("domain settings updated in DNS registry",
 		"name", domainName,
@@ -371,6 +722,128 @@ func (r *Registry) UpdateDomainSettings(name string, ttl uint32, algorithm strin
 	return nil
 }
 
+// SetStaticRecords replaces the static records of a domain.
+// Records without a TTL use the domain TTL.
+func (r *Registry) SetStaticRecords(name string, records []config.StaticRecord) error {
+	if err := config.ValidateStaticRecords(name, records); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	domainName := normalizeDomain(name)
+	entry, exists := r.domains[domainName]
+	if !exists {
+		return fmt.Errorf("domain %q not found in DNS registry", name)
+	}
+
+	rrs, err := parseStaticRecords(name, records, entry.TTL)
+	if err != nil {
+		return err
+	}
+	entry.Records = rrs
+	r.records = indexRecords(r.domains)
+
+	slog.Info("static records updated in DNS registry",
+		"name", domainName,
+		"records", len(rrs),
+	)
+	return nil
+}
+
+// StaticRecords returns the static records owned by name.
+// Names without records of their own and no exact domain entry use the
+// records of the most specific covering wildcard owner.
+func (r *Registry) StaticRecords(name string) []dns.RR {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name = strings.ToLower(normalizeDomain(name))
+	if records, ok := r.records[name]; ok {
+		return records
+	}
+	if _, ok := r.domains[name]; ok {
+		return nil
+	}
+	labels := dns.SplitDomainName(name)
+	for i := 1; i < len(labels); i++ {
+		if records, ok := r.records["*."+strings.Join(labels[i:], ".")+"."]; ok {
+			return records
+		}
+	}
+	if r.parent != nil {
+		return r.parent.StaticRecords(name)
+	}
+	return nil
+}
+
+// AllStaticRecords returns the static records of every domain.
+func (r *Registry) AllStaticRecords() []dns.RR {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var records []dns.RR
+	for _, rrs := range r.records {
+		records = append(records, rrs...)
+	}
+	return records
+}
+
+// HasStaticName reports whether static records exist at or below name.
+// Names that only have records below them are empty non-terminals (RFC 8020).
+func (r *Registry) HasStaticName(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name = strings.ToLower(normalizeDomain(name))
+	for owner := range r.records {
+		if dns.IsSubDomain(name, owner) {
+			return true
+		}
+	}
+	return r.parent != nil && r.parent.HasStaticName(name)
+}
+
+// parseStaticRecords converts configured static records into resource records.
+func parseStaticRecords(domain string, records []config.StaticRecord, ttl uint32) ([]dns.RR, error) {
+	rrs := make([]dns.RR, 0, len(records))
+	for i, record := range records {
+		rr, err := record.RR(domain, int(ttl))
+		if err != nil {
+			return nil, fmt.Errorf("invalid record %d for domain %s: %w", i, domain, err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+// parseHTTPSParams converts a domain's HTTPS record configuration.
+// Returns nil when the domain publishes no HTTPS records.
+func parseHTTPSParams(domain string, cfg *config.HTTPSRecordConfig) (*HTTPSParams, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	params := &HTTPSParams{ALPN: cfg.ALPN, Port: uint16(cfg.Port)}
+	if cfg.ECH != "" {
+		ech, err := base64.StdEncoding.DecodeString(cfg.ECH)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ech for domain %s: %w", domain, err)
+		}
+		params.ECH = ech
+	}
+	return params, nil
+}
+
+// indexRecords builds the owner name index of the domains' static records.
+func indexRecords(domains map[string]*DomainEntry) map[string][]dns.RR {
+	index := make(map[string][]dns.RR)
+	for _, entry := range domains {
+		for _, rr := range entry.Records {
+			owner := strings.ToLower(rr.Header().Name)
+			index[owner] = append(index[owner], rr)
+		}
+	}
+	return index
+}
+
 // normalizeDomain ensures domain names are in a consistent format.
 func normalizeDomain(name string) string {
 	if len(name) == 0 {
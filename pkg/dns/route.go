@@ -0,0 +1,148 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// ErrUnknownView is returned by Route for a view that is not configured.
+var ErrUnknownView = errors.New("unknown view")
+
+// Routing decisions reported by Route.
+const (
+	RouteSuccess          = "success"
+	RouteNoHealthyBackend = "no_healthy_backend"
+	RouteDomainNotFound   = "domain_not_found"
+)
+
+// RouteResult is the outcome of a simulated query.
+type RouteResult struct {
+	View      string
+	Domain    string
+	Algorithm string
+	Decision  string
+	TTL       uint32
+	Selected  []*routing.Server // Most preferred first
+
+	// Stages shows how each stage of a routing pipeline narrowed the pool;
+	// empty for domains routed by a single algorithm
+	Stages []routing.StageTrace
+}
+
+// Route simulates the routing of an A or AAAA query for name from clientIP,
+// as used by the routing test API. An empty view selects the view by client
+// address, as for a query; otherwise the named view is used. Simulating has
+// no effect on routing state, metrics or panic mode.
+func (h *Handler) Route(name string, qtype uint16, clientIP net.IP, view string) (*RouteResult, error) {
+	if qtype != dns.TypeA && qtype != dns.TypeAAAA {
+		return nil, fmt.Errorf("unsupported query type %s", dns.TypeToString[qtype])
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var reg *Registry
+	switch view {
+	case "":
+		view, reg = h.matchView("", clientIP)
+	case config.DefaultViewName:
+		reg = h.registry
+	default:
+		if h.registry != nil {
+			reg = h.registry.View(view)
+		}
+		if reg == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownView, view)
+		}
+	}
+
+	result := &RouteResult{View: view, Domain: name, Decision: RouteDomainNotFound}
+	if reg == nil {
+		return result, nil
+	}
+	entry, label := reg.LookupMatch(name)
+	if entry == nil {
+		return result, nil
+	}
+	result.Domain = entry.Name
+	result.Algorithm = entry.Router.Algorithm()
+	result.TTL = entry.TTL
+	if result.TTL == 0 {
+		result.TTL = h.defaultTTL
+	}
+
+	servers := h.getHealthyIPv4Servers(entry)
+	if qtype == dns.TypeAAAA {
+		servers = h.getHealthyIPv6Servers(entry)
+	}
+	if len(servers) == 0 {
+		result.Decision = RouteNoHealthyBackend
+		return result, nil
+	}
+
+	// A dry run ranks as a query would without moving rotations, failover
+	// tiers or metrics, so testing cannot change what clients are answered
+	trace := &routing.PipelineTrace{}
+	ctx := routing.WithPipelineTrace(routing.WithDryRun(h.routingContext(entry, clientIP, label)), trace)
+	selected, err := h.selectServers(ctx, entry, routing.NewSimpleServerPool(servers))
+	if err != nil {
+		return nil, fmt.Errorf("routing failed for %s: %w", entry.Name, err)
+	}
+	result.Decision = RouteSuccess
+	result.Selected = selected
+	result.Stages = trace.Stages
+	return result, nil
+}
+
+// answeredDomain returns the GSLB domain a response was routed for and the
+// backend address it answered with: the first address record or HTTPS hint
+// of the answer, or the first address record of the additional section for
+// SRV responses. Returns nil for responses not answered by routing, such as
+// static records and negative answers.
+func answeredDomain(reg *Registry, resp *dns.Msg, q dns.Question) (*DomainEntry, net.IP) {
+	if reg == nil || resp.Rcode != dns.RcodeSuccess {
+		return nil, nil
+	}
+	name := q.Name
+	records := resp.Answer
+	if q.Qtype == dns.TypeSRV {
+		// _service._proto.<domain>
+		labels := dns.SplitDomainName(name)
+		if len(labels) < 3 {
+			return nil, nil
+		}
+		name = strings.Join(labels[2:], ".")
+		records = resp.Extra
+	}
+
+	var backend net.IP
+	for _, rr := range records {
+		if backend = addressOf(rr); backend != nil {
+			break
+		}
+		if backend = hintOf(rr); backend != nil {
+			break
+		}
+	}
+	if backend == nil {
+		return nil, nil
+	}
+	entry, _ := reg.match(name)
+	if entry == nil {
+		// Static address records are not routed
+		return nil, nil
+	}
+	return entry, backend
+}
@@ -23,20 +23,33 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/loganrossus/OpenGSLB/pkg/config"
 	"github.com/miekg/dns"
 )
 
-// Server is the DNS server that handles UDP and TCP queries.
+// Server is the DNS server that handles UDP and TCP queries, and optionally
+// DNS-over-TLS and DNS-over-HTTPS queries.
 type Server struct {
 	udpServer *dns.Server
 	tcpServer *dns.Server
+	dotServer *dns.Server
+	dohServer *http.Server
 	handler   *Handler
 	address   string
 	logger    *slog.Logger
 
+	dotAddress string
+	dohAddress string
+	dohPath    string
+	certFile   string
+	keyFile    string
+	certs      *certificateStore
+	tsig       dns.TsigProvider
+
 	mu      sync.Mutex
 	running bool
 }
@@ -46,6 +59,19 @@ type ServerConfig struct {
 	Address string
 	Handler *Handler
 	Logger  *slog.Logger
+
+	// DoTAddress enables the DNS-over-TLS listener when set
+	DoTAddress string
+	// DoHAddress enables the DNS-over-HTTPS listener when set
+	DoHAddress string
+	// DoHPath is the URL path DoH queries are served on (default "/dns-query")
+	DoHPath string
+	// CertFile and KeyFile hold the certificate of the DoT and DoH listeners
+	CertFile string
+	KeyFile  string
+
+	// Keyring verifies and signs TSIG messages; nil disables TSIG
+	Keyring *Keyring
 }
 
 // NewServer creates a new DNS server.
@@ -55,11 +81,28 @@ func NewServer(cfg ServerConfig) *Server {
 		logger = slog.Default()
 	}
 
-	return &Server{
-		handler: cfg.Handler,
-		address: cfg.Address,
-		logger:  logger,
+	dohPath := cfg.DoHPath
+	if dohPath == "" {
+		dohPath = config.DefaultDoHPath
+	}
+
+	s := &Server{
+		handler:    cfg.Handler,
+		address:    cfg.Address,
+		logger:     logger,
+		dotAddress: cfg.DoTAddress,
+		dohAddress: cfg.DoHAddress,
+		dohPath:    dohPath,
+		certFile:   cfg.CertFile,
+		keyFile:    cfg.KeyFile,
+	}
+	if s.dotAddress != "" || s.dohAddress != "" {
+		s.certs = &certificateStore{}
+	}
+	if cfg.Keyring != nil {
+		s.tsig = cfg.Keyring
 	}
+	return s
 }
 
 // Start begins listening for DNS queries on both UDP and TCP.
@@ -70,6 +113,12 @@ func (s *Server) Start(ctx context.Context) error {
 		s.mu.Unlock()
 		return errors.New("server already running")
 	}
+	if s.certs != nil {
+		if err := s.certs.load(s.certFile, s.keyFile); err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("encrypted DNS listeners: %w", err)
+		}
+	}
 	s.running = true
 	s.mu.Unlock()
 
@@ -79,19 +128,23 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Create UDP server
 	s.udpServer = &dns.Server{
-		Addr:    s.address,
-		Net:     "udp",
-		Handler: s.handler,
+		Addr:          s.address,
+		Net:           "udp",
+		Handler:       transportHandler{transport: transportUDP, handler: s.handler},
+		TsigProvider:  s.tsig,
+		MsgAcceptFunc: acceptMsg,
 	}
 
 	// Create TCP server
 	s.tcpServer = &dns.Server{
-		Addr:    s.address,
-		Net:     "tcp",
-		Handler: s.handler,
+		Addr:          s.address,
+		Net:           "tcp",
+		Handler:       transportHandler{transport: transportTCP, handler: s.handler},
+		TsigProvider:  s.tsig,
+		MsgAcceptFunc: acceptMsg,
 	}
 
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 4)
 
 	// Start UDP listener
 	go func() {
@@ -109,6 +162,45 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
+	// Start DNS-over-TLS listener
+	if s.dotAddress != "" {
+		s.dotServer = &dns.Server{
+			Addr:          s.dotAddress,
+			Net:           "tcp-tls",
+			TLSConfig:     s.certs.tlsConfig("dot"),
+			Handler:       transportHandler{transport: transportDoT, handler: s.handler},
+			TsigProvider:  s.tsig,
+			MsgAcceptFunc: acceptMsg,
+		}
+		go func() {
+			s.logger.Info("starting DoT listener", "address", s.dotAddress)
+			if err := s.dotServer.ListenAndServe(); err != nil {
+				errChan <- fmt.Errorf("DoT server error: %w", err)
+			}
+		}()
+	}
+
+	// Start DNS-over-HTTPS listener
+	if s.dohAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle(s.dohPath, &dohHandler{
+			handler: transportHandler{transport: transportDoH, handler: s.handler},
+			logger:  s.logger,
+		})
+		s.dohServer = &http.Server{
+			Addr:              s.dohAddress,
+			Handler:           mux,
+			TLSConfig:         s.certs.tlsConfig("h2", "http/1.1"),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			s.logger.Info("starting DoH listener", "address", s.dohAddress, "path", s.dohPath)
+			if err := s.dohServer.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errChan <- fmt.Errorf("DoH server error: %w", err)
+			}
+		}()
+	}
+
 	// Wait for context cancellation or server error
 	select {
 	case <-ctx.Done():
@@ -152,6 +244,18 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Shutdown encrypted listeners
+	if s.dotServer != nil {
+		if err := s.dotServer.ShutdownContext(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("DoT shutdown error: %w", err))
+		}
+	}
+	if s.dohServer != nil {
+		if err := s.dohServer.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("DoH shutdown error: %w", err))
+		}
+	}
+
 	s.running = false
 	s.logger.Info("DNS server stopped")
 
@@ -161,6 +265,21 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// ReloadCertificate re-reads the certificate of the DoT and DoH listeners.
+// Empty paths keep the current files. New connections use the new
+// certificate; no listener is restarted. A no-op when neither listener is
+// configured.
+func (s *Server) ReloadCertificate(certFile, keyFile string) error {
+	if s.certs == nil {
+		return nil
+	}
+	if err := s.certs.load(certFile, keyFile); err != nil {
+		return err
+	}
+	s.logger.Info("reloaded DNS TLS certificate")
+	return nil
+}
+
 // IsRunning returns whether the server is currently running.
 func (s *Server) IsRunning() bool {
 	s.mu.Lock()
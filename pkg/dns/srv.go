@@ -0,0 +1,214 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"context"
+	"math"
+	"net"
+	"strings"
+
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// srvTargetPrefix starts the synthesized target label of a backend,
+// e.g. "ip-10-0-1-10.app.example.com." or "ip-2001-db8--1.app.example.com.".
+const srvTargetPrefix = "ip-"
+
+// loadSharingAlgorithms spread traffic across equal servers rather than
+// ordering them. Their SRV records share one priority so clients split load
+// by weight; other algorithms map their ranking onto increasing priorities.
+var loadSharingAlgorithms = map[string]bool{
+	routing.AlgorithmRoundRobin: true,
+	routing.AlgorithmWeighted:   true,
+}
+
+// handleSRVQuery answers SRV queries for _service._proto.<domain> from the
+// domain's healthy backends, narrowed to the client's traffic split group.
+// Static SRV records take precedence.
+func (h *Handler) handleSRVQuery(reg *Registry, m *dns.Msg, q dns.Question, clientIP net.IP) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.answerStaticRecords(reg, m, q) {
+		return
+	}
+
+	entry, domain, label := h.srvDomain(reg, q.Name)
+	if entry == nil {
+		h.answerMissingType(reg, m, q)
+		return
+	}
+
+	servers := append(h.routingServers(entry, "ipv4"), h.routingServers(entry, "ipv6")...)
+	if len(servers) == 0 {
+		if len(entry.Servers) == 0 {
+			h.logger.Debug("no servers for SRV query, returning NODATA", "name", q.Name)
+			return
+		}
+		h.logger.Debug("no healthy servers for SRV query", "name", q.Name)
+		m.SetRcode(m, dns.RcodeServerFailure)
+		return
+	}
+
+	ctx := h.routingContext(entry, clientIP, label)
+	ranked, err := entry.Router.Rank(ctx, h.splitPool(ctx, entry, routing.NewSimpleServerPool(servers)))
+	if err == nil && len(ranked) == 0 {
+		err = routing.ErrNoHealthyServers
+	}
+	if err != nil {
+		h.logger.Error("routing failed", "domain", q.Name, "error", err)
+		m.SetRcode(m, dns.RcodeServerFailure)
+		return
+	}
+	if entry.MaxAnswers > 1 && len(ranked) > entry.MaxAnswers {
+		ranked = ranked[:entry.MaxAnswers]
+	}
+
+	loadSharing := loadSharingAlgorithms[entry.Router.Algorithm()]
+	for i, server := range ranked {
+		ip := net.ParseIP(server.Address)
+		if ip == nil {
+			h.logger.Error("invalid IP address", "address", server.Address)
+			continue
+		}
+
+		var priority uint16
+		if !loadSharing {
+			priority = uint16(min(i, math.MaxUint16))
+		}
+		target := srvTargetName(ip, domain)
+
+		m.Answer = append(m.Answer, &dns.SRV{
+			Hdr: dns.RR_Header{
+				Name:   q.Name,
+				Rrtype: dns.TypeSRV,
+				Class:  dns.ClassINET,
+				Ttl:    h.ttlFor(entry),
+			},
+			Priority: priority,
+			Weight:   uint16(max(0, min(server.Weight, math.MaxUint16))),
+			Port:     uint16(server.Port),
+			Target:   target,
+		})
+		m.Extra = append(m.Extra, addressRecord(target, ip, h.ttlFor(entry)))
+	}
+	metrics.RecordRoutingDecision(entry.Name, entry.Router.Algorithm(), ranked[0].Address)
+
+	h.logger.Debug("resolved SRV query",
+		"name", q.Name,
+		"answers", len(m.Answer),
+		"algorithm", entry.Router.Algorithm(),
+	)
+}
+
+// srvDomain returns the domain entry for an SRV owner name of the form
+// _service._proto.<domain>, along with <domain> and the labels matched by a
+// wildcard entry. Returns a nil entry if qname is not such a name.
+// Caller must hold h.mu.
+func (h *Handler) srvDomain(reg *Registry, qname string) (*DomainEntry, string, string) {
+	labels := dns.SplitDomainName(qname)
+	if len(labels) < 3 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+		return nil, "", ""
+	}
+	if reg == nil {
+		return nil, "", ""
+	}
+	domain := strings.Join(labels[2:], ".")
+	entry, label := reg.LookupMatch(domain)
+	return entry, domain, label
+}
+
+// answerSRVTarget answers A/AAAA queries for the synthesized SRV target name
+// of a configured backend. Reports whether qname is such a name; the answer is
+// empty (NODATA) when the backend's address family does not match.
+// Caller must hold h.mu.
+func (h *Handler) answerSRVTarget(reg *Registry, m *dns.Msg, q dns.Question) bool {
+	entry, ip := h.srvTarget(reg, q.Name)
+	if entry == nil {
+		return false
+	}
+	if (ip.To4() != nil) == (q.Qtype == dns.TypeA) {
+		rr := addressRecord(q.Name, ip, h.ttlFor(entry))
+		m.Answer = append(m.Answer, rr)
+	}
+	return true
+}
+
+// srvTarget resolves a synthesized SRV target name to its domain entry and
+// backend address. Returns nil if the name is not a target of a configured
+// backend.
+// Caller must hold h.mu.
+func (h *Handler) srvTarget(reg *Registry, qname string) (*DomainEntry, net.IP) {
+	label, parent, ok := strings.Cut(strings.ToLower(qname), ".")
+	if !ok || !strings.HasPrefix(label, srvTargetPrefix) || reg == nil {
+		return nil, nil
+	}
+
+	addr := strings.TrimPrefix(label, srvTargetPrefix)
+	var ip net.IP
+	if strings.Count(addr, "-") == 3 {
+		ip = net.ParseIP(strings.ReplaceAll(addr, "-", "."))
+	}
+	if ip == nil {
+		ip = net.ParseIP(strings.ReplaceAll(addr, "-", ":"))
+	}
+	if ip == nil {
+		return nil, nil
+	}
+
+	entry := reg.Lookup(parent)
+	if entry == nil {
+		return nil, nil
+	}
+	for _, server := range entry.Servers {
+		if server.Address.Equal(ip) {
+			return entry, ip
+		}
+	}
+	return nil, nil
+}
+
+// srvTargetName returns the synthesized SRV target name of a backend address.
+func srvTargetName(ip net.IP, domain string) string {
+	var label string
+	if ip4 := ip.To4(); ip4 != nil {
+		label = strings.ReplaceAll(ip4.String(), ".", "-")
+	} else {
+		label = strings.ReplaceAll(ip.String(), ":", "-")
+	}
+	return srvTargetPrefix + label + "." + normalizeDomain(strings.ToLower(domain))
+}
+
+// ttlFor returns the TTL for an entry's records.
+func (h *Handler) ttlFor(entry *DomainEntry) uint32 {
+	if entry.TTL == 0 {
+		return h.defaultTTL
+	}
+	return entry.TTL
+}
+
+// routingContext returns the context passed to routers: the client IP for
+// geolocation routing, the domain name for learned latency routing (ADR-017),
+// the labels matched by a wildcard domain and the address families in panic
+// mode.
+func (h *Handler) routingContext(entry *DomainEntry, clientIP net.IP, wildcardLabel string) context.Context {
+	ctx := context.Background()
+	if families := h.panicFamilies(entry); len(families) > 0 {
+		ctx = routing.WithPanic(ctx, families...)
+	}
+	if clientIP != nil {
+		ctx = routing.WithClientIP(ctx, clientIP)
+	}
+	if wildcardLabel != "" {
+		ctx = routing.WithWildcardLabel(ctx, wildcardLabel)
+	}
+	// Use entry.Name which is normalized, strip trailing dot for service name format
+	return routing.WithDomain(ctx, strings.TrimSuffix(entry.Name, "."))
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+func newSRVTestHandler(router routing.Router) (*Handler, *mockHealthProvider) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "grpc.example.com",
+		TTL:    30,
+		Router: router,
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 50051, Weight: 100},
+			{Address: net.ParseIP("10.0.0.2"), Port: 50052, Weight: 50},
+			{Address: net.ParseIP("2001:db8::1"), Port: 50053, Weight: 10},
+		},
+	})
+
+	health := newMockHealthProvider()
+	return NewHandler(HandlerConfig{
+		Registry:       registry,
+		HealthProvider: health,
+		DefaultTTL:     60,
+	}), health
+}
+
+func TestHandler_SRVFromBackends(t *testing.T) {
+	handler, health := newSRVTestHandler(routing.NewFailoverRouter())
+	health.SetHealthy("10.0.0.2", false)
+
+	resp := query(t, handler, "_grpc._tcp.grpc.example.com.", dns.TypeSRV)
+	if resp.Rcode != dns.RcodeSuccess {
+		t.Fatalf("expected NOERROR, got %s", dns.RcodeToString[resp.Rcode])
+	}
+	if len(resp.Answer) != 2 {
+		t.Fatalf("expected 2 SRV records for healthy backends, got %d", len(resp.Answer))
+	}
+
+	first := resp.Answer[0].(*dns.SRV)
+	second := resp.Answer[1].(*dns.SRV)
+	if first.Port != 50051 || first.Target != "ip-10-0-0-1.grpc.example.com." {
+		t.Errorf("unexpected first SRV: %v", first)
+	}
+	if second.Port != 50053 || second.Target != "ip-2001-db8--1.grpc.example.com." {
+		t.Errorf("unexpected second SRV: %v", second)
+	}
+	// Failover ranks servers, so priorities follow the ranking
+	if first.Priority != 0 || second.Priority != 1 {
+		t.Errorf("expected priorities 0 and 1, got %d and %d", first.Priority, second.Priority)
+	}
+	if first.Weight != 100 || second.Weight != 10 {
+		t.Errorf("expected backend weights, got %d and %d", first.Weight, second.Weight)
+	}
+	if first.Hdr.Ttl != 30 {
+		t.Errorf("expected domain TTL 30, got %d", first.Hdr.Ttl)
+	}
+
+	if len(resp.Extra) != 2 {
+		t.Fatalf("expected 2 additional records, got %d", len(resp.Extra))
+	}
+	if a, ok := resp.Extra[0].(*dns.A); !ok || a.Hdr.Name != first.Target || !a.A.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("unexpected additional A record: %v", resp.Extra[0])
+	}
+	if aaaa, ok := resp.Extra[1].(*dns.AAAA); !ok || aaaa.Hdr.Name != second.Target {
+		t.Errorf("unexpected additional AAAA record: %v", resp.Extra[1])
+	}
+}
+
+func TestHandler_SRVLoadSharingPriority(t *testing.T) {
+	handler, _ := newSRVTestHandler(routing.NewWeightedRouter())
+
+	resp := query(t, handler, "_grpc._tcp.grpc.example.com.", dns.TypeSRV)
+	if len(resp.Answer) != 3 {
+		t.Fatalf("expected 3 SRV records, got %d", len(resp.Answer))
+	}
+	for _, rr := range resp.Answer {
+		if srv := rr.(*dns.SRV); srv.Priority != 0 {
+			t.Errorf("expected shared priority 0 for weighted routing, got %d", srv.Priority)
+		}
+	}
+}
+
+func TestHandler_SRVAllUnhealthy(t *testing.T) {
+	handler, health := newSRVTestHandler(routing.NewFailoverRouter())
+	health.SetHealthy("10.0.0.1", false)
+	health.SetHealthy("10.0.0.2", false)
+	health.SetHealthy("2001:db8::1", false)
+
+	resp := query(t, handler, "_grpc._tcp.grpc.example.com.", dns.TypeSRV)
+	if resp.Rcode != dns.RcodeServerFailure {
+		t.Errorf("expected SERVFAIL, got %s", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestHandler_SRVTargetNames(t *testing.T) {
+	handler, _ := newSRVTestHandler(routing.NewFailoverRouter())
+
+	tests := []struct {
+		name    string
+		qname   string
+		qtype   uint16
+		rcode   int
+		answers int
+	}{
+		{"IPv4 target A", "ip-10-0-0-2.grpc.example.com.", dns.TypeA, dns.RcodeSuccess, 1},
+		{"IPv4 target AAAA is NODATA", "ip-10-0-0-2.grpc.example.com.", dns.TypeAAAA, dns.RcodeSuccess, 0},
+		{"IPv6 target AAAA", "ip-2001-db8--1.grpc.example.com.", dns.TypeAAAA, dns.RcodeSuccess, 1},
+		{"unknown backend is NXDOMAIN", "ip-10-9-9-9.grpc.example.com.", dns.TypeA, dns.RcodeNameError, 0},
+		{"SRV owner TXT is NODATA", "_grpc._tcp.grpc.example.com.", dns.TypeTXT, dns.RcodeSuccess, 0},
+		{"SRV for unknown domain is NXDOMAIN", "_grpc._tcp.other.example.com.", dns.TypeSRV, dns.RcodeNameError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, handler, tt.qname, tt.qtype)
+			if resp.Rcode != tt.rcode {
+				t.Fatalf("expected %s, got %s", dns.RcodeToString[tt.rcode], dns.RcodeToString[resp.Rcode])
+			}
+			if len(resp.Answer) != tt.answers {
+				t.Errorf("expected %d answers, got %d", tt.answers, len(resp.Answer))
+			}
+		})
+	}
+}
+
+func TestHandler_StaticSRVTakesPrecedence(t *testing.T) {
+	handler, _ := newSRVTestHandler(routing.NewFailoverRouter())
+	err := handler.registry.SetStaticRecords("grpc.example.com", []config.StaticRecord{
+		{Name: "_sip._udp", Type: "SRV", Value: "5 5 5060 sip.example.net."},
+	})
+	if err != nil {
+		t.Fatalf("failed to set static records: %v", err)
+	}
+
+	resp := query(t, handler, "_sip._udp.grpc.example.com.", dns.TypeSRV)
+	if len(resp.Answer) != 1 || resp.Answer[0].(*dns.SRV).Target != "sip.example.net." {
+		t.Errorf("expected static SRV record, got %v", resp.Answer)
+	}
+}
+
+func TestHandler_SRVTrafficSplit(t *testing.T) {
+	cfg := newViewTestConfig()
+	cfg.Views = nil
+	cfg.Regions[0].Servers = append(cfg.Regions[0].Servers, config.Server{
+		Address: "203.0.113.11", Port: 80, Weight: 100, Service: "app.example.com",
+		Labels: map[string]string{"version": "v2"},
+	})
+	cfg.Domains[0].TrafficSplit = []config.TrafficSplit{{Match: map[string]string{"version": "v2"}, Percent: 50}}
+
+	registry, err := BuildRegistry(cfg, mockRouterFactory, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+	handler := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60})
+
+	targets := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		req := new(dns.Msg)
+		req.SetQuestion("_http._tcp.app.example.com.", dns.TypeSRV)
+		w := newTestResponseWriter()
+		w.remote = &net.UDPAddr{IP: net.IPv4(198, 51, byte(i), 1), Port: 53000}
+		handler.ServeDNS(w, req)
+		if w.msg == nil || w.msg.Rcode != dns.RcodeSuccess {
+			t.Fatalf("expected NOERROR, got %v", w.msg)
+		}
+		// The split narrows the answers to the client's group
+		if len(w.msg.Answer) != 1 {
+			t.Fatalf("expected 1 SRV record for the client's group, got %d", len(w.msg.Answer))
+		}
+		targets[w.msg.Answer[0].(*dns.SRV).Target]++
+	}
+	if targets["ip-203-0-113-10.app.example.com."] == 0 || targets["ip-203-0-113-11.app.example.com."] == 0 {
+		t.Errorf("expected clients in both groups, got %v", targets)
+	}
+}
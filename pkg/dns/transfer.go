@@ -0,0 +1,408 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/miekg/dns"
+)
+
+const (
+	// transferChunkSize is the number of records per zone transfer message.
+	transferChunkSize = 100
+
+	// defaultZoneCheckInterval is used by WatchZones when no interval is set.
+	defaultZoneCheckInterval = 5 * time.Second
+
+	// notifyTimeout and notifyAttempts bound how long a secondary is retried.
+	notifyTimeout  = 2 * time.Second
+	notifyAttempts = 3
+)
+
+// TransferSettings controls outbound zone transfers and NOTIFY.
+type TransferSettings struct {
+	AllowFrom []*net.IPNet // Clients allowed to transfer without TSIG
+	Keys      []string     // Fully qualified TSIG key names allowed from any address
+	Notify    []string     // Secondaries (ip:port) notified when a zone changes
+}
+
+// BuildTransferSettings creates the transfer settings from dns.transfer.
+func BuildTransferSettings(cfg *config.Config) (*TransferSettings, error) {
+	t := cfg.DNS.Transfer
+	settings := &TransferSettings{}
+	for _, entry := range t.AllowFrom {
+		network, err := config.ParseNetwork(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transfer allow_from entry: %w", err)
+		}
+		settings.AllowFrom = append(settings.AllowFrom, network)
+	}
+	for _, name := range t.TSIGKeys {
+		settings.Keys = append(settings.Keys, dns.Fqdn(strings.ToLower(name)))
+	}
+	for _, addr := range t.Notify {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "53")
+		}
+		settings.Notify = append(settings.Notify, addr)
+	}
+	return settings, nil
+}
+
+// zoneState is the zone contents and serial last seen by UpdateZoneSerials.
+type zoneState struct {
+	digest uint64
+	serial uint32
+}
+
+// SetTransfer replaces the zone transfer settings. nil refuses all transfers.
+func (h *Handler) SetTransfer(settings *TransferSettings) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.transfer = settings
+}
+
+// serveTransfer answers AXFR and IXFR queries with the contents of a zone
+// (RFC 5936). There is no journal, so IXFR is answered with the full zone
+// unless the secondary is already current (RFC 1995 section 4). Returns the
+// reply written; for a streamed transfer, a reply carrying its rcode and no
+// records.
+func (h *Handler) serveTransfer(w dns.ResponseWriter, r *dns.Msg, start time.Time) *dns.Msg {
+	q := r.Question[0]
+	qtype := dns.TypeToString[q.Qtype]
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+
+	h.mu.RLock()
+	zone := findZone(h.zones, q.Name)
+	if zone == nil || !zone.IsApex(q.Name) {
+		h.mu.RUnlock()
+		h.logger.Debug("zone transfer for a name that is not a zone apex", "name", q.Name, "type", qtype)
+		m.SetRcode(m, dns.RcodeNotAuth)
+		h.writeResponse(w, m, start, "NOTAUTH", q.Name)
+		return m
+	}
+	if !h.transferAllowed(w, r) {
+		h.mu.RUnlock()
+		h.logger.Warn("zone transfer refused",
+			"zone", zone.Name,
+			"type", qtype,
+			"client", w.RemoteAddr().String(),
+		)
+		metrics.RecordDNSZoneTransfer(zone.Name, qtype, "refused")
+		m.SetRcode(m, dns.RcodeRefused)
+		h.writeResponse(w, m, start, "REFUSED", zone.Name)
+		return m
+	}
+
+	soa := zone.SOARecord()
+	_, overUDP := w.RemoteAddr().(*net.UDPAddr)
+	if q.Qtype == dns.TypeIXFR && (overUDP || ixfrCurrent(r, soa.Serial)) {
+		// The secondary is current, or must retry over TCP (RFC 1995 section 2)
+		h.mu.RUnlock()
+		m.Authoritative = true
+		m.Answer = []dns.RR{soa}
+		signReply(m, r)
+		metrics.RecordDNSZoneTransfer(zone.Name, qtype, "current")
+		h.writeResponse(w, m, start, "NOERROR", zone.Name)
+		return m
+	}
+	if overUDP {
+		h.mu.RUnlock()
+		h.logger.Debug("AXFR over UDP refused", "zone", zone.Name)
+		m.SetRcode(m, dns.RcodeRefused)
+		h.writeResponse(w, m, start, "REFUSED", zone.Name)
+		return m
+	}
+	records := h.zoneRecords(zone)
+	h.mu.RUnlock()
+
+	all := make([]dns.RR, 0, len(records)+2)
+	all = append(all, soa)
+	all = append(all, records...)
+	all = append(all, soa)
+
+	ch := make(chan *dns.Envelope)
+	go func() {
+		defer close(ch)
+		for i := 0; i < len(all); i += transferChunkSize {
+			ch <- &dns.Envelope{RR: all[i:min(i+transferChunkSize, len(all))]}
+		}
+	}()
+
+	tr := new(dns.Transfer)
+	err := tr.Out(w, r, ch)
+	for range ch {
+		// Drain the remaining envelopes after a write error
+	}
+
+	status, result := "NOERROR", "success"
+	if err != nil {
+		status, result = "SERVFAIL", "failure"
+		h.logger.Error("zone transfer failed", "zone", zone.Name, "type", qtype, "error", err)
+	} else {
+		h.logger.Info("zone transferred",
+			"zone", zone.Name,
+			"type", qtype,
+			"serial", soa.Serial,
+			"records", len(all),
+			"client", w.RemoteAddr().String(),
+		)
+	}
+	metrics.RecordDNSZoneTransfer(zone.Name, qtype, result)
+	metrics.RecordDNSQuery(zone.Name, qtype, status)
+	metrics.RecordDNSQueryDuration(zone.Name, status, time.Since(start).Seconds())
+	m.Rcode = dns.StringToRcode[status]
+	return m
+}
+
+// transferAllowed reports whether a zone transfer request is signed with an
+// allowed TSIG key or comes from an allowed network. Requests with a TSIG
+// that fails verification are always refused.
+// Caller must hold h.mu.
+func (h *Handler) transferAllowed(w dns.ResponseWriter, r *dns.Msg) bool {
+	if h.transfer == nil {
+		return false
+	}
+
+	key, ok := verifiedKey(w, r)
+	if !ok {
+		return false
+	}
+	if key != "" && slices.Contains(h.transfer.Keys, key) && h.keyring.Has(key) {
+		return true
+	}
+
+	ip := addrIP(w.RemoteAddr())
+	for _, network := range h.transfer.AllowFrom {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneRecords returns the contents of a zone apart from its SOA: the apex NS
+// set and glue, the healthy backends of each domain in the zone (its sorry
+// servers when none is healthy) and static records. Records are sorted so
+// that unchanged contents always produce the same transfer.
+// Caller must hold h.mu.
+func (h *Handler) zoneRecords(zone *Zone) []dns.RR {
+	records := append(zone.NSRecords(), zone.GlueRecords()...)
+
+	if h.registry != nil {
+		for _, name := range h.registry.Domains() {
+			entry := h.registry.Lookup(name)
+			if entry == nil || findZone(h.zones, entry.Name) != zone {
+				continue
+			}
+			for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+				servers := h.getHealthyIPv4Servers(entry)
+				if qtype == dns.TypeAAAA {
+					servers = h.getHealthyIPv6Servers(entry)
+				}
+				if len(servers) == 0 {
+					servers = sorryServers(entry, qtype)
+				}
+				for _, server := range servers {
+					if ip := net.ParseIP(server.Address); ip != nil {
+						records = append(records, addressRecord(entry.Name, ip, h.ttlFor(entry)))
+					}
+				}
+			}
+			if entry.HTTPS != nil {
+				// Secondaries cannot route, so they hint every healthy backend
+				healthy := append(h.getHealthyIPv4Servers(entry), h.getHealthyIPv6Servers(entry)...)
+				for _, rrtype := range []uint16{dns.TypeSVCB, dns.TypeHTTPS} {
+					records = append(records, httpsRecord(entry.Name, rrtype, h.ttlFor(entry), entry.HTTPS, healthy))
+				}
+			}
+		}
+
+		for _, rr := range h.registry.AllStaticRecords() {
+			if findZone(h.zones, rr.Header().Name) == zone {
+				records = append(records, rr)
+			}
+		}
+	}
+
+	// Backends listening on several ports share one address record
+	seen := make(map[string]bool, len(records))
+	unique := records[:0]
+	for _, rr := range records {
+		if key := rr.String(); !seen[key] {
+			seen[key] = true
+			unique = append(unique, rr)
+		}
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		a, b := unique[i].Header(), unique[j].Header()
+		if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+			return an < bn
+		}
+		if a.Rrtype != b.Rrtype {
+			return a.Rrtype < b.Rrtype
+		}
+		return unique[i].String() < unique[j].String()
+	})
+	return unique
+}
+
+// UpdateZoneSerials bumps the SOA serial of every zone whose contents changed
+// since the last call, including changes in backend health. The first call
+// only records the current contents. Returns the SOA records of the zones
+// whose serial changed, including serials changed by a configuration reload.
+func (h *Handler) UpdateZoneSerials() []*dns.SOA {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var changed []*dns.SOA
+	for _, zone := range h.zones {
+		digest := zoneDigest(h.zoneRecords(zone))
+		state, seen := h.zoneStates[zone.Name]
+		if seen && state.digest != digest && zone.SOA.Serial == state.serial {
+			zone.SOA.Serial++
+		}
+		if seen && zone.SOA.Serial != state.serial {
+			changed = append(changed, zone.SOARecord())
+		}
+		h.zoneStates[zone.Name] = zoneState{digest: digest, serial: zone.SOA.Serial}
+	}
+	return changed
+}
+
+// WatchZones checks the zones for changes every interval until ctx is done,
+// bumping serials and sending NOTIFY to the configured secondaries.
+func (h *Handler) WatchZones(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultZoneCheckInterval
+	}
+	h.UpdateZoneSerials()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, soa := range h.UpdateZoneSerials() {
+				h.logger.Info("zone serial updated", "zone", soa.Hdr.Name, "serial", soa.Serial)
+				h.notifySecondaries(soa)
+			}
+		}
+	}
+}
+
+// notifySecondaries sends NOTIFY for a changed zone to every configured
+// secondary in the background.
+func (h *Handler) notifySecondaries(soa *dns.SOA) {
+	h.mu.RLock()
+	var secondaries []string
+	if h.transfer != nil {
+		secondaries = h.transfer.Notify
+	}
+	h.mu.RUnlock()
+
+	for _, addr := range secondaries {
+		go func() {
+			if err := h.sendNotify(addr, soa); err != nil {
+				h.logger.Warn("failed to notify secondary",
+					"zone", soa.Hdr.Name,
+					"secondary", addr,
+					"error", err,
+				)
+			}
+		}()
+	}
+}
+
+// sendNotify sends a NOTIFY for the zone of soa to one secondary (RFC 1996),
+// retrying on timeouts.
+func (h *Handler) sendNotify(addr string, soa *dns.SOA) error {
+	msg := new(dns.Msg)
+	msg.SetNotify(soa.Hdr.Name)
+	msg.Answer = []dns.RR{soa}
+
+	client := &dns.Client{Net: "udp", Timeout: notifyTimeout}
+	var err error
+	for attempt := 0; attempt < notifyAttempts; attempt++ {
+		var resp *dns.Msg
+		resp, _, err = client.Exchange(msg, addr)
+		if err != nil {
+			continue
+		}
+		if resp.Rcode != dns.RcodeSuccess {
+			err = fmt.Errorf("secondary answered %s", dns.RcodeToString[resp.Rcode])
+			break
+		}
+		metrics.RecordDNSNotify(soa.Hdr.Name, "success")
+		h.logger.Debug("secondary notified", "zone", soa.Hdr.Name, "secondary", addr, "serial", soa.Serial)
+		return nil
+	}
+	metrics.RecordDNSNotify(soa.Hdr.Name, "failure")
+	return err
+}
+
+// ixfrCurrent reports whether the serial in an IXFR request's authority
+// section is not older than serial (RFC 1982 serial arithmetic).
+func ixfrCurrent(r *dns.Msg, serial uint32) bool {
+	for _, rr := range r.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return int32(serial-soa.Serial) <= 0
+		}
+	}
+	return false
+}
+
+// signReply adds a TSIG record to the reply of a signed request; the server
+// computes the MAC when the reply is written.
+func signReply(m, r *dns.Msg) {
+	if tsig := r.IsTsig(); tsig != nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+}
+
+// zoneDigest returns a hash of a zone's records.
+func zoneDigest(records []dns.RR) uint64 {
+	h := fnv.New64a()
+	for _, rr := range records {
+		h.Write([]byte(rr.String()))
+		h.Write([]byte{'\n'})
+	}
+	return h.Sum64()
+}
+
+// addrIP returns the IP address of a network address.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	}
+	if addr == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
@@ -0,0 +1,326 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/miekg/dns"
+)
+
+var testTSIGSecret = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+// transferWriter captures every message of a zone transfer.
+type transferWriter struct {
+	testResponseWriter
+	msgs       []*dns.Msg
+	tsigStatus error
+}
+
+func newTransferWriter(remote net.Addr) *transferWriter {
+	return &transferWriter{testResponseWriter: testResponseWriter{remote: remote}}
+}
+
+func (w *transferWriter) WriteMsg(m *dns.Msg) error { w.msgs = append(w.msgs, m); return nil }
+func (w *transferWriter) TsigStatus() error         { return w.tsigStatus }
+
+// transferRecords returns the records of all transfer messages.
+func (w *transferWriter) transferRecords() []dns.RR {
+	var records []dns.RR
+	for _, m := range w.msgs {
+		records = append(records, m.Answer...)
+	}
+	return records
+}
+
+func newTransferTestHandler(t *testing.T) (*Handler, *mockHealthProvider) {
+	t.Helper()
+	handler := newZoneTestHandler(t)
+	handler.registry.Register(&DomainEntry{
+		Name:   "api.gslb.example.com",
+		TTL:    20,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.1.1"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.1.2"), Port: 80, Weight: 100},
+		},
+		SorryServers: []net.IP{net.ParseIP("192.0.2.200")},
+	})
+	if err := handler.registry.SetStaticRecords("api.gslb.example.com", []config.StaticRecord{
+		{Type: "TXT", Value: "v=spf1 -all"},
+	}); err != nil {
+		t.Fatalf("failed to set static records: %v", err)
+	}
+
+	health := newMockHealthProvider()
+	handler.health = health
+	handler.keyring = NewKeyring([]TSIGKey{{
+		Name:      "transfer-key.",
+		Algorithm: dns.HmacSHA256,
+		Secret:    []byte("0123456789abcdef0123456789abcdef"),
+	}})
+	handler.transfer = &TransferSettings{
+		AllowFrom: []*net.IPNet{{IP: net.ParseIP("192.0.2.0").To4(), Mask: net.CIDRMask(24, 32)}},
+		Keys:      []string{"transfer-key."},
+	}
+	return handler, health
+}
+
+func axfr(handler *Handler, w *transferWriter, qtype uint16, tsig bool) {
+	req := new(dns.Msg)
+	req.SetQuestion("gslb.example.com.", qtype)
+	if tsig {
+		req.SetTsig("transfer-key.", dns.HmacSHA256, 300, time.Now().Unix())
+	}
+	handler.ServeDNS(w, req)
+}
+
+func hasRecord(records []dns.RR, name string, rrtype uint16, value string) bool {
+	for _, rr := range records {
+		if rr.Header().Name != name || rr.Header().Rrtype != rrtype {
+			continue
+		}
+		switch v := rr.(type) {
+		case *dns.A:
+			if v.A.String() == value {
+				return true
+			}
+		case *dns.TXT:
+			if len(v.Txt) > 0 && v.Txt[0] == value {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func TestHandler_AXFR(t *testing.T) {
+	handler, health := newTransferTestHandler(t)
+	health.SetHealthy("10.0.1.2", false)
+
+	w := newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000})
+	axfr(handler, w, dns.TypeAXFR, false)
+
+	records := w.transferRecords()
+	if len(records) < 2 {
+		t.Fatalf("expected a zone transfer, got %d records", len(records))
+	}
+	first, firstOK := records[0].(*dns.SOA)
+	last, lastOK := records[len(records)-1].(*dns.SOA)
+	if !firstOK || !lastOK || first.Serial != 2025010101 || last.Serial != first.Serial {
+		t.Fatalf("expected transfer to start and end with the SOA, got %v ... %v", records[0], records[len(records)-1])
+	}
+
+	if !hasRecord(records, "gslb.example.com.", dns.TypeNS, "") {
+		t.Error("expected apex NS records")
+	}
+	if !hasRecord(records, "ns1.gslb.example.com.", dns.TypeA, "192.0.2.53") {
+		t.Error("expected nameserver glue")
+	}
+	if !hasRecord(records, "api.gslb.example.com.", dns.TypeA, "10.0.1.1") {
+		t.Error("expected healthy backend 10.0.1.1")
+	}
+	if hasRecord(records, "api.gslb.example.com.", dns.TypeA, "10.0.1.2") {
+		t.Error("expected unhealthy backend 10.0.1.2 to be left out")
+	}
+	if !hasRecord(records, "api.gslb.example.com.", dns.TypeTXT, "v=spf1 -all") {
+		t.Error("expected static TXT record")
+	}
+}
+
+func TestHandler_AXFRSorryServers(t *testing.T) {
+	handler, health := newTransferTestHandler(t)
+	health.SetHealthy("10.0.1.1", false)
+	health.SetHealthy("10.0.1.2", false)
+
+	w := newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000})
+	axfr(handler, w, dns.TypeAXFR, false)
+
+	if !hasRecord(w.transferRecords(), "api.gslb.example.com.", dns.TypeA, "192.0.2.200") {
+		t.Error("expected sorry server when every backend is down")
+	}
+}
+
+func TestHandler_TransferAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		remote     net.Addr
+		tsig       bool
+		tsigStatus error
+		want       int
+	}{
+		{"allowed network", &net.TCPAddr{IP: net.ParseIP("192.0.2.10")}, false, nil, dns.RcodeSuccess},
+		{"other network", &net.TCPAddr{IP: net.ParseIP("203.0.113.10")}, false, nil, dns.RcodeRefused},
+		{"allowed key", &net.TCPAddr{IP: net.ParseIP("203.0.113.10")}, true, nil, dns.RcodeSuccess},
+		{"bad signature", &net.TCPAddr{IP: net.ParseIP("192.0.2.10")}, true, dns.ErrSig, dns.RcodeRefused},
+		{"AXFR over UDP", &net.UDPAddr{IP: net.ParseIP("192.0.2.10")}, false, nil, dns.RcodeRefused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTransferTestHandler(t)
+			w := newTransferWriter(tt.remote)
+			w.tsigStatus = tt.tsigStatus
+			axfr(handler, w, dns.TypeAXFR, tt.tsig)
+
+			if len(w.msgs) == 0 {
+				t.Fatal("no response written")
+			}
+			if rcode := w.msgs[0].Rcode; rcode != tt.want {
+				t.Errorf("expected %s, got %s", dns.RcodeToString[tt.want], dns.RcodeToString[rcode])
+			}
+		})
+	}
+}
+
+func TestHandler_TransferWithoutSettings(t *testing.T) {
+	handler, _ := newTransferTestHandler(t)
+	handler.SetTransfer(nil)
+
+	w := newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10")})
+	axfr(handler, w, dns.TypeAXFR, false)
+	if len(w.msgs) != 1 || w.msgs[0].Rcode != dns.RcodeRefused {
+		t.Errorf("expected REFUSED without transfer settings, got %v", w.msgs)
+	}
+}
+
+func TestHandler_IXFRCurrent(t *testing.T) {
+	handler, _ := newTransferTestHandler(t)
+
+	req := new(dns.Msg)
+	req.SetIxfr("gslb.example.com.", 2025010101, "ns1.gslb.example.com.", "hostmaster.gslb.example.com.")
+	w := newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10")})
+	handler.ServeDNS(w, req)
+
+	if len(w.msgs) != 1 || len(w.msgs[0].Answer) != 1 {
+		t.Fatalf("expected a single SOA for a current secondary, got %v", w.msgs)
+	}
+	if _, ok := w.msgs[0].Answer[0].(*dns.SOA); !ok {
+		t.Errorf("expected SOA, got %v", w.msgs[0].Answer[0])
+	}
+
+	// An outdated secondary gets the full zone
+	req.SetIxfr("gslb.example.com.", 2025010100, "ns1.gslb.example.com.", "hostmaster.gslb.example.com.")
+	w = newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10")})
+	handler.ServeDNS(w, req)
+	if records := w.transferRecords(); len(records) < 4 {
+		t.Errorf("expected full zone for an outdated secondary, got %d records", len(records))
+	}
+}
+
+func TestHandler_UpdateZoneSerials(t *testing.T) {
+	handler, health := newTransferTestHandler(t)
+
+	if changed := handler.UpdateZoneSerials(); len(changed) != 0 {
+		t.Fatalf("expected first call to only record contents, got %v", changed)
+	}
+	if changed := handler.UpdateZoneSerials(); len(changed) != 0 {
+		t.Fatalf("expected no change, got %v", changed)
+	}
+
+	// A health change alters the transferred contents
+	health.SetHealthy("10.0.1.2", false)
+	changed := handler.UpdateZoneSerials()
+	if len(changed) != 1 || changed[0].Serial != 2025010102 {
+		t.Fatalf("expected serial bump to 2025010102, got %v", changed)
+	}
+
+	// A registry change does too
+	if err := handler.registry.RegisterServer("api.gslb.example.com", "10.0.1.3", 80, 100, ""); err != nil {
+		t.Fatalf("RegisterServer failed: %v", err)
+	}
+	changed = handler.UpdateZoneSerials()
+	if len(changed) != 1 || changed[0].Serial != 2025010103 {
+		t.Fatalf("expected serial bump to 2025010103, got %v", changed)
+	}
+
+	// Reloading zones with the configured serial keeps the bumped one
+	zones, err := BuildZones(&config.Config{DNS: config.DNSConfig{
+		DefaultTTL: 300,
+		Zones:      []string{"gslb.example.com"},
+		SOA:        config.SOAConfig{Serial: 2025010101},
+	}})
+	if err != nil {
+		t.Fatalf("BuildZones failed: %v", err)
+	}
+	handler.SetZones(zones)
+	if serial := zones[0].SOA.Serial; serial != 2025010103 {
+		t.Errorf("expected serial to survive reload, got %d", serial)
+	}
+}
+
+func TestHandler_SendNotify(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	received := make(chan *dns.Msg, 1)
+	secondary := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			received <- r
+			m := new(dns.Msg)
+			m.SetReply(r)
+			_ = w.WriteMsg(m)
+		}),
+	}
+	go func() { _ = secondary.ActivateAndServe() }()
+	defer func() { _ = secondary.Shutdown() }()
+
+	handler, _ := newTransferTestHandler(t)
+	soa := handler.zones[0].SOARecord()
+	if err := handler.sendNotify(pc.LocalAddr().String(), soa); err != nil {
+		t.Fatalf("sendNotify failed: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.Opcode != dns.OpcodeNotify || msg.Question[0].Name != "gslb.example.com." {
+			t.Errorf("unexpected NOTIFY: %v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("secondary did not receive NOTIFY")
+	}
+}
+
+func TestKeyring_TSIG(t *testing.T) {
+	keys, err := BuildTSIGKeys(&config.Config{DNS: config.DNSConfig{
+		TSIGKeys: []config.TSIGKeyConfig{{Name: "Transfer-Key", Secret: testTSIGSecret}},
+	}})
+	if err != nil {
+		t.Fatalf("BuildTSIGKeys failed: %v", err)
+	}
+	keyring := NewKeyring(keys)
+	if !keyring.Has("transfer-key.") {
+		t.Fatal("expected key to be normalized to transfer-key.")
+	}
+
+	req := new(dns.Msg)
+	req.SetQuestion("gslb.example.com.", dns.TypeAXFR)
+	req.SetTsig("transfer-key.", dns.HmacSHA256, 300, time.Now().Unix())
+	packed, _, err := dns.TsigGenerateWithProvider(req, keyring, "", false)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if err := dns.TsigVerifyWithProvider(packed, keyring, "", false); err != nil {
+		t.Errorf("expected signature to verify, got %v", err)
+	}
+
+	// The key only signs with its configured algorithm
+	req.SetTsig("transfer-key.", dns.HmacSHA512, 300, time.Now().Unix())
+	if _, _, err := dns.TsigGenerateWithProvider(req, keyring, "", false); !errors.Is(err, dns.ErrKeyAlg) {
+		t.Errorf("expected ErrKeyAlg for another algorithm, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/miekg/dns"
+)
+
+// Transports, used as the "transport" metric label.
+const (
+	transportUDP = "udp"
+	transportTCP = "tcp"
+	transportDoT = "dot"
+	transportDoH = "doh"
+)
+
+// transportHandler passes queries received over one transport to the shared
+// Handler and records per-transport query metrics.
+type transportHandler struct {
+	transport string
+	handler   dns.Handler
+}
+
+func (t transportHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	rw := &rcodeWriter{ResponseWriter: w}
+	t.handler.ServeDNS(rw, r)
+
+	rcode := "NONE"
+	if rw.written {
+		rcode = dns.RcodeToString[rw.rcode]
+	}
+	metrics.RecordDNSTransportQuery(t.transport, rcode)
+}
+
+// rcodeWriter captures the response code of the reply written through it.
+type rcodeWriter struct {
+	dns.ResponseWriter
+	rcode   int
+	written bool
+}
+
+func (w *rcodeWriter) WriteMsg(m *dns.Msg) error {
+	w.rcode = m.Rcode
+	w.written = true
+	return w.ResponseWriter.WriteMsg(m)
+}
+
+// certificateStore holds the certificate served by the DoT and DoH listeners.
+// It is consulted on every handshake, so a reload takes effect for new
+// connections without restarting any listener.
+type certificateStore struct {
+	mu       sync.RWMutex
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+}
+
+// load reads the certificate and key from disk, replacing the current pair.
+// Empty paths keep the previously configured files. On error the current
+// certificate stays in use.
+func (c *certificateStore) load(certFile, keyFile string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if certFile == "" {
+		certFile = c.certFile
+	}
+	if keyFile == "" {
+		keyFile = c.keyFile
+	}
+	if certFile == "" || keyFile == "" {
+		return errors.New("certificate and key files are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	c.certFile, c.keyFile, c.cert = certFile, keyFile, &cert
+	return nil
+}
+
+// getCertificate implements tls.Config.GetCertificate.
+func (c *certificateStore) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.cert == nil {
+		return nil, errors.New("no certificate loaded")
+	}
+	return c.cert, nil
+}
+
+// tlsConfig returns a TLS configuration serving the stored certificate.
+func (c *certificateStore) tlsConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: c.getCertificate,
+		NextProtos:     nextProtos,
+	}
+}
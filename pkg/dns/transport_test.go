@@ -0,0 +1,114 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate for commonName to dir
+// and returns the certificate and key paths.
+func writeTestCertificate(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "dns.crt")
+	keyFile := filepath.Join(dir, "dns.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func servedCommonName(t *testing.T, certs *certificateStore) string {
+	t.Helper()
+	cert, err := certs.getCertificate(nil)
+	if err != nil {
+		t.Fatalf("getCertificate failed: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestServer_ReloadCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "old.example.com")
+
+	server := NewServer(ServerConfig{
+		Address:    "127.0.0.1:0",
+		DoTAddress: "127.0.0.1:0",
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+	})
+	if err := server.certs.load(certFile, keyFile); err != nil {
+		t.Fatalf("initial load failed: %v", err)
+	}
+	if cn := servedCommonName(t, server.certs); cn != "old.example.com" {
+		t.Fatalf("expected old certificate, got %s", cn)
+	}
+
+	// A renewed certificate at the same paths is served after a reload
+	writeTestCertificate(t, dir, "new.example.com")
+	if err := server.ReloadCertificate("", ""); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if cn := servedCommonName(t, server.certs); cn != "new.example.com" {
+		t.Errorf("expected renewed certificate, got %s", cn)
+	}
+
+	// A broken certificate keeps the current one in use
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("failed to corrupt certificate: %v", err)
+	}
+	if err := server.ReloadCertificate("", ""); err == nil {
+		t.Error("expected reload of an invalid certificate to fail")
+	}
+	if cn := servedCommonName(t, server.certs); cn != "new.example.com" {
+		t.Errorf("expected previous certificate to stay in use, got %s", cn)
+	}
+}
+
+func TestServer_ReloadCertificateWithoutEncryptedListeners(t *testing.T) {
+	server := NewServer(ServerConfig{Address: "127.0.0.1:0"})
+	if err := server.ReloadCertificate("/nonexistent.crt", "/nonexistent.key"); err != nil {
+		t.Errorf("expected no-op without DoT or DoH, got %v", err)
+	}
+}
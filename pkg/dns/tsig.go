@@ -0,0 +1,154 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/miekg/dns"
+)
+
+// TSIGKey is a shared secret used to authenticate requests (RFC 8945).
+type TSIGKey struct {
+	Name      string // Fully qualified, lower-case key name
+	Algorithm string // Fully qualified algorithm name, e.g. dns.HmacSHA256
+	Secret    []byte
+}
+
+// BuildTSIGKeys creates the TSIG keys listed in dns.tsig_keys.
+func BuildTSIGKeys(cfg *config.Config) ([]TSIGKey, error) {
+	keys := make([]TSIGKey, 0, len(cfg.DNS.TSIGKeys))
+	for _, key := range cfg.DNS.TSIGKeys {
+		secret, err := base64.StdEncoding.DecodeString(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret for TSIG key %s: %w", key.Name, err)
+		}
+		algorithm := key.Algorithm
+		if algorithm == "" {
+			algorithm = config.DefaultTSIGAlgorithm
+		}
+		keys = append(keys, TSIGKey{
+			Name:      dns.Fqdn(strings.ToLower(key.Name)),
+			Algorithm: dns.Fqdn(strings.ToLower(algorithm)),
+			Secret:    secret,
+		})
+	}
+	return keys, nil
+}
+
+// Keyring holds the TSIG keys and implements dns.TsigProvider. Requests are
+// only verified when signed with a known key using its configured algorithm.
+// It is shared by the DNS servers, which verify and sign messages, and the
+// Handler, which authorizes requests by key name.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys map[string]TSIGKey
+}
+
+// NewKeyring creates a keyring holding keys.
+func NewKeyring(keys []TSIGKey) *Keyring {
+	k := &Keyring{}
+	k.SetKeys(keys)
+	return k
+}
+
+// SetKeys replaces the keys of the keyring.
+func (k *Keyring) SetKeys(keys []TSIGKey) {
+	byName := make(map[string]TSIGKey, len(keys))
+	for _, key := range keys {
+		byName[key.Name] = key
+	}
+	k.mu.Lock()
+	k.keys = byName
+	k.mu.Unlock()
+}
+
+// Has reports whether the keyring holds a key named name.
+func (k *Keyring) Has(name string) bool {
+	_, ok := k.key(name)
+	return ok
+}
+
+func (k *Keyring) key(name string) (TSIGKey, bool) {
+	if k == nil {
+		return TSIGKey{}, false
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[dns.Fqdn(strings.ToLower(name))]
+	return key, ok
+}
+
+// Generate implements dns.TsigProvider.
+func (k *Keyring) Generate(msg []byte, t *dns.TSIG) ([]byte, error) {
+	key, ok := k.key(t.Hdr.Name)
+	if !ok {
+		return nil, dns.ErrSecret
+	}
+	if !strings.EqualFold(dns.Fqdn(t.Algorithm), key.Algorithm) {
+		return nil, dns.ErrKeyAlg
+	}
+
+	var newHash func() hash.Hash
+	switch key.Algorithm {
+	case dns.HmacSHA1:
+		newHash = sha1.New
+	case dns.HmacSHA224:
+		newHash = sha256.New224
+	case dns.HmacSHA256:
+		newHash = sha256.New
+	case dns.HmacSHA384:
+		newHash = sha512.New384
+	case dns.HmacSHA512:
+		newHash = sha512.New
+	default:
+		return nil, dns.ErrKeyAlg
+	}
+
+	mac := hmac.New(newHash, key.Secret)
+	mac.Write(msg)
+	return mac.Sum(nil), nil
+}
+
+// Verify implements dns.TsigProvider.
+func (k *Keyring) Verify(msg []byte, t *dns.TSIG) error {
+	expected, err := k.Generate(msg, t)
+	if err != nil {
+		return err
+	}
+	mac, err := hex.DecodeString(t.MAC)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, mac) {
+		return dns.ErrSig
+	}
+	return nil
+}
+
+// verifiedKey returns the name of the TSIG key that signed r, or "" if r is
+// unsigned. ok is false when r is signed but failed verification.
+func verifiedKey(w dns.ResponseWriter, r *dns.Msg) (name string, ok bool) {
+	tsig := r.IsTsig()
+	if tsig == nil {
+		return "", true
+	}
+	if err := w.TsigStatus(); err != nil {
+		return "", false
+	}
+	return strings.ToLower(tsig.Hdr.Name), true
+}
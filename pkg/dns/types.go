@@ -9,17 +9,22 @@ package dns
 import (
 	"log/slog"
 	"net"
+	"slices"
 	"time"
 
+	"github.com/loganrossus/OpenGSLB/pkg/querylog"
 	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
 )
 
 // ServerInfo contains information about a backend server.
 type ServerInfo struct {
-	Address net.IP
-	Port    int
-	Weight  int
-	Region  string
+	Address  net.IP
+	Port     int
+	Weight   int
+	Region   string
+	Priority int // Failover tier; lower values are preferred
+	Labels   map[string]string
 }
 
 // DomainEntry contains configuration for a single domain.
@@ -29,6 +34,65 @@ type DomainEntry struct {
 	RoutingAlgorithm string
 	Router           routing.Router
 	Servers          []ServerInfo
+	MaxAnswers       int            // Records per A/AAAA answer; 0 or 1 returns the single routed server
+	SorryServers     []net.IP       // Fallback addresses served when every backend is unhealthy
+	Records          []dns.RR       // Static records (TXT, MX, CAA, SRV, CNAME) at or below the domain name
+	Regions          []string       // Regions the servers are drawn from
+	RegionPriority   map[string]int // Failover tier of servers registered in each region
+	View             string         // Split-horizon view of the entry; empty for the default view
+
+	// HTTPS holds the parameters of the synthesized HTTPS and SVCB records;
+	// nil when the domain publishes none
+	HTTPS *HTTPSParams
+
+	// DNS64 is the /96 prefix AAAA answers are synthesized in from IPv4
+	// backends when no IPv6 backend is healthy; nil disables DNS64
+	DNS64 net.IP
+
+	// PanicThreshold is the percentage of healthy backends below which
+	// health is ignored; 0 disables panic mode
+	PanicThreshold int
+
+	// Split routes shares of the domain's clients to servers by label; nil
+	// routes every client across all servers
+	Split *routing.TrafficSplit
+
+	// FailoverPolicy is the configured tier policy of a failover router;
+	// nil uses the router's defaults
+	FailoverPolicy *routing.FailoverPolicy
+}
+
+// WeightProfile overrides the weights and failover priorities of a
+// domain's servers, such as while a scheduled profile is active.
+type WeightProfile struct {
+	Name             string
+	RegionWeights    map[string]int // Weight of every server in a region
+	ServerWeights    map[string]int // Weight by server address, overriding RegionWeights
+	RegionPreference []string       // Regions most preferred first; unlisted regions come last
+}
+
+// Apply sets the weight and priority of a server from the profile.
+func (p *WeightProfile) Apply(s *routing.Server) {
+	if w, ok := p.RegionWeights[s.Region]; ok {
+		s.Weight = w
+	}
+	if w, ok := p.ServerWeights[s.Address]; ok {
+		s.Weight = w
+	}
+	if len(p.RegionPreference) > 0 {
+		s.Priority = len(p.RegionPreference) + 1
+		if i := slices.Index(p.RegionPreference, s.Region); i >= 0 {
+			s.Priority = i + 1
+		}
+	}
+}
+
+// HTTPSParams are the service parameters of a domain's HTTPS and SVCB
+// records (RFC 9460). Address hints come from the domain's backends.
+type HTTPSParams struct {
+	ALPN []string
+	Port uint16 // 0 omits the port parameter
+	ECH  []byte // ECHConfigList; nil omits the ech parameter
 }
 
 // HealthProvider checks if a server is healthy.
@@ -36,6 +100,25 @@ type HealthProvider interface {
 	IsHealthy(address string, port int) bool
 }
 
+// OverrideProvider is optionally implemented by a HealthProvider to report
+// servers an operator has overridden to unhealthy. Panic mode never routes
+// to them.
+type OverrideProvider interface {
+	IsOverriddenUnhealthy(address string, port int) bool
+}
+
+// PanicEvent reports a domain's pool of one address family entering or
+// leaving panic mode.
+type PanicEvent struct {
+	Domain    string
+	View      string // Empty for the default view
+	Family    string // "ipv4" or "ipv6"
+	Active    bool
+	Healthy   int // Healthy servers in the pool
+	Total     int // Servers in the pool not overridden to unhealthy
+	Threshold int // Panic threshold in percent
+}
+
 // LatencyInfo contains latency information for a backend server.
 type LatencyInfo struct {
 	// SmoothedLatency is the EMA of validation latency measurements.
@@ -68,6 +151,13 @@ type DNSSECSigner interface {
 	SignResponse(msg interface{}) (interface{}, error)
 }
 
+// DNSSECNoDataSigner is optionally implemented by a DNSSECSigner to add
+// authenticated denial of existence to NODATA responses.
+// existingTypes lists the record types present at the queried name.
+type DNSSECNoDataSigner interface {
+	SignNoDataResponse(msg interface{}, existingTypes []uint16) (interface{}, error)
+}
+
 // HandlerConfig contains configuration for the DNS handler.
 type HandlerConfig struct {
 	Registry       *Registry
@@ -77,5 +167,38 @@ type HandlerConfig struct {
 	DNSSECEnabled  bool          // Whether DNSSEC is enabled
 	ECSEnabled     bool          // Whether to use EDNS Client Subnet for geolocation
 	DefaultTTL     uint32
-	Logger         *slog.Logger
+	Zones          []*Zone // Authoritative zones served with SOA/NS at the apex
+
+	// ReturnLastHealthy replays the last healthy answer when every backend of
+	// a domain is down instead of returning SERVFAIL.
+	ReturnLastHealthy bool
+	// LastHealthyTTL is the TTL of fallback answers (default: 10 seconds).
+	LastHealthyTTL uint32
+	// MaxUDPSize is the EDNS buffer size advertised and the largest UDP
+	// response sent (default: 1232 bytes).
+	MaxUDPSize uint16
+
+	// Keyring holds the TSIG keys used to authorize requests by key name
+	Keyring *Keyring
+	// Transfer controls zone transfers and NOTIFY; nil refuses all transfers
+	Transfer *TransferSettings
+	// Updater applies dynamic updates signed with one of UpdateKeys;
+	// nil refuses all updates
+	Updater    BackendUpdater
+	UpdateKeys []string
+	// RateLimiter limits UDP responses per client prefix; nil disables limiting
+	RateLimiter *RateLimiter
+	// Views select split-horizon views by source network or TSIG key; the
+	// view registries are held by Registry
+	Views []ViewSettings
+	// QueryLog records each answered query; nil disables query logging
+	QueryLog *querylog.Logger
+	// Forwarders answer the names of their zones that are not managed by
+	// OpenGSLB from the zones' existing authoritative servers
+	Forwarders []*Forwarder
+	// PanicListener is called when a domain enters or leaves panic mode.
+	// Changes are queued and delivered by WatchPanicMode, outside queries
+	PanicListener func(PanicEvent)
+
+	Logger *slog.Logger
 }
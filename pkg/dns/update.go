@@ -0,0 +1,264 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/miekg/dns"
+)
+
+// BackendUpdater registers and removes backend servers on behalf of dynamic
+// updates (RFC 2136). domain is the GSLB domain name without the trailing dot
+// and key is the TSIG key that signed the update.
+type BackendUpdater interface {
+	AddBackend(domain string, ip net.IP, key string) error
+	RemoveBackend(domain string, ip net.IP, key string) error
+}
+
+// ErrUpdateRefused is returned by a BackendUpdater for a change the update
+// may not make, such as adding a record of a backend registered by another
+// source. The update is answered REFUSED.
+var ErrUpdateRefused = errors.New("dynamic update refused")
+
+// backendChange is one validated change from the update section.
+type backendChange struct {
+	domain string
+	ip     net.IP
+	remove bool
+}
+
+// SetUpdate replaces the dynamic update settings. keys are the TSIG key names
+// allowed to send updates; a nil updater or no keys refuses all updates.
+func (h *Handler) SetUpdate(updater BackendUpdater, keys []string) {
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		normalized = append(normalized, dns.Fqdn(strings.ToLower(key)))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.updater = updater
+	h.updateKeys = normalized
+}
+
+// serveUpdate applies a dynamic update (RFC 2136) that adds or removes A and
+// AAAA records of GSLB domains. Each record maps to a backend server, so the
+// update registers or deregisters backends rather than editing the zone.
+// Prerequisites are not supported. Returns the reply written.
+func (h *Handler) serveUpdate(w dns.ResponseWriter, r *dns.Msg, start time.Time) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetReply(r)
+
+	reply := func(rcode int, zone string) {
+		status := dns.RcodeToString[rcode]
+		if zone != "" {
+			metrics.RecordDNSUpdate(zone, strings.ToLower(status))
+		}
+		m.SetRcode(m, rcode)
+		signReply(m, r)
+		h.writeResponse(w, m, start, status, zone)
+	}
+
+	if len(r.Question) != 1 || r.Question[0].Qtype != dns.TypeSOA {
+		reply(dns.RcodeFormatError, "")
+		return m
+	}
+	z := r.Question[0]
+
+	h.mu.RLock()
+	zone := findZone(h.zones, z.Name)
+	updater := h.updater
+	if zone == nil || !zone.IsApex(z.Name) {
+		h.mu.RUnlock()
+		h.logger.Debug("dynamic update for a name that is not a zone apex", "zone", z.Name)
+		reply(dns.RcodeNotAuth, "")
+		return m
+	}
+
+	key, ok := verifiedKey(w, r)
+	if !ok {
+		h.mu.RUnlock()
+		h.logger.Warn("dynamic update with invalid TSIG signature",
+			"zone", zone.Name,
+			"client", w.RemoteAddr().String(),
+		)
+		reply(dns.RcodeNotAuth, zone.Name)
+		return m
+	}
+	if updater == nil || key == "" || !slices.Contains(h.updateKeys, key) || !h.keyring.Has(key) {
+		h.mu.RUnlock()
+		h.logger.Warn("dynamic update refused",
+			"zone", zone.Name,
+			"key", key,
+			"client", w.RemoteAddr().String(),
+		)
+		reply(dns.RcodeRefused, zone.Name)
+		return m
+	}
+	if len(r.Answer) > 0 {
+		h.mu.RUnlock()
+		h.logger.Debug("dynamic update with prerequisites", "zone", zone.Name)
+		reply(dns.RcodeNotImplemented, zone.Name)
+		return m
+	}
+
+	changes, rcode := h.updateChanges(zone, r.Ns)
+	h.mu.RUnlock()
+	if rcode != dns.RcodeSuccess {
+		h.logger.Warn("dynamic update rejected",
+			"zone", zone.Name,
+			"key", key,
+			"rcode", dns.RcodeToString[rcode],
+		)
+		reply(rcode, zone.Name)
+		return m
+	}
+
+	for _, change := range changes {
+		var err error
+		if change.remove {
+			err = updater.RemoveBackend(change.domain, change.ip, key)
+		} else {
+			err = updater.AddBackend(change.domain, change.ip, key)
+		}
+		if errors.Is(err, ErrUpdateRefused) {
+			h.logger.Warn("dynamic update refused",
+				"zone", zone.Name,
+				"domain", change.domain,
+				"address", change.ip.String(),
+				"key", key,
+				"error", err,
+			)
+			reply(dns.RcodeRefused, zone.Name)
+			return m
+		}
+		if err != nil {
+			h.logger.Error("dynamic update failed",
+				"zone", zone.Name,
+				"domain", change.domain,
+				"address", change.ip.String(),
+				"key", key,
+				"error", err,
+			)
+			reply(dns.RcodeServerFailure, zone.Name)
+			return m
+		}
+	}
+
+	h.logger.Info("dynamic update applied",
+		"zone", zone.Name,
+		"key", key,
+		"changes", len(changes),
+		"client", w.RemoteAddr().String(),
+	)
+	reply(dns.RcodeSuccess, zone.Name)
+	return m
+}
+
+// updateChanges prescans the update section (RFC 2136 section 3.4.1) and
+// translates it to backend changes. Nothing is applied unless every record
+// is valid.
+// Caller must hold h.mu.
+func (h *Handler) updateChanges(zone *Zone, updates []dns.RR) ([]backendChange, int) {
+	var changes []backendChange
+	for _, rr := range updates {
+		hdr := rr.Header()
+		if findZone(h.zones, hdr.Name) != zone {
+			return nil, dns.RcodeNotZone
+		}
+
+		entry := h.lookupUpdateDomain(hdr.Name)
+		if entry == nil {
+			return nil, dns.RcodeRefused
+		}
+		domain := strings.TrimSuffix(entry.Name, ".")
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			ip := addressOf(rr)
+			if ip == nil {
+				return nil, dns.RcodeRefused
+			}
+			changes = append(changes, backendChange{domain: domain, ip: ip})
+
+		case dns.ClassNONE:
+			// Delete an RR from an RRset
+			if hdr.Ttl != 0 {
+				return nil, dns.RcodeFormatError
+			}
+			ip := addressOf(rr)
+			if ip == nil {
+				return nil, dns.RcodeRefused
+			}
+			changes = append(changes, backendChange{domain: domain, ip: ip, remove: true})
+
+		case dns.ClassANY:
+			// Delete an RRset, or all RRsets of a name
+			if hdr.Ttl != 0 || hdr.Rdlength != 0 {
+				return nil, dns.RcodeFormatError
+			}
+			if hdr.Rrtype != dns.TypeA && hdr.Rrtype != dns.TypeAAAA && hdr.Rrtype != dns.TypeANY {
+				return nil, dns.RcodeRefused
+			}
+			for _, server := range entry.Servers {
+				if hdr.Rrtype == dns.TypeANY || (server.Address.To4() != nil) == (hdr.Rrtype == dns.TypeA) {
+					changes = append(changes, backendChange{domain: domain, ip: server.Address, remove: true})
+				}
+			}
+
+		default:
+			return nil, dns.RcodeFormatError
+		}
+	}
+	return changes, dns.RcodeSuccess
+}
+
+// lookupUpdateDomain returns the GSLB domain named exactly name. Wildcard
+// domains cannot be updated.
+// Caller must hold h.mu.
+func (h *Handler) lookupUpdateDomain(name string) *DomainEntry {
+	if h.registry == nil {
+		return nil
+	}
+	entry, label := h.registry.LookupMatch(name)
+	if entry == nil || label != "" {
+		return nil
+	}
+	return entry
+}
+
+// addressOf returns the address of an A or AAAA record, or nil for any
+// other record.
+func addressOf(rr dns.RR) net.IP {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A
+	case *dns.AAAA:
+		return v.AAAA
+	}
+	return nil
+}
+
+// acceptMsg accepts dynamic updates, which the default accept function
+// rejects, and applies the default checks to every other message.
+func acceptMsg(dh dns.Header) dns.MsgAcceptAction {
+	const qr = 1 << 15
+	opcode := int(dh.Bits>>11) & 0xF
+	if dh.Bits&qr == 0 && opcode == dns.OpcodeUpdate {
+		if dh.Qdcount != 1 {
+			return dns.MsgReject
+		}
+		return dns.MsgAccept
+	}
+	return dns.DefaultMsgAcceptFunc(dh)
+}
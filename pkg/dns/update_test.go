@@ -0,0 +1,252 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// mockUpdater records the backend changes of dynamic updates.
+type mockUpdater struct {
+	added   []string
+	removed []string
+	err     error
+}
+
+func (u *mockUpdater) AddBackend(domain string, ip net.IP, key string) error {
+	u.added = append(u.added, domain+"/"+ip.String())
+	return u.err
+}
+
+func (u *mockUpdater) RemoveBackend(domain string, ip net.IP, key string) error {
+	u.removed = append(u.removed, domain+"/"+ip.String())
+	return u.err
+}
+
+func newUpdateTestHandler(t *testing.T) (*Handler, *mockUpdater) {
+	t.Helper()
+	handler, _ := newTransferTestHandler(t)
+	updater := &mockUpdater{}
+	handler.SetUpdate(updater, []string{"transfer-key"})
+	return handler, updater
+}
+
+// sendUpdate serves a TSIG-signed update for the gslb.example.com zone.
+func sendUpdate(handler *Handler, build func(m *dns.Msg)) *dns.Msg {
+	req := new(dns.Msg)
+	req.SetUpdate("gslb.example.com.")
+	build(req)
+	req.SetTsig("transfer-key.", dns.HmacSHA256, 300, time.Now().Unix())
+
+	w := newTransferWriter(&net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 40000})
+	handler.ServeDNS(w, req)
+	if len(w.msgs) != 1 {
+		return nil
+	}
+	return w.msgs[0]
+}
+
+func mustRR(t *testing.T, s string) dns.RR {
+	t.Helper()
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", s, err)
+	}
+	return rr
+}
+
+func TestHandler_UpdateAddAndRemove(t *testing.T) {
+	handler, updater := newUpdateTestHandler(t)
+
+	resp := sendUpdate(handler, func(m *dns.Msg) {
+		m.Insert([]dns.RR{
+			mustRR(t, "api.gslb.example.com. 60 IN A 10.0.1.3"),
+			mustRR(t, "api.gslb.example.com. 60 IN AAAA 2001:db8::3"),
+		})
+		m.Remove([]dns.RR{mustRR(t, "api.gslb.example.com. 60 IN A 10.0.1.1")})
+	})
+	if resp == nil || resp.Rcode != dns.RcodeSuccess {
+		t.Fatalf("expected NOERROR, got %v", resp)
+	}
+	if resp.IsTsig() == nil {
+		t.Error("expected signed reply")
+	}
+	if len(updater.added) != 2 || updater.added[0] != "api.gslb.example.com/10.0.1.3" ||
+		updater.added[1] != "api.gslb.example.com/2001:db8::3" {
+		t.Errorf("unexpected additions: %v", updater.added)
+	}
+	if len(updater.removed) != 1 || updater.removed[0] != "api.gslb.example.com/10.0.1.1" {
+		t.Errorf("unexpected removals: %v", updater.removed)
+	}
+
+	// Deleting the A RRset removes every IPv4 backend
+	updater.removed = nil
+	resp = sendUpdate(handler, func(m *dns.Msg) {
+		m.RemoveRRset([]dns.RR{mustRR(t, "api.gslb.example.com. 0 IN A 0.0.0.0")})
+	})
+	if resp == nil || resp.Rcode != dns.RcodeSuccess {
+		t.Fatalf("expected NOERROR, got %v", resp)
+	}
+	if len(updater.removed) != 2 {
+		t.Errorf("expected both IPv4 backends removed, got %v", updater.removed)
+	}
+}
+
+func TestHandler_UpdateRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(m *dns.Msg)
+		rcode int
+	}{
+		{
+			name:  "name outside the zone",
+			build: func(m *dns.Msg) { m.Insert([]dns.RR{mustRR(t, "api.other.example. 60 IN A 10.0.1.3")}) },
+			rcode: dns.RcodeNotZone,
+		},
+		{
+			name:  "unknown domain",
+			build: func(m *dns.Msg) { m.Insert([]dns.RR{mustRR(t, "new.gslb.example.com. 60 IN A 10.0.1.3")}) },
+			rcode: dns.RcodeRefused,
+		},
+		{
+			name:  "unsupported type",
+			build: func(m *dns.Msg) { m.Insert([]dns.RR{mustRR(t, `api.gslb.example.com. 60 IN TXT "x"`)}) },
+			rcode: dns.RcodeRefused,
+		},
+		{
+			name:  "prerequisites",
+			build: func(m *dns.Msg) { m.NameUsed([]dns.RR{mustRR(t, "api.gslb.example.com. 0 IN A 0.0.0.0")}) },
+			rcode: dns.RcodeNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, updater := newUpdateTestHandler(t)
+			resp := sendUpdate(handler, func(m *dns.Msg) {
+				// A valid record first: nothing is applied when any record fails
+				m.Insert([]dns.RR{mustRR(t, "api.gslb.example.com. 60 IN A 10.0.1.4")})
+				tt.build(m)
+			})
+			if resp == nil || resp.Rcode != tt.rcode {
+				t.Fatalf("expected %s, got %v", dns.RcodeToString[tt.rcode], resp)
+			}
+			if len(updater.added) != 0 || len(updater.removed) != 0 {
+				t.Errorf("expected no changes, got added=%v removed=%v", updater.added, updater.removed)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateAccess(t *testing.T) {
+	handler, updater := newUpdateTestHandler(t)
+	insert := []dns.RR{mustRR(t, "api.gslb.example.com. 60 IN A 10.0.1.3")}
+
+	// Unsigned updates are refused
+	req := new(dns.Msg)
+	req.SetUpdate("gslb.example.com.")
+	req.Insert(insert)
+	w := newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000})
+	handler.ServeDNS(w, req)
+	if len(w.msgs) != 1 || w.msgs[0].Rcode != dns.RcodeRefused {
+		t.Fatalf("expected REFUSED for unsigned update, got %v", w.msgs)
+	}
+
+	// A signature that fails verification is NOTAUTH
+	req.SetTsig("transfer-key.", dns.HmacSHA256, 300, time.Now().Unix())
+	w = newTransferWriter(&net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000})
+	w.tsigStatus = dns.ErrSig
+	handler.ServeDNS(w, req)
+	if len(w.msgs) != 1 || w.msgs[0].Rcode != dns.RcodeNotAuth {
+		t.Fatalf("expected NOTAUTH for bad signature, got %v", w.msgs)
+	}
+
+	// A zone that is not served is NOTAUTH
+	resp := sendUpdate(handler, func(m *dns.Msg) {
+		m.Question[0].Name = "other.example."
+		m.Insert([]dns.RR{mustRR(t, "api.other.example. 60 IN A 10.0.1.3")})
+	})
+	if resp == nil || resp.Rcode != dns.RcodeNotAuth {
+		t.Fatalf("expected NOTAUTH for unknown zone, got %v", resp)
+	}
+
+	// Keys not listed for updates are refused
+	handler.SetUpdate(updater, nil)
+	resp = sendUpdate(handler, func(m *dns.Msg) { m.Insert(insert) })
+	if resp == nil || resp.Rcode != dns.RcodeRefused {
+		t.Fatalf("expected REFUSED without update keys, got %v", resp)
+	}
+	if len(updater.added) != 0 {
+		t.Errorf("expected no changes, got %v", updater.added)
+	}
+}
+
+func TestHandler_UpdateFailure(t *testing.T) {
+	handler, updater := newUpdateTestHandler(t)
+	updater.err = errors.New("registry unavailable")
+
+	resp := sendUpdate(handler, func(m *dns.Msg) {
+		m.Insert([]dns.RR{mustRR(t, "api.gslb.example.com. 60 IN A 10.0.1.3")})
+	})
+	if resp == nil || resp.Rcode != dns.RcodeServerFailure {
+		t.Fatalf("expected SERVFAIL, got %v", resp)
+	}
+}
+
+func TestHandler_UpdateRefused(t *testing.T) {
+	handler, updater := newUpdateTestHandler(t)
+	updater.err = fmt.Errorf("%w: registered by static", ErrUpdateRefused)
+
+	resp := sendUpdate(handler, func(m *dns.Msg) {
+		m.Insert([]dns.RR{mustRR(t, "api.gslb.example.com. 60 IN A 10.0.1.3")})
+	})
+	if resp == nil || resp.Rcode != dns.RcodeRefused {
+		t.Fatalf("expected REFUSED, got %v", resp)
+	}
+}
+
+func TestAcceptMsg(t *testing.T) {
+	update := new(dns.Msg)
+	update.SetUpdate("gslb.example.com.")
+	update.Insert([]dns.RR{
+		&dns.A{Hdr: dns.RR_Header{Name: "api.gslb.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET}, A: net.ParseIP("10.0.1.3")},
+		&dns.A{Hdr: dns.RR_Header{Name: "api.gslb.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET}, A: net.ParseIP("10.0.1.4")},
+	})
+	if action := acceptMsg(headerOf(t, update)); action != dns.MsgAccept {
+		t.Errorf("expected update to be accepted, got %v", action)
+	}
+
+	query := new(dns.Msg)
+	query.SetQuestion("api.gslb.example.com.", dns.TypeA)
+	query.Response = true
+	if action := acceptMsg(headerOf(t, query)); action != dns.MsgIgnore {
+		t.Errorf("expected responses to be ignored, got %v", action)
+	}
+}
+
+// headerOf returns the wire header of m, as seen by a MsgAcceptFunc.
+func headerOf(t *testing.T, m *dns.Msg) dns.Header {
+	t.Helper()
+	packed, err := m.Pack()
+	if err != nil {
+		t.Fatalf("failed to pack: %v", err)
+	}
+	return dns.Header{
+		Id:      uint16(packed[0])<<8 | uint16(packed[1]),
+		Bits:    uint16(packed[2])<<8 | uint16(packed[3]),
+		Qdcount: uint16(packed[4])<<8 | uint16(packed[5]),
+		Ancount: uint16(packed[6])<<8 | uint16(packed[7]),
+		Nscount: uint16(packed[8])<<8 | uint16(packed[9]),
+		Arcount: uint16(packed[10])<<8 | uint16(packed[11]),
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/miekg/dns"
+)
+
+// ViewSettings selects the clients of a split-horizon view.
+type ViewSettings struct {
+	Name         string
+	MatchClients []*net.IPNet // Source networks of the view's clients
+	Keys         []string     // Fully qualified TSIG key names selecting the view
+}
+
+// BuildViews creates the view selection settings from the views section.
+func BuildViews(cfg *config.Config) ([]ViewSettings, error) {
+	views := make([]ViewSettings, 0, len(cfg.Views))
+	for _, view := range cfg.Views {
+		settings := ViewSettings{Name: view.Name}
+		for _, entry := range view.MatchClients {
+			network, err := config.ParseNetwork(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid match_clients entry in view %s: %w", view.Name, err)
+			}
+			settings.MatchClients = append(settings.MatchClients, network)
+		}
+		for _, name := range view.TSIGKeys {
+			settings.Keys = append(settings.Keys, dns.Fqdn(strings.ToLower(name)))
+		}
+		views = append(views, settings)
+	}
+	return views, nil
+}
+
+// SetViews replaces the view selection settings. The view registries are
+// held by the handler's registry.
+func (h *Handler) SetViews(views []ViewSettings) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.views = views
+}
+
+// selectView returns the name of the view answering r and its registry.
+// A query signed with a view's TSIG key selects that view; otherwise the
+// first view matching the source address does. Clients matching no view get
+// the default view.
+func (h *Handler) selectView(w dns.ResponseWriter, r *dns.Msg) (string, *Registry) {
+	key, ok := verifiedKey(w, r)
+	if !ok {
+		key = ""
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if key != "" && !h.keyring.Has(key) {
+		key = ""
+	}
+	return h.matchView(key, addrIP(w.RemoteAddr()))
+}
+
+// matchView returns the view selected by a TSIG key name or, failing that,
+// by client address.
+// Caller must hold h.mu.
+func (h *Handler) matchView(key string, ip net.IP) (string, *Registry) {
+	if h.registry == nil {
+		return config.DefaultViewName, nil
+	}
+	if key != "" {
+		for _, view := range h.views {
+			if slices.Contains(view.Keys, key) {
+				return view.Name, h.viewRegistry(view.Name)
+			}
+		}
+	}
+	if ip != nil {
+		for _, view := range h.views {
+			for _, network := range view.MatchClients {
+				if network.Contains(ip) {
+					return view.Name, h.viewRegistry(view.Name)
+				}
+			}
+		}
+	}
+	return config.DefaultViewName, h.registry
+}
+
+// viewRegistry returns the registry of a view, falling back to the default
+// registry when the view overrides no domains.
+// Caller must hold h.mu.
+func (h *Handler) viewRegistry(name string) *Registry {
+	if name == config.DefaultViewName {
+		return h.registry
+	}
+	if view := h.registry.View(name); view != nil {
+		return view
+	}
+	return h.registry
+}
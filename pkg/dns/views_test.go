@@ -0,0 +1,399 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/routing"
+	"github.com/miekg/dns"
+)
+
+// newViewTestConfig serves app.example.com from a public region, and from a
+// private region in the internal view.
+func newViewTestConfig() *config.Config {
+	return &config.Config{
+		Regions: []config.Region{
+			{Name: "public", Servers: []config.Server{
+				{Address: "203.0.113.10", Port: 80, Weight: 100, Service: "app.example.com"},
+				{Address: "203.0.113.20", Port: 80, Weight: 100, Service: "www.example.com"},
+			}},
+			{Name: "private", Servers: []config.Server{{Address: "10.0.0.10", Port: 80, Weight: 100, Service: "app.example.com"}}},
+		},
+		Domains: []config.Domain{
+			{Name: "app.example.com", RoutingAlgorithm: "round-robin", Regions: []string{"public"}, TTL: 30},
+			{Name: "www.example.com", RoutingAlgorithm: "round-robin", Regions: []string{"public"}, TTL: 30},
+		},
+		Views: []config.View{{
+			Name:         "internal",
+			MatchClients: []string{"10.0.0.0/8"},
+			TSIGKeys:     []string{"internal-key"},
+			Domains: []config.ViewDomain{
+				{Name: "app.example.com", Regions: []string{"private"}, RoutingAlgorithm: "weighted"},
+			},
+		}},
+	}
+}
+
+func mockRouterFactory(algorithm string) (routing.Router, error) {
+	return &mockRouter{algorithm: algorithm}, nil
+}
+
+func newViewTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	cfg := newViewTestConfig()
+	registry, err := BuildRegistry(cfg, mockRouterFactory, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+	views, err := BuildViews(cfg)
+	if err != nil {
+		t.Fatalf("BuildViews failed: %v", err)
+	}
+	return NewHandler(HandlerConfig{
+		Registry:   registry,
+		DefaultTTL: 60,
+		Keyring: NewKeyring([]TSIGKey{{
+			Name:      "internal-key.",
+			Algorithm: dns.HmacSHA256,
+			Secret:    []byte("0123456789abcdef0123456789abcdef"),
+		}}),
+		Views: views,
+	})
+}
+
+func TestBuildRegistry_Views(t *testing.T) {
+	registry, err := BuildRegistry(newViewTestConfig(), mockRouterFactory, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+
+	view := registry.View("internal")
+	if view == nil {
+		t.Fatal("expected internal view registry")
+	}
+	if view.Count() != 1 {
+		t.Errorf("expected view to hold only its overridden domain, got %d", view.Count())
+	}
+
+	entry := view.Lookup("app.example.com")
+	if entry == nil || entry.View != "internal" || entry.RoutingAlgorithm != "weighted" {
+		t.Fatalf("unexpected view entry: %+v", entry)
+	}
+	if len(entry.Servers) != 1 || !entry.Servers[0].Address.Equal(net.ParseIP("10.0.0.10")) {
+		t.Errorf("expected private server in view, got %v", entry.Servers)
+	}
+
+	// Domains the view does not override come from the default registry
+	if entry, _ := view.LookupMatch("www.example.com"); entry == nil || entry.View != "" {
+		t.Errorf("expected default entry for www.example.com, got %+v", entry)
+	}
+
+	// Dynamic servers reach views using their region only
+	if err := registry.RegisterServer("app.example.com", "10.0.0.11", 80, 100, "private"); err != nil {
+		t.Fatalf("RegisterServer failed: %v", err)
+	}
+	if err := registry.RegisterServer("app.example.com", "203.0.113.11", 80, 100, "public"); err != nil {
+		t.Fatalf("RegisterServer failed: %v", err)
+	}
+	if got := len(view.Lookup("app.example.com").Servers); got != 2 {
+		t.Errorf("expected 2 servers in view, got %d", got)
+	}
+	if err := registry.DeregisterServer("app.example.com", "10.0.0.11", 80); err != nil {
+		t.Fatalf("DeregisterServer failed: %v", err)
+	}
+	if got := len(view.Lookup("app.example.com").Servers); got != 1 {
+		t.Errorf("expected deregistered server removed from view, got %d servers", got)
+	}
+}
+
+func TestHandler_Views(t *testing.T) {
+	handler := newViewTestHandler(t)
+
+	resolve := func(t *testing.T, w *transferWriter, req *dns.Msg) string {
+		t.Helper()
+		handler.ServeDNS(w, req)
+		if len(w.msgs) != 1 || len(w.msgs[0].Answer) != 1 {
+			t.Fatalf("expected one answer, got %v", w.msgs)
+		}
+		return w.msgs[0].Answer[0].(*dns.A).A.String()
+	}
+	newQuery := func(name string) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeA)
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		client string
+		query  string
+		signed bool
+		want   string
+	}{
+		{name: "internet client", client: "198.51.100.1", query: "app.example.com.", want: "203.0.113.10"},
+		{name: "internal client", client: "10.1.2.3", query: "app.example.com.", want: "10.0.0.10"},
+		{name: "signed query", client: "198.51.100.1", query: "app.example.com.", signed: true, want: "10.0.0.10"},
+		{name: "domain not overridden", client: "10.1.2.3", query: "www.example.com.", want: "203.0.113.20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newQuery(tt.query)
+			if tt.signed {
+				req.SetTsig("internal-key.", dns.HmacSHA256, 300, time.Now().Unix())
+			}
+			w := newTransferWriter(&net.UDPAddr{IP: net.ParseIP(tt.client), Port: 53000})
+			if got := resolve(t, w, req); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if tt.signed && w.msgs[0].IsTsig() == nil {
+				t.Error("expected signed reply to a signed query")
+			}
+		})
+	}
+
+	// A signature that fails verification does not select the view
+	req := newQuery("app.example.com.")
+	req.SetTsig("internal-key.", dns.HmacSHA256, 300, time.Now().Unix())
+	w := newTransferWriter(&net.UDPAddr{IP: net.ParseIP("198.51.100.1"), Port: 53000})
+	w.tsigStatus = dns.ErrSig
+	if got := resolve(t, w, req); got != "203.0.113.10" {
+		t.Errorf("expected public answer for bad signature, got %s", got)
+	}
+}
+
+func TestHandler_Route(t *testing.T) {
+	handler := newViewTestHandler(t)
+
+	route, err := handler.Route("app.example.com.", dns.TypeA, net.ParseIP("10.1.2.3"), "")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if route.View != "internal" || route.Decision != RouteSuccess || route.Algorithm != "weighted" ||
+		len(route.Selected) != 1 || route.Selected[0].Address != "10.0.0.10" {
+		t.Errorf("unexpected route: %+v", route)
+	}
+
+	// An explicit view overrides the client address
+	route, err = handler.Route("app.example.com.", dns.TypeA, net.ParseIP("10.1.2.3"), config.DefaultViewName)
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if route.View != config.DefaultViewName || len(route.Selected) != 1 || route.Selected[0].Address != "203.0.113.10" {
+		t.Errorf("unexpected route: %+v", route)
+	}
+
+	route, err = handler.Route("missing.example.com.", dns.TypeA, net.ParseIP("198.51.100.1"), "")
+	if err != nil || route.Decision != RouteDomainNotFound {
+		t.Errorf("expected domain_not_found, got %+v (err %v)", route, err)
+	}
+	route, err = handler.Route("app.example.com.", dns.TypeAAAA, net.ParseIP("198.51.100.1"), "")
+	if err != nil || route.Decision != RouteNoHealthyBackend {
+		t.Errorf("expected no_healthy_backend, got %+v (err %v)", route, err)
+	}
+
+	if _, err := handler.Route("app.example.com.", dns.TypeA, nil, "partners"); !errors.Is(err, ErrUnknownView) {
+		t.Errorf("expected ErrUnknownView, got %v", err)
+	}
+}
+
+func TestHandler_RouteHasNoSideEffects(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.example.com",
+		TTL:    60,
+		Router: routing.NewRoundRobinRouter(),
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+			{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 100},
+		},
+	})
+	handler := NewHandler(HandlerConfig{Registry: registry, HealthProvider: newMockHealthProvider(), DefaultTTL: 60})
+
+	// Testing the route repeatedly does not take the next client's turn
+	for i := 0; i < 3; i++ {
+		route, err := handler.Route("app.example.com.", dns.TypeA, net.ParseIP("192.0.2.1"), "")
+		if err != nil || route.Selected[0].Address != "10.0.0.1" {
+			t.Fatalf("expected the test to show 10.0.0.1, got %+v (err %v)", route, err)
+		}
+	}
+	for _, want := range []string{"10.0.0.1", "10.0.0.2"} {
+		resp := query(t, handler, "app.example.com.", dns.TypeA)
+		if len(resp.Answer) != 1 || !resp.Answer[0].(*dns.A).A.Equal(net.ParseIP(want)) {
+			t.Errorf("expected %s, got %v", want, resp.Answer)
+		}
+	}
+}
+
+func TestHandler_RoutePipeline(t *testing.T) {
+	cfg := newViewTestConfig()
+	cfg.Regions[0].Servers = append(cfg.Regions[0].Servers, config.Server{
+		Address: "203.0.113.11", Port: 80, Weight: 100, Service: "app.example.com",
+		Labels: map[string]string{"version": "v2"},
+	})
+	cfg.Domains[0].RoutingAlgorithm = config.RoutingAlgorithmPipeline
+	cfg.Domains[0].RoutingPipeline = []config.RoutingStage{
+		{Type: config.StageLabels, Match: map[string]string{"version": "v2"}},
+		{Type: config.StageSelect, Algorithm: "failover"},
+	}
+
+	if _, err := BuildRegistry(cfg, mockRouterFactory, nil); err == nil {
+		t.Error("expected an error without a pipeline factory")
+	}
+	registry, err := BuildRegistry(cfg, mockRouterFactory, routing.NewFactory(routing.FactoryConfig{}).NewPipeline)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+	views, err := BuildViews(cfg)
+	if err != nil {
+		t.Fatalf("BuildViews failed: %v", err)
+	}
+	handler := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60, Views: views})
+
+	route, err := handler.Route("app.example.com.", dns.TypeA, net.ParseIP("198.51.100.1"), "")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if route.Algorithm != routing.AlgorithmPipeline || len(route.Selected) != 1 || route.Selected[0].Address != "203.0.113.11" {
+		t.Errorf("unexpected route: %+v", route)
+	}
+	if len(route.Stages) != 2 || route.Stages[0].Stage != routing.StageLabels ||
+		len(route.Stages[0].Input) != 2 || len(route.Stages[0].Output) != 1 {
+		t.Errorf("expected the label stage to narrow the pool to one server, got %+v", route.Stages)
+	}
+
+	// The view's routing algorithm replaces the pipeline
+	route, err = handler.Route("app.example.com.", dns.TypeA, net.ParseIP("10.1.2.3"), "")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if route.Algorithm != "weighted" || len(route.Stages) != 0 {
+		t.Errorf("expected the view's algorithm without stages, got %+v", route)
+	}
+}
+
+func TestHandler_RouteTrafficSplit(t *testing.T) {
+	cfg := newViewTestConfig()
+	cfg.Views = nil
+	cfg.Regions[0].Servers = append(cfg.Regions[0].Servers, config.Server{
+		Address: "203.0.113.11", Port: 80, Weight: 100, Service: "app.example.com",
+		Labels: map[string]string{"version": "v2"},
+	})
+	cfg.Domains[0].TrafficSplit = []config.TrafficSplit{{Match: map[string]string{"version": "v2"}, Percent: 50}}
+
+	registry, err := BuildRegistry(cfg, mockRouterFactory, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+	handler := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60})
+
+	groups := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		clientIP := net.IPv4(198, 51, byte(i), 1)
+		route, err := handler.Route("app.example.com.", dns.TypeA, clientIP, "")
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		if len(route.Stages) != 1 || route.Stages[0].Stage != routing.StageTrafficSplit || len(route.Stages[0].Output) != 1 {
+			t.Fatalf("expected a traffic-split stage narrowing the pool to one server, got %+v", route.Stages)
+		}
+		group := route.Stages[0].Detail
+		groups[group]++
+
+		// A client's subnet stays in its group
+		again, err := handler.Route("app.example.com.", dns.TypeA, net.IPv4(198, 51, byte(i), 200), "")
+		if err != nil || again.Stages[0].Detail != group {
+			t.Fatalf("client subnet %s moved from group %s", clientIP, group)
+		}
+	}
+	if groups["version=v2"] == 0 || groups["default"] == 0 {
+		t.Errorf("expected clients in both groups, got %v", groups)
+	}
+
+	// Agent-reported labels move a server into a group
+	if err := registry.SetServerLabels("app.example.com", "203.0.113.10", 80, map[string]string{"version": "v2"}); err != nil {
+		t.Fatalf("SetServerLabels failed: %v", err)
+	}
+	entry := registry.Lookup("app.example.com")
+	for _, server := range entry.Servers {
+		if server.Labels["version"] != "v2" {
+			t.Errorf("expected server %s to carry version=v2, got %v", server.Address, server.Labels)
+		}
+	}
+	if err := registry.SetServerLabels("app.example.com", "203.0.113.99", 80, nil); err == nil {
+		t.Error("expected an error for an unknown server")
+	}
+}
+
+func TestRegistry_FailoverTiers(t *testing.T) {
+	cfg := &config.Config{
+		Regions: []config.Region{
+			{Name: "primary", Priority: 1, Servers: []config.Server{
+				{Address: "203.0.113.10", Port: 80, Weight: 100, Service: "app.example.com"},
+				{Address: "203.0.113.11", Port: 80, Weight: 100, Service: "app.example.com"},
+				{Address: "203.0.113.12", Port: 80, Weight: 100, Service: "app.example.com", Priority: 3},
+			}},
+			{Name: "secondary", Priority: 2, Servers: []config.Server{
+				{Address: "198.51.100.10", Port: 80, Weight: 100, Service: "app.example.com"},
+			}},
+		},
+		Domains: []config.Domain{{
+			Name:             "app.example.com",
+			RoutingAlgorithm: "failover",
+			Regions:          []string{"primary", "secondary"},
+			TTL:              30,
+			Failover:         &config.FailoverConfig{MinHealthy: 2, FailbackHoldDown: time.Minute},
+		}},
+	}
+	factory := routing.NewFactory(routing.FactoryConfig{})
+	registry, err := BuildRegistry(cfg, factory.NewRouter, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry failed: %v", err)
+	}
+
+	tiers, policy, ok := registry.FailoverTiers("app.example.com")
+	if !ok {
+		t.Fatal("expected failover tiers")
+	}
+	if policy.MinHealthy != 2 || policy.FailbackHoldDown != time.Minute {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+	// Before the first query only the tier sizes are known
+	if len(tiers) != 3 || tiers[0].Servers != 2 || tiers[1].Servers != 1 || tiers[2].Priority != 3 || tiers[0].Active {
+		t.Errorf("unexpected tiers: %+v", tiers)
+	}
+
+	handler := NewHandler(HandlerConfig{Registry: registry, DefaultTTL: 60})
+	route, err := handler.Route("app.example.com.", dns.TypeA, net.ParseIP("192.0.2.1"), "")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if route.Selected[0].Address != "203.0.113.10" {
+		t.Errorf("expected the primary tier, got %+v", route.Selected)
+	}
+	// Testing the route leaves the tiers to real queries
+	if tiers, _, _ = registry.FailoverTiers("app.example.com"); tiers[0].Active {
+		t.Errorf("expected no active tier after a route test, got %+v", tiers)
+	}
+
+	resp := query(t, handler, "app.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 || !resp.Answer[0].(*dns.A).A.Equal(net.ParseIP("203.0.113.10")) {
+		t.Errorf("expected the primary tier, got %v", resp.Answer)
+	}
+	tiers, _, _ = registry.FailoverTiers("app.example.com")
+	if !tiers[0].Active || tiers[0].Healthy != 2 || tiers[1].Active {
+		t.Errorf("expected the primary tier active, got %+v", tiers)
+	}
+
+	if _, _, ok := registry.FailoverTiers("missing.example.com"); ok {
+		t.Error("expected no tiers for an unknown domain")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/miekg/dns"
+)
+
+// Nameserver is an authoritative nameserver published in a zone's NS set.
+type Nameserver struct {
+	Name    string
+	Address net.IP // Optional: served as glue when Name is inside the zone
+}
+
+// SOASettings contains the fields of a zone's SOA record.
+// Timer values are in seconds.
+type SOASettings struct {
+	PrimaryNS string
+	Mailbox   string
+	Serial    uint32
+	Refresh   uint32
+	Retry     uint32
+	Expire    uint32
+	Minimum   uint32
+}
+
+// Zone is an authoritative zone whose apex carries SOA and NS records.
+type Zone struct {
+	Name        string
+	TTL         uint32 // TTL for the apex SOA and NS RRsets
+	SOA         SOASettings
+	Nameservers []Nameserver
+}
+
+// BuildZones creates the authoritative zones listed in dns.zones.
+// A zero SOA serial is replaced with the current Unix time so that restarts
+// always move the serial forward.
+func BuildZones(cfg *config.Config) ([]*Zone, error) {
+	nameservers := make([]Nameserver, 0, len(cfg.DNS.Nameservers))
+	for _, ns := range cfg.DNS.Nameservers {
+		var ip net.IP
+		if ns.Address != "" {
+			ip = net.ParseIP(ns.Address)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address for nameserver %s: %s", ns.Name, ns.Address)
+			}
+		}
+		nameservers = append(nameservers, Nameserver{
+			Name:    dns.Fqdn(strings.ToLower(ns.Name)),
+			Address: ip,
+		})
+	}
+
+	soaCfg := cfg.DNS.SOA
+	serial := soaCfg.Serial
+	if serial == 0 {
+		serial = uint32(time.Now().Unix())
+	}
+
+	zones := make([]*Zone, 0, len(cfg.DNS.Zones))
+	for _, name := range cfg.DNS.Zones {
+		zoneName := normalizeDomain(strings.ToLower(name))
+
+		primary := soaCfg.PrimaryNS
+		if primary == "" && len(nameservers) > 0 {
+			primary = nameservers[0].Name
+		}
+		if primary == "" {
+			primary = "ns1." + zoneName
+		}
+
+		mailbox := soaCfg.Mailbox
+		if mailbox == "" {
+			mailbox = "hostmaster." + zoneName
+		}
+
+		zones = append(zones, &Zone{
+			Name: zoneName,
+			TTL:  uint32(cfg.DNS.DefaultTTL),
+			SOA: SOASettings{
+				PrimaryNS: dns.Fqdn(primary),
+				Mailbox:   dns.Fqdn(strings.Replace(mailbox, "@", ".", 1)),
+				Serial:    serial,
+				Refresh:   uint32(soaCfg.Refresh / time.Second),
+				Retry:     uint32(soaCfg.Retry / time.Second),
+				Expire:    uint32(soaCfg.Expire / time.Second),
+				Minimum:   uint32(soaCfg.Minimum / time.Second),
+			},
+			Nameservers: nameservers,
+		})
+	}
+
+	return zones, nil
+}
+
+// IsApex reports whether name is the apex of the zone.
+func (z *Zone) IsApex(name string) bool {
+	return strings.EqualFold(normalizeDomain(name), z.Name)
+}
+
+// Contains reports whether name is at or below the zone apex.
+func (z *Zone) Contains(name string) bool {
+	return dns.IsSubDomain(z.Name, strings.ToLower(normalizeDomain(name)))
+}
+
+// SOARecord returns the SOA record for the zone apex.
+func (z *Zone) SOARecord() *dns.SOA {
+	return &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name:   z.Name,
+			Rrtype: dns.TypeSOA,
+			Class:  dns.ClassINET,
+			Ttl:    z.TTL,
+		},
+		Ns:      z.SOA.PrimaryNS,
+		Mbox:    z.SOA.Mailbox,
+		Serial:  z.SOA.Serial,
+		Refresh: z.SOA.Refresh,
+		Retry:   z.SOA.Retry,
+		Expire:  z.SOA.Expire,
+		Minttl:  z.SOA.Minimum,
+	}
+}
+
+// NegativeSOARecord returns the SOA record for the authority section of
+// NXDOMAIN and NODATA responses. Per RFC 2308 its TTL is the lesser of the
+// SOA TTL and the SOA minimum field.
+func (z *Zone) NegativeSOARecord() *dns.SOA {
+	soa := z.SOARecord()
+	if z.SOA.Minimum < soa.Hdr.Ttl {
+		soa.Hdr.Ttl = z.SOA.Minimum
+	}
+	return soa
+}
+
+// NSRecords returns the NS RRset for the zone apex.
+func (z *Zone) NSRecords() []dns.RR {
+	records := make([]dns.RR, 0, len(z.Nameservers))
+	for _, ns := range z.Nameservers {
+		records = append(records, &dns.NS{
+			Hdr: dns.RR_Header{
+				Name:   z.Name,
+				Rrtype: dns.TypeNS,
+				Class:  dns.ClassINET,
+				Ttl:    z.TTL,
+			},
+			Ns: ns.Name,
+		})
+	}
+	return records
+}
+
+// GlueRecords returns A/AAAA records for nameservers that live inside the zone.
+// Out-of-zone nameservers are resolved through their own zones and get no glue.
+func (z *Zone) GlueRecords() []dns.RR {
+	var records []dns.RR
+	for _, ns := range z.Nameservers {
+		if ns.Address == nil || !z.Contains(ns.Name) {
+			continue
+		}
+		records = append(records, addressRecord(ns.Name, ns.Address, z.TTL))
+	}
+	return records
+}
+
+// NameserverAddress returns the address record for an in-zone nameserver
+// name, or nil if name is not one of the zone's nameservers or the address
+// family does not match qtype.
+func (z *Zone) NameserverAddress(name string, qtype uint16) dns.RR {
+	name = normalizeDomain(name)
+	for _, ns := range z.Nameservers {
+		if ns.Address == nil || !strings.EqualFold(ns.Name, name) || !z.Contains(ns.Name) {
+			continue
+		}
+		isIPv4 := ns.Address.To4() != nil
+		if (qtype == dns.TypeA && isIPv4) || (qtype == dns.TypeAAAA && !isIPv4) {
+			return addressRecord(ns.Name, ns.Address, z.TTL)
+		}
+	}
+	return nil
+}
+
+// IsNameserver reports whether name is one of the zone's in-zone nameservers.
+func (z *Zone) IsNameserver(name string) bool {
+	name = normalizeDomain(name)
+	for _, ns := range z.Nameservers {
+		if strings.EqualFold(ns.Name, name) && z.Contains(ns.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressRecord builds the A or AAAA record for ip at name.
+func addressRecord(name string, ip net.IP, ttl uint32) dns.RR {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &dns.A{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+			A:   ip4,
+		}
+	}
+	return &dns.AAAA{
+		Hdr:  dns.RR_Header{Name: name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: ttl},
+		AAAA: ip,
+	}
+}
+
+// findZone returns the most specific zone containing name, or nil.
+func findZone(zones []*Zone, name string) *Zone {
+	var best *Zone
+	for _, z := range zones {
+		if z.Contains(name) && (best == nil || len(z.Name) > len(best.Name)) {
+			best = z
+		}
+	}
+	return best
+}
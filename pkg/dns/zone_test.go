@@ -0,0 +1,202 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/miekg/dns"
+)
+
+// newZoneTestHandler returns a handler serving gslb.example.com with one domain.
+func newZoneTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	cfg := &config.Config{
+		DNS: config.DNSConfig{
+			DefaultTTL: 300,
+			Zones:      []string{"gslb.example.com"},
+			SOA: config.SOAConfig{
+				Serial:  2025010101,
+				Refresh: time.Hour,
+				Retry:   10 * time.Minute,
+				Expire:  7 * 24 * time.Hour,
+				Minimum: 30 * time.Second,
+			},
+			Nameservers: []config.NameserverConfig{
+				{Name: "ns1.gslb.example.com", Address: "192.0.2.53"},
+				{Name: "ns2.example.net"},
+			},
+		},
+	}
+	zones, err := BuildZones(cfg)
+	if err != nil {
+		t.Fatalf("BuildZones failed: %v", err)
+	}
+
+	registry := NewRegistry()
+	registry.Register(&DomainEntry{
+		Name:   "app.gslb.example.com",
+		TTL:    30,
+		Router: &mockRouter{},
+		Servers: []ServerInfo{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 100},
+		},
+	})
+
+	return NewHandler(HandlerConfig{
+		Registry:   registry,
+		DefaultTTL: 60,
+		Zones:      zones,
+	})
+}
+
+func TestBuildZones(t *testing.T) {
+	cfg := &config.Config{
+		DNS: config.DNSConfig{
+			DefaultTTL:  60,
+			Zones:       []string{"GSLB.Example.com"},
+			Nameservers: []config.NameserverConfig{{Name: "ns1.example.net"}},
+			SOA:         config.SOAConfig{Mailbox: "ops@example.com", Minimum: time.Minute},
+		},
+	}
+
+	zones, err := BuildZones(cfg)
+	if err != nil {
+		t.Fatalf("BuildZones failed: %v", err)
+	}
+	if len(zones) != 1 {
+		t.Fatalf("expected 1 zone, got %d", len(zones))
+	}
+
+	z := zones[0]
+	if z.Name != "gslb.example.com." {
+		t.Errorf("expected normalized zone name, got %q", z.Name)
+	}
+	if z.SOA.PrimaryNS != "ns1.example.net." {
+		t.Errorf("expected primary NS from first nameserver, got %q", z.SOA.PrimaryNS)
+	}
+	if z.SOA.Mailbox != "ops.example.com." {
+		t.Errorf("expected mailbox ops.example.com., got %q", z.SOA.Mailbox)
+	}
+	if z.SOA.Serial == 0 {
+		t.Error("expected serial to be derived when not configured")
+	}
+	if z.SOA.Minimum != 60 {
+		t.Errorf("expected minimum 60, got %d", z.SOA.Minimum)
+	}
+}
+
+func TestHandler_SOAAtApex(t *testing.T) {
+	h := newZoneTestHandler(t)
+
+	resp := query(t, h, "gslb.example.com.", dns.TypeSOA)
+	if resp.Rcode != dns.RcodeSuccess {
+		t.Fatalf("expected NOERROR, got %s", dns.RcodeToString[resp.Rcode])
+	}
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected 1 answer, got %d", len(resp.Answer))
+	}
+	soa, ok := resp.Answer[0].(*dns.SOA)
+	if !ok {
+		t.Fatalf("expected SOA answer, got %T", resp.Answer[0])
+	}
+	if soa.Serial != 2025010101 || soa.Refresh != 3600 || soa.Minttl != 30 {
+		t.Errorf("unexpected SOA fields: %s", soa.String())
+	}
+	if soa.Ns != "ns1.gslb.example.com." {
+		t.Errorf("expected MNAME ns1.gslb.example.com., got %q", soa.Ns)
+	}
+}
+
+func TestHandler_NSAtApexWithGlue(t *testing.T) {
+	h := newZoneTestHandler(t)
+
+	resp := query(t, h, "gslb.example.com.", dns.TypeNS)
+	if len(resp.Answer) != 2 {
+		t.Fatalf("expected 2 NS records, got %d", len(resp.Answer))
+	}
+	// Only the in-zone nameserver gets glue
+	if len(resp.Extra) != 1 {
+		t.Fatalf("expected 1 glue record, got %d", len(resp.Extra))
+	}
+	glue, ok := resp.Extra[0].(*dns.A)
+	if !ok || glue.Hdr.Name != "ns1.gslb.example.com." || !glue.A.Equal(net.ParseIP("192.0.2.53")) {
+		t.Errorf("unexpected glue record: %v", resp.Extra[0])
+	}
+}
+
+func TestHandler_NameserverAddress(t *testing.T) {
+	h := newZoneTestHandler(t)
+
+	resp := query(t, h, "ns1.gslb.example.com.", dns.TypeA)
+	if len(resp.Answer) != 1 {
+		t.Fatalf("expected nameserver A record, got %d answers", len(resp.Answer))
+	}
+
+	// The nameserver exists but has no IPv6 address: NODATA with SOA
+	resp = query(t, h, "ns1.gslb.example.com.", dns.TypeAAAA)
+	if resp.Rcode != dns.RcodeSuccess || len(resp.Answer) != 0 {
+		t.Fatalf("expected NODATA, got %s with %d answers", dns.RcodeToString[resp.Rcode], len(resp.Answer))
+	}
+	if len(resp.Ns) != 1 || resp.Ns[0].Header().Rrtype != dns.TypeSOA {
+		t.Errorf("expected SOA in authority section, got %v", resp.Ns)
+	}
+}
+
+func TestHandler_NegativeResponsesCarrySOA(t *testing.T) {
+	h := newZoneTestHandler(t)
+
+	tests := []struct {
+		name  string
+		qname string
+		qtype uint16
+		rcode int
+	}{
+		{"NXDOMAIN", "missing.gslb.example.com.", dns.TypeA, dns.RcodeNameError},
+		{"NODATA at apex", "gslb.example.com.", dns.TypeA, dns.RcodeSuccess},
+		{"SOA below apex", "app.gslb.example.com.", dns.TypeSOA, dns.RcodeSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, h, tt.qname, tt.qtype)
+			if resp.Rcode != tt.rcode {
+				t.Fatalf("expected %s, got %s", dns.RcodeToString[tt.rcode], dns.RcodeToString[resp.Rcode])
+			}
+			if len(resp.Answer) != 0 {
+				t.Fatalf("expected empty answer, got %v", resp.Answer)
+			}
+			if len(resp.Ns) != 1 {
+				t.Fatalf("expected SOA in authority section, got %v", resp.Ns)
+			}
+			soa, ok := resp.Ns[0].(*dns.SOA)
+			if !ok {
+				t.Fatalf("expected SOA, got %T", resp.Ns[0])
+			}
+			// RFC 2308: negative TTL is min(SOA TTL, minimum)
+			if soa.Hdr.Ttl != 30 {
+				t.Errorf("expected negative TTL 30, got %d", soa.Hdr.Ttl)
+			}
+		})
+	}
+}
+
+func TestHandler_NoSOAOutsideZones(t *testing.T) {
+	h := newZoneTestHandler(t)
+
+	resp := query(t, h, "other.example.org.", dns.TypeA)
+	if resp.Rcode != dns.RcodeNameError {
+		t.Fatalf("expected NXDOMAIN, got %s", dns.RcodeToString[resp.Rcode])
+	}
+	if len(resp.Ns) != 0 {
+		t.Errorf("expected no authority records outside configured zones, got %v", resp.Ns)
+	}
+}
@@ -386,6 +386,76 @@ func TestSignerWithKey(t *testing.T) {
 	}
 }
 
+// TestSignerNoDataProof tests that NODATA responses carry a signed NSEC3 proof.
+func TestSignerNoDataProof(t *testing.T) {
+	km := NewKeyManager("test-node")
+	if _, err := km.GenerateKey("example.com.", AlgorithmECDSAP256SHA256); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	nsec3, err := NewNSEC3Manager(DefaultNSEC3Config())
+	if err != nil {
+		t.Fatalf("failed to create NSEC3 manager: %v", err)
+	}
+
+	signer := NewSigner(SignerConfig{
+		KeyManager:   km,
+		NSEC3Manager: nsec3,
+	})
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("www.example.com.", dns.TypeAAAA)
+
+	signed, err := signer.SignNoDataResponse(msg, []uint16{dns.TypeA, dns.TypeRRSIG})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signedMsg := signed.(*dns.Msg)
+
+	var proof *dns.NSEC3
+	hasRRSIG := false
+	for _, rr := range signedMsg.Ns {
+		switch r := rr.(type) {
+		case *dns.NSEC3:
+			proof = r
+		case *dns.RRSIG:
+			if r.TypeCovered == dns.TypeNSEC3 {
+				hasRRSIG = true
+			}
+		}
+	}
+	if proof == nil {
+		t.Fatal("expected NSEC3 record in authority section")
+	}
+	if !hasRRSIG {
+		t.Error("expected RRSIG covering the NSEC3 record")
+	}
+	for _, typ := range proof.TypeBitMap {
+		if typ == dns.TypeAAAA {
+			t.Error("NSEC3 type bitmap must not include the queried type")
+		}
+	}
+	hasA := false
+	for _, typ := range proof.TypeBitMap {
+		if typ == dns.TypeA {
+			hasA = true
+		}
+	}
+	if !hasA {
+		t.Errorf("expected type bitmap to include A, got %v", proof.TypeBitMap)
+	}
+
+	// A plain SignResponse of the same message adds no NSEC3 proof
+	signed, err = signer.SignResponse(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, rr := range signed.(*dns.Msg).Ns {
+		if rr.Header().Rrtype == dns.TypeNSEC3 {
+			t.Error("unexpected NSEC3 record without NODATA types")
+		}
+	}
+}
+
 // TestKeySyncerCreation tests key syncer creation.
 func TestKeySyncerCreation(t *testing.T) {
 	km := NewKeyManager("test-node")
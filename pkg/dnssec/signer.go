@@ -80,13 +80,32 @@ func (s *Signer) SignResponse(msg interface{}) (interface{}, error) {
 	if !ok {
 		return msg, fmt.Errorf("expected *dns.Msg, got %T", msg)
 	}
-	return s.signDNSMessage(dnsMsg)
+	return s.signDNSMessage(dnsMsg, nil)
+}
+
+// SignNoDataResponse implements the DNSSECNoDataSigner interface.
+// It signs a NODATA response and adds an NSEC3 record proving that the
+// queried type is absent from the set of types that exist at the name.
+func (s *Signer) SignNoDataResponse(msg interface{}, existingTypes []uint16) (interface{}, error) {
+	if msg == nil {
+		return msg, nil
+	}
+	dnsMsg, ok := msg.(*dns.Msg)
+	if !ok {
+		return msg, fmt.Errorf("expected *dns.Msg, got %T", msg)
+	}
+	if existingTypes == nil {
+		existingTypes = []uint16{}
+	}
+	return s.signDNSMessage(dnsMsg, existingTypes)
 }
 
 // signDNSMessage signs a DNS response message.
+// noDataTypes is non-nil for NODATA responses and lists the types present at
+// the queried name for the NSEC3 type bitmap.
 // Returns the signed message and any error encountered.
 // If signing fails for any reason, the original message is returned unchanged.
-func (s *Signer) signDNSMessage(msg *dns.Msg) (*dns.Msg, error) {
+func (s *Signer) signDNSMessage(msg *dns.Msg, noDataTypes []uint16) (*dns.Msg, error) {
 	if msg == nil || len(msg.Question) == 0 {
 		return msg, nil
 	}
@@ -126,15 +145,22 @@ func (s *Signer) signDNSMessage(msg *dns.Msg) (*dns.Msg, error) {
 		}
 	}
 
-	// Handle NXDOMAIN with NSEC3
-	if msg.Rcode == dns.RcodeNameError && s.config.NSEC3Manager != nil {
-		nsec3Records := s.config.NSEC3Manager.GenerateNXDOMAIN(zone, msg.Question[0].Name)
-		signed.Ns = append(signed.Ns, nsec3Records...)
-		// Sign the NSEC3 records
-		if err := s.signSection(&signed.Ns, keyPair, zone); err != nil {
+	// Handle NXDOMAIN and NODATA with NSEC3
+	if s.config.NSEC3Manager != nil {
+		var nsec3Records []dns.RR
+		switch {
+		case msg.Rcode == dns.RcodeNameError:
+			nsec3Records = s.config.NSEC3Manager.GenerateNXDOMAIN(zone, msg.Question[0].Name)
+		case noDataTypes != nil && msg.Rcode == dns.RcodeSuccess && len(msg.Answer) == 0:
+			nsec3Records = s.config.NSEC3Manager.GenerateNoData(zone, msg.Question[0].Name, noDataTypes)
+		}
+
+		// Sign the NSEC3 records separately so the authority RRsets are not signed twice
+		if err := s.signSection(&nsec3Records, keyPair, zone); err != nil {
 			s.recordMetrics(zone, time.Since(start), false)
 			return msg, fmt.Errorf("failed to sign NSEC3 records: %w", err)
 		}
+		signed.Ns = append(signed.Ns, nsec3Records...)
 	}
 
 	// Set the AD (Authenticated Data) flag
@@ -12,6 +12,7 @@ import (
 	"log/slog"
 	"net"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/oschwald/geoip2-golang"
@@ -21,6 +22,7 @@ import (
 type Database struct {
 	mu      sync.RWMutex
 	reader  *geoip2.Reader
+	city    bool // City database, with client coordinates
 	path    string
 	logger  *slog.Logger
 	modTime int64
@@ -66,6 +68,7 @@ func (d *Database) load() error {
 	}
 
 	d.reader = reader
+	d.city = strings.Contains(reader.Metadata().DatabaseType, "City")
 	d.modTime = info.ModTime().Unix()
 
 	d.logger.Info("GeoIP database loaded",
@@ -108,12 +111,25 @@ type LookupResult struct {
 	// Continent is the continent code (e.g., "NA" for North America)
 	Continent string
 
+	// Latitude and Longitude are the approximate position of the address,
+	// from a City database
+	Latitude  float64
+	Longitude float64
+
+	// AccuracyRadius is the radius in kilometers around the position the
+	// address is likely within
+	AccuracyRadius int
+
+	// HasLocation indicates whether Latitude and Longitude are set
+	HasLocation bool
+
 	// Found indicates whether the lookup was successful
 	Found bool
 }
 
 // Lookup performs a GeoIP lookup for the given IP address.
-// Returns the country and continent codes.
+// Returns the country and continent codes, and the position of the address
+// when the database is a City database.
 func (d *Database) Lookup(ip net.IP) (*LookupResult, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -122,6 +138,25 @@ func (d *Database) Lookup(ip net.IP) (*LookupResult, error) {
 		return &LookupResult{Found: false}, fmt.Errorf("GeoIP database not loaded")
 	}
 
+	if d.city {
+		record, err := d.reader.City(ip)
+		if err != nil {
+			return &LookupResult{Found: false}, err
+		}
+		loc := record.Location
+		// MaxMind leaves the position empty for addresses it cannot place
+		hasLocation := loc.Latitude != 0 || loc.Longitude != 0 || loc.AccuracyRadius != 0
+		return &LookupResult{
+			Country:        record.Country.IsoCode,
+			Continent:      record.Continent.Code,
+			Latitude:       loc.Latitude,
+			Longitude:      loc.Longitude,
+			AccuracyRadius: int(loc.AccuracyRadius),
+			HasLocation:    hasLocation,
+			Found:          record.Country.IsoCode != "" || record.Continent.Code != "" || hasLocation,
+		}, nil
+	}
+
 	record, err := d.reader.Country(ip)
 	if err != nil {
 		return &LookupResult{Found: false}, err
@@ -152,6 +187,14 @@ func (d *Database) Path() string {
 	return d.path
 }
 
+// HasLocation reports whether lookups return positions, as with a
+// GeoLite2-City or GeoIP2-City database.
+func (d *Database) HasLocation() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.city
+}
+
 // DatabaseType returns the database type string (e.g., "GeoLite2-Country").
 func (d *Database) DatabaseType() string {
 	d.mu.RLock()
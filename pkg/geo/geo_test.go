@@ -7,9 +7,11 @@
 package geo
 
 import (
+	"log/slog"
 	"net"
 	"testing"
 
+	"github.com/loganrossus/OpenGSLB/pkg/config"
 	"github.com/miekg/dns"
 )
 
@@ -326,3 +328,62 @@ func TestAddECSResponse(t *testing.T) {
 		t.Errorf("expected scope 24, got %d", ecs.SourceScope)
 	}
 }
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", 51.5, -0.13, 51.5, -0.13, 0},
+		{"London to New York", 51.507, -0.128, 40.713, -74.006, 5570},
+		{"Virginia to Oregon", 38.9, -77.4, 45.6, -121.2, 3634},
+		{"antipodes", 0, 0, 0, 180, 20015},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if diff := got - tt.want; diff < -tt.want/100-1 || diff > tt.want/100+1 {
+				t.Errorf("expected about %.0f km, got %.0f km", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolver_Locate(t *testing.T) {
+	lat, lon := 45.6, -121.2
+	resolver := &Resolver{
+		database:       &Database{}, // No database loaded
+		customMappings: NewCustomMappings(nil),
+		logger:         slog.Default(),
+	}
+	resolver.loadRegions([]config.Region{
+		{Name: "us-west", Latitude: &lat, Longitude: &lon, Bias: 20},
+		{Name: "us-east"},
+	})
+	if err := resolver.customMappings.LoadFromConfig([]CustomMapping{
+		{CIDR: "10.1.0.0/16", Region: "us-west"},
+		{CIDR: "10.2.0.0/16", Region: "us-east"},
+	}); err != nil {
+		t.Fatalf("failed to load mappings: %v", err)
+	}
+
+	loc, ok := resolver.Locate(net.ParseIP("10.1.2.3"))
+	if !ok || loc.Latitude != lat || loc.Longitude != lon || loc.MatchType != MatchTypeCustomMapping ||
+		loc.Region != "us-west" || loc.MatchedCIDR != "10.1.0.0/16" {
+		t.Errorf("expected the mapped region's position, got %+v (ok %v)", loc, ok)
+	}
+
+	// A region without coordinates cannot place its clients
+	if loc, ok := resolver.Locate(net.ParseIP("10.2.0.1")); ok {
+		t.Errorf("expected no position for a region without coordinates, got %+v", loc)
+	}
+	if loc, ok := resolver.Locate(net.ParseIP("192.0.2.1")); ok {
+		t.Errorf("expected no position without a City database, got %+v", loc)
+	}
+
+	region, ok := resolver.GetRegion("us-west")
+	if !ok || !region.HasCoordinates || region.Bias != 20 {
+		t.Errorf("expected the region's coordinates and bias, got %+v", region)
+	}
+}
@@ -43,6 +43,13 @@ type RegionMatch struct {
 
 	// Comment is the mapping comment (only for custom mapping matches)
 	Comment string
+
+	// Latitude, Longitude and AccuracyRadius (km) are the position of the
+	// IP address (only for GeoIP lookups in a City database)
+	Latitude       float64
+	Longitude      float64
+	AccuracyRadius int
+	HasLocation    bool
 }
 
 // RegionConfig defines geographic mapping for a region.
@@ -50,6 +57,32 @@ type RegionConfig struct {
 	Name       string
 	Countries  []string // ISO country codes
 	Continents []string // Continent codes
+
+	// Latitude and Longitude are the position of the region, valid when
+	// HasCoordinates is set
+	Latitude       float64
+	Longitude      float64
+	HasCoordinates bool
+
+	// Bias expands (positive) or shrinks (negative) the region's
+	// geoproximity catchment, from -99 to 99
+	Bias int
+}
+
+// Location is the position of a client used for geoproximity routing.
+type Location struct {
+	Latitude       float64
+	Longitude      float64
+	AccuracyRadius int // Kilometers; 0 for custom mappings
+
+	// MatchType is custom_mapping when the client is placed at the
+	// position of its mapped region, or geoip
+	MatchType MatchType
+
+	// Region and MatchedCIDR are the mapped region and network (only for
+	// custom mapping matches)
+	Region      string
+	MatchedCIDR string
 }
 
 // Resolver provides unified IP-to-region resolution using custom mappings
@@ -135,6 +168,12 @@ func (r *Resolver) loadRegions(regions []config.Region) {
 			Name:       region.Name,
 			Countries:  region.Countries,
 			Continents: region.Continents,
+			Bias:       region.Bias,
+		}
+		if region.Latitude != nil && region.Longitude != nil {
+			cfg.Latitude = *region.Latitude
+			cfg.Longitude = *region.Longitude
+			cfg.HasCoordinates = true
 		}
 		r.regions[region.Name] = cfg
 
@@ -183,27 +222,29 @@ func (r *Resolver) Resolve(ip net.IP) *RegionMatch {
 
 	geoResult, err := r.database.Lookup(ip)
 	if err == nil && geoResult.Found {
+		match := &RegionMatch{
+			MatchType:      MatchTypeGeoIP,
+			Country:        geoResult.Country,
+			Continent:      geoResult.Continent,
+			Latitude:       geoResult.Latitude,
+			Longitude:      geoResult.Longitude,
+			AccuracyRadius: geoResult.AccuracyRadius,
+			HasLocation:    geoResult.HasLocation,
+		}
+
 		// Try country match first (more specific)
 		if geoResult.Country != "" {
 			if region, ok := r.countryToRegion[strings.ToUpper(geoResult.Country)]; ok {
-				return &RegionMatch{
-					Region:    region,
-					MatchType: MatchTypeGeoIP,
-					Country:   geoResult.Country,
-					Continent: geoResult.Continent,
-				}
+				match.Region = region
+				return match
 			}
 		}
 
 		// Fall back to continent match
 		if geoResult.Continent != "" {
 			if region, ok := r.continentToRegion[strings.ToUpper(geoResult.Continent)]; ok {
-				return &RegionMatch{
-					Region:    region,
-					MatchType: MatchTypeGeoIP,
-					Country:   geoResult.Country,
-					Continent: geoResult.Continent,
-				}
+				match.Region = region
+				return match
 			}
 		}
 	}
@@ -215,6 +256,38 @@ func (r *Resolver) Resolve(ip net.IP) *RegionMatch {
 	}
 }
 
+// Locate returns the position of an IP address for geoproximity routing.
+// A custom mapping places the address at its region's coordinates;
+// otherwise the position comes from a City database. It reports false when
+// neither gives a position.
+func (r *Resolver) Locate(ip net.IP) (Location, bool) {
+	if result := r.customMappings.Lookup(ip); result.Found {
+		r.mu.RLock()
+		region, ok := r.regions[result.Region]
+		r.mu.RUnlock()
+		if ok && region.HasCoordinates {
+			return Location{
+				Latitude:    region.Latitude,
+				Longitude:   region.Longitude,
+				MatchType:   MatchTypeCustomMapping,
+				Region:      region.Name,
+				MatchedCIDR: result.CIDR,
+			}, true
+		}
+	}
+
+	geoResult, err := r.database.Lookup(ip)
+	if err != nil || !geoResult.HasLocation {
+		return Location{}, false
+	}
+	return Location{
+		Latitude:       geoResult.Latitude,
+		Longitude:      geoResult.Longitude,
+		AccuracyRadius: geoResult.AccuracyRadius,
+		MatchType:      MatchTypeGeoIP,
+	}, true
+}
+
 // TestIP returns detailed resolution information for an IP address.
 // This is useful for debugging and the API test endpoint.
 func (r *Resolver) TestIP(ip net.IP) *RegionMatch {
@@ -250,6 +323,12 @@ func (r *Resolver) Close() error {
 	return r.database.Close()
 }
 
+// HasLocation reports whether the GeoIP database returns client positions,
+// as needed by geoproximity routing.
+func (r *Resolver) HasLocation() bool {
+	return r.database.HasLocation()
+}
+
 // DefaultRegion returns the configured default region.
 func (r *Resolver) DefaultRegion() string {
 	r.mu.RLock()
@@ -266,9 +345,13 @@ func (r *Resolver) GetRegion(name string) (*RegionConfig, bool) {
 		return nil, false
 	}
 	return &RegionConfig{
-		Name:       cfg.Name,
-		Countries:  append([]string{}, cfg.Countries...),
-		Continents: append([]string{}, cfg.Continents...),
+		Name:           cfg.Name,
+		Countries:      append([]string{}, cfg.Countries...),
+		Continents:     append([]string{}, cfg.Continents...),
+		Latitude:       cfg.Latitude,
+		Longitude:      cfg.Longitude,
+		HasCoordinates: cfg.HasCoordinates,
+		Bias:           cfg.Bias,
 	}, true
 }
 
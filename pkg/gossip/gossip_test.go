@@ -425,7 +425,7 @@ func TestRegisterDeregisterMessages(t *testing.T) {
 	time.Sleep(1 * time.Second)
 
 	// Send register message
-	err = sender.SendRegister("test-agent", "us-east-1", "api", "10.0.0.1", 8080, 100)
+	err = sender.SendRegister("test-agent", "us-east-1", "api", "10.0.0.1", 8080, 100, map[string]string{"version": "v2"})
 	if err != nil {
 		t.Errorf("SendRegister() error = %v", err)
 	}
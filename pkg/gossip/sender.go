@@ -216,11 +216,14 @@ func (s *MemberlistSender) SendHealthUpdate(msg agent.HealthUpdateMessage) error
 	var backends []overwatch.BackendHeartbeat
 	for _, b := range msg.Backends {
 		backends = append(backends, overwatch.BackendHeartbeat{
-			Service: b.Service,
-			Address: b.Address,
-			Port:    b.Port,
-			Weight:  b.Weight,
-			Healthy: b.Healthy,
+			Service:           b.Service,
+			Address:           b.Address,
+			Port:              b.Port,
+			Weight:            b.Weight,
+			Labels:            b.Labels,
+			Healthy:           b.Healthy,
+			ActiveConnections: b.ActiveConnections,
+			Capacity:          b.Capacity,
 		})
 	}
 
@@ -275,7 +278,7 @@ func (s *MemberlistSender) SendHeartbeat(msg agent.HeartbeatMessage) error {
 }
 
 // SendRegister sends a backend registration message.
-func (s *MemberlistSender) SendRegister(agentID, region, service, address string, port, weight int) error {
+func (s *MemberlistSender) SendRegister(agentID, region, service, address string, port, weight int, labels map[string]string) error {
 	s.mu.RLock()
 	if !s.running || s.list == nil {
 		s.mu.RUnlock()
@@ -293,6 +296,7 @@ func (s *MemberlistSender) SendRegister(agentID, region, service, address string
 			Address: address,
 			Port:    port,
 			Weight:  weight,
+			Labels:  labels,
 		},
 	}
 
@@ -311,6 +311,34 @@ func TestHTTPChecker_CustomStatusCodes(t *testing.T) {
 	}
 }
 
+func TestHTTPChecker_ReportedCapacity(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{header: "250", want: 250},
+		{header: "", want: 0},
+		{header: "lots", want: 0},
+		{header: "-5", want: 0},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.header != "" {
+				w.Header().Set(CapacityHeader, tt.header)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		result := NewHTTPChecker().Check(context.Background(), parseTestServer(server, "/health"))
+		server.Close()
+
+		if result.Capacity != tt.want {
+			t.Errorf("header %q: expected capacity %d, got %d", tt.header, tt.want, result.Capacity)
+		}
+	}
+}
+
 func TestHTTPChecker_NoRedirect(t *testing.T) {
 	redirectServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/other", http.StatusMovedPermanently)
@@ -24,9 +24,14 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// CapacityHeader is the response header in which a backend reports the
+// number of connections it can serve.
+const CapacityHeader = "X-OpenGSLB-Capacity"
+
 // HTTPChecker performs HTTP health checks.
 type HTTPChecker struct {
 	client *http.Client
@@ -194,6 +199,11 @@ func (c *HTTPChecker) Check(ctx context.Context, target Target) Result {
 		result.Error = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
+	// Backends may report how many connections they can serve
+	if capacity, err := strconv.Atoi(resp.Header.Get(CapacityHeader)); err == nil && capacity > 0 {
+		result.Capacity = capacity
+	}
+
 	return result
 }
 
@@ -50,6 +50,9 @@ type Result struct {
 	Latency   time.Duration
 	Error     error
 	Timestamp time.Time
+	// Capacity is the capacity reported by the backend in the
+	// X-OpenGSLB-Capacity response header, or 0 when not reported.
+	Capacity int
 }
 
 // ServerHealth tracks the health state of a single server.
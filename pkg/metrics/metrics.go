@@ -49,6 +49,93 @@ var (
 		},
 		[]string{"domain", "status"},
 	)
+
+	// DNSTransportQueriesTotal counts DNS queries by transport (udp, tcp,
+	// dot, doh) and response code.
+	DNSTransportQueriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_transport_queries_total",
+			Help:      "Total number of DNS queries by transport and response code",
+		},
+		[]string{"transport", "rcode"},
+	)
+
+	// DNSZoneTransfersTotal counts outbound zone transfers by zone, type
+	// (AXFR, IXFR) and result (success, refused, failure, current).
+	DNSZoneTransfersTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_zone_transfers_total",
+			Help:      "Total number of outbound zone transfers by zone, type and result",
+		},
+		[]string{"zone", "type", "result"},
+	)
+
+	// DNSNotifyTotal counts NOTIFY messages sent to secondaries by zone and result.
+	DNSNotifyTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_notify_total",
+			Help:      "Total number of DNS NOTIFY messages sent to secondaries by zone and result",
+		},
+		[]string{"zone", "result"},
+	)
+
+	// DNSUpdatesTotal counts dynamic updates (RFC 2136) by zone and result
+	// (success, refused, notauth, notzone, formerr, notimp, failure).
+	DNSUpdatesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_updates_total",
+			Help:      "Total number of DNS dynamic updates by zone and result",
+		},
+		[]string{"zone", "result"},
+	)
+
+	// DNSRateLimitedTotal counts responses limited by response rate limiting,
+	// by response category (answer, nxdomain, error) and action (dropped, slipped).
+	DNSRateLimitedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_rate_limited_total",
+			Help:      "Total number of DNS responses dropped or slipped by response rate limiting",
+		},
+		[]string{"category", "action"},
+	)
+
+	// DNSQueryLogDroppedTotal counts query log entries that were not written,
+	// by reason (queue_full when the sinks fall behind, or write_error).
+	DNSQueryLogDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_query_log_dropped_total",
+			Help:      "Total number of query log entries dropped",
+		},
+		[]string{"reason"},
+	)
+
+	// DNSFallthroughTotal counts queries for unmanaged names answered by a
+	// zone's fallthrough upstream, by result (cached, forwarded or error).
+	DNSFallthroughTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_fallthrough_total",
+			Help:      "Total number of DNS queries answered by a fallthrough upstream",
+		},
+		[]string{"zone", "result"},
+	)
+
+	// DNSFallbackResponsesTotal counts answers served while every backend of a
+	// domain was unhealthy, by fallback source (last_healthy or sorry_server).
+	DNSFallbackResponsesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dns_fallback_responses_total",
+			Help:      "Total number of DNS fallback answers served when no backend was healthy",
+		},
+		[]string{"domain", "type", "source"},
+	)
 )
 
 // Health check metrics
@@ -96,6 +183,17 @@ var (
 		},
 		[]string{"domain", "algorithm", "server"},
 	)
+
+	// RoutingPanicMode is 1 while a domain's pool of an address family is in
+	// panic mode in a view, routing to every backend regardless of health.
+	RoutingPanicMode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "routing_panic_mode",
+			Help:      "Whether a domain is in panic mode (1) and ignores backend health",
+		},
+		[]string{"domain", "view", "family"},
+	)
 )
 
 // Geolocation routing metrics (Sprint 6)
@@ -329,6 +427,46 @@ func RecordDNSQueryDuration(domain, status string, durationSeconds float64) {
 	DNSQueryDuration.WithLabelValues(domain, status).Observe(durationSeconds)
 }
 
+// RecordDNSTransportQuery records a DNS query answered over a transport.
+func RecordDNSTransportQuery(transport, rcode string) {
+	DNSTransportQueriesTotal.WithLabelValues(transport, rcode).Inc()
+}
+
+// RecordDNSZoneTransfer records an outbound zone transfer.
+func RecordDNSZoneTransfer(zone, transferType, result string) {
+	DNSZoneTransfersTotal.WithLabelValues(zone, transferType, result).Inc()
+}
+
+// RecordDNSNotify records a NOTIFY message sent to a secondary.
+func RecordDNSNotify(zone, result string) {
+	DNSNotifyTotal.WithLabelValues(zone, result).Inc()
+}
+
+// RecordDNSUpdate records a dynamic update request.
+func RecordDNSUpdate(zone, result string) {
+	DNSUpdatesTotal.WithLabelValues(zone, result).Inc()
+}
+
+// RecordDNSRateLimited records a response dropped or slipped by rate limiting.
+func RecordDNSRateLimited(category, action string) {
+	DNSRateLimitedTotal.WithLabelValues(category, action).Inc()
+}
+
+// RecordDNSQueryLogDropped records a query log entry that was not written.
+func RecordDNSQueryLogDropped(reason string) {
+	DNSQueryLogDroppedTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordDNSFallthrough records a query answered by a fallthrough upstream.
+func RecordDNSFallthrough(zone, result string) {
+	DNSFallthroughTotal.WithLabelValues(zone, result).Inc()
+}
+
+// RecordDNSFallback records a fallback answer served for a domain with no healthy backends.
+func RecordDNSFallback(domain, queryType, source string) {
+	DNSFallbackResponsesTotal.WithLabelValues(domain, queryType, source).Inc()
+}
+
 // RecordHealthCheckResult records a health check result.
 func RecordHealthCheckResult(region, server, result string) {
 	HealthCheckResultsTotal.WithLabelValues(region, server, result).Inc()
@@ -349,6 +487,16 @@ func RecordRoutingDecision(domain, algorithm, server string) {
 	RoutingDecisionsTotal.WithLabelValues(domain, algorithm, server).Inc()
 }
 
+// SetRoutingPanicMode sets whether a domain's pool of an address family is in panic mode
+// in a view.
+func SetRoutingPanicMode(domain, view, family string, active bool) {
+	value := 0.0
+	if active {
+		value = 1
+	}
+	RoutingPanicMode.WithLabelValues(domain, view, family).Set(value)
+}
+
 // RecordGeoRoutingDecision records a geolocation routing decision.
 func RecordGeoRoutingDecision(domain, country, continent, region string) {
 	RoutingGeoDecisionsTotal.WithLabelValues(domain, country, continent, region).Inc()
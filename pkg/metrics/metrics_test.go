@@ -25,6 +25,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestRecordDNSQuery(t *testing.T) {
@@ -44,6 +46,38 @@ func TestRecordDNSQueryDuration(t *testing.T) {
 	RecordDNSQueryDuration("example.com", "nxdomain", 0.002)
 }
 
+func TestRecordDNSFallback(t *testing.T) {
+	RecordDNSFallback("example.com", "A", "last_healthy")
+	RecordDNSFallback("example.com", "AAAA", "sorry_server")
+}
+
+func TestRecordDNSTransportQuery(t *testing.T) {
+	RecordDNSTransportQuery("udp", "NOERROR")
+	RecordDNSTransportQuery("dot", "NOERROR")
+	RecordDNSTransportQuery("doh", "NXDOMAIN")
+}
+
+func TestRecordDNSZoneTransfer(t *testing.T) {
+	RecordDNSZoneTransfer("gslb.example.com.", "AXFR", "success")
+	RecordDNSZoneTransfer("gslb.example.com.", "IXFR", "refused")
+	RecordDNSNotify("gslb.example.com.", "success")
+}
+
+func TestRecordDNSUpdate(t *testing.T) {
+	RecordDNSUpdate("gslb.example.com.", "success")
+	RecordDNSUpdate("gslb.example.com.", "refused")
+}
+
+func TestRecordDNSRateLimited(t *testing.T) {
+	RecordDNSRateLimited("answer", "dropped")
+	RecordDNSRateLimited("nxdomain", "slipped")
+}
+
+func TestRecordDNSQueryLogDropped(t *testing.T) {
+	RecordDNSQueryLogDropped("queue_full")
+	RecordDNSQueryLogDropped("write_error")
+}
+
 func TestRecordHealthCheckResult(t *testing.T) {
 	RecordHealthCheckResult("us-east-1", "10.0.1.10:80", "healthy")
 	RecordHealthCheckResult("us-east-1", "10.0.1.10:80", "unhealthy")
@@ -67,6 +101,19 @@ func TestRecordRoutingDecision(t *testing.T) {
 	RecordRoutingDecision("other.com", "weighted", "10.0.2.10:80")
 }
 
+func TestSetRoutingPanicMode(t *testing.T) {
+	SetRoutingPanicMode("panic.example.com", "internal", "ipv4", true)
+	SetRoutingPanicMode("panic.example.com", "default", "ipv4", false)
+
+	// Views of the same domain are reported separately
+	if got := testutil.ToFloat64(RoutingPanicMode.WithLabelValues("panic.example.com", "internal", "ipv4")); got != 1 {
+		t.Errorf("expected the internal view in panic mode, got %v", got)
+	}
+	if got := testutil.ToFloat64(RoutingPanicMode.WithLabelValues("panic.example.com", "default", "ipv4")); got != 0 {
+		t.Errorf("expected the default view out of panic mode, got %v", got)
+	}
+}
+
 func TestSetAppInfo(t *testing.T) {
 	SetAppInfo("0.1.0-dev")
 }
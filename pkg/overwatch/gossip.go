@@ -75,6 +75,13 @@ type BackendHeartbeat struct {
 	Port    int    `json:"port"`
 	Weight  int    `json:"weight"`
 	Healthy bool   `json:"healthy"`
+	// Labels are the free-form labels configured for the backend on the agent.
+	Labels map[string]string `json:"labels,omitempty"`
+	// ActiveConnections is the number of established TCP connections to the
+	// backend. nil when the agent cannot count them.
+	ActiveConnections *int `json:"active_connections,omitempty"`
+	// Capacity is the number of connections the backend can serve (0 = unknown).
+	Capacity int `json:"capacity,omitempty"`
 }
 
 // RegisterPayload is the payload for registration messages.
@@ -83,6 +90,8 @@ type RegisterPayload struct {
 	Address string `json:"address"`
 	Port    int    `json:"port"`
 	Weight  int    `json:"weight"`
+	// Labels are the free-form labels configured for the backend on the agent.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // DeregisterPayload is the payload for deregistration messages.
@@ -166,6 +175,12 @@ type DNSRegistry interface {
 	DeregisterServer(service string, address string, port int) error
 }
 
+// DNSLabelRegistry is implemented by DNS registries that route on server
+// labels, such as for traffic splits.
+type DNSLabelRegistry interface {
+	SetServerLabels(service string, address string, port int, labels map[string]string) error
+}
+
 // LatencyTable stores learned latency data from agents (ADR-017).
 type LatencyTable interface {
 	// Update processes a latency report from an agent.
@@ -346,6 +361,14 @@ func (h *GossipHandler) handleHeartbeat(msg GossipMessage) {
 			)
 			continue // Skip DNS registration if backend registry fails
 		}
+		if backend.ActiveConnections != nil {
+			h.registry.UpdateLoad(backend.Service, backend.Address, backend.Port, *backend.ActiveConnections, backend.Capacity)
+		}
+		// Backends reported without labels keep their labels, as in the DNS
+		// registry (see setDNSLabels)
+		if len(backend.Labels) > 0 {
+			h.registry.UpdateLabels(backend.Service, backend.Address, backend.Port, backend.Labels)
+		}
 
 		// v1.1.0: Also register in DNS registry (for DNS responses)
 		if h.dnsRegistry != nil {
@@ -363,6 +386,7 @@ func (h *GossipHandler) handleHeartbeat(msg GossipMessage) {
 					"error", err,
 				)
 			} else {
+				h.setDNSLabels(backend.Service, backend.Address, backend.Port, backend.Labels)
 				h.logger.Debug("registered backend in DNS registry",
 					"agent_id", msg.AgentID,
 					"service", backend.Service,
@@ -429,6 +453,31 @@ func (h *GossipHandler) handleRegister(msg GossipMessage) {
 			"service", payload.Service,
 			"error", err,
 		)
+		return
+	}
+	if len(payload.Labels) > 0 {
+		h.registry.UpdateLabels(payload.Service, payload.Address, payload.Port, payload.Labels)
+	}
+	// Servers already known to DNS, such as configured ones, get their split
+	// group before the first heartbeat
+	h.setDNSLabels(payload.Service, payload.Address, payload.Port, payload.Labels)
+}
+
+// setDNSLabels passes the labels an agent reported for a backend to the DNS
+// registry. Backends reported without labels keep the labels configured
+// for the server. Backends not yet in the DNS registry, such as agent
+// backends registered before their first heartbeat, are skipped.
+func (h *GossipHandler) setDNSLabels(service, address string, port int, labels map[string]string) {
+	labeler, ok := h.dnsRegistry.(DNSLabelRegistry)
+	if !ok || len(labels) == 0 {
+		return
+	}
+	if err := labeler.SetServerLabels(service, address, port, labels); err != nil {
+		h.logger.Debug("backend labels not set in DNS registry",
+			"service", service,
+			"address", address,
+			"error", err,
+		)
 	}
 }
 
@@ -544,6 +593,14 @@ func (h *GossipHandler) parseHeartbeatPayload(m map[string]interface{}) Heartbea
 				if healthy, ok := bm["healthy"].(bool); ok {
 					backend.Healthy = healthy
 				}
+				if c, ok := bm["active_connections"].(float64); ok {
+					conns := int(c)
+					backend.ActiveConnections = &conns
+				}
+				if c, ok := bm["capacity"].(float64); ok {
+					backend.Capacity = int(c)
+				}
+				backend.Labels = parseLabels(bm["labels"])
 				payload.Backends = append(payload.Backends, backend)
 			}
 		}
@@ -601,10 +658,26 @@ func (h *GossipHandler) parseRegisterPayload(m map[string]interface{}) RegisterP
 	if w, ok := m["weight"].(float64); ok {
 		payload.Weight = int(w)
 	}
+	payload.Labels = parseLabels(m["labels"])
 
 	return payload
 }
 
+// parseLabels parses labels from a JSON object, skipping non-string values.
+func parseLabels(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(m))
+	for key, value := range m {
+		if s, ok := value.(string); ok {
+			labels[key] = s
+		}
+	}
+	return labels
+}
+
 // parseDeregisterPayload parses a deregister payload from a map.
 func (h *GossipHandler) parseDeregisterPayload(m map[string]interface{}) DeregisterPayload {
 	payload := DeregisterPayload{}
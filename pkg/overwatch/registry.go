@@ -12,6 +12,7 @@ package overwatch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -48,8 +49,15 @@ const (
 	SourceAgent RegistrationSource = "agent"
 	// SourceAPI indicates the backend was registered via API.
 	SourceAPI RegistrationSource = "api"
+	// SourceDNSUpdate indicates the backend was registered via an RFC 2136
+	// dynamic DNS update.
+	SourceDNSUpdate RegistrationSource = "dns_update"
 )
 
+// ErrSourceConflict is returned when registering a backend that already
+// exists with another registration source.
+var ErrSourceConflict = errors.New("backend registered by another source")
+
 // Backend represents a registered backend from an agent.
 type Backend struct {
 	// Service is the service name (maps to DNS domain).
@@ -60,6 +68,9 @@ type Backend struct {
 	Port int `json:"port"`
 	// Weight is the routing weight.
 	Weight int `json:"weight"`
+	// Labels are free-form key/value pairs from the agent or configuration,
+	// matched by label routing stages and traffic splits.
+	Labels map[string]string `json:"labels,omitempty"`
 
 	// AgentID is the ID of the agent that registered this backend.
 	// Empty for static/API-registered backends.
@@ -110,6 +121,14 @@ type Backend struct {
 	// ErrorRate is the current error rate from predictive health.
 	ErrorRate float64 `json:"error_rate,omitempty"`
 
+	// Load reported by the agent for least-load routing
+	// ActiveConnections is the number of established TCP connections to the backend
+	ActiveConnections int `json:"active_connections,omitempty"`
+	// Capacity is the number of connections the backend can serve (0 = unknown)
+	Capacity int `json:"capacity,omitempty"`
+	// LoadUpdatedAt is when the load was last reported
+	LoadUpdatedAt time.Time `json:"load_updated_at,omitempty"`
+
 	// EffectiveStatus is the computed effective status based on the hierarchy.
 	EffectiveStatus BackendStatus `json:"effective_status"`
 
@@ -369,14 +388,39 @@ func (r *Registry) RegisterStatic(service, address string, port, weight int, reg
 // RegisterAPI registers a backend via API call.
 // v1.1.0: API-registered servers use same validation as agent and static servers.
 func (r *Registry) RegisterAPI(service, address string, port, weight int, region string) error {
+	_, err := r.registerExternal(SourceAPI, service, address, port, weight, region)
+	return err
+}
+
+// RegisterDNSUpdate registers a backend added by an RFC 2136 dynamic update.
+// Like static servers, these are validated externally and never go stale:
+// they stay until an update deletes them. Adding a backend already added by an update is a no-op, as adding
+// an existing record is in RFC 2136; a backend registered by another source
+// is not taken over and ErrSourceConflict is returned. Reports whether the
+// backend was created.
+func (r *Registry) RegisterDNSUpdate(service, address string, port, weight int, region string) (bool, error) {
+	return r.registerExternal(SourceDNSUpdate, service, address, port, weight, region)
+}
+
+// registerExternal registers a backend that is not reported by an agent:
+// it is assumed healthy until validated. Reports whether the backend was
+// created. An existing backend is an error, except for dynamic updates
+// adding a backend they already added.
+func (r *Registry) registerExternal(source RegistrationSource, service, address string, port, weight int, region string) (bool, error) {
 	r.mu.Lock()
 
 	key := backendKey(service, address, port)
 
 	// Check if already exists
-	if _, exists := r.backends[key]; exists {
+	if existing, exists := r.backends[key]; exists {
 		r.mu.Unlock()
-		return fmt.Errorf("server already exists: %s", key)
+		switch {
+		case source != SourceDNSUpdate:
+			return false, fmt.Errorf("server already exists: %s", key)
+		case existing.Source != SourceDNSUpdate:
+			return false, fmt.Errorf("%w: %s is registered by %s", ErrSourceConflict, key, existing.Source)
+		}
+		return false, nil
 	}
 
 	now := time.Now()
@@ -386,7 +430,7 @@ func (r *Registry) RegisterAPI(service, address string, port, weight int, region
 		Port:            port,
 		Weight:          weight,
 		Region:          region,
-		Source:          SourceAPI, // v1.1.0: Mark as API-registered
+		Source:          source,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 		AgentID:         "", // No agent for API-registered servers
@@ -409,12 +453,12 @@ func (r *Registry) RegisterAPI(service, address string, port, weight int, region
 		}
 	}
 
-	r.config.Logger.Info("API backend registered",
+	r.config.Logger.Info("backend registered",
 		"service", service,
 		"address", address,
 		"port", port,
 		"region", region,
-		"source", "api",
+		"source", string(source),
 	)
 
 	// Release lock before calling callback
@@ -425,7 +469,7 @@ func (r *Registry) RegisterAPI(service, address string, port, weight int, region
 		r.onStatusChange(&backendCopy, "", newStatus)
 	}
 
-	return nil
+	return true, nil
 }
 
 // Update updates an existing backend's weight and/or region.
@@ -745,6 +789,32 @@ func (r *Registry) UpdateDraining(agentID string, draining bool, reason string,
 	}
 }
 
+// UpdateLoad records the load an agent reported for a backend.
+func (r *Registry) UpdateLoad(service, address string, port, activeConnections, capacity int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backend, exists := r.backends[backendKey(service, address, port)]
+	if !exists {
+		return
+	}
+	backend.ActiveConnections = activeConnections
+	backend.Capacity = capacity
+	backend.LoadUpdatedAt = time.Now()
+}
+
+// UpdateLabels sets the labels of a backend.
+func (r *Registry) UpdateLabels(service, address string, port int, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backend, exists := r.backends[backendKey(service, address, port)]
+	if !exists {
+		return
+	}
+	backend.Labels = labels
+}
+
 // GetBackend returns a backend by key.
 func (r *Registry) GetBackend(service, address string, port int) (*Backend, bool) {
 	r.mu.RLock()
@@ -817,6 +887,21 @@ func (r *Registry) IsHealthy(address string, port int) bool {
 	return false
 }
 
+// IsOverriddenUnhealthy returns true if the backend has a manual override
+// marking it unhealthy (for DNS panic mode, which ignores health but not
+// overrides).
+func (r *Registry) IsOverriddenUnhealthy(address string, port int) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, backend := range r.backends {
+		if backend.Address == address && backend.Port == port {
+			return backend.OverrideStatus != nil && !*backend.OverrideStatus
+		}
+	}
+	return false
+}
+
 // LatencyInfo contains latency information for a backend.
 type LatencyInfo struct {
 	// SmoothedLatency is the EMA of validation latency measurements.
@@ -848,6 +933,35 @@ func (r *Registry) GetLatency(address string, port int) LatencyInfo {
 	return LatencyInfo{HasData: false}
 }
 
+// LoadInfo contains load information for a backend.
+type LoadInfo struct {
+	// ActiveConnections is the number of established TCP connections.
+	ActiveConnections int
+	// Capacity is the number of connections the backend can serve (0 = unknown).
+	Capacity int
+	// HasData indicates whether a load report is available that is no older
+	// than the stale threshold.
+	HasData bool
+}
+
+// GetLoad returns load information for a backend (for least-load routing).
+func (r *Registry) GetLoad(address string, port int) LoadInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Search for backend by address:port (service-agnostic for DNS)
+	for _, backend := range r.backends {
+		if backend.Address == address && backend.Port == port {
+			return LoadInfo{
+				ActiveConnections: backend.ActiveConnections,
+				Capacity:          backend.Capacity,
+				HasData:           !backend.LoadUpdatedAt.IsZero() && time.Since(backend.LoadUpdatedAt) <= r.config.StaleThreshold,
+			}
+		}
+	}
+	return LoadInfo{HasData: false}
+}
+
 // BackendCount returns the total number of registered backends.
 func (r *Registry) BackendCount() int {
 	r.mu.RLock()
@@ -947,8 +1061,9 @@ func (r *Registry) checkStaleBackends() {
 
 	for key, backend := range r.backends {
 		// v1.1.1: Skip staleness check for static servers - they don't have agent heartbeats
-		// Static servers are defined in config and validated externally, not via agent gossip
-		if backend.Source == SourceStatic {
+		// Static servers are defined in config and validated externally, not via agent gossip.
+		// The same holds for servers added by dynamic DNS updates until they are deleted.
+		if backend.Source == SourceStatic || backend.Source == SourceDNSUpdate {
 			// Still recompute effective status for static servers (validation results, overrides)
 			oldStatus := backend.EffectiveStatus
 			r.computeEffectiveStatus(backend)
@@ -7,6 +7,9 @@
 package overwatch
 
 import (
+	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 )
@@ -514,3 +517,179 @@ func TestRegistry_HealthAuthorityHierarchy(t *testing.T) {
 		t.Errorf("step 6: expected healthy from validation (recovered from stale), got %s", backend.EffectiveStatus)
 	}
 }
+
+func TestRegistry_RegisterDNSUpdateConcurrent(t *testing.T) {
+	registry := NewRegistry(RegistryConfig{StaleThreshold: 30 * time.Second}, nil)
+
+	// Identical adds racing each other all succeed
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := registry.RegisterDNSUpdate("app.example.com", "10.0.0.1", 80, 100, "us-east")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected concurrent adds to succeed, got %v", err)
+		}
+	}
+}
+
+func TestRegistry_RegisterDNSUpdate(t *testing.T) {
+	cfg := RegistryConfig{
+		StaleThreshold: 30 * time.Second,
+		RemoveAfter:    time.Millisecond,
+	}
+	registry := NewRegistry(cfg, nil)
+
+	if created, err := registry.RegisterDNSUpdate("app.example.com", "10.0.0.1", 80, 100, "us-east"); err != nil || !created {
+		t.Fatalf("failed to register backend: %v, created %v", err, created)
+	}
+	// Adding the same record again is a no-op
+	if created, err := registry.RegisterDNSUpdate("app.example.com", "10.0.0.1", 80, 100, "us-east"); err != nil || created {
+		t.Errorf("expected duplicate add to succeed without creating, got %v, created %v", err, created)
+	}
+	// Backends of other sources are not taken over
+	if err := registry.RegisterAPI("app.example.com", "10.0.0.2", 80, 100, "us-east"); err != nil {
+		t.Fatalf("failed to register API backend: %v", err)
+	}
+	if _, err := registry.RegisterDNSUpdate("app.example.com", "10.0.0.2", 80, 100, "us-east"); !errors.Is(err, ErrSourceConflict) {
+		t.Errorf("expected ErrSourceConflict, got %v", err)
+	}
+	if backend, _ := registry.GetBackend("app.example.com", "10.0.0.2", 80); backend.Source != SourceAPI {
+		t.Errorf("expected the API backend to keep its source, got %s", backend.Source)
+	}
+
+	backend, exists := registry.GetBackend("app.example.com", "10.0.0.1", 80)
+	if !exists {
+		t.Fatal("backend not found after registration")
+	}
+	if backend.Source != SourceDNSUpdate {
+		t.Errorf("expected source %s, got %s", SourceDNSUpdate, backend.Source)
+	}
+	if backend.EffectiveStatus != StatusHealthy {
+		t.Errorf("expected status healthy, got %s", backend.EffectiveStatus)
+	}
+
+	// Without agent heartbeats the backend is kept until it is deleted
+	time.Sleep(5 * time.Millisecond)
+	registry.checkStaleBackends()
+	if _, exists := registry.GetBackend("app.example.com", "10.0.0.1", 80); !exists {
+		t.Error("expected dynamic update backend to survive stale detection")
+	}
+
+	// Health validation applies as for API-registered servers
+	if err := registry.UpdateValidation("app.example.com", "10.0.0.1", 80, false, "connection refused"); err != nil {
+		t.Fatalf("UpdateValidation failed: %v", err)
+	}
+	if registry.IsHealthy("10.0.0.1", 80) {
+		t.Error("expected failed validation to mark backend unhealthy")
+	}
+}
+
+func TestRegistry_Load(t *testing.T) {
+	registry := NewRegistry(RegistryConfig{StaleThreshold: 30 * time.Second}, nil)
+	if err := registry.Register("agent-1", "us-east", "web", "192.168.1.1", 80, 100, true); err != nil {
+		t.Fatalf("failed to register backend: %v", err)
+	}
+
+	if info := registry.GetLoad("192.168.1.1", 80); info.HasData {
+		t.Errorf("expected no load data before a report, got %+v", info)
+	}
+
+	registry.UpdateLoad("web", "192.168.1.1", 80, 42, 500)
+	info := registry.GetLoad("192.168.1.1", 80)
+	if !info.HasData || info.ActiveConnections != 42 || info.Capacity != 500 {
+		t.Errorf("expected the reported load, got %+v", info)
+	}
+
+	// Reports for unknown backends are ignored
+	registry.UpdateLoad("web", "192.168.1.2", 80, 1, 0)
+	if info := registry.GetLoad("192.168.1.2", 80); info.HasData {
+		t.Errorf("expected no load data for an unknown backend, got %+v", info)
+	}
+
+	// Reports older than the stale threshold are not used
+	registry.mu.Lock()
+	registry.backends[backendKey("web", "192.168.1.1", 80)].LoadUpdatedAt = time.Now().Add(-time.Minute)
+	registry.mu.Unlock()
+	if info := registry.GetLoad("192.168.1.1", 80); info.HasData {
+		t.Errorf("expected a stale load report to be ignored, got %+v", info)
+	}
+}
+
+// labelDNSRegistry records the labels set through DNSLabelRegistry.
+type labelDNSRegistry struct {
+	labels map[string]map[string]string
+}
+
+func (r *labelDNSRegistry) RegisterServer(service string, address string, port int, weight int, region string) error {
+	return nil
+}
+
+func (r *labelDNSRegistry) DeregisterServer(service string, address string, port int) error {
+	return nil
+}
+
+func (r *labelDNSRegistry) SetServerLabels(service string, address string, port int, labels map[string]string) error {
+	r.labels[address] = labels
+	return nil
+}
+
+func TestGossipHandler_Labels(t *testing.T) {
+	registry := NewRegistry(RegistryConfig{StaleThreshold: 30 * time.Second}, nil)
+	dnsRegistry := &labelDNSRegistry{labels: make(map[string]map[string]string)}
+	handler := NewGossipHandler(registry, dnsRegistry, nil)
+
+	// Payloads arrive as JSON objects after decoding
+	var payload map[string]interface{}
+	data := `{"backends": [
+		{"service": "web", "address": "192.168.1.1", "port": 80, "weight": 100, "healthy": true, "labels": {"version": "v2"}},
+		{"service": "web", "address": "192.168.1.2", "port": 80, "weight": 100, "healthy": true}
+	]}`
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	handler.handleHeartbeat(GossipMessage{Type: MessageHeartbeat, AgentID: "agent-1", Region: "us-east", Payload: payload})
+
+	backend, ok := registry.GetBackend("web", "192.168.1.1", 80)
+	if !ok || backend.Labels["version"] != "v2" {
+		t.Fatalf("expected the reported labels on the backend, got %+v", backend)
+	}
+	if got := dnsRegistry.labels["192.168.1.1"]["version"]; got != "v2" {
+		t.Errorf("expected the labels to reach the DNS registry, got %q", got)
+	}
+	// Backends reported without labels keep their configured DNS labels
+	if _, ok := dnsRegistry.labels["192.168.1.2"]; ok {
+		t.Error("expected no DNS labels set for a backend reported without labels")
+	}
+
+	// A later heartbeat without labels keeps the reported labels in both
+	// registries
+	data = `{"backends": [{"service": "web", "address": "192.168.1.1", "port": 80, "weight": 100, "healthy": true}]}`
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	handler.handleHeartbeat(GossipMessage{Type: MessageHeartbeat, AgentID: "agent-1", Region: "us-east", Payload: payload})
+	if backend, _ := registry.GetBackend("web", "192.168.1.1", 80); backend.Labels["version"] != "v2" {
+		t.Errorf("expected the backend to keep its labels, got %v", backend.Labels)
+	}
+	if got := dnsRegistry.labels["192.168.1.1"]["version"]; got != "v2" {
+		t.Errorf("expected the DNS labels to be kept, got %q", got)
+	}
+
+	// Labels of a registration reach the DNS registry before any heartbeat
+	if err := json.Unmarshal([]byte(`{"service": "web", "address": "192.168.1.3", "port": 80, "weight": 100, "labels": {"version": "v3"}}`), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	handler.handleRegister(GossipMessage{Type: MessageRegister, AgentID: "agent-1", Region: "us-east", Payload: payload})
+	if got := dnsRegistry.labels["192.168.1.3"]["version"]; got != "v3" {
+		t.Errorf("expected the registered labels to reach the DNS registry, got %q", got)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package querylog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/version"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// dnstapContentType is the frame stream content type of dnstap data frames.
+const dnstapContentType = "protobuf:dnstap.Dnstap"
+
+// Frame stream control frame types.
+const (
+	fstrmControlAccept = 0x01
+	fstrmControlStart  = 0x02
+	fstrmControlStop   = 0x03
+	fstrmControlReady  = 0x04
+	fstrmControlFinish = 0x05
+
+	fstrmFieldContentType = 0x01
+)
+
+// dnstap Message types, socket families and protocols.
+const (
+	dnstapTypeMessage      = 1
+	dnstapAuthQuery        = 1
+	dnstapAuthResponse     = 2
+	dnstapFamilyINET       = 1
+	dnstapFamilyINET6      = 2
+	dnstapProtocolUDP      = 1
+	dnstapProtocolTCP      = 2
+	dnstapMaxControlLength = 512
+)
+
+const (
+	// dnstapIOTimeout bounds writes to and the handshake with a collector.
+	dnstapIOTimeout = 2 * time.Second
+	// dnstapRedialInterval is the minimum time between connection attempts.
+	dnstapRedialInterval = 5 * time.Second
+)
+
+// errNotConnected is returned by Write while the collector is unreachable.
+var errNotConnected = errors.New("dnstap collector not connected")
+
+// DnstapSink writes each entry as a dnstap AUTH_QUERY and AUTH_RESPONSE
+// message pair in a frame stream, either to a collector's unix socket
+// (bidirectional stream, reconnecting when the collector goes away) or to a
+// file (unidirectional stream).
+type DnstapSink struct {
+	identity []byte
+	version  []byte
+	socket   string // Collector socket; empty for file output
+	logger   *slog.Logger
+
+	conn     io.WriteCloser
+	w        *bufio.Writer
+	nextDial time.Time
+}
+
+// NewDnstapSocketSink creates a sink writing to the collector listening on
+// the unix socket at path. The connection is made on the first write.
+func NewDnstapSocketSink(path, identity string, logger *slog.Logger) *DnstapSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DnstapSink{
+		identity: []byte(identity),
+		version:  []byte("OpenGSLB " + version.GetVersion()),
+		socket:   path,
+		logger:   logger,
+	}
+}
+
+// NewDnstapFileSink creates a sink writing a frame stream to the file at
+// path, replacing its contents.
+func NewDnstapFileSink(path, identity string) (*DnstapSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dnstap file: %w", err)
+	}
+	s := &DnstapSink{
+		identity: []byte(identity),
+		version:  []byte("OpenGSLB " + version.GetVersion()),
+		logger:   slog.Default(),
+		conn:     file,
+		w:        bufio.NewWriter(file),
+	}
+	if err := writeControlFrame(s.w, fstrmControlStart, true); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write dnstap file: %w", err)
+	}
+	return s, nil
+}
+
+// Write writes the query and response messages of e.
+func (s *DnstapSink) Write(e *Entry) error {
+	if s.w == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	s.setDeadline()
+	if e.Query != nil {
+		if err := writeDataFrame(s.w, s.message(e, dnstapAuthQuery)); err != nil {
+			s.disconnect(err)
+			return err
+		}
+	}
+	if e.Response != nil {
+		if err := writeDataFrame(s.w, s.message(e, dnstapAuthResponse)); err != nil {
+			s.disconnect(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes buffered frames.
+func (s *DnstapSink) Flush() error {
+	if s.w == nil {
+		return nil
+	}
+	s.setDeadline()
+	if err := s.w.Flush(); err != nil {
+		s.disconnect(err)
+		return err
+	}
+	return nil
+}
+
+// Close ends the frame stream and closes the socket or file.
+func (s *DnstapSink) Close() error {
+	if s.w == nil {
+		return nil
+	}
+	s.setDeadline()
+	err := writeControlFrame(s.w, fstrmControlStop, false)
+	if err == nil {
+		err = s.w.Flush()
+	}
+	if err == nil && s.socket != "" {
+		// The collector acknowledges the end of the stream
+		if conn, ok := s.conn.(net.Conn); ok {
+			err = readControlFrame(conn, fstrmControlFinish)
+		}
+	}
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	s.conn = nil
+	s.w = nil
+	return err
+}
+
+// dial connects to the collector and performs the frame stream handshake.
+// Attempts are spaced by dnstapRedialInterval.
+func (s *DnstapSink) dial() error {
+	if s.socket == "" || time.Now().Before(s.nextDial) {
+		return errNotConnected
+	}
+	s.nextDial = time.Now().Add(dnstapRedialInterval)
+
+	conn, err := net.DialTimeout("unix", s.socket, dnstapIOTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to dnstap collector: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(dnstapIOTimeout))
+	w := bufio.NewWriter(conn)
+	err = writeControlFrame(w, fstrmControlReady, true)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = readControlFrame(conn, fstrmControlAccept)
+	}
+	if err == nil {
+		err = writeControlFrame(w, fstrmControlStart, true)
+	}
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("dnstap handshake failed: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	s.conn = conn
+	s.w = w
+	s.logger.Info("connected to dnstap collector", "socket", s.socket)
+	return nil
+}
+
+// disconnect drops the collector connection after a write error. File
+// output is kept open.
+func (s *DnstapSink) disconnect(err error) {
+	if s.socket == "" {
+		return
+	}
+	s.logger.Warn("lost connection to dnstap collector", "socket", s.socket, "error", err)
+	s.conn.Close()
+	s.conn = nil
+	s.w = nil
+}
+
+func (s *DnstapSink) setDeadline() {
+	if conn, ok := s.conn.(net.Conn); ok {
+		_ = conn.SetWriteDeadline(time.Now().Add(dnstapIOTimeout))
+	}
+}
+
+// message encodes a dnstap protobuf message of msgType for e.
+func (s *DnstapSink) message(e *Entry, msgType uint64) []byte {
+	var m []byte
+	m = protowire.AppendTag(m, 1, protowire.VarintType)
+	m = protowire.AppendVarint(m, msgType)
+
+	family, clientIP, serverIP := uint64(dnstapFamilyINET6), e.ClientIP.To16(), e.ServerIP.To16()
+	if ip4 := e.ClientIP.To4(); ip4 != nil {
+		family, clientIP = dnstapFamilyINET, ip4
+		if server4 := e.ServerIP.To4(); server4 != nil {
+			serverIP = server4
+		}
+	}
+	protocol := uint64(dnstapProtocolTCP)
+	if e.Protocol == "udp" {
+		protocol = dnstapProtocolUDP
+	}
+	m = protowire.AppendTag(m, 2, protowire.VarintType)
+	m = protowire.AppendVarint(m, family)
+	m = protowire.AppendTag(m, 3, protowire.VarintType)
+	m = protowire.AppendVarint(m, protocol)
+	if clientIP != nil {
+		m = protowire.AppendTag(m, 4, protowire.BytesType)
+		m = protowire.AppendBytes(m, clientIP)
+	}
+	if serverIP != nil {
+		m = protowire.AppendTag(m, 5, protowire.BytesType)
+		m = protowire.AppendBytes(m, serverIP)
+	}
+	m = protowire.AppendTag(m, 6, protowire.VarintType)
+	m = protowire.AppendVarint(m, uint64(e.ClientPort))
+	m = protowire.AppendTag(m, 7, protowire.VarintType)
+	m = protowire.AppendVarint(m, uint64(e.ServerPort))
+	m = protowire.AppendTag(m, 8, protowire.VarintType)
+	m = protowire.AppendVarint(m, uint64(e.Time.Unix()))
+	m = protowire.AppendTag(m, 9, protowire.Fixed32Type)
+	m = protowire.AppendFixed32(m, uint32(e.Time.Nanosecond()))
+
+	var extra []byte
+	if msgType == dnstapAuthQuery {
+		if wire, err := e.Query.Pack(); err == nil {
+			m = protowire.AppendTag(m, 10, protowire.BytesType)
+			m = protowire.AppendBytes(m, wire)
+		}
+	} else {
+		responseTime := e.Time.Add(e.Latency)
+		m = protowire.AppendTag(m, 12, protowire.VarintType)
+		m = protowire.AppendVarint(m, uint64(responseTime.Unix()))
+		m = protowire.AppendTag(m, 13, protowire.Fixed32Type)
+		m = protowire.AppendFixed32(m, uint32(responseTime.Nanosecond()))
+		if wire, err := e.Response.Pack(); err == nil {
+			m = protowire.AppendTag(m, 14, protowire.BytesType)
+			m = protowire.AppendBytes(m, wire)
+		}
+		extra = routingExtra(e)
+	}
+
+	var d []byte
+	d = protowire.AppendTag(d, 1, protowire.BytesType)
+	d = protowire.AppendBytes(d, s.identity)
+	d = protowire.AppendTag(d, 2, protowire.BytesType)
+	d = protowire.AppendBytes(d, s.version)
+	if extra != nil {
+		d = protowire.AppendTag(d, 3, protowire.BytesType)
+		d = protowire.AppendBytes(d, extra)
+	}
+	d = protowire.AppendTag(d, 14, protowire.BytesType)
+	d = protowire.AppendBytes(d, m)
+	d = protowire.AppendTag(d, 15, protowire.VarintType)
+	d = protowire.AppendVarint(d, dnstapTypeMessage)
+	return d
+}
+
+// routingExtra returns the routing decision carried in the dnstap extra
+// field of responses, or nil when the response was not routed.
+func routingExtra(e *Entry) []byte {
+	if e.Backend == "" && e.Algorithm == "" && e.View == "" {
+		return nil
+	}
+	extra, err := json.Marshal(struct {
+		Backend   string `json:"backend,omitempty"`
+		Algorithm string `json:"algorithm,omitempty"`
+		View      string `json:"view,omitempty"`
+	}{e.Backend, e.Algorithm, e.View})
+	if err != nil {
+		return nil
+	}
+	return extra
+}
+
+// writeDataFrame writes a length-prefixed frame stream data frame.
+func writeDataFrame(w io.Writer, payload []byte) error {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(payload)))
+	if _, err := w.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// writeControlFrame writes a frame stream control frame, with the dnstap
+// content type field when contentType is set.
+func writeControlFrame(w io.Writer, controlType uint32, contentType bool) error {
+	payload := binary.BigEndian.AppendUint32(nil, controlType)
+	if contentType {
+		payload = binary.BigEndian.AppendUint32(payload, fstrmFieldContentType)
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(dnstapContentType)))
+		payload = append(payload, dnstapContentType...)
+	}
+	frame := binary.BigEndian.AppendUint32(nil, 0) // Escape: control frame follows
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readControlFrame reads a control frame and checks its type.
+func readControlFrame(r io.Reader, want uint32) error {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	if escape := binary.BigEndian.Uint32(header[:4]); escape != 0 {
+		return fmt.Errorf("expected control frame, got data frame")
+	}
+	length := binary.BigEndian.Uint32(header[4:])
+	if length < 4 || length > dnstapMaxControlLength {
+		return fmt.Errorf("invalid control frame length %d", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return err
+	}
+	if got := binary.BigEndian.Uint32(payload[:4]); got != want {
+		return fmt.Errorf("expected control frame type %d, got %d", want, got)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package querylog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// jsonEntry is the JSON lines representation of an entry.
+type jsonEntry struct {
+	Time       time.Time `json:"time"`
+	ClientIP   string    `json:"client_ip"`
+	ClientPort int       `json:"client_port"`
+	Protocol   string    `json:"protocol"`
+	ECS        string    `json:"ecs,omitempty"`
+	QName      string    `json:"qname"`
+	QType      string    `json:"qtype"`
+	Rcode      string    `json:"rcode"`
+	Backend    string    `json:"backend,omitempty"`
+	Algorithm  string    `json:"algorithm,omitempty"`
+	View       string    `json:"view,omitempty"`
+	LatencyUS  int64     `json:"latency_us"`
+}
+
+// JSONSink writes entries as JSON lines to a file, rotating it when it
+// reaches maxSize bytes. Rotated files are renamed path.1, path.2, ... up to
+// maxBackups, the oldest being removed.
+type JSONSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	buf  *bufio.Writer
+	size int64
+}
+
+// NewJSONSink opens path for appending. maxSize <= 0 disables rotation.
+func NewJSONSink(path string, maxSize int64, maxBackups int) (*JSONSink, error) {
+	s := &JSONSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *JSONSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open query log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat query log file: %w", err)
+	}
+	s.file = file
+	s.buf = bufio.NewWriter(file)
+	s.size = info.Size()
+	return nil
+}
+
+// Write appends e as one JSON line.
+func (s *JSONSink) Write(e *Entry) error {
+	line, err := json.Marshal(jsonEntry{
+		Time:       e.Time.UTC(),
+		ClientIP:   e.ClientIP.String(),
+		ClientPort: e.ClientPort,
+		Protocol:   e.Protocol,
+		ECS:        e.ECS,
+		QName:      e.QName,
+		QType:      e.QType,
+		Rcode:      e.Rcode,
+		Backend:    e.Backend,
+		Algorithm:  e.Algorithm,
+		View:       e.View,
+		LatencyUS:  e.Latency.Microseconds(),
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if s.file == nil {
+		// A failed rotation left no file open
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.buf.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotate closes the file, shifts the rotated files and opens a new file.
+func (s *JSONSink) rotate() error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+	if s.maxBackups > 0 {
+		_ = os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+		for i := s.maxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+		}
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate query log file: %w", err)
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return fmt.Errorf("failed to rotate query log file: %w", err)
+	}
+	return s.open()
+}
+
+// Flush writes buffered lines to the file.
+func (s *JSONSink) Flush() error {
+	if s.buf == nil {
+		return nil
+	}
+	return s.buf.Flush()
+}
+
+// Close flushes and closes the file.
+func (s *JSONSink) Close() error {
+	return s.closeFile()
+}
+
+func (s *JSONSink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.buf.Flush()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	s.buf = nil
+	return err
+}
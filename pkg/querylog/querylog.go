@@ -0,0 +1,191 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+// Package querylog records DNS queries and their answers to dnstap and JSON
+// lines outputs without delaying the DNS handler.
+package querylog
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/config"
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+	"github.com/miekg/dns"
+)
+
+// Entry is one logged query and its response.
+type Entry struct {
+	Time       time.Time // When the query was received
+	ClientIP   net.IP
+	ClientPort int
+	ServerIP   net.IP
+	ServerPort int
+	Protocol   string // udp or tcp
+	ECS        string // EDNS Client Subnet of the query, e.g. 198.51.100.0/24
+	QName      string
+	QType      string
+	Rcode      string
+	Backend    string // Address of the backend chosen by routing
+	Algorithm  string // Routing algorithm of the answered domain
+	View       string // Split-horizon view that answered
+	Latency    time.Duration
+
+	// Query and Response are the messages, packed only by sinks that need
+	// the wire format
+	Query    *dns.Msg
+	Response *dns.Msg
+}
+
+// Sink writes query log entries. Sinks are only called from the logger's
+// writer goroutine.
+type Sink interface {
+	Write(e *Entry) error
+	// Flush writes buffered entries; called when the queue is empty
+	Flush() error
+	Close() error
+}
+
+// Logger queues entries for its sinks. Log never blocks: entries are
+// dropped when the queue is full.
+type Logger struct {
+	sinks   []Sink
+	entries chan *Entry
+	done    chan struct{}
+	logger  *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New creates a logger writing to sinks through a queue of bufferSize
+// entries, and starts its writer goroutine.
+func New(sinks []Sink, bufferSize int, logger *slog.Logger) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	l := &Logger{
+		sinks:   sinks,
+		entries: make(chan *Entry, bufferSize),
+		done:    make(chan struct{}),
+		logger:  logger,
+	}
+	go l.run()
+	return l
+}
+
+// Build creates the query log from dns.query_log.
+// Returns nil when query logging is disabled.
+func Build(cfg config.QueryLogConfig, logger *slog.Logger) (*Logger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var sinks []Sink
+	closeAll := func() {
+		for _, sink := range sinks {
+			_ = sink.Close()
+		}
+	}
+	if cfg.File.Path != "" {
+		sink, err := NewJSONSink(cfg.File.Path, int64(cfg.File.MaxSizeMB)<<20, cfg.File.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.Dnstap.Socket != "" || cfg.Dnstap.File != "" {
+		identity := cfg.Dnstap.Identity
+		if identity == "" {
+			identity, _ = os.Hostname()
+		}
+		var sink *DnstapSink
+		var err error
+		if cfg.Dnstap.Socket != "" {
+			sink = NewDnstapSocketSink(cfg.Dnstap.Socket, identity, logger)
+		} else {
+			sink, err = NewDnstapFileSink(cfg.Dnstap.File, identity)
+		}
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("query log has no output configured")
+	}
+	return New(sinks, cfg.BufferSize, logger), nil
+}
+
+// Log queues an entry. The entry must not be modified afterwards.
+func (l *Logger) Log(e *Entry) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
+	select {
+	case l.entries <- e:
+	default:
+		metrics.RecordDNSQueryLogDropped("queue_full")
+	}
+}
+
+// Close stops accepting entries, writes those queued and closes the sinks.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.entries)
+	l.mu.Unlock()
+
+	<-l.done
+	var errs []error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run writes queued entries until the queue is closed, flushing the sinks
+// whenever it is empty.
+func (l *Logger) run() {
+	defer close(l.done)
+	for e := range l.entries {
+		l.write(e)
+		if len(l.entries) == 0 {
+			l.flush()
+		}
+	}
+	l.flush()
+}
+
+func (l *Logger) write(e *Entry) {
+	for _, sink := range l.sinks {
+		if err := sink.Write(e); err != nil {
+			metrics.RecordDNSQueryLogDropped("write_error")
+			l.logger.Debug("failed to write query log entry", "error", err)
+		}
+	}
+}
+
+func (l *Logger) flush() {
+	for _, sink := range l.sinks {
+		if err := sink.Flush(); err != nil {
+			l.logger.Warn("failed to flush query log", "error", err)
+		}
+	}
+}
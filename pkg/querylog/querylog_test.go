@@ -0,0 +1,337 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package querylog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// blockingSink records entries, blocking each write until released.
+type blockingSink struct {
+	release chan struct{}
+	written chan *Entry
+}
+
+func (s *blockingSink) Write(e *Entry) error {
+	<-s.release
+	s.written <- e
+	return nil
+}
+func (s *blockingSink) Flush() error { return nil }
+func (s *blockingSink) Close() error { return nil }
+
+func testEntry() *Entry {
+	query := new(dns.Msg)
+	query.SetQuestion("app.example.com.", dns.TypeA)
+	response := new(dns.Msg)
+	response.SetReply(query)
+	response.Answer = append(response.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: "app.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 30},
+		A:   net.ParseIP("10.0.1.10"),
+	})
+	return &Entry{
+		Time:       time.Date(2025, 1, 1, 14, 3, 0, 0, time.UTC),
+		ClientIP:   net.ParseIP("198.51.100.7"),
+		ClientPort: 53000,
+		ServerIP:   net.ParseIP("192.0.2.53"),
+		ServerPort: 53,
+		Protocol:   "udp",
+		QName:      "app.example.com.",
+		QType:      "A",
+		Rcode:      "NOERROR",
+		Backend:    "10.0.1.10",
+		Algorithm:  "weighted",
+		View:       "default",
+		Latency:    250 * time.Microsecond,
+		Query:      query,
+		Response:   response,
+	}
+}
+
+func TestLogger_NeverBlocks(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{}), written: make(chan *Entry, 10)}
+	l := New([]Sink{sink}, 2, nil)
+
+	done := make(chan struct{})
+	go func() {
+		// One entry held by the writer, two queued, the rest dropped
+		for i := 0; i < 10; i++ {
+			l.Log(testEntry())
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Log blocked on a slow sink")
+	}
+
+	close(sink.release)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := len(sink.written); n < 2 || n > 3 {
+		t.Errorf("expected the queued entries to be written, got %d", n)
+	}
+
+	// Entries logged after Close are ignored
+	l.Log(testEntry())
+}
+
+func TestJSONSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.log")
+	sink, err := NewJSONSink(path, 300, 2)
+	if err != nil {
+		t.Fatalf("NewJSONSink failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := sink.Write(testEntry()); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	var line map[string]any
+	if err := json.Unmarshal([]byte(strings.SplitN(string(data), "\n", 2)[0]), &line); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if line["client_ip"] != "198.51.100.7" || line["qname"] != "app.example.com." || line["backend"] != "10.0.1.10" ||
+		line["algorithm"] != "weighted" || line["latency_us"] != float64(250) {
+		t.Errorf("unexpected entry: %v", line)
+	}
+
+	for _, suffix := range []string{".1", ".2"} {
+		if _, err := os.Stat(path + suffix); err != nil {
+			t.Errorf("expected rotated file %s: %v", suffix, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only max_backups rotated files to be kept")
+	}
+}
+
+// nextFrame reads one frame stream frame. Control frames return their type
+// and a nil payload.
+func nextFrame(r io.Reader) (uint32, []byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return 0, nil, err
+	}
+	if length == 0 {
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return 0, nil, err
+		}
+		control := make([]byte, length)
+		if _, err := io.ReadFull(r, control); err != nil {
+			return 0, nil, err
+		}
+		return binary.BigEndian.Uint32(control), nil, nil
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return 0, payload, nil
+}
+
+// readStream reads a frame stream from its START to its STOP control frame
+// and returns the data frames.
+func readStream(r io.Reader) ([][]byte, error) {
+	if control, _, err := nextFrame(r); err != nil || control != fstrmControlStart {
+		return nil, fmt.Errorf("expected START frame, got %d (err %v)", control, err)
+	}
+	var frames [][]byte
+	for {
+		control, payload, err := nextFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		if payload == nil {
+			if control != fstrmControlStop {
+				return nil, fmt.Errorf("expected STOP frame, got %d", control)
+			}
+			return frames, nil
+		}
+		frames = append(frames, payload)
+	}
+}
+
+// dnstapFields decodes the top-level fields of a protobuf message.
+func dnstapFields(t *testing.T, b []byte) map[protowire.Number][]byte {
+	t.Helper()
+	fields := make(map[protowire.Number][]byte)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var value []byte
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			value = protowire.AppendVarint(nil, v)
+		case protowire.Fixed32Type:
+			_, n = protowire.ConsumeFixed32(b)
+			value = b[:4]
+		}
+		if n < 0 {
+			t.Fatalf("invalid field %d: %v", num, protowire.ParseError(n))
+		}
+		fields[num] = value
+		b = b[n:]
+	}
+	return fields
+}
+
+func varint(b []byte) uint64 {
+	v, _ := protowire.ConsumeVarint(b)
+	return v
+}
+
+// checkDnstapFrames checks the query and response frames of testEntry.
+func checkDnstapFrames(t *testing.T, frames [][]byte) {
+	t.Helper()
+	if len(frames) != 2 {
+		t.Fatalf("expected query and response frames, got %d", len(frames))
+	}
+	for i, wantType := range []uint64{dnstapAuthQuery, dnstapAuthResponse} {
+		d := dnstapFields(t, frames[i])
+		if string(d[1]) != "ns1" || varint(d[15]) != dnstapTypeMessage {
+			t.Fatalf("unexpected dnstap envelope: %v", d)
+		}
+		m := dnstapFields(t, d[14])
+		if varint(m[1]) != wantType || varint(m[2]) != dnstapFamilyINET || varint(m[3]) != dnstapProtocolUDP {
+			t.Errorf("unexpected message header: %v", m)
+		}
+		if !net.IP(m[4]).Equal(net.ParseIP("198.51.100.7")) || varint(m[6]) != 53000 {
+			t.Errorf("unexpected query address %v:%d", net.IP(m[4]), varint(m[6]))
+		}
+
+		wireField := protowire.Number(10)
+		if wantType == dnstapAuthResponse {
+			wireField = 14
+			if !strings.Contains(string(d[3]), `"backend":"10.0.1.10"`) {
+				t.Errorf("expected routing decision in extra, got %q", d[3])
+			}
+		}
+		msg := new(dns.Msg)
+		if err := msg.Unpack(m[wireField]); err != nil || msg.Question[0].Name != "app.example.com." {
+			t.Errorf("unexpected DNS message: %v (err %v)", msg, err)
+		}
+	}
+}
+
+func TestDnstapSink_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.dnstap")
+	sink, err := NewDnstapFileSink(path, "ns1")
+	if err != nil {
+		t.Fatalf("NewDnstapFileSink failed: %v", err)
+	}
+	if err := sink.Write(testEntry()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open dnstap file: %v", err)
+	}
+	defer file.Close()
+	frames, err := readStream(bufio.NewReader(file))
+	if err != nil {
+		t.Fatalf("invalid frame stream: %v", err)
+	}
+	checkDnstapFrames(t, frames)
+}
+
+func TestDnstapSink_Socket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnstap.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+
+	// A collector: READY/ACCEPT handshake, then the stream, then FINISH
+	type result struct {
+		frames [][]byte
+		err    error
+	}
+	collected := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			collected <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		err = readControlFrame(conn, fstrmControlReady)
+		if err == nil {
+			err = writeControlFrame(conn, fstrmControlAccept, true)
+		}
+		var frames [][]byte
+		if err == nil {
+			frames, err = readStream(conn)
+		}
+		if err == nil {
+			err = writeControlFrame(conn, fstrmControlFinish, false)
+		}
+		collected <- result{frames: frames, err: err}
+	}()
+
+	sink := NewDnstapSocketSink(path, "ns1", nil)
+	if err := sink.Write(testEntry()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	res := <-collected
+	if res.err != nil {
+		t.Fatalf("collector failed: %v", res.err)
+	}
+	checkDnstapFrames(t, res.frames)
+}
+
+func TestDnstapSink_CollectorUnavailable(t *testing.T) {
+	sink := NewDnstapSocketSink(filepath.Join(t.TempDir(), "missing.sock"), "ns1", nil)
+	if err := sink.Write(testEntry()); err == nil {
+		t.Fatal("expected error without a collector")
+	}
+	// Reconnection attempts are spaced out
+	if err := sink.Write(testEntry()); err != errNotConnected {
+		t.Errorf("expected errNotConnected, got %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AlgorithmConsistentHash is the algorithm name for consistent hashing.
+const AlgorithmConsistentHash = "consistent-hash"
+
+const (
+	// ringPoints is the number of ring points of a server with the default
+	// weight (100); other weights get proportionally more or fewer.
+	ringPoints = 160
+	// maxRingPoints bounds the ring points of a single server.
+	maxRingPoints = 16000
+	// ringMemberTTL is how long servers missing from every routed pool stay
+	// on the ring. Unhealthy servers are skipped, not removed, so they stay
+	// while they recover.
+	ringMemberTTL = 10 * time.Minute
+
+	// Client prefix lengths the ring is keyed on: clients of a subnet share
+	// a backend, and resolvers can cache the answer per subnet.
+	consistentHashIPv4Prefix = 24
+	consistentHashIPv6Prefix = 56
+)
+
+// ConsistentHashRouter keeps clients on the same backend across TTL expiries
+// by hashing the client's /24 (/56 for IPv6) onto a weighted ring of the
+// servers. When a server fails only its clients move, each to the next
+// server on the ring; when it recovers they move back. The ring changes
+// incrementally: adding a server inserts its points and removing one
+// deletes them, without moving the points of other servers.
+type ConsistentHashRouter struct {
+	mu        sync.Mutex
+	points    []ringPoint
+	members   map[string]*ringMember
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// ringPoint is a point on the ring owned by a server.
+type ringPoint struct {
+	hash uint64
+	key  string // Server address:port
+}
+
+// ringMember is a server on the ring.
+type ringMember struct {
+	weight   int
+	lastSeen time.Time
+}
+
+// NewConsistentHashRouter creates a new consistent hash router.
+func NewConsistentHashRouter() *ConsistentHashRouter {
+	return &ConsistentHashRouter{
+		members: make(map[string]*ringMember),
+		now:     time.Now,
+	}
+}
+
+// Route selects the server owning the client's subnet.
+func (r *ConsistentHashRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank orders the pool by walking the ring clockwise from the hash of the
+// client's subnet: the client's server first, then the servers its clients
+// move to, in order, as servers fail. Servers not in the pool are skipped.
+func (r *ConsistentHashRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+
+	inPool := make(map[string]*Server, len(servers))
+	for _, s := range servers {
+		inPool[ringKey(s)] = s
+	}
+	hash := ringHash(clientSubnet(GetClientIP(ctx)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sync(servers)
+
+	ranked := make([]*Server, 0, len(servers))
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= hash })
+	for i := 0; i < len(r.points) && len(inPool) > 0; i++ {
+		p := r.points[(start+i)%len(r.points)]
+		if s, ok := inPool[p.key]; ok {
+			ranked = append(ranked, s)
+			delete(inPool, p.key)
+		}
+	}
+	return appendMissing(ranked, servers), nil
+}
+
+// sync adds the pool's servers to the ring, replaces the points of servers
+// whose weight changed, and removes servers missing from every pool for
+// ringMemberTTL. Only the points of changed servers are computed.
+// Caller must hold r.mu.
+func (r *ConsistentHashRouter) sync(servers []*Server) {
+	now := r.now()
+	var added []ringPoint
+	removed := make(map[string]bool)
+
+	for _, s := range servers {
+		key, weight := ringKey(s), effectiveWeight(s)
+		m, ok := r.members[key]
+		if ok && m.weight != weight {
+			removed[key] = true
+			ok = false
+		}
+		if !ok {
+			m = &ringMember{weight: weight}
+			r.members[key] = m
+			added = append(added, serverPoints(key, weight)...)
+		}
+		m.lastSeen = now
+	}
+
+	if now.Sub(r.lastPrune) >= ringMemberTTL {
+		for key, m := range r.members {
+			if now.Sub(m.lastSeen) >= ringMemberTTL {
+				removed[key] = true
+				delete(r.members, key)
+			}
+		}
+		r.lastPrune = now
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		r.points = mergePoints(r.points, added, removed)
+	}
+}
+
+// ECSScope returns the client prefix length the ring is keyed on.
+func (r *ConsistentHashRouter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	if clientIP.To4() != nil {
+		return consistentHashIPv4Prefix
+	}
+	return consistentHashIPv6Prefix
+}
+
+// Algorithm returns the algorithm name.
+func (r *ConsistentHashRouter) Algorithm() string {
+	return AlgorithmConsistentHash
+}
+
+// mergePoints returns the sorted ring points without those of removed
+// servers and with added merged in.
+func mergePoints(points, added []ringPoint, removed map[string]bool) []ringPoint {
+	sort.Slice(added, func(i, j int) bool { return lessPoint(added[i], added[j]) })
+
+	merged := make([]ringPoint, 0, len(points)+len(added))
+	i := 0
+	for _, p := range points {
+		if removed[p.key] {
+			continue
+		}
+		for i < len(added) && lessPoint(added[i], p) {
+			merged = append(merged, added[i])
+			i++
+		}
+		merged = append(merged, p)
+	}
+	return append(merged, added[i:]...)
+}
+
+// lessPoint orders ring points by hash, breaking ties by server so that the
+// ring does not depend on the order servers were added in.
+func lessPoint(a, b ringPoint) bool {
+	if a.hash != b.hash {
+		return a.hash < b.hash
+	}
+	return a.key < b.key
+}
+
+// serverPoints returns the ring points of a server, proportional to its
+// weight. A server always gets the same points, so it takes back the same
+// clients when it returns.
+func serverPoints(key string, weight int) []ringPoint {
+	n := min(max(weight*ringPoints/100, 1), maxRingPoints)
+	points := make([]ringPoint, n)
+	for i := range points {
+		points[i] = ringPoint{hash: ringHash(key + "#" + strconv.Itoa(i)), key: key}
+	}
+	return points
+}
+
+// ringKey identifies a server on the ring.
+func ringKey(s *Server) string {
+	return net.JoinHostPort(s.Address, strconv.Itoa(s.Port))
+}
+
+// clientSubnet returns the subnet a client is hashed on, or "" without a
+// client address.
+func clientSubnet(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(consistentHashIPv4Prefix, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(consistentHashIPv6Prefix, 128)).String()
+}
+
+// ringHash hashes s onto the ring. FNV-1a is followed by the splitmix64
+// finalizer, as FNV alone spreads similar short keys poorly.
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
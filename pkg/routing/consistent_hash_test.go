@@ -0,0 +1,209 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// hashServers returns n servers of weight 100.
+func hashServers(n int) []*Server {
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = &Server{Address: fmt.Sprintf("10.0.0.%d", i+1), Port: 80, Weight: 100}
+	}
+	return servers
+}
+
+// assignments routes 1000 client /24s and returns the server of each.
+func assignments(t *testing.T, router *ConsistentHashRouter, servers []*Server) map[int]string {
+	t.Helper()
+	result := make(map[int]string)
+	for i := 0; i < 1000; i++ {
+		ctx := WithClientIP(context.Background(), net.IPv4(100, byte(i/256), byte(i%256), 7))
+		selected, err := router.Route(ctx, NewSimpleServerPool(servers))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result[i] = selected.Address
+	}
+	return result
+}
+
+func TestConsistentHashRouter_Affinity(t *testing.T) {
+	router := NewConsistentHashRouter()
+	pool := NewSimpleServerPool(hashServers(4))
+
+	route := func(ip string) string {
+		selected, err := router.Route(WithClientIP(context.Background(), net.ParseIP(ip)), pool)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return selected.Address
+	}
+
+	// Clients of a subnet share a backend, query after query
+	first := route("198.51.100.1")
+	for i := 0; i < 10; i++ {
+		if got := route(fmt.Sprintf("198.51.100.%d", 10+i)); got != first {
+			t.Errorf("expected every client of 198.51.100.0/24 on %s, got %s", first, got)
+		}
+	}
+	if route("2001:db8:0:ff::1") != route("2001:db8:0:aa::2") {
+		t.Error("expected clients of an IPv6 /56 to share a backend")
+	}
+
+	// Another router instance makes the same decisions
+	other := NewConsistentHashRouter()
+	selected, _ := other.Route(WithClientIP(context.Background(), net.ParseIP("198.51.100.1")), pool)
+	if selected.Address != first {
+		t.Errorf("expected the ring to be deterministic, got %s and %s", first, selected.Address)
+	}
+
+	if _, err := router.Route(context.Background(), NewSimpleServerPool(nil)); err != ErrNoHealthyServers {
+		t.Errorf("expected ErrNoHealthyServers, got %v", err)
+	}
+}
+
+func TestConsistentHashRouter_FailureRemapsOnlyItsClients(t *testing.T) {
+	router := NewConsistentHashRouter()
+	servers := hashServers(5)
+	before := assignments(t, router, servers)
+
+	// 10.0.0.3 fails: it leaves the healthy pool but stays on the ring
+	healthy := append(append([]*Server{}, servers[:2]...), servers[3:]...)
+	during := assignments(t, router, healthy)
+	moved := 0
+	for client, addr := range before {
+		if addr != "10.0.0.3" && during[client] != addr {
+			t.Fatalf("client %d moved from healthy %s to %s", client, addr, during[client])
+		}
+		if addr == "10.0.0.3" {
+			moved++
+		}
+	}
+	if moved < 100 || moved > 300 {
+		t.Errorf("expected about a fifth of the clients on the failed server, got %d", moved)
+	}
+
+	// Its clients return when it recovers
+	after := assignments(t, router, servers)
+	for client, addr := range before {
+		if after[client] != addr {
+			t.Fatalf("client %d did not return to %s, got %s", client, addr, after[client])
+		}
+	}
+}
+
+func TestConsistentHashRouter_MembershipChanges(t *testing.T) {
+	router := NewConsistentHashRouter()
+	now := time.Now()
+	router.now = func() time.Time { return now }
+	servers := hashServers(4)
+	before := assignments(t, router, servers)
+
+	// A new server takes clients only for itself
+	grown := append(append([]*Server{}, servers...), &Server{Address: "10.0.0.99", Port: 80, Weight: 100})
+	after := assignments(t, router, grown)
+	moved := 0
+	for client, addr := range before {
+		if after[client] != addr {
+			if after[client] != "10.0.0.99" {
+				t.Fatalf("client %d moved between existing servers: %s to %s", client, addr, after[client])
+			}
+			moved++
+		}
+	}
+	if moved < 100 || moved > 300 {
+		t.Errorf("expected about a fifth of the clients to move to the new server, got %d", moved)
+	}
+
+	// Servers absent from every pool are removed after ringMemberTTL
+	if len(router.members) != 5 {
+		t.Fatalf("expected 5 ring members, got %d", len(router.members))
+	}
+	now = now.Add(ringMemberTTL)
+	assignments(t, router, servers)
+	if len(router.members) != 4 || len(router.points) != 4*ringPoints {
+		t.Errorf("expected the removed server's points to be dropped, got %d members and %d points",
+			len(router.members), len(router.points))
+	}
+	for i := 1; i < len(router.points); i++ {
+		if lessPoint(router.points[i], router.points[i-1]) {
+			t.Fatal("ring points are not sorted")
+		}
+	}
+}
+
+func TestConsistentHashRouter_Weights(t *testing.T) {
+	router := NewConsistentHashRouter()
+	servers := []*Server{
+		{Address: "10.0.0.1", Port: 80, Weight: 300},
+		{Address: "10.0.0.2", Port: 80, Weight: 100},
+	}
+	counts := make(map[string]int)
+	for _, addr := range assignments(t, router, servers) {
+		counts[addr]++
+	}
+	if counts["10.0.0.1"] < 650 || counts["10.0.0.1"] > 850 {
+		t.Errorf("expected about 75%% of the clients on the weight 300 server, got %v", counts)
+	}
+
+	// A weight change keeps most clients in place
+	before := assignments(t, router, servers)
+	servers[0] = &Server{Address: "10.0.0.1", Port: 80, Weight: 200}
+	after := assignments(t, router, servers)
+	moved := 0
+	for client, addr := range before {
+		if after[client] != addr {
+			moved++
+		}
+	}
+	if moved == 0 || moved > 200 {
+		t.Errorf("expected a small share of the clients to move on a weight change, got %d", moved)
+	}
+}
+
+func TestConsistentHashRouter_Rank(t *testing.T) {
+	router := NewConsistentHashRouter()
+	servers := hashServers(4)
+	ctx := WithClientIP(context.Background(), net.ParseIP("203.0.113.9"))
+
+	ranked, err := router.Rank(ctx, NewSimpleServerPool(servers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ranked) != 4 {
+		t.Fatalf("expected every server ranked, got %d", len(ranked))
+	}
+
+	// The second server is where the client goes when the first fails
+	var rest []*Server
+	for _, s := range servers {
+		if s != ranked[0] {
+			rest = append(rest, s)
+		}
+	}
+	selected, _ := router.Route(ctx, NewSimpleServerPool(rest))
+	if selected != ranked[1] {
+		t.Errorf("expected failover to %s, got %s", ranked[1].Address, selected.Address)
+	}
+}
+
+func TestConsistentHashRouter_ECSScope(t *testing.T) {
+	router := NewConsistentHashRouter()
+	if scope := router.ECSScope(net.ParseIP("198.51.100.7"), 32); scope != 24 {
+		t.Errorf("expected IPv4 scope 24, got %d", scope)
+	}
+	if scope := router.ECSScope(net.ParseIP("2001:db8::1"), 64); scope != 56 {
+		t.Errorf("expected IPv6 scope 56, got %d", scope)
+	}
+}
@@ -25,8 +25,10 @@ const (
 )
 
 // NewRouter creates a router based on the algorithm name.
-// Supported algorithms: round-robin, weighted, failover, geolocation, latency.
-// For geolocation or latency routing with providers, use Factory.NewRouter().
+// Supported algorithms: round-robin, weighted, failover, geolocation,
+// geoproximity, latency, consistent-hash, least-load.
+// For geolocation, geoproximity, latency or least-load routing with
+// providers, use Factory.NewRouter().
 func NewRouter(algorithm string) (Router, error) {
 	switch strings.ToLower(algorithm) {
 	case AlgorithmRoundRobin, "roundrobin", "rr":
@@ -38,10 +40,20 @@ func NewRouter(algorithm string) (Router, error) {
 	case AlgorithmGeolocation, "geo":
 		// Return a GeoRouter without resolver - must be configured later
 		return NewGeoRouter(GeoRouterConfig{}), nil
+	case AlgorithmGeoProximity, "geo-proximity", "geo_proximity":
+		// Return a GeoProximityRouter without locator - must be configured
+		// later
+		return NewGeoProximityRouter(GeoProximityRouterConfig{}), nil
+	case AlgorithmConsistentHash, "consistent_hash":
+		return NewConsistentHashRouter(), nil
 	case AlgorithmLatency:
 		// Return a LatencyRouter without provider - will fall back to round-robin
 		// until a provider is set via SetProvider()
 		return NewLatencyRouter(LatencyRouterConfig{}), nil
+	case AlgorithmLeastLoad, "least_load":
+		// Return a LeastLoadRouter without provider - will fall back to weighted
+		// until a provider is set via SetProvider()
+		return NewLeastLoadRouter(LeastLoadRouterConfig{}), nil
 	default:
 		return nil, fmt.Errorf("unknown routing algorithm: %s", algorithm)
 	}
@@ -52,6 +64,7 @@ type Factory struct {
 	geoResolver            *geo.Resolver
 	latencyProvider        LatencyProvider
 	learnedLatencyProvider LearnedLatencyProvider // ADR-017: Passive latency learning
+	loadProvider           LoadProvider
 	defaultRegion          string
 	maxLatencyMs           int
 	minLatencySamples      int
@@ -63,6 +76,7 @@ type FactoryConfig struct {
 	GeoResolver            *geo.Resolver
 	LatencyProvider        LatencyProvider
 	LearnedLatencyProvider LearnedLatencyProvider // ADR-017: Passive latency learning
+	LoadProvider           LoadProvider           // Agent-reported connection counts
 	DefaultRegion          string
 	MaxLatencyMs           int // Max latency threshold for latency routing (default: 500)
 	MinLatencySamples      int // Min samples required before using latency data (default: 3)
@@ -90,6 +104,7 @@ func NewFactory(cfg FactoryConfig) *Factory {
 		geoResolver:            cfg.GeoResolver,
 		latencyProvider:        cfg.LatencyProvider,
 		learnedLatencyProvider: cfg.LearnedLatencyProvider,
+		loadProvider:           cfg.LoadProvider,
 		defaultRegion:          cfg.DefaultRegion,
 		maxLatencyMs:           maxLatencyMs,
 		minLatencySamples:      minSamples,
@@ -113,6 +128,14 @@ func (f *Factory) NewRouter(algorithm string) (Router, error) {
 			DefaultRegion: f.defaultRegion,
 			Logger:        f.logger,
 		}), nil
+	case AlgorithmGeoProximity, "geo-proximity", "geo_proximity":
+		cfg := GeoProximityRouterConfig{Logger: f.logger}
+		if f.geoResolver != nil {
+			cfg.Locator = f.geoResolver
+		}
+		return NewGeoProximityRouter(cfg), nil
+	case AlgorithmConsistentHash, "consistent_hash":
+		return NewConsistentHashRouter(), nil
 	case AlgorithmLatency:
 		return NewLatencyRouter(LatencyRouterConfig{
 			Provider:     f.latencyProvider,
@@ -128,6 +151,11 @@ func (f *Factory) NewRouter(algorithm string) (Router, error) {
 			MinSamples:   f.minLatencySamples,
 			Logger:       f.logger,
 		}), nil
+	case AlgorithmLeastLoad, "least_load":
+		return NewLeastLoadRouter(LeastLoadRouterConfig{
+			Provider: f.loadProvider,
+			Logger:   f.logger,
+		}), nil
 	default:
 		return nil, fmt.Errorf("unknown routing algorithm: %s", algorithm)
 	}
@@ -153,6 +181,11 @@ func (f *Factory) SetLearnedLatencyProvider(provider LearnedLatencyProvider) {
 	f.learnedLatencyProvider = provider
 }
 
+// SetLoadProvider sets or updates the load provider for creating LeastLoadRouters.
+func (f *Factory) SetLoadProvider(provider LoadProvider) {
+	f.loadProvider = provider
+}
+
 // SetLatencyConfig updates the latency routing configuration.
 func (f *Factory) SetLatencyConfig(maxLatencyMs, minSamples int) {
 	if maxLatencyMs > 0 {
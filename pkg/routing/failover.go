@@ -8,31 +8,235 @@ package routing
 
 import (
 	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-// FailoverRouter implements active/standby failover selection.
-// It always selects the first available server in the pool,
-// providing predictable failover ordering.
-type FailoverRouter struct{}
+// FailoverPolicy controls how a FailoverRouter moves between priority tiers.
+type FailoverPolicy struct {
+	// MinHealthy is the number of healthy servers a tier needs to receive
+	// traffic. Default: 1
+	MinHealthy int
+
+	// FailbackHoldDown is how long a higher tier must stay at MinHealthy
+	// before traffic returns to it. 0 fails back immediately.
+	FailbackHoldDown time.Duration
+
+	// ManualFailback keeps traffic on a lower tier until that tier fails,
+	// instead of returning to a recovered higher tier.
+	ManualFailback bool
+}
+
+// FailoverTier is the state of one priority tier of a failover domain.
+type FailoverTier struct {
+	Priority   int
+	Servers    int       // Servers in the tier, healthy or not; 0 when unknown
+	Healthy    int       // Healthy servers in the tier at the last routing decision
+	Active     bool      // The tier receives traffic
+	Panic      bool      // Health was ignored (panic mode), so Healthy counts every server routed to
+	FailbackAt time.Time // When traffic returns to this tier; zero when no failback is pending
+}
+
+// FailoverRouter implements active/standby failover across priority tiers.
+// Servers are grouped by priority, lower values first. Traffic goes to the
+// highest tier with at least MinHealthy healthy servers, in pool order
+// within the tier. When that tier falls below MinHealthy, traffic spills to
+// the next tier; when it recovers, traffic returns after the failback
+// hold-down. Without priorities every server is in one tier, so the first
+// server of the pool is selected.
+type FailoverRouter struct {
+	mu     sync.Mutex
+	policy FailoverPolicy
+	states map[string]*failoverState // By address family, as A and AAAA pools differ
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+// failoverState is the tier state of one address family.
+type failoverState struct {
+	active     int       // Priority of the tier receiving traffic
+	failback   int       // Priority of the tier waiting out the hold-down
+	failbackAt time.Time // Zero when no failback is pending
+	tiers      []FailoverTier
+}
 
 // NewFailoverRouter creates a new failover router.
 func NewFailoverRouter() *FailoverRouter {
-	return &FailoverRouter{}
+	return &FailoverRouter{
+		policy: FailoverPolicy{MinHealthy: 1},
+		states: make(map[string]*failoverState),
+		now:    time.Now,
+		logger: slog.Default(),
+	}
 }
 
-// Route selects the first server in the pool (active/standby).
-// The pool should be ordered by priority (primary first).
+// SetPolicy sets the tier policy.
+func (r *FailoverRouter) SetPolicy(policy FailoverPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	policy.MinHealthy = max(policy.MinHealthy, 1)
+	r.policy = policy
+}
+
+// Policy returns the tier policy.
+func (r *FailoverRouter) Policy() FailoverPolicy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.policy
+}
+
+// Route selects the first server of the active tier.
 func (r *FailoverRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank returns the servers of the active tier in pool order, followed by
+// the other tiers in priority order.
+func (r *FailoverRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
 	servers := pool.Servers()
 	if len(servers) == 0 {
 		return nil, ErrNoHealthyServers
 	}
 
-	// Always return the first (highest priority) server
-	return servers[0], nil
+	tiers := make(map[int][]*Server)
+	var priorities []int
+	for _, s := range servers {
+		if _, ok := tiers[s.Priority]; !ok {
+			priorities = append(priorities, s.Priority)
+		}
+		tiers[s.Priority] = append(tiers[s.Priority], s)
+	}
+	sort.Ints(priorities)
+
+	active := r.activeTier(ctx, addressFamily(servers[0]), priorities, tiers)
+
+	ranked := make([]*Server, 0, len(servers))
+	ranked = append(ranked, tiers[active]...)
+	for _, p := range priorities {
+		if p != active {
+			ranked = append(ranked, tiers[p]...)
+		}
+	}
+	return ranked, nil
+}
+
+// activeTier returns the priority of the tier to receive traffic, moving
+// between tiers as the policy allows. A dry run works on a copy of the tier
+// state, so the tiers and hold-downs of real traffic are unchanged.
+func (r *FailoverRouter) activeTier(ctx context.Context, family string, priorities []int, tiers map[int][]*Server) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dryRun := IsDryRun(ctx)
+
+	// The highest tier at MinHealthy, or the highest tier with any healthy
+	// server when none is
+	desired := priorities[0]
+	for _, p := range priorities {
+		if len(tiers[p]) >= r.policy.MinHealthy {
+			desired = p
+			break
+		}
+	}
+
+	now := r.now()
+	state, ok := r.states[family]
+	if ok && dryRun {
+		scratch := *state
+		scratch.tiers = nil
+		state = &scratch
+	}
+	switch {
+	case !ok:
+		state = &failoverState{active: desired}
+		if !dryRun {
+			r.states[family] = state
+		}
+	case desired == state.active:
+		state.failbackAt = time.Time{}
+	case desired > state.active || len(tiers[state.active]) < r.policy.MinHealthy:
+		// The active tier fell below MinHealthy: fail over immediately
+		r.switchTier(ctx, state, desired, "failover")
+	case r.policy.ManualFailback:
+		// A higher tier recovered; stay on the active tier while it holds
+	case r.policy.FailbackHoldDown <= 0:
+		r.switchTier(ctx, state, desired, "failback")
+	case state.failbackAt.IsZero() || state.failback != desired:
+		state.failback = desired
+		state.failbackAt = now.Add(r.policy.FailbackHoldDown)
+	case !now.Before(state.failbackAt):
+		r.switchTier(ctx, state, desired, "failback")
+	}
+
+	state.tiers = state.tiers[:0]
+	for _, p := range priorities {
+		tier := FailoverTier{Priority: p, Healthy: len(tiers[p]), Active: p == state.active, Panic: IsPanic(ctx, family)}
+		if p == state.failback && !state.failbackAt.IsZero() {
+			tier.FailbackAt = state.failbackAt
+		}
+		state.tiers = append(state.tiers, tier)
+	}
+	return state.active
+}
+
+// switchTier moves traffic to the tier with the given priority.
+// Caller must hold r.mu.
+func (r *FailoverRouter) switchTier(ctx context.Context, state *failoverState, priority int, reason string) {
+	if !IsDryRun(ctx) {
+		r.logger.Info("failover tier changed",
+			"domain", GetDomain(ctx),
+			"from_priority", state.active,
+			"to_priority", priority,
+			"reason", reason,
+		)
+	}
+	state.active = priority
+	state.failbackAt = time.Time{}
+}
+
+// Tiers returns the tiers seen at the last routing decision, merged across
+// address families, in priority order. Servers is left zero.
+func (r *FailoverRouter) Tiers() []FailoverTier {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	merged := make(map[int]*FailoverTier)
+	for _, state := range r.states {
+		for _, tier := range state.tiers {
+			m, ok := merged[tier.Priority]
+			if !ok {
+				m = &FailoverTier{Priority: tier.Priority}
+				merged[tier.Priority] = m
+			}
+			m.Healthy += tier.Healthy
+			m.Active = m.Active || tier.Active
+			m.Panic = m.Panic || tier.Panic
+			if !tier.FailbackAt.IsZero() && (m.FailbackAt.IsZero() || tier.FailbackAt.Before(m.FailbackAt)) {
+				m.FailbackAt = tier.FailbackAt
+			}
+		}
+	}
+
+	tiers := make([]FailoverTier, 0, len(merged))
+	for _, tier := range merged {
+		tiers = append(tiers, *tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Priority < tiers[j].Priority })
+	return tiers
 }
 
 // Algorithm returns the algorithm name.
 func (r *FailoverRouter) Algorithm() string {
 	return AlgorithmFailover
 }
+
+// addressFamily returns "ipv6" for servers with an IPv6 address and "ipv4"
+// otherwise.
+func addressFamily(s *Server) string {
+	if strings.Contains(s.Address, ":") {
+		return "ipv6"
+	}
+	return "ipv4"
+}
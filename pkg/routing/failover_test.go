@@ -9,6 +9,7 @@ package routing
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 func TestFailoverRouter_EmptyPool(t *testing.T) {
@@ -81,3 +82,213 @@ func TestFailoverRouter_Algorithm(t *testing.T) {
 		t.Errorf("expected %s, got %s", AlgorithmFailover, router.Algorithm())
 	}
 }
+
+func TestFailoverRouter_Rank(t *testing.T) {
+	router := NewFailoverRouter()
+	servers := []*Server{
+		{Address: "10.0.0.1", Port: 80},
+		{Address: "10.0.0.2", Port: 80},
+		{Address: "10.0.0.3", Port: 80},
+	}
+
+	ranked, err := router.Rank(context.Background(), NewSimpleServerPool(servers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, s := range ranked {
+		if s != servers[i] {
+			t.Errorf("position %d: expected %s, got %s", i, servers[i].Address, s.Address)
+		}
+	}
+}
+
+func newTierTestServers(primaryHealthy int) []*Server {
+	var servers []*Server
+	for i := 0; i < primaryHealthy; i++ {
+		servers = append(servers, &Server{Address: "10.0.1." + string(rune('1'+i)), Port: 80, Priority: 1})
+	}
+	return append(servers,
+		&Server{Address: "10.0.2.1", Port: 80, Priority: 2},
+		&Server{Address: "10.0.2.2", Port: 80, Priority: 2},
+	)
+}
+
+func TestFailoverRouter_MinHealthySpillover(t *testing.T) {
+	router := NewFailoverRouter()
+	router.SetPolicy(FailoverPolicy{MinHealthy: 2})
+	ctx := context.Background()
+
+	selected, err := router.Route(ctx, NewSimpleServerPool(newTierTestServers(2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if selected.Address != "10.0.1.1" {
+		t.Errorf("expected the primary tier, got %s", selected.Address)
+	}
+
+	// One primary server left: traffic spills to the secondary tier
+	ranked, err := router.Rank(ctx, NewSimpleServerPool(newTierTestServers(1)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := addresses(ranked), "10.0.2.1,10.0.2.2,10.0.1.1"; got != want {
+		t.Errorf("expected ranking %s, got %s", want, got)
+	}
+
+	tiers := router.Tiers()
+	if len(tiers) != 2 || tiers[0].Active || !tiers[1].Active || tiers[0].Healthy != 1 {
+		t.Errorf("expected the secondary tier active, got %+v", tiers)
+	}
+}
+
+func TestFailoverRouter_FailbackHoldDown(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := NewFailoverRouter()
+	router.now = func() time.Time { return now }
+	router.SetPolicy(FailoverPolicy{MinHealthy: 2, FailbackHoldDown: time.Minute})
+	ctx := context.Background()
+
+	route := func(primaryHealthy int) string {
+		t.Helper()
+		selected, err := router.Route(ctx, NewSimpleServerPool(newTierTestServers(primaryHealthy)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return selected.Address
+	}
+
+	if got := route(1); got != "10.0.2.1" {
+		t.Fatalf("expected failover to the secondary tier, got %s", got)
+	}
+
+	// The primary tier recovers: traffic stays until the hold-down expires
+	if got := route(2); got != "10.0.2.1" {
+		t.Errorf("expected the secondary tier during the hold-down, got %s", got)
+	}
+	tiers := router.Tiers()
+	if want := now.Add(time.Minute); !tiers[0].FailbackAt.Equal(want) {
+		t.Errorf("expected failback at %v, got %+v", want, tiers)
+	}
+
+	now = now.Add(30 * time.Second)
+	if got := route(2); got != "10.0.2.1" {
+		t.Errorf("expected the secondary tier during the hold-down, got %s", got)
+	}
+
+	now = now.Add(30 * time.Second)
+	if got := route(2); got != "10.0.1.1" {
+		t.Errorf("expected failback to the primary tier, got %s", got)
+	}
+	if tiers := router.Tiers(); !tiers[0].Active || !tiers[0].FailbackAt.IsZero() {
+		t.Errorf("expected the primary tier active, got %+v", tiers)
+	}
+}
+
+func TestFailoverRouter_DryRun(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := NewFailoverRouter()
+	router.now = func() time.Time { return now }
+	router.SetPolicy(FailoverPolicy{MinHealthy: 2, FailbackHoldDown: time.Minute})
+
+	route := func(ctx context.Context, primaryHealthy int) string {
+		t.Helper()
+		selected, err := router.Route(ctx, NewSimpleServerPool(newTierTestServers(primaryHealthy)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return selected.Address
+	}
+
+	// A dry run creates no tier state
+	if got := route(WithDryRun(context.Background()), 1); got != "10.0.2.1" {
+		t.Fatalf("expected the dry run to fail over, got %s", got)
+	}
+	if tiers := router.Tiers(); len(tiers) != 0 {
+		t.Fatalf("expected no tier state after a dry run, got %+v", tiers)
+	}
+
+	if got := route(context.Background(), 1); got != "10.0.2.1" {
+		t.Fatalf("expected failover to the secondary tier, got %s", got)
+	}
+	before := router.Tiers()
+
+	// A dry run with the primary tier recovered starts no hold-down
+	if got := route(WithDryRun(context.Background()), 2); got != "10.0.2.1" {
+		t.Errorf("expected the secondary tier during the hold-down, got %s", got)
+	}
+	after := router.Tiers()
+	if len(after) != len(before) || !after[0].FailbackAt.IsZero() || after[0].Healthy != before[0].Healthy {
+		t.Errorf("expected the tier state unchanged by a dry run, got %+v, was %+v", after, before)
+	}
+}
+
+func TestFailoverRouter_HoldDownRestartsOnFlap(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := NewFailoverRouter()
+	router.now = func() time.Time { return now }
+	router.SetPolicy(FailoverPolicy{MinHealthy: 2, FailbackHoldDown: time.Minute})
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		advance        time.Duration
+		primaryHealthy int
+		want           string
+	}{
+		{0, 1, "10.0.2.1"},
+		{0, 2, "10.0.2.1"},                // Hold-down starts
+		{45 * time.Second, 1, "10.0.2.1"}, // Primary drops again: hold-down cleared
+		{0, 2, "10.0.2.1"},                // Hold-down restarts
+		{45 * time.Second, 2, "10.0.2.1"},
+		{15 * time.Second, 2, "10.0.1.1"},
+	} {
+		now = now.Add(step.advance)
+		selected, err := router.Route(ctx, NewSimpleServerPool(newTierTestServers(step.primaryHealthy)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if selected.Address != step.want {
+			t.Errorf("at %v with %d primary servers: expected %s, got %s",
+				now.Format(time.TimeOnly), step.primaryHealthy, step.want, selected.Address)
+		}
+	}
+}
+
+func TestFailoverRouter_ManualFailback(t *testing.T) {
+	router := NewFailoverRouter()
+	router.SetPolicy(FailoverPolicy{MinHealthy: 2, ManualFailback: true})
+	ctx := context.Background()
+
+	for _, primaryHealthy := range []int{1, 2, 2} {
+		selected, err := router.Route(ctx, NewSimpleServerPool(newTierTestServers(primaryHealthy)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if selected.Address != "10.0.2.1" {
+			t.Errorf("expected traffic to stay on the secondary tier, got %s", selected.Address)
+		}
+	}
+}
+
+func TestFailoverRouter_PanicTiers(t *testing.T) {
+	router := NewFailoverRouter()
+	router.SetPolicy(FailoverPolicy{MinHealthy: 2})
+	pool := NewSimpleServerPool(newTierTestServers(2))
+
+	if _, err := router.Route(WithPanic(context.Background(), "ipv6"), pool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tier := range router.Tiers() {
+		if tier.Panic {
+			t.Errorf("expected no panic for an IPv4 pool, got %+v", tier)
+		}
+	}
+
+	if _, err := router.Route(WithPanic(context.Background(), "ipv4"), pool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tier := range router.Tiers() {
+		if !tier.Panic {
+			t.Errorf("expected tiers routed in panic mode to report it, got %+v", tier)
+		}
+	}
+}
@@ -93,6 +93,13 @@ func NewGeoRouter(cfg GeoRouterConfig) *GeoRouter {
 // The client IP should be provided in the context using WithClientIP().
 // If no client IP is available, falls back to round-robin.
 func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank orders servers by geographic preference: servers in the client's
+// region first (round-robin among them), then the default region, then
+// every other server in pool order.
+func (r *GeoRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
 	servers := pool.Servers()
 	if len(servers) == 0 {
 		return nil, ErrNoHealthyServers
@@ -100,15 +107,16 @@ func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error)
 
 	// Get domain from context for metrics
 	domain := GetDomain(ctx)
+	recordMetrics := domain != "" && !IsDryRun(ctx)
 
 	// Get client IP from context
 	clientIP := GetClientIP(ctx)
 	if clientIP == nil {
 		r.logger.Debug("no client IP in context, using round-robin fallback")
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordGeoFallback(domain, "no_client_ip")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	r.mu.RLock()
@@ -117,10 +125,10 @@ func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error)
 
 	if resolver == nil {
 		r.logger.Warn("geo resolver not configured, using round-robin fallback")
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordGeoFallback(domain, "no_resolver")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	// Resolve client IP to region
@@ -133,7 +141,7 @@ func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error)
 	)
 
 	// Record metrics based on match type
-	if domain != "" {
+	if recordMetrics {
 		switch match.MatchType {
 		case geo.MatchTypeCustomMapping:
 			metrics.RecordGeoCustomHit(domain, match.Region, match.MatchedCIDR)
@@ -151,7 +159,12 @@ func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error)
 	if len(regionServers) > 0 {
 		// Use round-robin among servers in the matched region
 		regionPool := NewSimpleServerPool(regionServers)
-		return r.fallback.Route(ctx, regionPool)
+		ranked, err := r.fallback.Rank(ctx, regionPool)
+		if err != nil {
+			return nil, err
+		}
+		ranked = appendMissing(ranked, r.filterByRegion(servers, r.defaultRegion))
+		return appendMissing(ranked, servers), nil
 	}
 
 	// No servers in matched region, try default region
@@ -160,13 +173,17 @@ func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error)
 			"matchedRegion", match.Region,
 			"defaultRegion", r.defaultRegion,
 		)
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordGeoFallback(domain, "no_servers_in_region")
 		}
 		defaultServers := r.filterByRegion(servers, r.defaultRegion)
 		if len(defaultServers) > 0 {
 			defaultPool := NewSimpleServerPool(defaultServers)
-			return r.fallback.Route(ctx, defaultPool)
+			ranked, err := r.fallback.Rank(ctx, defaultPool)
+			if err != nil {
+				return nil, err
+			}
+			return appendMissing(ranked, servers), nil
 		}
 	}
 
@@ -174,21 +191,15 @@ func (r *GeoRouter) Route(ctx context.Context, pool ServerPool) (*Server, error)
 	r.logger.Debug("no servers in region or default, using any available server",
 		"matchedRegion", match.Region,
 	)
-	if domain != "" {
+	if recordMetrics {
 		metrics.RecordGeoFallback(domain, "no_match")
 	}
-	return r.fallback.Route(ctx, pool)
+	return r.fallback.Rank(ctx, pool)
 }
 
 // filterByRegion returns servers that belong to the specified region.
 func (r *GeoRouter) filterByRegion(servers []*Server, region string) []*Server {
-	var result []*Server
-	for _, s := range servers {
-		if s.Region == region {
-			result = append(result, s)
-		}
-	}
-	return result
+	return filterRegion(servers, region)
 }
 
 // Algorithm returns the algorithm name.
@@ -196,6 +207,28 @@ func (r *GeoRouter) Algorithm() string {
 	return AlgorithmGeolocation
 }
 
+// ECSScope implements ECSScoper. A region from a custom mapping applies to the
+// mapped network; a GeoIP or default region applies to the subnet the
+// resolver sent, as GeoIP networks are not known.
+func (r *GeoRouter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	r.mu.RLock()
+	resolver := r.resolver
+	r.mu.RUnlock()
+	if resolver == nil {
+		// Round-robin fallback: the same answer for every client
+		return 0
+	}
+
+	match := resolver.Resolve(clientIP)
+	if match.MatchType == geo.MatchTypeCustomMapping {
+		if _, network, err := net.ParseCIDR(match.MatchedCIDR); err == nil {
+			ones, _ := network.Mask.Size()
+			return ones
+		}
+	}
+	return sourcePrefix
+}
+
 // SetResolver sets or updates the geo resolver.
 func (r *GeoRouter) SetResolver(resolver *geo.Resolver) {
 	r.mu.Lock()
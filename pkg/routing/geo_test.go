@@ -70,6 +70,13 @@ func TestGeoRouter_WithClientIP_NoResolver(t *testing.T) {
 	}
 }
 
+func TestGeoRouter_ECSScope_NoResolver(t *testing.T) {
+	router := NewGeoRouter(GeoRouterConfig{})
+	if scope := router.ECSScope(net.ParseIP("198.51.100.0"), 24); scope != 0 {
+		t.Errorf("expected scope 0 for the round-robin fallback, got %d", scope)
+	}
+}
+
 func TestGeoRouter_FilterByRegion(t *testing.T) {
 	router := NewGeoRouter(GeoRouterConfig{DefaultRegion: "us-east-1"})
 	servers := []*Server{
@@ -138,3 +145,39 @@ func TestGeoRouter_AliasNames(t *testing.T) {
 		t.Errorf("expected algorithm %s, got %s", AlgorithmGeolocation, router.Algorithm())
 	}
 }
+
+func TestGeoRouter_Rank_NoClientIP(t *testing.T) {
+	router := NewGeoRouter(GeoRouterConfig{})
+	servers := []*Server{
+		{Address: "10.0.1.10", Port: 80, Region: "us-east-1"},
+		{Address: "10.0.2.10", Port: 80, Region: "us-west-2"},
+		{Address: "10.0.3.10", Port: 80, Region: "eu-west-1"},
+	}
+
+	// Without client IP the ranking is the round-robin fallback over every server
+	ranked, err := router.Rank(context.Background(), NewSimpleServerPool(servers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ranked) != len(servers) {
+		t.Fatalf("expected %d servers, got %d", len(servers), len(ranked))
+	}
+	seen := make(map[*Server]bool)
+	for _, s := range ranked {
+		seen[s] = true
+	}
+	if len(seen) != len(servers) {
+		t.Errorf("expected every server exactly once, got %v", ranked)
+	}
+}
+
+func TestAppendMissing(t *testing.T) {
+	a := &Server{Address: "10.0.0.1"}
+	b := &Server{Address: "10.0.0.2"}
+	c := &Server{Address: "10.0.0.3"}
+
+	ranked := appendMissing([]*Server{b}, []*Server{a, b, c})
+	if len(ranked) != 3 || ranked[0] != b || ranked[1] != a || ranked[2] != c {
+		t.Errorf("unexpected ranking: %v", ranked)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/loganrossus/OpenGSLB/pkg/geo"
+	"github.com/loganrossus/OpenGSLB/pkg/metrics"
+)
+
+// AlgorithmGeoProximity is the algorithm name for geoproximity routing.
+const AlgorithmGeoProximity = "geoproximity"
+
+// Locator places clients and regions for geoproximity routing.
+// *geo.Resolver implements it.
+type Locator interface {
+	// Locate returns the position of a client.
+	Locate(ip net.IP) (geo.Location, bool)
+	// GetRegion returns the configuration of a region, with its coordinates
+	// and bias.
+	GetRegion(name string) (*geo.RegionConfig, bool)
+	// DefaultRegion returns the region preferred for clients that cannot be
+	// located.
+	DefaultRegion() string
+}
+
+// GeoProximityRouter routes clients to the nearest region by great-circle
+// distance between the client's position, from a City database, and the
+// regions' coordinates. A region's bias scales the distances to it by
+// 1 - bias/100, expanding or shrinking the area it serves.
+type GeoProximityRouter struct {
+	mu       sync.RWMutex
+	locator  Locator
+	fallback Router
+	logger   *slog.Logger
+}
+
+// GeoProximityRouterConfig contains configuration for creating a
+// GeoProximityRouter.
+type GeoProximityRouterConfig struct {
+	Locator Locator
+	Logger  *slog.Logger
+}
+
+// NewGeoProximityRouter creates a new geoproximity router.
+func NewGeoProximityRouter(cfg GeoProximityRouterConfig) *GeoProximityRouter {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &GeoProximityRouter{
+		locator:  cfg.Locator,
+		fallback: NewRoundRobinRouter(),
+		logger:   logger,
+	}
+}
+
+// Route selects a server in the region nearest the client.
+func (r *GeoProximityRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank orders servers by the biased distance of their region to the client:
+// the nearest region's servers first (round-robin among them), then the
+// other regions nearest first, then servers of regions without coordinates.
+// Clients that cannot be located get the default region first.
+func (r *GeoProximityRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+
+	domain := GetDomain(ctx)
+	recordMetrics := domain != "" && !IsDryRun(ctx)
+	r.mu.RLock()
+	locator := r.locator
+	r.mu.RUnlock()
+
+	if locator == nil {
+		r.logger.Warn("geo resolver not configured, using round-robin fallback")
+		if recordMetrics {
+			metrics.RecordGeoFallback(domain, "no_resolver")
+		}
+		return r.fallback.Rank(ctx, pool)
+	}
+
+	var loc geo.Location
+	located := false
+	if clientIP := GetClientIP(ctx); clientIP != nil {
+		loc, located = locator.Locate(clientIP)
+	}
+	if !located {
+		if recordMetrics {
+			metrics.RecordGeoFallback(domain, "no_location")
+		}
+		return r.rankRegionFirst(ctx, servers, locator.DefaultRegion())
+	}
+
+	// Biased distance of each region with coordinates
+	type regionDistance struct {
+		name     string
+		distance float64
+	}
+	var regions []regionDistance
+	seen := make(map[string]bool)
+	for _, s := range servers {
+		if seen[s.Region] {
+			continue
+		}
+		seen[s.Region] = true
+		cfg, ok := locator.GetRegion(s.Region)
+		if !ok || !cfg.HasCoordinates {
+			continue
+		}
+		d := geo.Distance(loc.Latitude, loc.Longitude, cfg.Latitude, cfg.Longitude)
+		regions = append(regions, regionDistance{name: s.Region, distance: d * (1 - float64(cfg.Bias)/100)})
+	}
+	if len(regions) == 0 {
+		r.logger.Debug("no region with coordinates, using default region")
+		if recordMetrics {
+			metrics.RecordGeoFallback(domain, "no_region_coordinates")
+		}
+		return r.rankRegionFirst(ctx, servers, locator.DefaultRegion())
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].distance != regions[j].distance {
+			return regions[i].distance < regions[j].distance
+		}
+		return regions[i].name < regions[j].name
+	})
+
+	r.logger.Debug("geoproximity resolution",
+		"latitude", loc.Latitude,
+		"longitude", loc.Longitude,
+		"accuracy_radius_km", loc.AccuracyRadius,
+		"region", regions[0].name,
+		"biased_distance_km", regions[0].distance,
+	)
+	if recordMetrics {
+		metrics.RecordGeoRoutingDecision(domain, "", "", regions[0].name)
+	}
+
+	ranked, err := r.fallback.Rank(ctx, NewSimpleServerPool(filterRegion(servers, regions[0].name)))
+	if err != nil {
+		return nil, err
+	}
+	for _, region := range regions[1:] {
+		ranked = append(ranked, filterRegion(servers, region.name)...)
+	}
+	// Servers of regions without coordinates keep their pool order at the end
+	return appendMissing(ranked, servers), nil
+}
+
+// rankRegionFirst ranks the servers of a region first, round-robin among
+// them, followed by every other server in pool order. Without servers in
+// the region every server is ranked round-robin.
+func (r *GeoProximityRouter) rankRegionFirst(ctx context.Context, servers []*Server, region string) ([]*Server, error) {
+	regionServers := filterRegion(servers, region)
+	if len(regionServers) == 0 {
+		return r.fallback.Rank(ctx, NewSimpleServerPool(servers))
+	}
+	ranked, err := r.fallback.Rank(ctx, NewSimpleServerPool(regionServers))
+	if err != nil {
+		return nil, err
+	}
+	return appendMissing(ranked, servers), nil
+}
+
+// filterRegion returns the servers of a region.
+func filterRegion(servers []*Server, region string) []*Server {
+	var result []*Server
+	for _, s := range servers {
+		if s.Region == region {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Algorithm returns the algorithm name.
+func (r *GeoProximityRouter) Algorithm() string {
+	return AlgorithmGeoProximity
+}
+
+// ECSScope implements ECSScoper. A position from a custom mapping applies to
+// the mapped network; a GeoIP position applies to the subnet the resolver
+// sent, as GeoIP networks are not known.
+func (r *GeoProximityRouter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	r.mu.RLock()
+	locator := r.locator
+	r.mu.RUnlock()
+	if locator == nil {
+		// Round-robin fallback: the same answer for every client
+		return 0
+	}
+
+	loc, ok := locator.Locate(clientIP)
+	if ok && loc.MatchType == geo.MatchTypeCustomMapping {
+		if _, network, err := net.ParseCIDR(loc.MatchedCIDR); err == nil {
+			ones, _ := network.Mask.Size()
+			return ones
+		}
+	}
+	return sourcePrefix
+}
+
+// SetLocator sets or updates the locator.
+func (r *GeoProximityRouter) SetLocator(locator Locator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locator = locator
+}
@@ -0,0 +1,209 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/loganrossus/OpenGSLB/pkg/geo"
+)
+
+// fakeLocator places clients by exact IP address.
+type fakeLocator struct {
+	clients       map[string]geo.Location
+	regions       map[string]*geo.RegionConfig
+	defaultRegion string
+}
+
+func (f *fakeLocator) Locate(ip net.IP) (geo.Location, bool) {
+	loc, ok := f.clients[ip.String()]
+	return loc, ok
+}
+
+func (f *fakeLocator) GetRegion(name string) (*geo.RegionConfig, bool) {
+	cfg, ok := f.regions[name]
+	return cfg, ok
+}
+
+func (f *fakeLocator) DefaultRegion() string {
+	return f.defaultRegion
+}
+
+// newFakeLocator returns a locator with us-east (Virginia), eu-west (Dublin)
+// and ap-south (Singapore), and clients in Paris and Reykjavik.
+func newFakeLocator() *fakeLocator {
+	return &fakeLocator{
+		clients: map[string]geo.Location{
+			"192.0.2.1": {Latitude: 48.85, Longitude: 2.35, MatchType: geo.MatchTypeGeoIP},   // Paris
+			"192.0.2.2": {Latitude: 64.15, Longitude: -21.94, MatchType: geo.MatchTypeGeoIP}, // Reykjavik
+			"10.1.0.1":  {Latitude: 1.35, Longitude: 103.8, MatchType: geo.MatchTypeCustomMapping, Region: "ap-south", MatchedCIDR: "10.1.0.0/16"},
+		},
+		regions: map[string]*geo.RegionConfig{
+			"us-east":  {Name: "us-east", Latitude: 38.9, Longitude: -77.4, HasCoordinates: true},
+			"eu-west":  {Name: "eu-west", Latitude: 53.35, Longitude: -6.26, HasCoordinates: true},
+			"ap-south": {Name: "ap-south", Latitude: 1.35, Longitude: 103.8, HasCoordinates: true},
+			"onprem":   {Name: "onprem"},
+		},
+		defaultRegion: "us-east",
+	}
+}
+
+func proximityServers() []*Server {
+	return []*Server{
+		{Address: "10.0.0.1", Port: 80, Region: "onprem"},
+		{Address: "10.0.1.1", Port: 80, Region: "us-east"},
+		{Address: "10.0.2.1", Port: 80, Region: "ap-south"},
+		{Address: "10.0.3.1", Port: 80, Region: "eu-west"},
+		{Address: "10.0.3.2", Port: 80, Region: "eu-west"},
+	}
+}
+
+func rankRegions(ranked []*Server) []string {
+	var regions []string
+	for _, s := range ranked {
+		regions = append(regions, s.Region)
+	}
+	return regions
+}
+
+func TestGeoProximityRouter_Nearest(t *testing.T) {
+	router := NewGeoProximityRouter(GeoProximityRouterConfig{Locator: newFakeLocator()})
+	pool := NewSimpleServerPool(proximityServers())
+	ctx := WithClientIP(context.Background(), net.ParseIP("192.0.2.1"))
+
+	ranked, err := router.Rank(ctx, pool)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"eu-west", "eu-west", "us-east", "ap-south", "onprem"}
+	got := rankRegions(ranked)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	// Round-robin among the nearest region's servers
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		server, err := router.Route(ctx, pool)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[server.Address] = true
+	}
+	if len(seen) != 2 || !seen["10.0.3.1"] || !seen["10.0.3.2"] {
+		t.Errorf("expected both eu-west servers, got %v", seen)
+	}
+}
+
+func TestGeoProximityRouter_Bias(t *testing.T) {
+	locator := newFakeLocator()
+	router := NewGeoProximityRouter(GeoProximityRouterConfig{Locator: locator})
+	pool := NewSimpleServerPool(proximityServers())
+	ctx := WithClientIP(context.Background(), net.ParseIP("192.0.2.2"))
+
+	server, err := router.Route(ctx, pool)
+	if err != nil || server.Region != "eu-west" {
+		t.Fatalf("expected eu-west without bias, got %v (%v)", server, err)
+	}
+
+	// Expanding us-east draws Reykjavik across the Atlantic
+	locator.regions["us-east"].Bias = 80
+	server, err = router.Route(ctx, pool)
+	if err != nil || server.Region != "us-east" {
+		t.Fatalf("expected us-east with a bias of 80, got %v (%v)", server, err)
+	}
+
+	// Shrinking us-east hands it back
+	locator.regions["us-east"].Bias = -50
+	server, err = router.Route(ctx, pool)
+	if err != nil || server.Region != "eu-west" {
+		t.Fatalf("expected eu-west with a bias of -50, got %v (%v)", server, err)
+	}
+}
+
+func TestGeoProximityRouter_UnlocatedClient(t *testing.T) {
+	router := NewGeoProximityRouter(GeoProximityRouterConfig{Locator: newFakeLocator()})
+	pool := NewSimpleServerPool(proximityServers())
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		WithClientIP(context.Background(), net.ParseIP("203.0.113.9")),
+	} {
+		ranked, err := router.Rank(ctx, pool)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ranked[0].Region != "us-east" || len(ranked) != 5 {
+			t.Errorf("expected the default region first, got %v", rankRegions(ranked))
+		}
+	}
+}
+
+func TestGeoProximityRouter_NoLocator(t *testing.T) {
+	router := NewGeoProximityRouter(GeoProximityRouterConfig{})
+	pool := NewSimpleServerPool(proximityServers())
+	ctx := WithClientIP(context.Background(), net.ParseIP("192.0.2.1"))
+
+	ranked, err := router.Rank(ctx, pool)
+	if err != nil || len(ranked) != 5 {
+		t.Fatalf("expected round-robin over every server, got %v (%v)", ranked, err)
+	}
+	if got := router.ECSScope(net.ParseIP("192.0.2.1"), 24); got != 0 {
+		t.Errorf("expected scope 0 without a locator, got %d", got)
+	}
+
+	router.SetLocator(newFakeLocator())
+	server, err := router.Route(ctx, pool)
+	if err != nil || server.Region != "eu-west" {
+		t.Errorf("expected eu-west once a locator is set, got %v (%v)", server, err)
+	}
+}
+
+func TestGeoProximityRouter_NoServers(t *testing.T) {
+	router := NewGeoProximityRouter(GeoProximityRouterConfig{Locator: newFakeLocator()})
+	if _, err := router.Route(context.Background(), NewSimpleServerPool(nil)); err != ErrNoHealthyServers {
+		t.Errorf("expected ErrNoHealthyServers, got %v", err)
+	}
+}
+
+func TestGeoProximityRouter_ECSScope(t *testing.T) {
+	router := NewGeoProximityRouter(GeoProximityRouterConfig{Locator: newFakeLocator()})
+
+	if got := router.ECSScope(net.ParseIP("10.1.0.1"), 24); got != 16 {
+		t.Errorf("expected the custom mapping's prefix 16, got %d", got)
+	}
+	if got := router.ECSScope(net.ParseIP("192.0.2.1"), 24); got != 24 {
+		t.Errorf("expected the source prefix 24, got %d", got)
+	}
+}
+
+func TestGeoProximityRouter_AliasNames(t *testing.T) {
+	for _, name := range []string{"geoproximity", "geo-proximity", "geo_proximity"} {
+		router, err := NewRouter(name)
+		if err != nil {
+			t.Fatalf("failed to create router %q: %v", name, err)
+		}
+		if router.Algorithm() != AlgorithmGeoProximity {
+			t.Errorf("expected algorithm %s, got %s", AlgorithmGeoProximity, router.Algorithm())
+		}
+	}
+
+	router, err := NewFactory(FactoryConfig{}).NewRouter(AlgorithmGeoProximity)
+	if err != nil {
+		t.Fatalf("failed to create geoproximity router: %v", err)
+	}
+	if router.Algorithm() != AlgorithmGeoProximity {
+		t.Errorf("expected algorithm %s, got %s", AlgorithmGeoProximity, router.Algorithm())
+	}
+}
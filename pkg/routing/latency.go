@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
@@ -106,6 +107,13 @@ type serverLatency struct {
 // - No servers have sufficient latency samples
 // - All servers are above the maximum latency threshold
 func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank orders servers by ascending smoothed latency. Servers within the
+// latency threshold come first, then servers above it, then servers without
+// enough samples in pool order. Uses the same fallbacks as Route.
+func (r *LatencyRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
 	servers := pool.Servers()
 	if len(servers) == 0 {
 		return nil, ErrNoHealthyServers
@@ -113,6 +121,7 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 
 	// Get domain from context for metrics
 	domain := GetDomain(ctx)
+	recordMetrics := domain != "" && !IsDryRun(ctx)
 
 	r.mu.RLock()
 	provider := r.provider
@@ -123,10 +132,10 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 	// If no provider, fall back to round-robin
 	if provider == nil {
 		r.logger.Debug("no latency provider configured, using round-robin fallback")
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordLatencyFallback(domain, "no_provider")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	// Collect latency data for all servers
@@ -147,7 +156,7 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 				server:  server,
 				latency: info,
 			})
-		} else if domain != "" {
+		} else if recordMetrics {
 			// Record servers rejected due to insufficient data
 			serverAddr := fmt.Sprintf("%s:%d", server.Address, server.Port)
 			metrics.RecordLatencyRejection(domain, serverAddr, "no_data")
@@ -160,10 +169,10 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 			"total_servers", len(servers),
 			"min_samples_required", minSamples,
 		)
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordLatencyFallback(domain, "no_latency_data")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	// Filter by max latency threshold (if configured)
@@ -172,7 +181,7 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 		for _, sl := range withLatency {
 			if sl.latency.SmoothedLatency <= maxLatency {
 				withinThreshold = append(withinThreshold, sl)
-			} else if domain != "" {
+			} else if recordMetrics {
 				// Record servers rejected due to latency threshold
 				serverAddr := fmt.Sprintf("%s:%d", sl.server.Address, sl.server.Port)
 				metrics.RecordLatencyRejection(domain, serverAddr, "above_threshold")
@@ -193,7 +202,9 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 	}
 
 	// Select server with lowest smoothed latency
-	selected := r.selectLowestLatency(withinThreshold)
+	sortByLatency(withinThreshold)
+	sortByLatency(withLatency)
+	selected := withinThreshold[0]
 
 	r.logger.Debug("latency-based routing decision",
 		"selected_address", selected.server.Address,
@@ -203,27 +214,25 @@ func (r *LatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, er
 	)
 
 	// Record the selected server latency
-	if domain != "" {
+	if recordMetrics {
 		serverAddr := fmt.Sprintf("%s:%d", selected.server.Address, selected.server.Port)
 		metrics.RecordLatencyRoutingDecision(domain, serverAddr, float64(selected.latency.SmoothedLatency.Milliseconds()))
 	}
 
-	return selected.server, nil
-}
-
-// selectLowestLatency returns the server with the lowest smoothed latency.
-func (r *LatencyRouter) selectLowestLatency(servers []serverLatency) serverLatency {
-	if len(servers) == 0 {
-		return serverLatency{}
+	// Servers within the threshold are the sorted prefix of withLatency
+	ranked := make([]*Server, 0, len(servers))
+	for _, sl := range withLatency {
+		ranked = append(ranked, sl.server)
 	}
+	return appendMissing(ranked, servers), nil
+}
 
-	lowest := servers[0]
-	for _, sl := range servers[1:] {
-		if sl.latency.SmoothedLatency < lowest.latency.SmoothedLatency {
-			lowest = sl
-		}
-	}
-	return lowest
+// sortByLatency orders servers by ascending smoothed latency.
+// The sort is stable so equal latencies keep pool order.
+func sortByLatency(servers []serverLatency) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		return servers[i].latency.SmoothedLatency < servers[j].latency.SmoothedLatency
+	})
 }
 
 // Algorithm returns the algorithm name.
@@ -461,3 +461,38 @@ func TestNewRouter_Latency(t *testing.T) {
 		t.Errorf("expected algorithm %q, got %q", AlgorithmLatency, router.Algorithm())
 	}
 }
+
+func TestLatencyRouter_Rank(t *testing.T) {
+	provider := newMockLatencyProvider()
+	provider.SetLatency("10.0.1.1", 8080, LatencyInfo{SmoothedLatency: 100 * time.Millisecond, Samples: 5, HasData: true})
+	provider.SetLatency("10.0.1.2", 8080, LatencyInfo{SmoothedLatency: 50 * time.Millisecond, Samples: 5, HasData: true})
+	provider.SetLatency("10.0.1.3", 8080, LatencyInfo{SmoothedLatency: 900 * time.Millisecond, Samples: 5, HasData: true})
+
+	router := NewLatencyRouter(LatencyRouterConfig{
+		Provider:     provider,
+		MinSamples:   3,
+		MaxLatencyMs: 500,
+	})
+
+	servers := []*Server{
+		{Address: "10.0.1.4", Port: 8080}, // No data
+		{Address: "10.0.1.3", Port: 8080}, // Above threshold
+		{Address: "10.0.1.1", Port: 8080},
+		{Address: "10.0.1.2", Port: 8080},
+	}
+
+	ranked, err := router.Rank(context.Background(), NewSimpleServerPool(servers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"10.0.1.2", "10.0.1.1", "10.0.1.3", "10.0.1.4"}
+	if len(ranked) != len(want) {
+		t.Fatalf("expected %d servers, got %d", len(want), len(ranked))
+	}
+	for i, s := range ranked {
+		if s.Address != want[i] {
+			t.Errorf("position %d: expected %s, got %s", i, want[i], s.Address)
+		}
+	}
+}
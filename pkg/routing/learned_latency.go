@@ -10,7 +10,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/netip"
+	"sort"
 	"sync"
 	"time"
 
@@ -128,6 +130,14 @@ type serverLearnedLatency struct {
 // - No servers have sufficient latency samples
 // - All servers are above the maximum latency threshold
 func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank orders servers by ascending learned latency for the client. Servers
+// within the latency threshold come first, then servers above it, then
+// servers without usable learned data in pool order. Uses the same
+// fallbacks as Route.
+func (r *LearnedLatencyRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
 	servers := pool.Servers()
 	if len(servers) == 0 {
 		return nil, ErrNoHealthyServers
@@ -135,6 +145,7 @@ func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Ser
 
 	// Get domain and client IP from context
 	domain := GetDomain(ctx)
+	recordMetrics := domain != "" && !IsDryRun(ctx)
 	clientIPOld := GetClientIP(ctx)
 
 	// Convert net.IP to netip.Addr
@@ -144,10 +155,10 @@ func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Ser
 		clientIP, ok = netip.AddrFromSlice(clientIPOld)
 		if !ok {
 			r.logger.Debug("could not convert client IP to netip.Addr, using fallback")
-			if domain != "" {
+			if recordMetrics {
 				metrics.RecordLatencyFallback(domain, "invalid_client_ip")
 			}
-			return r.fallback.Route(ctx, pool)
+			return r.fallback.Rank(ctx, pool)
 		}
 		// Normalize IPv4-mapped IPv6 to IPv4
 		if clientIP.Is4In6() {
@@ -165,18 +176,18 @@ func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Ser
 	// If no provider or no client IP, fall back
 	if provider == nil {
 		r.logger.Debug("no learned latency provider configured, using fallback")
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordLatencyFallback(domain, "no_provider")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	if !clientIP.IsValid() {
 		r.logger.Debug("no client IP in context, using fallback")
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordLatencyFallback(domain, "no_client_ip")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	// Collect learned latency data for all servers
@@ -217,10 +228,10 @@ func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Ser
 			"total_servers", len(servers),
 			"client_ip", clientIP.String(),
 		)
-		if domain != "" {
+		if recordMetrics {
 			metrics.RecordLatencyFallback(domain, "no_learned_data")
 		}
-		return r.fallback.Route(ctx, pool)
+		return r.fallback.Rank(ctx, pool)
 	}
 
 	// Filter by max latency threshold (if configured)
@@ -245,7 +256,9 @@ func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Ser
 	}
 
 	// Select server with lowest learned latency
-	selected := r.selectLowestLatency(withinThreshold)
+	sortByLearnedLatency(withinThreshold)
+	sortByLearnedLatency(withLatency)
+	selected := withinThreshold[0]
 
 	r.logger.Debug("learned latency routing decision",
 		"selected_address", selected.server.Address,
@@ -256,27 +269,25 @@ func (r *LearnedLatencyRouter) Route(ctx context.Context, pool ServerPool) (*Ser
 	)
 
 	// Record the routing decision
-	if domain != "" {
+	if recordMetrics {
 		serverAddr := fmt.Sprintf("%s:%d", selected.server.Address, selected.server.Port)
 		metrics.RecordLatencyRoutingDecision(domain, serverAddr, float64(selected.latency.EWMA.Milliseconds()))
 	}
 
-	return selected.server, nil
-}
-
-// selectLowestLatency returns the server with the lowest learned latency.
-func (r *LearnedLatencyRouter) selectLowestLatency(servers []serverLearnedLatency) serverLearnedLatency {
-	if len(servers) == 0 {
-		return serverLearnedLatency{}
+	// Servers within the threshold are the sorted prefix of withLatency
+	ranked := make([]*Server, 0, len(servers))
+	for _, sl := range withLatency {
+		ranked = append(ranked, sl.server)
 	}
+	return appendMissing(ranked, servers), nil
+}
 
-	lowest := servers[0]
-	for _, sl := range servers[1:] {
-		if sl.latency.EWMA < lowest.latency.EWMA {
-			lowest = sl
-		}
-	}
-	return lowest
+// sortByLearnedLatency orders servers by ascending learned latency.
+// The sort is stable so equal latencies keep pool order.
+func sortByLearnedLatency(servers []serverLearnedLatency) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		return servers[i].latency.EWMA < servers[j].latency.EWMA
+	})
 }
 
 // Algorithm returns the algorithm name.
@@ -284,6 +295,21 @@ func (r *LearnedLatencyRouter) Algorithm() string {
 	return "learned_latency"
 }
 
+// ECSScope implements ECSScoper. Latencies are learned per client /24 (IPv4)
+// or /48 (IPv6), so the decision holds for that network.
+func (r *LearnedLatencyRouter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	r.mu.RLock()
+	provider := r.provider
+	r.mu.RUnlock()
+	if provider == nil {
+		return 0
+	}
+	if clientIP.To4() != nil {
+		return 24
+	}
+	return 48
+}
+
 // SetProvider sets or updates the learned latency provider.
 func (r *LearnedLatencyRouter) SetProvider(provider LearnedLatencyProvider) {
 	r.mu.Lock()
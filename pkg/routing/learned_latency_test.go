@@ -544,3 +544,53 @@ func TestNewRouter_LearnedLatencyAlias(t *testing.T) {
 		t.Errorf("expected algorithm %q, got %q", "learned_latency", router.Algorithm())
 	}
 }
+
+func TestLearnedLatencyRouter_Rank(t *testing.T) {
+	provider := newMockLearnedLatencyProvider()
+	provider.SetLatency("10.0.0.0/24", "web.test.local", "eu-west", 80*time.Millisecond, 10)
+	provider.SetLatency("10.0.0.0/24", "web.test.local", "ap-southeast", 5*time.Millisecond, 10)
+
+	router := NewLearnedLatencyRouter(LearnedLatencyRouterConfig{
+		Provider:   provider,
+		MinSamples: 5,
+	})
+
+	servers := []*Server{
+		{Address: "10.3.1.10", Port: 80, Region: "us-east"}, // No learned data
+		{Address: "10.1.1.10", Port: 80, Region: "eu-west"},
+		{Address: "10.2.1.10", Port: 80, Region: "ap-southeast"},
+	}
+
+	ctx := WithClientIP(context.Background(), net.ParseIP("10.0.0.50"))
+	ctx = WithDomain(ctx, "web.test.local")
+
+	ranked, err := router.Rank(ctx, NewSimpleServerPool(servers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"10.2.1.10", "10.1.1.10", "10.3.1.10"}
+	if len(ranked) != len(want) {
+		t.Fatalf("expected %d servers, got %d", len(want), len(ranked))
+	}
+	for i, s := range ranked {
+		if s.Address != want[i] {
+			t.Errorf("position %d: expected %s, got %s", i, want[i], s.Address)
+		}
+	}
+}
+
+func TestLearnedLatencyRouter_ECSScope(t *testing.T) {
+	router := NewLearnedLatencyRouter(LearnedLatencyRouterConfig{})
+	if scope := router.ECSScope(net.ParseIP("198.51.100.7"), 32); scope != 0 {
+		t.Errorf("expected scope 0 without a provider, got %d", scope)
+	}
+
+	router.SetProvider(newMockLearnedLatencyProvider())
+	if scope := router.ECSScope(net.ParseIP("198.51.100.7"), 32); scope != 24 {
+		t.Errorf("expected IPv4 scope 24, got %d", scope)
+	}
+	if scope := router.ECSScope(net.ParseIP("2001:db8::1"), 56); scope != 48 {
+		t.Errorf("expected IPv6 scope 48, got %d", scope)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// AlgorithmLeastLoad is the algorithm name for least-load routing.
+const AlgorithmLeastLoad = "least-load"
+
+// LoadInfo contains the load reported for a backend server by its agent.
+// This mirrors overwatch.LoadInfo to avoid circular imports.
+type LoadInfo struct {
+	// ActiveConnections is the number of established TCP connections to the
+	// backend.
+	ActiveConnections int
+	// Capacity is the number of connections the backend can serve, or 0 when
+	// unknown.
+	Capacity int
+	// HasData indicates whether a recent load report is available.
+	HasData bool
+}
+
+// LoadProvider provides load data for servers.
+type LoadProvider interface {
+	GetLoad(address string, port int) LoadInfo
+}
+
+// LeastLoadRouterConfig contains configuration for the LeastLoadRouter.
+type LeastLoadRouterConfig struct {
+	// Provider is the source of load data for servers.
+	Provider LoadProvider
+
+	// Logger for routing decisions.
+	Logger *slog.Logger
+}
+
+// LeastLoadRouter selects the server with the lowest utilization: its
+// active connections relative to its capacity. Servers without a reported
+// capacity use their weight as a relative capacity instead, so a server
+// with twice the weight takes twice the connections.
+type LeastLoadRouter struct {
+	mu       sync.RWMutex
+	provider LoadProvider
+	fallback Router
+	next     atomic.Uint64
+	logger   *slog.Logger
+}
+
+// NewLeastLoadRouter creates a new least-load router.
+func NewLeastLoadRouter(cfg LeastLoadRouterConfig) *LeastLoadRouter {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LeastLoadRouter{
+		provider: cfg.Provider,
+		fallback: NewWeightedRouter(),
+		logger:   logger,
+	}
+}
+
+// SetProvider sets or updates the load provider.
+func (r *LeastLoadRouter) SetProvider(provider LoadProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.provider = provider
+}
+
+// serverLoad pairs a server with its utilization for sorting.
+type serverLoad struct {
+	server      *Server
+	utilization float64
+}
+
+// Route selects the server with the lowest utilization.
+// Falls back to weighted routing when no load provider is configured or no
+// server has reported its load.
+func (r *LeastLoadRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank orders servers by ascending utilization, followed by servers without
+// load data in pool order. Servers tied for the lowest utilization take
+// turns being first, so idle servers share new clients instead of one taking
+// them all until the next load report.
+func (r *LeastLoadRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+
+	r.mu.RLock()
+	provider := r.provider
+	r.mu.RUnlock()
+
+	if provider == nil {
+		r.logger.Debug("no load provider configured, using weighted fallback")
+		return r.fallback.Rank(ctx, pool)
+	}
+
+	var withLoad []serverLoad
+	for _, server := range servers {
+		info := provider.GetLoad(server.Address, server.Port)
+		if !info.HasData {
+			continue
+		}
+		withLoad = append(withLoad, serverLoad{server: server, utilization: utilization(server, info)})
+	}
+
+	if len(withLoad) == 0 {
+		r.logger.Debug("no servers with load data, using weighted fallback",
+			"total_servers", len(servers),
+		)
+		return r.fallback.Rank(ctx, pool)
+	}
+
+	sort.SliceStable(withLoad, func(i, j int) bool {
+		return withLoad[i].utilization < withLoad[j].utilization
+	})
+	r.rotateTies(withLoad, IsDryRun(ctx))
+
+	r.logger.Debug("least-load routing decision",
+		"selected_address", withLoad[0].server.Address,
+		"utilization", withLoad[0].utilization,
+		"candidates", len(withLoad),
+		"total_servers", len(servers),
+	)
+
+	ranked := make([]*Server, 0, len(servers))
+	for _, sl := range withLoad {
+		ranked = append(ranked, sl.server)
+	}
+	return appendMissing(ranked, servers), nil
+}
+
+// rotateTies rotates the servers sharing the lowest utilization by one
+// position per call, so each of them is first in turn. A dry run rotates
+// them as the next call would, without advancing the rotation.
+func (r *LeastLoadRouter) rotateTies(sorted []serverLoad, dryRun bool) {
+	n := 1
+	for n < len(sorted) && sorted[n].utilization == sorted[0].utilization {
+		n++
+	}
+	if n == 1 {
+		return
+	}
+	next := r.next.Load() + 1
+	if !dryRun {
+		next = r.next.Add(1)
+	}
+	k := int(next % uint64(n))
+	tied := append(append([]serverLoad(nil), sorted[k:n]...), sorted[:k]...)
+	copy(sorted, tied)
+}
+
+// utilization returns the share of a server's capacity in use. Without a
+// reported capacity the server's weight stands in for it, scaled so that the
+// default weight (100) counts as a capacity of 100 connections.
+func utilization(s *Server, info LoadInfo) float64 {
+	capacity := info.Capacity
+	if capacity <= 0 {
+		capacity = effectiveWeight(s)
+	}
+	return float64(info.ActiveConnections) / float64(capacity)
+}
+
+// Algorithm returns the algorithm name.
+func (r *LeastLoadRouter) Algorithm() string {
+	return AlgorithmLeastLoad
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// mockLoadProvider implements LoadProvider for testing.
+type mockLoadProvider map[string]LoadInfo // key: address
+
+func (m mockLoadProvider) GetLoad(address string, port int) LoadInfo {
+	return m[address]
+}
+
+func TestLeastLoadRouter_Rank(t *testing.T) {
+	servers := []*Server{
+		{Address: "10.0.1.1", Port: 80, Weight: 100},
+		{Address: "10.0.1.2", Port: 80, Weight: 100},
+		{Address: "10.0.1.3", Port: 80, Weight: 300},
+		{Address: "10.0.1.4", Port: 80, Weight: 100},
+	}
+	provider := mockLoadProvider{
+		// 50% of its capacity
+		"10.0.1.1": {ActiveConnections: 500, Capacity: 1000, HasData: true},
+		// Fewest connections, but 80% of its capacity
+		"10.0.1.2": {ActiveConnections: 80, Capacity: 100, HasData: true},
+		// No capacity: its weight makes it 150/300
+		"10.0.1.3": {ActiveConnections: 150, HasData: true},
+		// 10.0.1.4 has not reported
+	}
+	router := NewLeastLoadRouter(LeastLoadRouterConfig{Provider: provider})
+
+	ranked, err := router.Rank(context.Background(), NewSimpleServerPool(servers))
+	if err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	var got []string
+	for _, s := range ranked {
+		got = append(got, s.Address)
+	}
+	// 10.0.1.1 and 10.0.1.3 are both half used and take turns being first
+	if len(got) == 4 && got[0] == "10.0.1.3" {
+		got[0], got[1] = got[1], got[0]
+	}
+	want := []string{"10.0.1.1", "10.0.1.3", "10.0.1.2", "10.0.1.4"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLeastLoadRouter_TiesRotate(t *testing.T) {
+	servers := []*Server{
+		{Address: "10.0.1.1", Port: 80},
+		{Address: "10.0.1.2", Port: 80},
+		{Address: "10.0.1.3", Port: 80},
+	}
+	provider := mockLoadProvider{
+		"10.0.1.1": {HasData: true},
+		"10.0.1.2": {HasData: true},
+		"10.0.1.3": {ActiveConnections: 10, HasData: true},
+	}
+	router := NewLeastLoadRouter(LeastLoadRouterConfig{Provider: provider})
+	pool := NewSimpleServerPool(servers)
+
+	counts := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		server, err := router.Route(context.Background(), pool)
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		counts[server.Address]++
+	}
+	if counts["10.0.1.1"] != 5 || counts["10.0.1.2"] != 5 {
+		t.Errorf("expected the idle servers to alternate, got %v", counts)
+	}
+}
+
+func TestLeastLoadRouter_Fallback(t *testing.T) {
+	servers := []*Server{
+		{Address: "10.0.1.1", Port: 80, Weight: 100},
+		{Address: "10.0.1.2", Port: 80, Weight: 0},
+	}
+	pool := NewSimpleServerPool(servers)
+
+	for name, router := range map[string]*LeastLoadRouter{
+		"no provider": NewLeastLoadRouter(LeastLoadRouterConfig{}),
+		"no data":     NewLeastLoadRouter(LeastLoadRouterConfig{Provider: mockLoadProvider{}}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ranked, err := router.Rank(context.Background(), pool)
+			if err != nil {
+				t.Fatalf("Rank failed: %v", err)
+			}
+			if len(ranked) != 2 {
+				t.Errorf("expected both servers, got %v", ranked)
+			}
+		})
+	}
+
+	router := NewLeastLoadRouter(LeastLoadRouterConfig{})
+	if _, err := router.Route(context.Background(), NewSimpleServerPool(nil)); err != ErrNoHealthyServers {
+		t.Errorf("expected ErrNoHealthyServers, got %v", err)
+	}
+}
+
+func TestLeastLoadRouter_DryRun(t *testing.T) {
+	provider := mockLoadProvider{
+		"10.0.1.1": {HasData: true},
+		"10.0.1.2": {HasData: true},
+	}
+	router := NewLeastLoadRouter(LeastLoadRouterConfig{Provider: provider})
+	pool := NewSimpleServerPool([]*Server{{Address: "10.0.1.1", Port: 80}, {Address: "10.0.1.2", Port: 80}})
+
+	dry, err := router.Route(WithDryRun(context.Background()), pool)
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		again, _ := router.Route(WithDryRun(context.Background()), pool)
+		if again != dry {
+			t.Fatalf("expected dry runs not to rotate ties, got %s then %s", dry.Address, again.Address)
+		}
+	}
+	if real, _ := router.Route(context.Background(), pool); real != dry {
+		t.Errorf("expected the query to get the server the dry run showed, got %s and %s", real.Address, dry.Address)
+	}
+}
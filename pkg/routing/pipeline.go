@@ -0,0 +1,502 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AlgorithmPipeline is the algorithm name reported by routing pipelines.
+const AlgorithmPipeline = "pipeline"
+
+// Pipeline stage types.
+const (
+	StageGeo              = "geo"
+	StageHealthTier       = "health-tier"
+	StageLatencyThreshold = "latency-threshold"
+	StageLabels           = "labels"
+	StageSelect           = "select"
+)
+
+// Latency sources of a latency-threshold stage.
+const (
+	LatencySourceValidation = "validation"
+	LatencySourceLearned    = "learned"
+)
+
+// PipelineTraceKey is the context key for the trace of a routing pipeline.
+const PipelineTraceKey contextKey = "pipelineTrace"
+
+// PipelineStage configures one stage of a routing pipeline.
+// This mirrors config.RoutingStage to avoid circular imports.
+type PipelineStage struct {
+	// Type is one of the Stage constants.
+	Type string
+
+	// Regions orders the tiers of a health-tier stage, most preferred first.
+	Regions []string
+	// MinHealthy is the number of healthy servers a health-tier stage
+	// gathers before it stops adding tiers. Default: 1
+	MinHealthy int
+
+	// Source is the latency measurement of a latency-threshold stage.
+	// Default: validation
+	Source string
+	// MaxLatencyMs drops servers slower than this (0 = no limit).
+	MaxLatencyMs int
+	// ToleranceMs drops servers more than this slower than the fastest
+	// server (0 = no limit).
+	ToleranceMs int
+
+	// Match is the label selector of a labels stage: servers must have
+	// every label with the given value.
+	Match map[string]string
+
+	// Algorithm orders the remaining servers in a select stage.
+	// Default: weighted
+	Algorithm string
+}
+
+// PipelineFilter is a filter stage of a routing pipeline.
+type PipelineFilter interface {
+	// Filter returns the servers passing the stage, in pool order, and a
+	// short description of the decision for the pipeline trace.
+	Filter(ctx context.Context, servers []*Server) ([]*Server, string)
+
+	// Stage returns the stage type.
+	Stage() string
+}
+
+// StageTrace records how one pipeline stage narrowed the pool.
+type StageTrace struct {
+	Stage   string
+	Detail  string
+	Input   []*Server
+	Output  []*Server
+	Skipped bool // The stage would have removed every server and was ignored
+}
+
+// PipelineTrace collects the stages of a routing decision. Add one to the
+// context with WithPipelineTrace to inspect a decision.
+type PipelineTrace struct {
+	Stages []StageTrace
+}
+
+// WithPipelineTrace adds a pipeline trace to the context. Pipelines routing
+// with the context append their stages to it.
+func WithPipelineTrace(ctx context.Context, trace *PipelineTrace) context.Context {
+	return context.WithValue(ctx, PipelineTraceKey, trace)
+}
+
+// getPipelineTrace retrieves the pipeline trace from the context, or nil.
+func getPipelineTrace(ctx context.Context) *PipelineTrace {
+	trace, _ := ctx.Value(PipelineTraceKey).(*PipelineTrace)
+	return trace
+}
+
+// PipelineRouter narrows the pool through a chain of filter stages and lets
+// a selector router pick among the remaining servers, e.g. "servers in the
+// client's region, then those within 20ms of the fastest, then weighted".
+// A stage that would remove every server is skipped, so a pipeline always
+// answers while the pool has servers.
+type PipelineRouter struct {
+	filters  []PipelineFilter
+	selector Router
+}
+
+// NewPipelineRouter creates a routing pipeline. A nil selector selects by
+// weight.
+func NewPipelineRouter(filters []PipelineFilter, selector Router) *PipelineRouter {
+	if selector == nil {
+		selector = NewWeightedRouter()
+	}
+	return &PipelineRouter{filters: filters, selector: selector}
+}
+
+// Route selects a server with the selector among the servers passing every
+// stage.
+func (r *PipelineRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+	kept, _ := r.narrow(ctx, servers)
+	selected, err := r.selector.Route(ctx, NewSimpleServerPool(kept))
+	if err != nil {
+		return nil, err
+	}
+	r.traceSelect(ctx, kept, []*Server{selected})
+	return selected, nil
+}
+
+// Rank orders the servers passing every stage by the selector's preference,
+// followed by the servers removed by each stage, last stage first, so that
+// servers that came closest to passing are preferred among the rest.
+func (r *PipelineRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+	kept, removed := r.narrow(ctx, servers)
+	ranked, err := r.selector.Rank(ctx, NewSimpleServerPool(kept))
+	if err != nil {
+		return nil, err
+	}
+	r.traceSelect(ctx, kept, ranked)
+	for i := len(removed) - 1; i >= 0; i-- {
+		ranked = appendMissing(ranked, removed[i])
+	}
+	return appendMissing(ranked, servers), nil
+}
+
+// narrow runs the filter stages, returning the remaining servers and the
+// servers removed by each stage.
+func (r *PipelineRouter) narrow(ctx context.Context, servers []*Server) ([]*Server, [][]*Server) {
+	trace := getPipelineTrace(ctx)
+	removed := make([][]*Server, 0, len(r.filters))
+	for _, f := range r.filters {
+		out, detail := f.Filter(ctx, servers)
+		skipped := len(out) == 0
+		if skipped {
+			out = servers
+		}
+		if trace != nil {
+			trace.Stages = append(trace.Stages, StageTrace{
+				Stage:   f.Stage(),
+				Detail:  detail,
+				Input:   servers,
+				Output:  out,
+				Skipped: skipped,
+			})
+		}
+		removed = append(removed, without(servers, out))
+		servers = out
+	}
+	return servers, removed
+}
+
+// traceSelect records the selector stage in the context's trace.
+func (r *PipelineRouter) traceSelect(ctx context.Context, in, out []*Server) {
+	if trace := getPipelineTrace(ctx); trace != nil {
+		trace.Stages = append(trace.Stages, StageTrace{
+			Stage:  StageSelect,
+			Detail: r.selector.Algorithm(),
+			Input:  in,
+			Output: out,
+		})
+	}
+}
+
+// ECSScope implements ECSScoper. The decision holds for the most specific
+// network any client-dependent stage was based on.
+func (r *PipelineRouter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	scope := 0
+	for _, f := range r.filters {
+		if scoper, ok := f.(ECSScoper); ok {
+			scope = max(scope, scoper.ECSScope(clientIP, sourcePrefix))
+		}
+	}
+	if scoper, ok := r.selector.(ECSScoper); ok {
+		scope = max(scope, scoper.ECSScope(clientIP, sourcePrefix))
+	}
+	return scope
+}
+
+// Algorithm returns the algorithm name.
+func (r *PipelineRouter) Algorithm() string {
+	return AlgorithmPipeline
+}
+
+// Filters returns the filter stages of the pipeline.
+func (r *PipelineRouter) Filters() []PipelineFilter {
+	return r.filters
+}
+
+// Selector returns the router selecting among the remaining servers.
+func (r *PipelineRouter) Selector() Router {
+	return r.selector
+}
+
+// without returns the servers of all that are not in kept.
+func without(all, kept []*Server) []*Server {
+	var rest []*Server
+	for _, s := range all {
+		if !slices.Contains(kept, s) {
+			rest = append(rest, s)
+		}
+	}
+	return rest
+}
+
+// NewPipeline creates a routing pipeline from its stages. Every stage but
+// the last must be a filter; a final select stage picks the selector
+// algorithm, which defaults to weighted.
+func (f *Factory) NewPipeline(stages []PipelineStage) (Router, error) {
+	var filters []PipelineFilter
+	var selector Router
+	for i, stage := range stages {
+		if selector != nil {
+			return nil, fmt.Errorf("pipeline stage %d: select must be the last stage", i)
+		}
+		switch strings.ToLower(stage.Type) {
+		case StageGeo:
+			filters = append(filters, &geoFilter{router: NewGeoRouter(GeoRouterConfig{
+				Resolver:      f.geoResolver,
+				DefaultRegion: f.defaultRegion,
+				Logger:        f.logger,
+			})})
+		case StageHealthTier:
+			filters = append(filters, &healthTierFilter{regions: stage.Regions, minHealthy: max(stage.MinHealthy, 1)})
+		case StageLatencyThreshold:
+			filter := &latencyFilter{
+				source:     strings.ToLower(stage.Source),
+				maxLatency: time.Duration(stage.MaxLatencyMs) * time.Millisecond,
+				tolerance:  time.Duration(stage.ToleranceMs) * time.Millisecond,
+				minSamples: f.minLatencySamples,
+			}
+			switch filter.source {
+			case "", LatencySourceValidation:
+				filter.source = LatencySourceValidation
+				filter.validation = f.latencyProvider
+			case LatencySourceLearned:
+				filter.learned = f.learnedLatencyProvider
+			default:
+				return nil, fmt.Errorf("pipeline stage %d: unknown latency source: %s", i, stage.Source)
+			}
+			filters = append(filters, filter)
+		case StageLabels:
+			filters = append(filters, &labelFilter{match: stage.Match})
+		case StageSelect:
+			algorithm := stage.Algorithm
+			if algorithm == "" {
+				algorithm = AlgorithmWeighted
+			}
+			router, err := f.NewRouter(algorithm)
+			if err != nil {
+				return nil, fmt.Errorf("pipeline stage %d: %w", i, err)
+			}
+			selector = router
+		default:
+			return nil, fmt.Errorf("pipeline stage %d: unknown stage type: %s", i, stage.Type)
+		}
+	}
+	return NewPipelineRouter(filters, selector), nil
+}
+
+// geoFilter keeps the servers in the client's region, or in the default
+// region when the client's region has none. Regions are resolved as by
+// the geolocation router.
+type geoFilter struct {
+	router *GeoRouter
+}
+
+// Filter implements PipelineFilter.
+func (f *geoFilter) Filter(ctx context.Context, servers []*Server) ([]*Server, string) {
+	clientIP := GetClientIP(ctx)
+	if clientIP == nil {
+		return servers, "no client address"
+	}
+	resolver := f.router.GetResolver()
+	if resolver == nil {
+		return servers, "geo resolver not configured"
+	}
+
+	match := resolver.Resolve(clientIP)
+	if kept := f.router.filterByRegion(servers, match.Region); len(kept) > 0 {
+		return kept, "client region " + match.Region
+	}
+	if kept := f.router.filterByRegion(servers, f.router.defaultRegion); len(kept) > 0 {
+		return kept, fmt.Sprintf("no servers in client region %s, default region %s", match.Region, f.router.defaultRegion)
+	}
+	return nil, "no servers in client region " + match.Region
+}
+
+// Stage implements PipelineFilter.
+func (f *geoFilter) Stage() string {
+	return StageGeo
+}
+
+// ECSScope implements ECSScoper.
+func (f *geoFilter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	return f.router.ECSScope(clientIP, sourcePrefix)
+}
+
+// healthTierFilter keeps the servers of the most preferred region tiers
+// that together have at least minHealthy servers. The pool holds healthy
+// servers only, so a tier spills over to the next as its servers fail.
+// Servers in regions not listed form the last tier.
+type healthTierFilter struct {
+	regions    []string
+	minHealthy int
+}
+
+// Filter implements PipelineFilter.
+func (f *healthTierFilter) Filter(ctx context.Context, servers []*Server) ([]*Server, string) {
+	tier := func(s *Server) int {
+		if i := slices.Index(f.regions, s.Region); i >= 0 {
+			return i
+		}
+		return len(f.regions)
+	}
+
+	var kept []*Server
+	for t := 0; t <= len(f.regions) && len(kept) < f.minHealthy; t++ {
+		for _, s := range servers {
+			if tier(s) == t {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) >= f.minHealthy {
+			return kept, fmt.Sprintf("%d of %d tiers for %d healthy servers", t+1, len(f.regions)+1, f.minHealthy)
+		}
+	}
+	return servers, fmt.Sprintf("fewer than %d healthy servers in all tiers", f.minHealthy)
+}
+
+// Stage implements PipelineFilter.
+func (f *healthTierFilter) Stage() string {
+	return StageHealthTier
+}
+
+// latencyFilter keeps the servers with latency data that are no slower
+// than maxLatency and within tolerance of the fastest server. Without
+// latency data for any server it keeps every server.
+type latencyFilter struct {
+	source     string
+	validation LatencyProvider
+	learned    LearnedLatencyProvider
+	maxLatency time.Duration
+	tolerance  time.Duration
+	minSamples int
+}
+
+// Filter implements PipelineFilter.
+func (f *latencyFilter) Filter(ctx context.Context, servers []*Server) ([]*Server, string) {
+	latencies := make(map[*Server]time.Duration, len(servers))
+	for _, s := range servers {
+		if latency, ok := f.latency(ctx, s); ok {
+			latencies[s] = latency
+		}
+	}
+	if len(latencies) == 0 {
+		return servers, "no " + f.source + " latency data"
+	}
+
+	sorted := make([]time.Duration, 0, len(latencies))
+	for _, latency := range latencies {
+		sorted = append(sorted, latency)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	best := sorted[0]
+
+	var kept []*Server
+	for _, s := range servers {
+		latency, ok := latencies[s]
+		if !ok {
+			continue
+		}
+		if f.maxLatency > 0 && latency > f.maxLatency {
+			continue
+		}
+		if f.tolerance > 0 && latency > best+f.tolerance {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept, fmt.Sprintf("fastest %s latency %dms", f.source, best.Milliseconds())
+}
+
+// latency returns the latency of a server, and whether it has enough
+// recent samples.
+func (f *latencyFilter) latency(ctx context.Context, s *Server) (time.Duration, bool) {
+	if f.source == LatencySourceValidation {
+		if f.validation == nil {
+			return 0, false
+		}
+		info := f.validation.GetLatency(s.Address, s.Port)
+		return info.SmoothedLatency, info.HasData && info.Samples >= f.minSamples
+	}
+
+	domain := GetDomain(ctx)
+	clientIP, ok := netip.AddrFromSlice(GetClientIP(ctx))
+	if f.learned == nil || !ok || domain == "" || s.Region == "" {
+		return 0, false
+	}
+	data, ok := f.learned.GetLatencyForBackendInRegion(clientIP.Unmap(), domain, s.Region)
+	if !ok || data.SampleCount < uint64(f.minSamples) {
+		return 0, false
+	}
+	if time.Since(data.LastUpdated) > DefaultLearnedLatencyRouterConfig().StaleThreshold {
+		return 0, false
+	}
+	return data.EWMA, true
+}
+
+// Stage implements PipelineFilter.
+func (f *latencyFilter) Stage() string {
+	return StageLatencyThreshold
+}
+
+// ECSScope implements ECSScoper. Learned latencies are per client /24
+// (IPv4) or /48 (IPv6); validation latencies are the same for every client.
+func (f *latencyFilter) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	if f.learned == nil {
+		return 0
+	}
+	if clientIP.To4() != nil {
+		return 24
+	}
+	return 48
+}
+
+// labelFilter keeps the servers carrying every label of its selector.
+type labelFilter struct {
+	match map[string]string
+}
+
+// Filter implements PipelineFilter.
+func (f *labelFilter) Filter(ctx context.Context, servers []*Server) ([]*Server, string) {
+	var kept []*Server
+	for _, s := range servers {
+		if matchLabels(s.Labels, f.match) {
+			kept = append(kept, s)
+		}
+	}
+	return kept, formatLabels(f.match)
+}
+
+// Stage implements PipelineFilter.
+func (f *labelFilter) Stage() string {
+	return StageLabels
+}
+
+// matchLabels reports whether labels has every label of selector.
+func matchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLabels formats a label selector as sorted key=value pairs.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func addresses(servers []*Server) string {
+	var got []string
+	for _, s := range servers {
+		got = append(got, s.Address)
+	}
+	return strings.Join(got, ",")
+}
+
+func newPipelineTestServers() []*Server {
+	return []*Server{
+		{Address: "10.0.1.1", Port: 80, Weight: 100, Region: "us-east", Labels: map[string]string{"version": "v1"}},
+		{Address: "10.0.1.2", Port: 80, Weight: 100, Region: "us-east", Labels: map[string]string{"version": "v2"}},
+		{Address: "10.0.1.3", Port: 80, Weight: 100, Region: "us-east", Labels: map[string]string{"version": "v2"}},
+		{Address: "10.0.2.1", Port: 80, Weight: 100, Region: "us-west", Labels: map[string]string{"version": "v2"}},
+	}
+}
+
+func TestPipelineRouter_Rank(t *testing.T) {
+	latency := newMockLatencyProvider()
+	latency.SetLatency("10.0.1.2", 80, LatencyInfo{SmoothedLatency: 40 * time.Millisecond, Samples: 5, HasData: true})
+	latency.SetLatency("10.0.1.3", 80, LatencyInfo{SmoothedLatency: 10 * time.Millisecond, Samples: 5, HasData: true})
+	latency.SetLatency("10.0.2.1", 80, LatencyInfo{SmoothedLatency: 5 * time.Millisecond, Samples: 5, HasData: true})
+
+	factory := NewFactory(FactoryConfig{LatencyProvider: latency})
+	router, err := factory.NewPipeline([]PipelineStage{
+		{Type: StageHealthTier, Regions: []string{"us-east", "us-west"}, MinHealthy: 2},
+		{Type: StageLabels, Match: map[string]string{"version": "v2"}},
+		{Type: StageLatencyThreshold, ToleranceMs: 20},
+		{Type: StageSelect, Algorithm: AlgorithmFailover},
+	})
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+	if router.Algorithm() != AlgorithmPipeline {
+		t.Errorf("expected algorithm %q, got %q", AlgorithmPipeline, router.Algorithm())
+	}
+
+	trace := &PipelineTrace{}
+	ctx := WithPipelineTrace(context.Background(), trace)
+	ranked, err := router.Rank(ctx, NewSimpleServerPool(newPipelineTestServers()))
+	if err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	// 10.0.1.3 passes every stage; the latency stage removed 10.0.1.2, the
+	// label stage 10.0.1.1 and the tier stage 10.0.2.1
+	if got, want := addresses(ranked), "10.0.1.3,10.0.1.2,10.0.1.1,10.0.2.1"; got != want {
+		t.Errorf("expected ranking %s, got %s", want, got)
+	}
+
+	want := []struct {
+		stage  string
+		output string
+	}{
+		{StageHealthTier, "10.0.1.1,10.0.1.2,10.0.1.3"},
+		{StageLabels, "10.0.1.2,10.0.1.3"},
+		{StageLatencyThreshold, "10.0.1.3"},
+		{StageSelect, "10.0.1.3"},
+	}
+	if len(trace.Stages) != len(want) {
+		t.Fatalf("expected %d traced stages, got %+v", len(want), trace.Stages)
+	}
+	for i, w := range want {
+		st := trace.Stages[i]
+		if st.Stage != w.stage || addresses(st.Output) != w.output || st.Skipped {
+			t.Errorf("stage %d: expected %s -> %s, got %s -> %s (skipped=%v)",
+				i, w.stage, w.output, st.Stage, addresses(st.Output), st.Skipped)
+		}
+	}
+}
+
+func TestPipelineRouter_SkipsEmptyingStage(t *testing.T) {
+	router := NewPipelineRouter([]PipelineFilter{
+		&labelFilter{match: map[string]string{"version": "v3"}},
+		&healthTierFilter{regions: []string{"us-west"}, minHealthy: 1},
+	}, NewFailoverRouter())
+
+	trace := &PipelineTrace{}
+	ctx := WithPipelineTrace(context.Background(), trace)
+	selected, err := router.Route(ctx, NewSimpleServerPool(newPipelineTestServers()))
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if selected.Address != "10.0.2.1" {
+		t.Errorf("expected 10.0.2.1, got %s", selected.Address)
+	}
+	if len(trace.Stages) != 3 || !trace.Stages[0].Skipped || trace.Stages[1].Skipped {
+		t.Errorf("expected only the label stage to be skipped, got %+v", trace.Stages)
+	}
+}
+
+func TestHealthTierFilter_SpillsOver(t *testing.T) {
+	servers := newPipelineTestServers()
+	tests := []struct {
+		minHealthy int
+		want       string
+	}{
+		{minHealthy: 1, want: "10.0.1.1,10.0.1.2,10.0.1.3"},
+		{minHealthy: 4, want: "10.0.1.1,10.0.1.2,10.0.1.3,10.0.2.1"},
+		// Fewer healthy servers than required: every server is kept
+		{minHealthy: 5, want: "10.0.1.1,10.0.1.2,10.0.1.3,10.0.2.1"},
+	}
+	for _, tt := range tests {
+		f := &healthTierFilter{regions: []string{"us-east", "us-west"}, minHealthy: tt.minHealthy}
+		got, _ := f.Filter(context.Background(), servers)
+		if addresses(got) != tt.want {
+			t.Errorf("min_healthy %d: expected %s, got %s", tt.minHealthy, tt.want, addresses(got))
+		}
+	}
+}
+
+func TestPipelineRouter_LearnedLatency(t *testing.T) {
+	learned := newMockLearnedLatencyProvider()
+	learned.SetLatency("192.168.1.0/24", "app.example.com", "us-east", 30*time.Millisecond, 10)
+	learned.SetLatency("192.168.1.0/24", "app.example.com", "us-west", 80*time.Millisecond, 10)
+
+	factory := NewFactory(FactoryConfig{LearnedLatencyProvider: learned})
+	router, err := factory.NewPipeline([]PipelineStage{
+		{Type: StageGeo},
+		{Type: StageLatencyThreshold, Source: LatencySourceLearned, MaxLatencyMs: 50},
+	})
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+
+	ctx := WithDomain(WithClientIP(context.Background(), net.ParseIP("192.168.1.10")), "app.example.com")
+	ranked, err := router.Rank(ctx, NewSimpleServerPool(newPipelineTestServers()))
+	if err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	if ranked[len(ranked)-1].Address != "10.0.2.1" {
+		t.Errorf("expected the slow region last, got %s", addresses(ranked))
+	}
+
+	// The geo stage has no resolver; learned latency is per client /24
+	scoper := router.(ECSScoper)
+	if scope := scoper.ECSScope(net.ParseIP("192.168.1.10"), 32); scope != 24 {
+		t.Errorf("expected ECS scope 24, got %d", scope)
+	}
+}
+
+func TestFactory_NewPipeline_Invalid(t *testing.T) {
+	factory := NewFactory(FactoryConfig{})
+	tests := []struct {
+		name   string
+		stages []PipelineStage
+	}{
+		{name: "unknown stage", stages: []PipelineStage{{Type: "random"}}},
+		{name: "select not last", stages: []PipelineStage{{Type: StageSelect}, {Type: StageGeo}}},
+		{name: "unknown selector", stages: []PipelineStage{{Type: StageSelect, Algorithm: "fastest"}}},
+		{name: "unknown latency source", stages: []PipelineStage{{Type: StageLatencyThreshold, Source: "ping"}}},
+	}
+	for _, tt := range tests {
+		if _, err := factory.NewPipeline(tt.stages); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -23,16 +23,31 @@ func NewRoundRobinRouter() *RoundRobinRouter {
 
 // Route selects the next server in round-robin order.
 func (r *RoundRobinRouter) Route(ctx context.Context, pool ServerPool) (*Server, error) {
+	return firstRanked(r.Rank(ctx, pool))
+}
+
+// Rank returns the pool rotated so that the next server in round-robin
+// order comes first, followed by the rest in pool order.
+func (r *RoundRobinRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
 	servers := pool.Servers()
 	if len(servers) == 0 {
 		return nil, ErrNoHealthyServers
 	}
 
-	// Atomically increment and get the index
-	idx := atomic.AddUint64(&r.counter, 1) - 1
-	selected := servers[idx%uint64(len(servers))]
+	// Atomically increment and get the index; a dry run peeks at it
+	var idx uint64
+	if IsDryRun(ctx) {
+		idx = atomic.LoadUint64(&r.counter)
+	} else {
+		idx = atomic.AddUint64(&r.counter, 1) - 1
+	}
+	start := int(idx % uint64(len(servers)))
+
+	ranked := make([]*Server, 0, len(servers))
+	ranked = append(ranked, servers[start:]...)
+	ranked = append(ranked, servers[:start]...)
 
-	return selected, nil
+	return ranked, nil
 }
 
 // Algorithm returns the algorithm name.
@@ -9,17 +9,63 @@ package routing
 import (
 	"context"
 	"errors"
+	"net"
+	"slices"
 )
 
 // ErrNoHealthyServers is returned when no healthy servers are available.
 var ErrNoHealthyServers = errors.New("no healthy servers available")
 
+// WildcardLabelKey is the context key for the labels matched by a wildcard
+// domain, e.g. "acme" for acme.apps.example.com under *.apps.example.com.
+const WildcardLabelKey contextKey = "wildcardLabel"
+
+// DryRunKey is the context key marking a routing decision that is only
+// evaluated, such as by the routing test API, and not answered.
+const DryRunKey contextKey = "dryRun"
+
+// contextKey is the type of routing context keys.
+type contextKey string
+
+// WithDryRun marks the context's routing decisions as dry runs. Routers rank
+// servers as they would for a query without advancing rotations, moving
+// failover tiers or recording metrics, so evaluating a decision does not
+// change what clients are answered.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, DryRunKey, true)
+}
+
+// IsDryRun reports whether the context's routing decisions are dry runs.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(DryRunKey).(bool)
+	return dryRun
+}
+
+// PanicKey is the context key holding the address families whose pools are
+// in panic mode: health is ignored, so the pool includes unhealthy servers.
+const PanicKey contextKey = "panic"
+
+// WithPanic marks the pools of the given address families ("ipv4" or
+// "ipv6") as in panic mode.
+func WithPanic(ctx context.Context, families ...string) context.Context {
+	return context.WithValue(ctx, PanicKey, families)
+}
+
+// IsPanic reports whether the context's pool of an address family is in
+// panic mode.
+func IsPanic(ctx context.Context, family string) bool {
+	families, _ := ctx.Value(PanicKey).([]string)
+	return slices.Contains(families, family)
+}
+
 // Server represents a backend server for routing decisions.
 type Server struct {
-	Address string
-	Port    int
-	Weight  int
-	Region  string
+	Address  string
+	Port     int
+	Weight   int
+	Region   string
+	Priority int               // Failover tier; lower values are preferred
+	Labels   map[string]string // Free-form labels matched by pipeline label stages
 }
 
 // ServerPool provides access to servers for routing decisions.
@@ -36,10 +82,26 @@ type Router interface {
 	// Returns ErrNoHealthyServers if the pool is empty.
 	Route(ctx context.Context, pool ServerPool) (*Server, error)
 
+	// Rank orders every server in the pool from most to least preferred.
+	// The first server is the one Route would select. Used for multi-value
+	// answers so clients can fail over locally.
+	// Returns ErrNoHealthyServers if the pool is empty.
+	Rank(ctx context.Context, pool ServerPool) ([]*Server, error)
+
 	// Algorithm returns the name of the routing algorithm.
 	Algorithm() string
 }
 
+// ECSScoper is implemented by routers whose decision depends on the client
+// address. Routers without it answer every client alike.
+type ECSScoper interface {
+	// ECSScope returns the prefix length of clientIP the decision was based
+	// on, returned to resolvers as the EDNS Client Subnet scope (RFC 7871) so
+	// they cache the answer for that network only. sourcePrefix is the
+	// prefix length of the client subnet sent by the resolver.
+	ECSScope(clientIP net.IP, sourcePrefix int) int
+}
+
 // SimpleServerPool is a basic implementation of ServerPool.
 type SimpleServerPool struct {
 	servers []*Server
@@ -54,3 +116,45 @@ func NewSimpleServerPool(servers []*Server) *SimpleServerPool {
 func (p *SimpleServerPool) Servers() []*Server {
 	return p.servers
 }
+
+// firstRanked returns the most preferred server of a ranking.
+// Routers implement Route in terms of Rank with this helper.
+func firstRanked(ranked []*Server, err error) (*Server, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(ranked) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+	return ranked[0], nil
+}
+
+// appendMissing appends the servers from rest that are not already in ranked,
+// preserving their order.
+func appendMissing(ranked []*Server, rest []*Server) []*Server {
+	seen := make(map[*Server]bool, len(ranked))
+	for _, s := range ranked {
+		seen[s] = true
+	}
+	for _, s := range rest {
+		if !seen[s] {
+			ranked = append(ranked, s)
+			seen[s] = true
+		}
+	}
+	return ranked
+}
+
+// WithWildcardLabel adds the labels matched by a wildcard domain to the context.
+func WithWildcardLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, WildcardLabelKey, label)
+}
+
+// GetWildcardLabel retrieves the labels matched by a wildcard domain from the
+// context. Returns "" when the query matched a domain exactly.
+func GetWildcardLabel(ctx context.Context) string {
+	if label, ok := ctx.Value(WildcardLabelKey).(string); ok {
+		return label
+	}
+	return ""
+}
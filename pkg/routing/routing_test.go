@@ -85,6 +85,8 @@ func TestNewRouter_ValidAlgorithms(t *testing.T) {
 		{"weight", AlgorithmWeighted},
 		{"failover", AlgorithmFailover},
 		{"active-standby", AlgorithmFailover},
+		{"consistent-hash", AlgorithmConsistentHash},
+		{"least-load", AlgorithmLeastLoad},
 	}
 
 	for _, tt := range tests {
@@ -119,3 +121,94 @@ func TestSimpleServerPool(t *testing.T) {
 		t.Errorf("expected 2 servers, got %d", len(result))
 	}
 }
+
+func TestRoundRobinRouter_Rank(t *testing.T) {
+	router := NewRoundRobinRouter()
+	servers := []*Server{
+		{Address: "10.0.0.1", Port: 80},
+		{Address: "10.0.0.2", Port: 80},
+		{Address: "10.0.0.3", Port: 80},
+	}
+	pool := NewSimpleServerPool(servers)
+
+	// Each ranking is the pool rotated by one more position
+	for i := 0; i < 6; i++ {
+		ranked, err := router.Rank(context.Background(), pool)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ranked) != len(servers) {
+			t.Fatalf("expected %d servers, got %d", len(servers), len(ranked))
+		}
+		for j, s := range ranked {
+			want := servers[(i+j)%len(servers)]
+			if s != want {
+				t.Errorf("rank %d position %d: expected %s, got %s", i, j, want.Address, s.Address)
+			}
+		}
+	}
+}
+
+func TestWeightedRouter_Rank(t *testing.T) {
+	router := NewWeightedRouter()
+	heavy := &Server{Address: "10.0.0.1", Port: 80, Weight: 1000}
+	light := &Server{Address: "10.0.0.2", Port: 80, Weight: 1}
+	pool := NewSimpleServerPool([]*Server{light, heavy})
+
+	heavyFirst := 0
+	for i := 0; i < 200; i++ {
+		ranked, err := router.Rank(context.Background(), pool)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ranked) != 2 || ranked[0] == ranked[1] {
+			t.Fatalf("expected each server exactly once, got %v", ranked)
+		}
+		if ranked[0] == heavy {
+			heavyFirst++
+		}
+	}
+	if heavyFirst < 180 {
+		t.Errorf("expected heavy server ranked first most of the time, got %d/200", heavyFirst)
+	}
+
+	if _, err := router.Rank(context.Background(), NewSimpleServerPool(nil)); err != ErrNoHealthyServers {
+		t.Errorf("expected ErrNoHealthyServers, got %v", err)
+	}
+}
+
+func TestWildcardLabelContext(t *testing.T) {
+	if label := GetWildcardLabel(context.Background()); label != "" {
+		t.Errorf("expected empty label, got %q", label)
+	}
+	ctx := WithWildcardLabel(context.Background(), "customer42")
+	if label := GetWildcardLabel(ctx); label != "customer42" {
+		t.Errorf("expected customer42, got %q", label)
+	}
+}
+
+func TestRoundRobinRouter_DryRun(t *testing.T) {
+	router := NewRoundRobinRouter()
+	pool := NewSimpleServerPool([]*Server{
+		{Address: "10.0.0.1", Port: 80},
+		{Address: "10.0.0.2", Port: 80},
+	})
+
+	// A dry run shows the next server without taking its turn
+	for i := 0; i < 3; i++ {
+		server, err := router.Route(WithDryRun(context.Background()), pool)
+		if err != nil || server.Address != "10.0.0.1" {
+			t.Fatalf("expected the dry run to show 10.0.0.1, got %v (%v)", server, err)
+		}
+	}
+	if !IsDryRun(WithDryRun(context.Background())) || IsDryRun(context.Background()) {
+		t.Error("expected only contexts from WithDryRun to be dry runs")
+	}
+
+	for _, want := range []string{"10.0.0.1", "10.0.0.2"} {
+		server, err := router.Route(context.Background(), pool)
+		if err != nil || server.Address != want {
+			t.Errorf("expected %s, got %v (%v)", want, server, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"math"
+	"net"
+)
+
+// StageTrafficSplit is the stage type of traffic splits in pipeline traces.
+const StageTrafficSplit = "traffic-split"
+
+// splitBuckets is the number of buckets client subnets are hashed into, so
+// shares have a resolution of 0.01%.
+const splitBuckets = 10000
+
+// SplitRule is a share of clients routed to the servers matching a label
+// selector.
+type SplitRule struct {
+	Match   map[string]string
+	Percent float64
+}
+
+// TrafficSplit divides a domain's clients between groups of servers by
+// label, such as a canary receiving 5% of clients. Clients are hashed by
+// their /24 (/56 for IPv6) subnet, so a client stays in its group across
+// queries and resolvers can cache the answer per subnet.
+type TrafficSplit struct {
+	rules  []SplitRule
+	bounds []uint64 // Cumulative upper bucket of each rule
+}
+
+// NewTrafficSplit creates a traffic split. Rules take consecutive shares in
+// order; clients outside every share are routed to the servers matching no
+// rule.
+func NewTrafficSplit(rules []SplitRule) *TrafficSplit {
+	s := &TrafficSplit{rules: rules, bounds: make([]uint64, len(rules))}
+	var bound uint64
+	for i, rule := range rules {
+		bound += uint64(math.Round(rule.Percent * splitBuckets / 100))
+		s.bounds[i] = bound
+	}
+	return s
+}
+
+// Filter returns the servers of the client's group, in pool order, and a
+// short description of the group. When the group has no server the whole
+// pool is returned, so a down canary does not fail its clients. The
+// decision is added to the context's pipeline trace as a traffic-split
+// stage.
+func (s *TrafficSplit) Filter(ctx context.Context, servers []*Server) ([]*Server, string) {
+	bucket := ringHash("traffic-split/"+clientSubnet(GetClientIP(ctx))) % splitBuckets
+
+	var kept []*Server
+	detail := "default"
+	if rule := s.rule(bucket); rule != nil {
+		detail = formatLabels(rule.Match)
+		for _, srv := range servers {
+			if matchLabels(srv.Labels, rule.Match) {
+				kept = append(kept, srv)
+			}
+		}
+	} else {
+		for _, srv := range servers {
+			if !s.matchesAny(srv) {
+				kept = append(kept, srv)
+			}
+		}
+	}
+	skipped := len(kept) == 0
+	if skipped {
+		kept = servers
+	}
+	if trace := getPipelineTrace(ctx); trace != nil {
+		trace.Stages = append(trace.Stages, StageTrace{
+			Stage:   StageTrafficSplit,
+			Detail:  detail,
+			Input:   servers,
+			Output:  kept,
+			Skipped: skipped,
+		})
+	}
+	return kept, detail
+}
+
+// rule returns the rule whose share holds bucket, or nil for the remainder.
+func (s *TrafficSplit) rule(bucket uint64) *SplitRule {
+	for i, bound := range s.bounds {
+		if bucket < bound {
+			return &s.rules[i]
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether a server matches any rule's selector.
+func (s *TrafficSplit) matchesAny(srv *Server) bool {
+	for _, rule := range s.rules {
+		if matchLabels(srv.Labels, rule.Match) {
+			return true
+		}
+	}
+	return false
+}
+
+// ECSScope implements ECSScoper: the group depends on the client's /24
+// (IPv4) or /56 (IPv6).
+func (s *TrafficSplit) ECSScope(clientIP net.IP, sourcePrefix int) int {
+	if clientIP.To4() != nil {
+		return consistentHashIPv4Prefix
+	}
+	return consistentHashIPv6Prefix
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package routing
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// splitServers returns two v1 servers and one v2 server.
+func splitServers() []*Server {
+	return []*Server{
+		{Address: "10.0.0.1", Port: 80, Weight: 100, Labels: map[string]string{"version": "v1"}},
+		{Address: "10.0.0.2", Port: 80, Weight: 100, Labels: map[string]string{"version": "v1"}},
+		{Address: "10.0.0.3", Port: 80, Weight: 100, Labels: map[string]string{"version": "v2"}},
+	}
+}
+
+func TestTrafficSplit_Share(t *testing.T) {
+	split := NewTrafficSplit([]SplitRule{{Match: map[string]string{"version": "v2"}, Percent: 10}})
+	servers := splitServers()
+
+	canary := 0
+	const clients = 10000
+	for i := 0; i < clients; i++ {
+		ctx := WithClientIP(context.Background(), net.IPv4(100, byte(i/256), byte(i%256), 7))
+		kept, _ := split.Filter(ctx, servers)
+		switch {
+		case len(kept) == 1 && kept[0].Address == "10.0.0.3":
+			canary++
+		case len(kept) == 2 && kept[0].Address == "10.0.0.1" && kept[1].Address == "10.0.0.2":
+		default:
+			t.Fatalf("client %d: unexpected group %v", i, kept)
+		}
+	}
+	if canary < clients*8/100 || canary > clients*12/100 {
+		t.Errorf("expected about 10%% of clients on the canary, got %d of %d", canary, clients)
+	}
+}
+
+func TestTrafficSplit_StickyPerSubnet(t *testing.T) {
+	split := NewTrafficSplit([]SplitRule{{Match: map[string]string{"version": "v2"}, Percent: 50}})
+	servers := splitServers()
+
+	for i := 0; i < 100; i++ {
+		first, _ := split.Filter(WithClientIP(context.Background(), net.IPv4(100, 64, byte(i), 1)), servers)
+		for host := 2; host < 255; host += 50 {
+			again, _ := split.Filter(WithClientIP(context.Background(), net.IPv4(100, 64, byte(i), byte(host))), servers)
+			if len(again) != len(first) || again[0] != first[0] {
+				t.Fatalf("subnet 100.64.%d.0/24 moved between groups", i)
+			}
+		}
+	}
+}
+
+func TestTrafficSplit_EmptyGroupUsesAll(t *testing.T) {
+	split := NewTrafficSplit([]SplitRule{{Match: map[string]string{"version": "v3"}, Percent: 100}})
+	servers := splitServers()
+
+	trace := &PipelineTrace{}
+	ctx := WithPipelineTrace(WithClientIP(context.Background(), net.ParseIP("192.0.2.1")), trace)
+	kept, detail := split.Filter(ctx, servers)
+	if len(kept) != len(servers) || detail != "version=v3" {
+		t.Errorf("expected every server when the group is empty, got %d (%s)", len(kept), detail)
+	}
+	if len(trace.Stages) != 1 || trace.Stages[0].Stage != StageTrafficSplit || !trace.Stages[0].Skipped {
+		t.Errorf("expected a skipped traffic-split stage in the trace, got %+v", trace.Stages)
+	}
+}
+
+func TestTrafficSplit_ECSScope(t *testing.T) {
+	split := NewTrafficSplit(nil)
+	if got := split.ECSScope(net.ParseIP("192.0.2.1"), 32); got != 24 {
+		t.Errorf("expected IPv4 scope 24, got %d", got)
+	}
+	if got := split.ECSScope(net.ParseIP("2001:db8::1"), 64); got != 56 {
+		t.Errorf("expected IPv6 scope 56, got %d", got)
+	}
+}
@@ -34,40 +34,66 @@ func (r *WeightedRouter) Route(ctx context.Context, pool ServerPool) (*Server, e
 		return nil, ErrNoHealthyServers
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return servers[r.pick(servers)], nil
+}
+
+// Rank orders servers by repeated weighted random selection without
+// replacement. The first server has the same distribution as Route, and
+// heavier servers tend to appear earlier in the list.
+func (r *WeightedRouter) Rank(ctx context.Context, pool ServerPool) ([]*Server, error) {
+	servers := pool.Servers()
+	if len(servers) == 0 {
+		return nil, ErrNoHealthyServers
+	}
+
+	remaining := make([]*Server, len(servers))
+	copy(remaining, servers)
+	ranked := make([]*Server, 0, len(servers))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(remaining) > 0 {
+		i := r.pick(remaining)
+		ranked = append(ranked, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+
+	return ranked, nil
+}
+
+// pick returns the index of a server chosen with probability proportional
+// to its weight. Caller must hold r.mu and pass a non-empty slice.
+func (r *WeightedRouter) pick(servers []*Server) int {
 	// Calculate total weight
 	totalWeight := 0
 	for _, s := range servers {
-		weight := s.Weight
-		if weight <= 0 {
-			weight = 1 // Default weight
-		}
-		totalWeight += weight
-	}
-
-	if totalWeight == 0 {
-		return nil, ErrNoHealthyServers
+		totalWeight += effectiveWeight(s)
 	}
 
 	// Select random point in weight space
-	r.mu.Lock()
 	point := r.rand.Intn(totalWeight)
-	r.mu.Unlock()
 
 	// Find the server at that point
 	cumulative := 0
-	for _, s := range servers {
-		weight := s.Weight
-		if weight <= 0 {
-			weight = 1
-		}
-		cumulative += weight
+	for i, s := range servers {
+		cumulative += effectiveWeight(s)
 		if point < cumulative {
-			return s, nil
+			return i
 		}
 	}
 
 	// Fallback (shouldn't happen)
-	return servers[len(servers)-1], nil
+	return len(servers) - 1
+}
+
+// effectiveWeight returns the server weight, treating non-positive weights as 1.
+func effectiveWeight(s *Server) int {
+	if s.Weight <= 0 {
+		return 1 // Default weight
+	}
+	return s.Weight
 }
 
 // Algorithm returns the algorithm name.
@@ -0,0 +1,206 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds how far Prev and Next look for a matching time. Eight
+// years covers every expression that matches at all, such as February 29.
+const searchLimit = 8 * 366 * 24 * time.Hour
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. Fields accept *, numbers, ranges (1-5), steps
+// (*/15, 0-30/10) and comma-separated lists; months and days of week also
+// accept three-letter names (jan, mon). Day of week 0 and 7 are Sunday. As
+// in Vixie cron, when both day fields are restricted a day matching either
+// one matches.
+type Cron struct {
+	expr   string
+	minute [60]bool
+	hour   [24]bool
+	dom    [32]bool
+	month  [13]bool
+	dow    [7]bool
+	anyDOM bool
+	anyDOW bool
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dowNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// ParseCron parses a five-field cron expression.
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+
+	c := &Cron{expr: expr}
+	var dow [8]bool
+	for _, f := range []struct {
+		name     string
+		field    string
+		min, max int
+		names    map[string]int
+		set      []bool
+	}{
+		{"minute", fields[0], 0, 59, nil, c.minute[:]},
+		{"hour", fields[1], 0, 23, nil, c.hour[:]},
+		{"day of month", fields[2], 1, 31, nil, c.dom[:]},
+		{"month", fields[3], 1, 12, monthNames, c.month[:]},
+		{"day of week", fields[4], 0, 7, dowNames, dow[:]},
+	} {
+		if err := parseField(f.field, f.min, f.max, f.names, f.set); err != nil {
+			return nil, fmt.Errorf("cron expression %q: %s: %w", expr, f.name, err)
+		}
+	}
+	copy(c.dow[:], dow[:7])
+	c.dow[0] = c.dow[0] || dow[7]
+	c.anyDOM = fields[2] == "*"
+	c.anyDOW = fields[4] == "*"
+	return c, nil
+}
+
+// parseField sets the values a cron field matches in set.
+func parseField(field string, min, max int, names map[string]int, set []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(loPart, min, max, names); err != nil {
+				return err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(hiPart, min, max, names); err != nil {
+					return err
+				}
+				if hi < lo {
+					return fmt.Errorf("invalid range %q", rangePart)
+				}
+			} else if hasStep {
+				// 5/15 means every 15 starting at 5
+				hi = max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+// parseValue parses a number or name within [min, max].
+func parseValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+// String returns the expression the Cron was parsed from.
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Matches reports whether t, to the minute, matches the expression.
+func (c *Cron) Matches(t time.Time) bool {
+	return c.month[t.Month()] && c.dayMatches(t) && c.hour[t.Hour()] && c.minute[t.Minute()]
+}
+
+// dayMatches reports whether the day of t matches the day fields.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom, dow := c.dom[t.Day()], c.dow[t.Weekday()]
+	switch {
+	case c.anyDOM && c.anyDOW:
+		return true
+	case c.anyDOM:
+		return dow
+	case c.anyDOW:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Prev returns the latest matching minute at or before t, in t's location.
+// It reports false when nothing matches within the search limit.
+func (c *Cron) Prev(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	limit := t.Add(-searchLimit)
+	t = t.Truncate(time.Minute)
+	for !t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !c.month[m]:
+			t = time.Date(y, m, 1, 0, 0, 0, 0, loc).Add(-time.Minute)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d, 0, 0, 0, 0, loc).Add(-time.Minute)
+		case !c.hour[t.Hour()]:
+			t = time.Date(y, m, d, t.Hour(), 0, 0, 0, loc).Add(-time.Minute)
+		case !c.minute[t.Minute()]:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Next returns the earliest matching minute after t, in t's location. It
+// reports false when nothing matches within the search limit.
+func (c *Cron) Next(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	limit := t.Add(searchLimit)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !c.month[m]:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !c.hour[t.Hour()]:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !c.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
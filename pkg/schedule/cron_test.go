@@ -0,0 +1,85 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): expected error", expr)
+		}
+	}
+}
+
+func TestCron_NextAndPrev(t *testing.T) {
+	// Wednesday
+	at := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		prev time.Time
+		next time.Time
+	}{
+		{"0 8 * * *", time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC), time.Date(2025, 1, 16, 8, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC), time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 1 * fri", time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) failed: %v", tt.expr, err)
+		}
+		if prev, ok := c.Prev(at); !ok || !prev.Equal(tt.prev) {
+			t.Errorf("%q: Prev = %v, %v; want %v", tt.expr, prev, ok, tt.prev)
+		}
+		if next, ok := c.Next(at); !ok || !next.Equal(tt.next) {
+			t.Errorf("%q: Next = %v, %v; want %v", tt.expr, next, ok, tt.next)
+		}
+	}
+}
+
+func TestCron_Timezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation failed: %v", err)
+	}
+	c, _ := ParseCron("0 9 * * *")
+
+	// 09:00 in Tokyo is 00:00 UTC
+	next, ok := c.Next(time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC).In(loc))
+	if want := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC); !ok || !next.Equal(want) {
+		t.Errorf("Next = %v, want %v", next, want)
+	}
+}
+
+func TestCron_Never(t *testing.T) {
+	c, _ := ParseCron("0 0 31 feb *")
+	if _, ok := c.Next(time.Now()); ok {
+		t.Error("expected no match for February 31")
+	}
+}
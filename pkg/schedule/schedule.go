@@ -0,0 +1,179 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+// Package schedule applies weight and region-preference profiles to domains
+// on cron schedules, for example to follow the sun across regions.
+//
+// Each profile of a schedule starts at the times its cron expression
+// matches, in the schedule's timezone, and stays active until another
+// profile of the schedule starts.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	// Timezones must resolve on hosts without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// Sources of schedules.
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+// Weight limits of profiles, matching server weights.
+const (
+	minWeight = 1
+	maxWeight = 1000
+)
+
+// Schedule switches a domain between profiles.
+type Schedule struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	// Timezone is the IANA timezone profile start times are in.
+	// Default: UTC
+	Timezone string    `json:"timezone,omitempty"`
+	Profiles []Profile `json:"profiles"`
+	// Source is config for schedules from the configuration file, which
+	// cannot be changed through the API, or api.
+	Source string `json:"source,omitempty"`
+}
+
+// Profile is a set of weight and region-preference changes applied to a
+// domain while the profile is active.
+type Profile struct {
+	Name string `json:"name"`
+	// Start is the cron expression of the times the profile becomes active.
+	Start string `json:"start"`
+	// RegionWeights sets the weight of every server of the domain in a
+	// region.
+	RegionWeights map[string]int `json:"region_weights,omitempty"`
+	// ServerWeights sets the weight of servers by address, overriding
+	// RegionWeights.
+	ServerWeights map[string]int `json:"server_weights,omitempty"`
+	// RegionPreference orders the domain's regions, most preferred first.
+	// Servers get the failover priority of their region's position;
+	// regions not listed come last.
+	RegionPreference []string `json:"region_preference,omitempty"`
+}
+
+// Active is the profile of a schedule active at a point in time.
+type Active struct {
+	Schedule   string    `json:"schedule"`
+	Domain     string    `json:"domain"`
+	Profile    *Profile  `json:"profile"`
+	Since      time.Time `json:"since"`
+	NextSwitch time.Time `json:"next_switch,omitempty"` // Zero when no other profile starts
+	NextName   string    `json:"next_profile,omitempty"`
+
+	source string // Source of the schedule
+}
+
+// Validate checks the schedule's timezone, profiles and cron expressions.
+func (s *Schedule) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if s.Domain == "" {
+		return errors.New("domain is required")
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	if len(s.Profiles) == 0 {
+		return errors.New("at least one profile is required")
+	}
+
+	names := make(map[string]bool, len(s.Profiles))
+	for i, p := range s.Profiles {
+		prefix := fmt.Sprintf("profiles[%d]", i)
+		if p.Name == "" {
+			return fmt.Errorf("%s.name is required", prefix)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("%s.name %q is duplicated", prefix, p.Name)
+		}
+		names[p.Name] = true
+		if _, err := ParseCron(p.Start); err != nil {
+			return fmt.Errorf("%s.start: %w", prefix, err)
+		}
+		for region, w := range p.RegionWeights {
+			if w < minWeight || w > maxWeight {
+				return fmt.Errorf("%s.region_weights[%s] must be between %d and %d", prefix, region, minWeight, maxWeight)
+			}
+		}
+		for addr, w := range p.ServerWeights {
+			if net.ParseIP(addr) == nil {
+				return fmt.Errorf("%s.server_weights: %q is not an IP address", prefix, addr)
+			}
+			if w < minWeight || w > maxWeight {
+				return fmt.Errorf("%s.server_weights[%s] must be between %d and %d", prefix, addr, minWeight, maxWeight)
+			}
+		}
+	}
+	return nil
+}
+
+// location returns the schedule's timezone.
+func (s *Schedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
+
+// ActiveAt returns the profile active at t: the one that started most
+// recently. It reports false for invalid schedules and when no profile has
+// started within the last eight years.
+func (s *Schedule) ActiveAt(t time.Time) (Active, bool) {
+	loc, err := s.location()
+	if err != nil {
+		return Active{}, false
+	}
+	t = t.In(loc)
+
+	active := Active{Schedule: s.Name, Domain: s.Domain}
+	for i := range s.Profiles {
+		cron, err := ParseCron(s.Profiles[i].Start)
+		if err != nil {
+			return Active{}, false
+		}
+		if since, ok := cron.Prev(t); ok && since.After(active.Since) {
+			active.Profile = &s.Profiles[i]
+			active.Since = since
+		}
+	}
+	if active.Profile == nil {
+		return Active{}, false
+	}
+
+	// The next start of another profile
+	for i := range s.Profiles {
+		p := &s.Profiles[i]
+		if p == active.Profile {
+			continue
+		}
+		cron, _ := ParseCron(p.Start)
+		if next, ok := cron.Next(t); ok && (active.NextSwitch.IsZero() || next.Before(active.NextSwitch)) {
+			active.NextSwitch = next
+			active.NextName = p.Name
+		}
+	}
+	active.Since = active.Since.UTC()
+	if !active.NextSwitch.IsZero() {
+		active.NextSwitch = active.NextSwitch.UTC()
+	}
+	return active, true
+}
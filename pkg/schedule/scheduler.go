@@ -0,0 +1,372 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/store"
+)
+
+// KeyPrefix is the prefix for schedules created through the API in the KV
+// store.
+const KeyPrefix = "schedules/"
+
+// Errors returned by Scheduler.
+var (
+	// ErrNotFound is returned for unknown schedules.
+	ErrNotFound = errors.New("schedule not found")
+	// ErrReadOnly is returned when changing a schedule from the
+	// configuration file.
+	ErrReadOnly = errors.New("schedule is defined in the configuration file")
+	// ErrConflict is returned when another schedule targets the domain.
+	ErrConflict = errors.New("domain already has a schedule")
+)
+
+// Switch reports a domain changing profile.
+type Switch struct {
+	Schedule string
+	Domain   string
+	From     string // Empty when no profile was active
+	To       string // Empty when the schedule was removed
+	At       time.Time
+}
+
+// Config contains configuration for the Scheduler.
+type Config struct {
+	// Store persists schedules created through the API; nil keeps them in
+	// memory only.
+	Store store.Store
+
+	// Apply is called with the active profile of a domain when it changes,
+	// and with nil when the domain no longer has a schedule.
+	Apply func(domain string, profile *Profile)
+
+	// OnSwitch is called when a domain changes profile.
+	OnSwitch func(Switch)
+
+	Logger *slog.Logger
+}
+
+// Scheduler keeps the active profile of each scheduled domain applied. It
+// re-evaluates the schedules every minute and whenever they change.
+type Scheduler struct {
+	store    store.Store
+	apply    func(string, *Profile)
+	onSwitch func(Switch)
+	logger   *slog.Logger
+	now      func() time.Time
+
+	// applyMu serializes Evaluate so profiles are applied in the order they
+	// are computed; it is taken before mu
+	applyMu   sync.Mutex
+	mu        sync.Mutex
+	schedules map[string]*Schedule // By name
+	active    map[string]Active    // By domain
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// New creates a new Scheduler.
+func New(cfg Config) *Scheduler {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Scheduler{
+		store:     cfg.Store,
+		apply:     cfg.Apply,
+		onSwitch:  cfg.OnSwitch,
+		logger:    logger,
+		now:       time.Now,
+		schedules: make(map[string]*Schedule),
+		active:    make(map[string]Active),
+	}
+}
+
+// Load reads the schedules created through the API from the KV store.
+func (s *Scheduler) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	pairs, err := s.store.List(ctx, KeyPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	s.mu.Lock()
+	for _, pair := range pairs {
+		var sched Schedule
+		if err := json.Unmarshal(pair.Value, &sched); err != nil {
+			s.logger.Warn("skipping invalid schedule", "key", pair.Key, "error", err)
+			continue
+		}
+		if err := sched.Validate(); err != nil {
+			s.logger.Warn("skipping invalid schedule", "key", pair.Key, "error", err)
+			continue
+		}
+		sched.Source = SourceAPI
+		s.schedules[sched.Name] = &sched
+	}
+	s.mu.Unlock()
+
+	s.Evaluate()
+	return nil
+}
+
+// SetConfigSchedules replaces the schedules from the configuration file.
+// Schedules created through the API for the same name or domain are
+// shadowed until the configuration no longer defines them.
+func (s *Scheduler) SetConfigSchedules(schedules []Schedule) error {
+	for i := range schedules {
+		if err := schedules[i].Validate(); err != nil {
+			return fmt.Errorf("schedule %q: %w", schedules[i].Name, err)
+		}
+	}
+
+	s.mu.Lock()
+	for name, sched := range s.schedules {
+		if sched.Source == SourceConfig {
+			delete(s.schedules, name)
+		}
+	}
+	for i := range schedules {
+		sched := schedules[i]
+		sched.Source = SourceConfig
+		s.schedules[sched.Name] = &sched
+	}
+	s.mu.Unlock()
+
+	s.Evaluate()
+	return nil
+}
+
+// List returns every schedule, sorted by name.
+func (s *Scheduler) List() []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		result = append(result, *sched)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Get returns a schedule by name.
+func (s *Scheduler) Get(name string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, ok := s.schedules[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	result := *sched
+	return &result, nil
+}
+
+// Put creates or replaces a schedule through the API and applies it. The
+// schedule is persisted before it takes effect, so a failed write leaves the
+// previous schedule in place.
+func (s *Scheduler) Put(ctx context.Context, sched Schedule) error {
+	if err := sched.Validate(); err != nil {
+		return err
+	}
+	sched.Source = SourceAPI
+
+	s.mu.Lock()
+	if existing, ok := s.schedules[sched.Name]; ok && existing.Source == SourceConfig {
+		s.mu.Unlock()
+		return ErrReadOnly
+	}
+	for name, other := range s.schedules {
+		if name != sched.Name && other.Domain == sched.Domain {
+			s.mu.Unlock()
+			return fmt.Errorf("%w: %s", ErrConflict, name)
+		}
+	}
+	if s.store != nil {
+		data, err := json.Marshal(sched)
+		if err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("failed to marshal schedule: %w", err)
+		}
+		if err := s.store.Set(ctx, KeyPrefix+sched.Name, data); err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("failed to persist schedule: %w", err)
+		}
+	}
+	s.schedules[sched.Name] = &sched
+	s.mu.Unlock()
+
+	s.Evaluate()
+	return nil
+}
+
+// Delete removes a schedule created through the API. Its domain returns to
+// the configured weights. A failed delete from the store leaves the schedule
+// in place.
+func (s *Scheduler) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	sched, ok := s.schedules[name]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return ErrNotFound
+	case sched.Source == SourceConfig:
+		s.mu.Unlock()
+		return ErrReadOnly
+	}
+	if s.store != nil {
+		if err := s.store.Delete(ctx, KeyPrefix+name); err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("failed to delete schedule: %w", err)
+		}
+	}
+	delete(s.schedules, name)
+	s.mu.Unlock()
+
+	s.Evaluate()
+	return nil
+}
+
+// Preview returns the profile of a schedule active at t.
+func (s *Scheduler) Preview(name string, t time.Time) (Active, error) {
+	sched, err := s.Get(name)
+	if err != nil {
+		return Active{}, err
+	}
+	active, ok := sched.ActiveAt(t)
+	if !ok {
+		return Active{}, fmt.Errorf("no profile of schedule %q is active at %s", name, t.Format(time.RFC3339))
+	}
+	return active, nil
+}
+
+// Active returns the active profile of a domain.
+func (s *Scheduler) Active(domain string) (Active, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active, ok := s.active[domain]
+	return active, ok
+}
+
+// Evaluate applies the profile active now to every scheduled domain whose
+// profile changed, and clears the profiles of domains no longer scheduled.
+func (s *Scheduler) Evaluate() {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	now := s.now()
+
+	s.mu.Lock()
+	current := make(map[string]Active)
+	for _, sched := range s.schedules {
+		// A configured schedule shadows API schedules of its domain
+		if prev, ok := current[sched.Domain]; ok && (prev.source == SourceConfig || sched.Source != SourceConfig) {
+			continue
+		}
+		if active, ok := sched.ActiveAt(now); ok {
+			active.source = sched.Source
+			current[sched.Domain] = active
+		}
+	}
+
+	var changes []Switch
+	var applied []Active
+	var cleared []string
+	for domain, a := range current {
+		prev, ok := s.active[domain]
+		s.active[domain] = a
+		if ok && prev.Schedule == a.Schedule && sameProfile(prev.Profile, a.Profile) {
+			continue
+		}
+		applied = append(applied, a)
+		if !ok || prev.Schedule != a.Schedule || prev.Profile.Name != a.Profile.Name {
+			from := ""
+			if ok {
+				from = prev.Profile.Name
+			}
+			changes = append(changes, Switch{Schedule: a.Schedule, Domain: domain, From: from, To: a.Profile.Name, At: now})
+		}
+	}
+	for domain, prev := range s.active {
+		if _, ok := current[domain]; !ok {
+			delete(s.active, domain)
+			cleared = append(cleared, domain)
+			changes = append(changes, Switch{Schedule: prev.Schedule, Domain: domain, From: prev.Profile.Name, At: now})
+		}
+	}
+	s.mu.Unlock()
+
+	if s.apply != nil {
+		for _, a := range applied {
+			s.apply(a.Domain, a.Profile)
+		}
+		for _, domain := range cleared {
+			s.apply(domain, nil)
+		}
+	}
+	for _, change := range changes {
+		s.logger.Info("schedule profile switched",
+			"schedule", change.Schedule,
+			"domain", change.Domain,
+			"from", change.From,
+			"to", change.To,
+		)
+		if s.onSwitch != nil {
+			s.onSwitch(change)
+		}
+	}
+}
+
+// sameProfile reports whether two profiles apply the same changes.
+func sameProfile(a, b *Profile) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// Start evaluates the schedules at the start of every minute until Stop is
+// called.
+func (s *Scheduler) Start() {
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	go func() {
+		defer close(s.doneCh)
+		for {
+			now := s.now()
+			timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+			select {
+			case <-s.stopCh:
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.Evaluate()
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler started by Start.
+func (s *Scheduler) Stop() {
+	if s.stopCh == nil {
+		return
+	}
+	close(s.stopCh)
+	<-s.doneCh
+	s.stopCh = nil
+}
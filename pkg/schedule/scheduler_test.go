@@ -0,0 +1,292 @@
+// Copyright (C) 2025 Logan Ross
+//
+// This file is part of OpenGSLB – https://opengslb.org
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-OpenGSLB-Commercial
+
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loganrossus/OpenGSLB/pkg/store"
+)
+
+// followTheSun returns a schedule preferring Europe from 08:00 and the
+// Americas from 16:00 in London.
+func followTheSun(name, domain string) Schedule {
+	return Schedule{
+		Name:     name,
+		Domain:   domain,
+		Timezone: "Europe/London",
+		Profiles: []Profile{
+			{Name: "emea", Start: "0 8 * * *", RegionWeights: map[string]int{"eu-west": 100, "us-east": 10}},
+			{Name: "americas", Start: "0 16 * * *", RegionWeights: map[string]int{"eu-west": 10, "us-east": 100}},
+		},
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Schedule)
+	}{
+		{"missing domain", func(s *Schedule) { s.Domain = "" }},
+		{"invalid timezone", func(s *Schedule) { s.Timezone = "Mars/Olympus" }},
+		{"no profiles", func(s *Schedule) { s.Profiles = nil }},
+		{"duplicate profile", func(s *Schedule) { s.Profiles[1].Name = "emea" }},
+		{"invalid cron", func(s *Schedule) { s.Profiles[0].Start = "0 25 * * *" }},
+		{"weight out of range", func(s *Schedule) { s.Profiles[0].RegionWeights["eu-west"] = 0 }},
+		{"invalid server address", func(s *Schedule) { s.Profiles[0].ServerWeights = map[string]int{"web1": 10} }},
+	}
+
+	valid := followTheSun("sun", "app.example.com")
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid schedule, got %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := followTheSun("sun", "app.example.com")
+			tt.modify(&s)
+			if err := s.Validate(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSchedule_ActiveAt(t *testing.T) {
+	s := followTheSun("sun", "app.example.com")
+
+	// 02:00 UTC in January is 02:00 in London: the Americas profile from
+	// 16:00 the day before is active until 08:00
+	active, ok := s.ActiveAt(time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC))
+	if !ok {
+		t.Fatal("expected an active profile")
+	}
+	if active.Profile.Name != "americas" {
+		t.Errorf("expected americas, got %s", active.Profile.Name)
+	}
+	if want := time.Date(2025, 1, 14, 16, 0, 0, 0, time.UTC); !active.Since.Equal(want) {
+		t.Errorf("Since = %v, want %v", active.Since, want)
+	}
+	if want := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC); !active.NextSwitch.Equal(want) || active.NextName != "emea" {
+		t.Errorf("next switch = %v to %s, want %v to emea", active.NextSwitch, active.NextName, want)
+	}
+
+	// British Summer Time: 08:00 in London is 07:00 UTC
+	active, _ = s.ActiveAt(time.Date(2025, 7, 15, 7, 30, 0, 0, time.UTC))
+	if active.Profile.Name != "emea" {
+		t.Errorf("expected emea during summer time, got %s", active.Profile.Name)
+	}
+}
+
+func TestScheduler_SwitchesProfiles(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	applied := make(map[string]string)
+	var switches []Switch
+
+	s := New(Config{
+		Apply: func(domain string, p *Profile) {
+			if p == nil {
+				delete(applied, domain)
+				return
+			}
+			applied[domain] = p.Name
+		},
+		OnSwitch: func(sw Switch) { switches = append(switches, sw) },
+	})
+	s.now = func() time.Time { return now }
+
+	if err := s.SetConfigSchedules([]Schedule{followTheSun("sun", "app.example.com")}); err != nil {
+		t.Fatalf("SetConfigSchedules failed: %v", err)
+	}
+	if applied["app.example.com"] != "emea" || len(switches) != 1 || switches[0].From != "" {
+		t.Fatalf("expected emea applied once, got %v, %+v", applied, switches)
+	}
+
+	// No switch within the profile
+	now = now.Add(time.Hour)
+	s.Evaluate()
+	if len(switches) != 1 {
+		t.Errorf("expected no switch, got %+v", switches)
+	}
+
+	now = time.Date(2025, 1, 15, 16, 0, 0, 0, time.UTC)
+	s.Evaluate()
+	if applied["app.example.com"] != "americas" || len(switches) != 2 || switches[1].From != "emea" {
+		t.Errorf("expected switch to americas, got %v, %+v", applied, switches)
+	}
+	if active, ok := s.Active("app.example.com"); !ok || active.Profile.Name != "americas" {
+		t.Errorf("expected americas active, got %+v", active)
+	}
+
+	// Removing the schedule restores the configured weights
+	if err := s.SetConfigSchedules(nil); err != nil {
+		t.Fatalf("SetConfigSchedules failed: %v", err)
+	}
+	if _, ok := applied["app.example.com"]; ok || switches[2].To != "" {
+		t.Errorf("expected profile cleared, got %v, %+v", applied, switches)
+	}
+}
+
+func TestScheduler_APISchedules(t *testing.T) {
+	kv, err := store.NewBboltStore(filepath.Join(t.TempDir(), "schedules.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer kv.Close()
+
+	ctx := context.Background()
+	s := New(Config{Store: kv})
+	if err := s.SetConfigSchedules([]Schedule{followTheSun("config", "app.example.com")}); err != nil {
+		t.Fatalf("SetConfigSchedules failed: %v", err)
+	}
+
+	if err := s.Put(ctx, followTheSun("config", "other.example.com")); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if err := s.Delete(ctx, "config"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if err := s.Put(ctx, followTheSun("api", "app.example.com")); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if err := s.Put(ctx, followTheSun("api", "other.example.com")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// API schedules survive a restart
+	reloaded := New(Config{Store: kv})
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	sched, err := reloaded.Get("api")
+	if err != nil || sched.Source != SourceAPI || sched.Domain != "other.example.com" {
+		t.Errorf("expected stored api schedule, got %+v, %v", sched, err)
+	}
+
+	preview, err := reloaded.Preview("api", time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC))
+	if err != nil || preview.Profile.Name != "americas" {
+		t.Errorf("expected americas preview, got %+v, %v", preview, err)
+	}
+
+	if err := reloaded.Delete(ctx, "api"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := reloaded.Get("api"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// failingStore fails writes while fail is set.
+type failingStore struct {
+	store.Store
+	fail bool
+}
+
+func (f *failingStore) Set(ctx context.Context, key string, value []byte) error {
+	if f.fail {
+		return errors.New("disk full")
+	}
+	return f.Store.Set(ctx, key, value)
+}
+
+func (f *failingStore) Delete(ctx context.Context, key string) error {
+	if f.fail {
+		return errors.New("disk full")
+	}
+	return f.Store.Delete(ctx, key)
+}
+
+func TestScheduler_PersistFailure(t *testing.T) {
+	kv, err := store.NewBboltStore(filepath.Join(t.TempDir(), "schedules.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer kv.Close()
+
+	ctx := context.Background()
+	fs := &failingStore{Store: kv, fail: true}
+	s := New(Config{Store: fs})
+
+	// A schedule that cannot be persisted does not take effect
+	if err := s.Put(ctx, followTheSun("api", "app.example.com")); err == nil {
+		t.Fatal("expected Put to fail")
+	}
+	if _, err := s.Get("api"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected no schedule after a failed Put, got %v", err)
+	}
+
+	// A failed replacement keeps the previous schedule
+	fs.fail = false
+	if err := s.Put(ctx, followTheSun("api", "app.example.com")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	fs.fail = true
+	if err := s.Put(ctx, followTheSun("api", "other.example.com")); err == nil {
+		t.Fatal("expected Put to fail")
+	}
+	if sched, err := s.Get("api"); err != nil || sched.Domain != "app.example.com" {
+		t.Errorf("expected the previous schedule, got %+v, %v", sched, err)
+	}
+
+	// A schedule that cannot be deleted from the store stays
+	if err := s.Delete(ctx, "api"); err == nil {
+		t.Fatal("expected Delete to fail")
+	}
+	if _, err := s.Get("api"); err != nil {
+		t.Errorf("expected the schedule to remain, got %v", err)
+	}
+}
+
+func TestScheduler_AppliesInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var applied []*Profile
+	started := make(chan struct{})
+	release := make(chan struct{})
+	first := true
+	s := New(Config{Apply: func(domain string, p *Profile) {
+		mu.Lock()
+		block := first
+		first = false
+		mu.Unlock()
+		if block {
+			close(started)
+			<-release
+		}
+		mu.Lock()
+		applied = append(applied, p)
+		mu.Unlock()
+	}})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := s.Put(ctx, followTheSun("api", "app.example.com")); err != nil {
+			t.Errorf("Put failed: %v", err)
+		}
+	}()
+	<-started
+	// The delete's profile reset waits for the put's profile to be applied
+	go func() {
+		defer wg.Done()
+		if err := s.Delete(ctx, "api"); err != nil {
+			t.Errorf("Delete failed: %v", err)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if len(applied) != 2 || applied[0] == nil || applied[1] != nil {
+		t.Errorf("expected the profile then its reset, got %+v", applied)
+	}
+}